@@ -63,6 +63,9 @@ func main() {
 		log.Fatal(ctx, err)
 	}
 	cfg.Dump(os.Stderr)
+	if len(cfg.BuildContexts) > 0 {
+		internal.BuildContexts = cfg.BuildContexts
+	}
 	if cfg.UseProfiler {
 		if err := profiler.Start(profiler.Config{}); err != nil {
 			log.Fatalf(ctx, "profiler.Start: %v", err)
@@ -164,6 +167,8 @@ func main() {
 		Reporter:          reporter,
 		VulndbClient:      vc,
 		DepsDevHTTPClient: &http.Client{Transport: new(ochttp.Transport)},
+		// Only the module contents are read, so no source client is needed.
+		SourceGetter: fetch.NewProxyModuleGetter(proxyClient, nil),
 	})
 	if err != nil {
 		log.Fatalf(ctx, "frontend.NewServer: %v", err)
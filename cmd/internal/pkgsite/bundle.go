@@ -0,0 +1,71 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pkgsite
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/pkgsite/internal/derrors"
+	"golang.org/x/pkgsite/internal/docbundle"
+	"golang.org/x/pkgsite/internal/log"
+	"golang.org/x/pkgsite/internal/stdlib"
+)
+
+// Bundle writes the documentation of the latest version of every module that
+// a server built with serverCfg would serve, other than the standard library,
+// to file, for reading offline. If the name of the file ends in ".html", it
+// is a single HTML file. Otherwise it is a docset for Dash and Zeal in a
+// gzipped tar file, named after the file.
+func Bundle(ctx context.Context, serverCfg ServerConfig, file string) (err error) {
+	defer derrors.Wrap(&err, "Bundle(%q)", file)
+
+	serverCfg.Watch = false
+	_, lds, err := buildServer(ctx, serverCfg)
+	if err != nil {
+		return err
+	}
+	mvs, err := lds.ListModules(ctx)
+	if err != nil {
+		return err
+	}
+	var pkgs []*docbundle.Package
+	for i, mv := range mvs {
+		// ListModules lists the latest version of each module first.
+		if mv.Path == stdlib.ModulePath || (i > 0 && mvs[i-1].Path == mv.Path) {
+			continue
+		}
+		mpkgs, err := docbundle.Load(ctx, lds, mv.Path, mv.Version)
+		if err != nil {
+			log.Errorf(ctx, "bundle: skipping %s: %v", mv, err)
+			continue
+		}
+		pkgs = append(pkgs, mpkgs...)
+	}
+
+	f, err := os.Create(file)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+	}()
+	name := filepath.Base(file)
+	if strings.HasSuffix(name, ".html") {
+		err = docbundle.WriteHTML(f, strings.TrimSuffix(name, ".html"), pkgs)
+	} else {
+		name = strings.TrimSuffix(strings.TrimSuffix(name, ".tgz"), ".docset")
+		err = docbundle.WriteDocset(f, name, pkgs)
+	}
+	if err != nil {
+		return err
+	}
+	log.Infof(ctx, "wrote the documentation of %d packages to %s", len(pkgs), file)
+	return nil
+}
@@ -0,0 +1,66 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pkgsite
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/pkgsite/internal/testenv"
+	"golang.org/x/pkgsite/internal/testing/testhelper"
+)
+
+func TestBundle(t *testing.T) {
+	testenv.MustHaveExecPath(t, "go") // for local modules
+
+	localModule, _ := testhelper.WriteTxtarToTempDir(t, `
+-- go.mod --
+module example.com/testmod
+-- a.go --
+// Package a is the top.
+package a
+
+import "example.com/testmod/sub"
+
+// A is [sub.S].
+const A = sub.S
+-- sub/sub.go --
+// Package sub is below.
+package sub
+
+// S is a string.
+const S = "s"
+`)
+	cfg := ServerConfig{Paths: []string{localModule}}
+	file := filepath.Join(t.TempDir(), "testmod.html")
+	if err := Bundle(context.Background(), cfg, file); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"<title>testmod</title>",
+		`<section id="example.com/testmod">`,
+		`<section id="example.com/testmod/sub">`,
+		`<a href="#example.com/testmod/sub.S">`,
+	} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("bundle does not contain %s", want)
+		}
+	}
+
+	file = filepath.Join(t.TempDir(), "testmod.docset.tgz")
+	if err := Bundle(context.Background(), cfg, file); err != nil {
+		t.Fatal(err)
+	}
+	if fi, err := os.Stat(file); err != nil || fi.Size() == 0 {
+		t.Errorf("docset not written: %v", err)
+	}
+}
@@ -0,0 +1,478 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pkgsite
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+	"golang.org/x/pkgsite/internal/derrors"
+	"golang.org/x/pkgsite/internal/fetchdatasource"
+	"golang.org/x/pkgsite/internal/frontend/versions"
+	"golang.org/x/pkgsite/internal/log"
+	"golang.org/x/pkgsite/internal/stdlib"
+	"golang.org/x/pkgsite/static"
+	thirdparty "golang.org/x/pkgsite/third_party"
+)
+
+// exportTabs are the names of the unit page tabs that are exported. They
+// match the tab names used in URLs by the frontend.
+var exportTabs = []string{"", "versions", "imports", "importedby", "licenses"}
+
+// externalSite is where links to pages that are not part of an export point.
+const externalSite = "https://pkg.go.dev"
+
+// Export writes a static copy of the documentation that a server built with
+// serverCfg would serve into dir, so that it can be browsed from the
+// filesystem or published on any static file server.
+//
+// Every unit of every module that the server's getters can list, other than
+// the standard library, is exported, along with the source files they link to
+// and the site's static assets.
+// The latest version of each module is written at its unversioned path.
+// Links between exported pages are rewritten to relative file paths, and
+// links to pages that were not exported point to pkg.go.dev. Search is
+// replaced with a page that searches an index of the exported packages in
+// the browser.
+func Export(ctx context.Context, serverCfg ServerConfig, dir string) (err error) {
+	defer derrors.Wrap(&err, "Export(%q)", dir)
+
+	serverCfg.Watch = false
+	server, lds, err := buildServer(ctx, serverCfg)
+	if err != nil {
+		return err
+	}
+	mux := http.NewServeMux()
+	server.Install(mux.Handle, nil, nil)
+
+	e := &exporter{
+		handler: mux,
+		dir:     dir,
+		pages:   map[string]string{},
+		files:   map[string]string{},
+	}
+	if err := e.collectPages(ctx, lds); err != nil {
+		return err
+	}
+	if err := e.renderPages(ctx); err != nil {
+		return err
+	}
+	staticFS := fs.FS(static.FS)
+	if serverCfg.DevMode {
+		staticFS = os.DirFS(serverCfg.DevModeStaticDir)
+	}
+	if err := e.copyAssets(staticFS, "static"); err != nil {
+		return err
+	}
+	if err := e.copyAssets(thirdparty.FS, "third_party"); err != nil {
+		return err
+	}
+	return e.writeSearch()
+}
+
+// An exporter writes the pages served by a handler to a directory.
+type exporter struct {
+	handler http.Handler
+	dir     string
+
+	// pages maps a page's URL, without a query except for a tab, to the file
+	// that holds it, relative to dir.
+	pages map[string]string
+	// jobs are the pages to render, in order.
+	jobs []exportJob
+	// files maps the URL path of a source file to the file that holds it,
+	// relative to dir, or to "" if it couldn't be exported.
+	files map[string]string
+	// search holds an entry for each exported package.
+	search []searchEntry
+}
+
+// An exportJob describes a page to render.
+type exportJob struct {
+	url  string // the URL to request, including the tab
+	file string // the file to write, relative to the export directory
+	// If search is non-nil, it is added to the search index once the
+	// synopsis is known.
+	search *searchEntry
+}
+
+// A searchEntry is an element of the search index of an export.
+type searchEntry struct {
+	Path     string `json:"path"`
+	Name     string `json:"name"`
+	Synopsis string `json:"synopsis"`
+	Module   string `json:"module"`
+	Version  string `json:"version"`
+	URL      string `json:"url"` // relative to the export directory
+}
+
+// collectPages determines the pages to export and the files they are
+// written to.
+func (e *exporter) collectPages(ctx context.Context, lds *fetchdatasource.FetchDataSource) error {
+	e.addPage("/", "", "index.html", nil)
+
+	mvs, err := lds.ListModules(ctx)
+	if err != nil {
+		return err
+	}
+	for i, mv := range mvs {
+		if mv.Path == stdlib.ModulePath {
+			// The standard library is large, and its documentation is
+			// always available on pkg.go.dev.
+			continue
+		}
+		// ListModules lists the latest version of each module first.
+		latest := i == 0 || mvs[i-1].Path != mv.Path
+		ums, err := lds.GetModuleUnitMetas(ctx, mv.Path, mv.Version)
+		if err != nil {
+			log.Errorf(ctx, "export: skipping %s: %v", mv, err)
+			continue
+		}
+		sort.Slice(ums, func(i, j int) bool { return ums[i].Path < ums[j].Path })
+		for _, um := range ums {
+			versioned := versions.ConstructUnitURL(um.Path, um.ModulePath, mv.Version)
+			urlPath := versioned
+			if latest {
+				urlPath = "/" + um.Path
+			}
+			for _, tab := range exportTabs {
+				file := strings.TrimPrefix(urlPath, "/") + "/index.html"
+				if tab != "" {
+					file = strings.TrimPrefix(urlPath, "/") + "/" + tab + ".html"
+				}
+				var se *searchEntry
+				if latest && tab == "" && um.IsPackage() {
+					se = &searchEntry{
+						Path:    um.Path,
+						Name:    um.Name,
+						Module:  um.ModulePath,
+						Version: mv.Version,
+						URL:     file,
+					}
+				}
+				e.addPage(urlPath, tab, file, se)
+				if versioned != urlPath {
+					// Links to the latest version may include it explicitly.
+					e.pages[pageKey(versioned, tab)] = file
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func (e *exporter) addPage(urlPath, tab, file string, se *searchEntry) {
+	key := pageKey(urlPath, tab)
+	if _, ok := e.pages[key]; ok {
+		return
+	}
+	e.pages[key] = file
+	e.jobs = append(e.jobs, exportJob{url: key, file: file, search: se})
+}
+
+// pageKey returns the key in exporter.pages for a URL path and tab.
+func pageKey(urlPath, tab string) string {
+	if tab == "" {
+		return urlPath
+	}
+	return urlPath + "?tab=" + tab
+}
+
+// renderPages requests each page, rewrites its links, and writes it out.
+// Pages that can't be served successfully are not exported, so they are
+// first all requested before any links are rewritten.
+func (e *exporter) renderPages(ctx context.Context) error {
+	docs := make([]*html.Node, len(e.jobs))
+	for i, j := range e.jobs {
+		body, ok := e.get(j.url)
+		if !ok {
+			delete(e.pages, j.url)
+			continue
+		}
+		doc, err := html.Parse(bytes.NewReader(body))
+		if err != nil {
+			return fmt.Errorf("%s: %v", j.url, err)
+		}
+		docs[i] = doc
+	}
+	n := 0
+	for i, j := range e.jobs {
+		doc := docs[i]
+		if doc == nil {
+			continue
+		}
+		if j.search != nil {
+			j.search.Synopsis = metaDescription(doc)
+			e.search = append(e.search, *j.search)
+		}
+		base, err := url.Parse(j.url)
+		if err != nil {
+			return err
+		}
+		e.rewriteLinks(doc, base, j.file)
+		var buf bytes.Buffer
+		if err := html.Render(&buf, doc); err != nil {
+			return err
+		}
+		if err := e.writeFile(j.file, buf.Bytes()); err != nil {
+			return err
+		}
+		n++
+	}
+	log.Infof(ctx, "exported %d pages to %s", n, e.dir)
+	return nil
+}
+
+// get serves a GET request for url. It reports whether the request
+// succeeded.
+func (e *exporter) get(url string) ([]byte, bool) {
+	w := httptest.NewRecorder()
+	e.handler.ServeHTTP(w, httptest.NewRequest("GET", url, nil))
+	if w.Code != http.StatusOK {
+		return nil, false
+	}
+	return w.Body.Bytes(), true
+}
+
+// metaDescription returns the content of doc's description meta tag.
+func metaDescription(doc *html.Node) string {
+	var desc string
+	walkHTML(doc, func(n *html.Node) {
+		if n.DataAtom == atom.Meta && strings.EqualFold(attrValue(n, "name"), "description") {
+			desc = attrValue(n, "content")
+		}
+	})
+	return desc
+}
+
+var scriptAssetRegexp = regexp.MustCompile(`"/((?:static|third_party)/[^"?]*)(\?[^"]*)?"`)
+
+// rewriteLinks rewrites the links in the page at base, which is written to
+// file, so that they work in the export.
+func (e *exporter) rewriteLinks(doc *html.Node, base *url.URL, file string) {
+	walkHTML(doc, func(n *html.Node) {
+		if n.Type != html.ElementNode {
+			return
+		}
+		if n.DataAtom == atom.Script && n.FirstChild != nil && n.FirstChild.Type == html.TextNode {
+			// Scripts in the page load assets by their absolute URLs.
+			n.FirstChild.Data = scriptAssetRegexp.ReplaceAllString(n.FirstChild.Data, `"`+relativePrefix(file)+`$1"`)
+		}
+		for i, a := range n.Attr {
+			switch a.Key {
+			case "href", "src", "action":
+				n.Attr[i].Val = e.rewriteLink(a.Val, base, file)
+			}
+		}
+	})
+}
+
+// rewriteLink returns the link to use in file, the export of the page at
+// base, in place of link.
+func (e *exporter) rewriteLink(link string, base *url.URL, file string) string {
+	u, err := url.Parse(link)
+	if err != nil || u.Scheme != "" || u.Host != "" || (u.Path == "" && u.RawQuery == "") {
+		return link
+	}
+	r := base.ResolveReference(u)
+	target := ""
+	switch {
+	case strings.HasPrefix(r.Path, "/static/") || strings.HasPrefix(r.Path, "/third_party/"):
+		target = strings.TrimPrefix(r.Path, "/")
+	case r.Path == "/search":
+		target = "search/index.html"
+	case strings.HasPrefix(r.Path, "/files/"):
+		target = e.exportFile(r.Path)
+	default:
+		if f, ok := e.pages[pageKey(r.Path, r.Query().Get("tab"))]; ok {
+			target = f
+		} else if f, ok := e.pages[r.Path]; ok {
+			target = f
+		}
+	}
+	if target == "" {
+		r.Scheme = "https"
+		r.Host = strings.TrimPrefix(externalSite, "https://")
+		return r.String()
+	}
+	link = relativeLink(file, target)
+	if r.Fragment != "" {
+		link += "#" + r.Fragment
+	}
+	return link
+}
+
+// exportFile writes the source file or directory listing served at urlPath,
+// and returns the file it was written to, or "" if it couldn't be served.
+func (e *exporter) exportFile(urlPath string) string {
+	if f, ok := e.files[urlPath]; ok {
+		return f
+	}
+	file := strings.TrimPrefix(urlPath, "/")
+	if strings.HasSuffix(file, "/") {
+		file += "index.html"
+	}
+	body, ok := e.get(urlPath)
+	if !ok || e.writeFile(file, body) != nil {
+		file = ""
+	}
+	e.files[urlPath] = file
+	if file != "" && strings.HasSuffix(urlPath, "/") {
+		// A directory listing links to its files relatively. Export them too.
+		if doc, err := html.Parse(bytes.NewReader(body)); err == nil {
+			walkHTML(doc, func(n *html.Node) {
+				if href := attrValue(n, "href"); n.DataAtom == atom.A && href != "" && !strings.Contains(href, "/") {
+					if name, err := url.PathUnescape(href); err == nil {
+						e.exportFile(urlPath + name)
+					}
+				}
+			})
+		}
+	}
+	return file
+}
+
+var cssAssetRegexp = regexp.MustCompile(`url\((['"]?)/(static|third_party)/`)
+
+// copyAssets writes the files of fsys that are used by pages to the subdirectory
+// prefix of the export.
+func (e *exporter) copyAssets(fsys fs.FS, prefix string) error {
+	return fs.WalkDir(fsys, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		switch path.Ext(p) {
+		case ".tmpl", ".ts", ".go", ".md":
+			return nil
+		}
+		data, err := fs.ReadFile(fsys, p)
+		if err != nil {
+			return err
+		}
+		file := prefix + "/" + p
+		if path.Ext(p) == ".css" {
+			data = cssAssetRegexp.ReplaceAll(data, []byte("url(${1}"+relativePrefix(file)+"$2/"))
+		}
+		return e.writeFile(file, data)
+	})
+}
+
+// writeSearch writes the search page and its index.
+func (e *exporter) writeSearch() error {
+	data, err := json.Marshal(e.search)
+	if err != nil {
+		return err
+	}
+	index := fmt.Sprintf("// Code generated by pkgsite -export. DO NOT EDIT.\n\nconst searchIndex = %s;\n", data)
+	if err := e.writeFile("search/index.js", []byte(index)); err != nil {
+		return err
+	}
+	return e.writeFile("search/index.html", []byte(searchPage))
+}
+
+// writeFile writes data to the file in the export directory.
+func (e *exporter) writeFile(file string, data []byte) error {
+	p := filepath.Join(e.dir, filepath.FromSlash(file))
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(p, data, 0o644)
+}
+
+// relativePrefix returns the relative path from file to the root of the
+// export, ending in a slash if it is non-empty.
+func relativePrefix(file string) string {
+	return strings.Repeat("../", strings.Count(file, "/"))
+}
+
+// relativeLink returns a relative link from the file from to the file to,
+// both of which are relative to the root of the export.
+func relativeLink(from, to string) string {
+	fromDir := strings.Split(path.Dir(from), "/")
+	if fromDir[0] == "." {
+		fromDir = nil
+	}
+	toParts := strings.Split(to, "/")
+	n := 0
+	for n < len(fromDir) && n < len(toParts)-1 && fromDir[n] == toParts[n] {
+		n++
+	}
+	return strings.Repeat("../", len(fromDir)-n) + strings.Join(toParts[n:], "/")
+}
+
+func walkHTML(n *html.Node, f func(*html.Node)) {
+	f(n)
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		walkHTML(c, f)
+	}
+}
+
+func attrValue(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+// searchPage is the page that searches the packages of an export. It is
+// written to search/index.html.
+const searchPage = `<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="utf-8">
+  <meta name="viewport" content="width=device-width, initial-scale=1.0">
+  <link href="../static/frontend/frontend.min.css" rel="stylesheet">
+  <title>Search - Go Packages</title>
+</head>
+<body>
+  <main class="go-Container" style="padding: 1rem 1.5rem">
+    <p><a href="../index.html">Home</a></p>
+    <form class="go-InputGroup" action="index.html" role="search">
+      <input class="go-Input" name="q" aria-label="Search packages" placeholder="Search packages" autofocus>
+      <button class="go-Button">Search</button>
+    </form>
+    <ul class="js-results"></ul>
+  </main>
+  <script src="index.js"></script>
+  <script>
+    const q = (new URLSearchParams(location.search).get('q') || '').trim().toLowerCase();
+    document.querySelector('input[name=q]').value = q;
+    const results = document.querySelector('.js-results');
+    for (const e of searchIndex) {
+      if (!q || [e.path, e.name, e.synopsis].some(s => s.toLowerCase().includes(q))) {
+        const li = document.createElement('li');
+        const a = document.createElement('a');
+        a.href = '../' + e.url;
+        a.textContent = e.path;
+        li.append(a, ' ' + e.synopsis);
+        results.append(li);
+      }
+    }
+    if (!results.firstChild) {
+      results.textContent = 'No matches.';
+    }
+  </script>
+</body>
+</html>
+`
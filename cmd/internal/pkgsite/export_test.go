@@ -0,0 +1,150 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pkgsite
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/net/html"
+	"golang.org/x/pkgsite/internal/testenv"
+	"golang.org/x/pkgsite/internal/testing/htmlcheck"
+	"golang.org/x/pkgsite/internal/testing/testhelper"
+)
+
+func TestExport(t *testing.T) {
+	testenv.MustHaveExecPath(t, "go") // for local modules
+
+	localModule, _ := testhelper.WriteTxtarToTempDir(t, `
+-- go.mod --
+module example.com/testmod
+-- a.go --
+// Package a is the top.
+package a
+
+import "example.com/testmod/sub"
+
+// A is sub.S.
+const A = sub.S
+-- sub/sub.go --
+// Package sub is below.
+package sub
+
+import "fmt"
+
+// S is a string.
+const S = "s"
+
+var _ = fmt.Sprint
+`)
+	cfg := ServerConfig{
+		Paths:    []string{localModule},
+		UseCache: true,
+		CacheDir: filepath.Join("..", "..", "..", "internal/fetch/testdata/modcache"),
+	}
+	dir := t.TempDir()
+	if err := Export(context.Background(), cfg, dir); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, file := range []string{
+		"index.html",
+		"example.com/testmod/index.html",
+		"example.com/testmod/imports.html",
+		"example.com/testmod/sub/index.html",
+		"modcache.com/index.html",
+		"static/frontend/frontend.min.css",
+		"static/frontend/frontend.js",
+		"third_party/dialog-polyfill/dialog-polyfill.js",
+		"search/index.html",
+		"search/index.js",
+	} {
+		if _, err := os.Stat(filepath.Join(dir, file)); err != nil {
+			t.Error(err)
+		}
+	}
+
+	read := func(file string) string {
+		t.Helper()
+		data, err := os.ReadFile(filepath.Join(dir, file))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(data)
+	}
+
+	page := read("example.com/testmod/index.html")
+	doc, err := html.Parse(strings.NewReader(page))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := htmlcheck.In("",
+		htmlcheck.In(`link[rel="stylesheet"]`, href("../../static/frontend/frontend.min.css")),
+		htmlcheck.In(`a[href="sub/index.html"]`, hasText("sub")),
+	)(doc); err != nil {
+		t.Error(err)
+	}
+	if !strings.Contains(page, `loadScript("../../static/frontend/frontend.js")`) {
+		t.Error("script asset was not rewritten")
+	}
+	// No links should refer to the root of a server.
+	walkHTML(doc, func(n *html.Node) {
+		for _, a := range n.Attr {
+			if (a.Key == "href" || a.Key == "src") && strings.HasPrefix(a.Val, "/") {
+				t.Errorf("%s has absolute link %q", n.Data, a.Val)
+			}
+		}
+	})
+	for _, n := range []*html.Node{doc} {
+		walkHTML(n, func(n *html.Node) {
+			if href := attrValue(n, "href"); strings.HasPrefix(href, "../../files/") {
+				f, _, _ := strings.Cut(strings.TrimPrefix(href, "../../"), "#")
+				if _, err := os.Stat(filepath.Join(dir, filepath.FromSlash(f))); err != nil {
+					t.Errorf("source link %q: %v", href, err)
+				}
+			}
+		})
+	}
+
+	imports := read("example.com/testmod/sub/imports.html")
+	if !strings.Contains(imports, `href="https://pkg.go.dev/fmt"`) {
+		t.Error("link to fmt does not point to pkg.go.dev")
+	}
+
+	if css := read("static/shared/header/header.css"); strings.Contains(css, "url(/static/") {
+		t.Error("absolute URL in CSS was not rewritten")
+	}
+
+	index := read("search/index.js")
+	for _, want := range []string{
+		`"path":"example.com/testmod/sub"`,
+		`"synopsis":"Package sub is below."`,
+		`"url":"example.com/testmod/sub/index.html"`,
+	} {
+		if !strings.Contains(index, want) {
+			t.Errorf("search index does not contain %s", want)
+		}
+	}
+}
+
+func TestRelativeLink(t *testing.T) {
+	for _, test := range []struct {
+		from, to, want string
+	}{
+		{"index.html", "static/a.css", "static/a.css"},
+		{"a/b/index.html", "static/a.css", "../../static/a.css"},
+		{"a/b/index.html", "a/b/imports.html", "imports.html"},
+		{"a/b/index.html", "a/b/c/index.html", "c/index.html"},
+		{"a/b/c/index.html", "a/index.html", "../../index.html"},
+		{"a/b/index.html", "a/bc/index.html", "../bc/index.html"},
+	} {
+		if got := relativeLink(test.from, test.to); got != test.want {
+			t.Errorf("relativeLink(%q, %q) = %q, want %q", test.from, test.to, got, test.want)
+		}
+	}
+}
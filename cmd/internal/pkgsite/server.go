@@ -11,6 +11,7 @@ import (
 	"fmt"
 	"io/fs"
 	"net/http"
+	"net/url"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -25,8 +26,10 @@ import (
 	"golang.org/x/pkgsite/internal/fetchdatasource"
 	"golang.org/x/pkgsite/internal/frontend"
 	"golang.org/x/pkgsite/internal/log"
+	"golang.org/x/pkgsite/internal/playground"
 	"golang.org/x/pkgsite/internal/proxy"
 	"golang.org/x/pkgsite/internal/source"
+	"golang.org/x/pkgsite/internal/vuln"
 	"golang.org/x/pkgsite/static"
 	thirdparty "golang.org/x/pkgsite/third_party"
 )
@@ -42,13 +45,28 @@ type ServerConfig struct {
 	DevMode          bool
 	DevModeStaticDir string
 	GoRepoPath       string
+	Watch            bool     // re-fetch local modules as they change, and reload open pages
+	VulnDBDir        string   // directory containing a copy of the vulnerability database, or ""
+	GitRepos         []string // local git repositories to serve modules from, at any ref
+	RemotePlayground bool     // send example programs to the Go playground instead of running them locally
+	PlayDir          string   // directory for programs shared from local playgrounds, or "" for the default
+	HTTPAddr         string   // address the server listens on, or "" if it doesn't serve HTTP
+
+	LoadOptions fetch.LoadOptions // how the packages of fetched modules are loaded
 
 	Proxy *proxy.Client // client, or nil; controlled by the -proxy flag
 }
 
 // BuildServer builds a *frontend.Server using the given configuration.
 func BuildServer(ctx context.Context, serverCfg ServerConfig) (*frontend.Server, error) {
-	if len(serverCfg.Paths) == 0 && !serverCfg.UseCache && serverCfg.Proxy == nil {
+	server, _, err := buildServer(ctx, serverCfg)
+	return server, err
+}
+
+// buildServer is like BuildServer, but also returns the data source that the
+// server uses.
+func buildServer(ctx context.Context, serverCfg ServerConfig) (*frontend.Server, *fetchdatasource.FetchDataSource, error) {
+	if len(serverCfg.Paths) == 0 && !serverCfg.UseCache && serverCfg.Proxy == nil && len(serverCfg.GitRepos) == 0 {
 		serverCfg.Paths = []string{"."}
 	}
 
@@ -56,6 +74,7 @@ func BuildServer(ctx context.Context, serverCfg ServerConfig) (*frontend.Server,
 		all:        serverCfg.UseListedMods,
 		proxy:      serverCfg.Proxy,
 		goRepoPath: serverCfg.GoRepoPath,
+		gitRepos:   serverCfg.GitRepos,
 	}
 
 	// By default, the requested Paths are interpreted as directories. However,
@@ -65,13 +84,13 @@ func BuildServer(ctx context.Context, serverCfg ServerConfig) (*frontend.Server,
 		var err error
 		cfg.dirs, err = getGOPATHModuleDirs(ctx, serverCfg.Paths)
 		if err != nil {
-			return nil, fmt.Errorf("searching GOPATH: %v", err)
+			return nil, nil, fmt.Errorf("searching GOPATH: %v", err)
 		}
 	} else {
 		var err error
 		cfg.dirs, err = getModuleDirs(ctx, serverCfg.Paths)
 		if err != nil {
-			return nil, fmt.Errorf("searching GOPATH: %v", err)
+			return nil, nil, fmt.Errorf("searching GOPATH: %v", err)
 		}
 	}
 
@@ -81,10 +100,10 @@ func BuildServer(ctx context.Context, serverCfg ServerConfig) (*frontend.Server,
 			var err error
 			cfg.modCacheDir, err = defaultCacheDir()
 			if err != nil {
-				return nil, err
+				return nil, nil, err
 			}
 			if cfg.modCacheDir == "" {
-				return nil, fmt.Errorf("empty value for GOMODCACHE")
+				return nil, nil, fmt.Errorf("empty value for GOMODCACHE")
 			}
 		}
 	}
@@ -95,7 +114,7 @@ func BuildServer(ctx context.Context, serverCfg ServerConfig) (*frontend.Server,
 
 	getters, err := buildGetters(ctx, cfg)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	// Collect unique module Paths served by this server.
@@ -114,7 +133,72 @@ func BuildServer(ctx context.Context, serverCfg ServerConfig) (*frontend.Server,
 		return allModules[i].ModulePath < allModules[j].ModulePath
 	})
 
-	return newServer(getters, allModules, cfg.proxy, serverCfg.DevMode, serverCfg.DevModeStaticDir)
+	var vc *vuln.Client
+	if serverCfg.VulnDBDir != "" {
+		vc, err = newLocalVulnClient(serverCfg.VulnDBDir)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	var play http.Handler
+	if !serverCfg.RemotePlayground {
+		// Anyone who can reach the local playground can run programs on
+		// this machine.
+		if serverCfg.HTTPAddr != "" && !playground.IsLoopbackAddr(serverCfg.HTTPAddr) {
+			return nil, nil, fmt.Errorf("the local playground can only be served on a loopback address, not %q; use -remote_play to serve on other addresses", serverCfg.HTTPAddr)
+		}
+		play, err = newLocalPlayground(serverCfg, cfg, allModules)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return newServer(getters, allModules, cfg.proxy, vc, play, serverCfg.DevMode, serverCfg.DevModeStaticDir, serverCfg.Watch, serverCfg.LoadOptions)
+}
+
+// newLocalPlayground returns a playground that runs programs with the local
+// Go toolchain. Programs use the local copies of local modules, and, unless
+// pkgsite may use the proxy, only modules that are already in the module
+// cache.
+func newLocalPlayground(serverCfg ServerConfig, cfg getterConfig, localModules []frontend.LocalModule) (*playground.Local, error) {
+	pcfg := playground.Config{
+		ShareDir:     serverCfg.PlayDir,
+		LocalModules: map[string]string{},
+	}
+	if pcfg.ShareDir == "" {
+		dir, err := os.UserCacheDir()
+		if err != nil {
+			return nil, fmt.Errorf("locating directory for shared playground programs: %v", err)
+		}
+		pcfg.ShareDir = filepath.Join(dir, "pkgsite", "play")
+	}
+	for _, m := range localModules {
+		pcfg.LocalModules[m.ModulePath] = m.Dir
+	}
+	if cfg.proxy == nil {
+		pcfg.Env = append(pcfg.Env, "GOPROXY=off")
+	}
+	if cfg.modCacheDir != "" {
+		pcfg.Env = append(pcfg.Env, "GOMODCACHE="+cfg.modCacheDir)
+	}
+	return playground.NewLocal(pcfg), nil
+}
+
+// newLocalVulnClient returns a vulnerability client that reads the database
+// in dir, which must have the layout of https://vuln.go.dev with uncompressed
+// JSON files, such as index/db.json and ID/GO-2022-0001.json.
+func newLocalVulnClient(dir string) (*vuln.Client, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	u := &url.URL{Scheme: "file", Path: filepath.ToSlash(abs)}
+	vc, err := vuln.NewClient(u.String())
+	if err != nil {
+		return nil, fmt.Errorf("opening vulnerability database: %v", err)
+	}
+	return vc, nil
 }
 
 // getModuleDirs returns the set of workspace modules for each directory,
@@ -199,14 +283,16 @@ type getterConfig struct {
 	proxy          *proxy.Client                     // proxy client, or nil
 	useLocalStdlib bool                              // use go/packages for the local stdlib
 	goRepoPath     string                            // repo path for local stdlib
+	gitRepos       []string                          // git repositories holding modules
 }
 
 // buildGetters constructs module getters based on the given configuration.
 //
 // Getters are returned in the following priority order:
 //  1. local getters for cfg.dirs, in the given order
-//  2. a module cache getter, if cfg.modCacheDir != ""
-//  3. a proxy getter, if cfg.proxy != nil
+//  2. git getters for cfg.gitRepos, in the given order
+//  3. a module cache getter, if cfg.modCacheDir != ""
+//  4. a proxy getter, if cfg.proxy != nil
 func buildGetters(ctx context.Context, cfg getterConfig) ([]fetch.ModuleGetter, error) {
 	var getters []fetch.ModuleGetter
 
@@ -217,7 +303,7 @@ func buildGetters(ctx context.Context, cfg getterConfig) ([]fetch.ModuleGetter,
 			patterns = append(patterns, "all")
 		} else {
 			for _, m := range modules {
-				patterns = append(patterns, fmt.Sprintf("%s/...", m))
+				patterns = append(patterns, fmt.Sprintf("%s/...", m.ModulePath))
 			}
 		}
 		mg, err := fetch.NewGoPackagesModuleGetter(ctx, dir, patterns...)
@@ -231,6 +317,15 @@ func buildGetters(ctx context.Context, cfg getterConfig) ([]fetch.ModuleGetter,
 		return nil, fmt.Errorf("failed to load any module(s) at %v", cfg.dirs)
 	}
 
+	// Add getters for git repositories.
+	for _, dir := range cfg.gitRepos {
+		g, err := fetch.NewGitModuleGetter(ctx, "", dir)
+		if err != nil {
+			return nil, err
+		}
+		getters = append(getters, g)
+	}
+
 	// Add a getter for the local module cache.
 	if cfg.modCacheDir != "" {
 		g, err := fetch.NewModCacheGetter(cfg.modCacheDir)
@@ -276,11 +371,12 @@ func getGOROOT() string {
 	return strings.TrimSpace(string(b))
 }
 
-func newServer(getters []fetch.ModuleGetter, localModules []frontend.LocalModule, prox *proxy.Client, devMode bool, staticFlag string) (*frontend.Server, error) {
+func newServer(getters []fetch.ModuleGetter, localModules []frontend.LocalModule, prox *proxy.Client, vc *vuln.Client, play http.Handler, devMode bool, staticFlag string, watch bool, loadOpts fetch.LoadOptions) (*frontend.Server, *fetchdatasource.FetchDataSource, error) {
 	lds := fetchdatasource.Options{
 		Getters:              getters,
 		ProxyClientForLatest: prox,
 		BypassLicenseCheck:   true,
+		LoadOptions:          loadOpts,
 	}.New()
 
 	// In dev mode, use a dirFS to pick up template/JS/CSS changes without
@@ -298,6 +394,12 @@ func newServer(getters []fetch.ModuleGetter, localModules []frontend.LocalModule
 	}
 	go lds.GetUnitMeta(context.Background(), "", "std", "latest")
 
+	var reloader *frontend.Reloader
+	if watch {
+		reloader = frontend.NewReloader()
+		go watchModules(context.Background(), lds, reloader)
+	}
+
 	server, err := frontend.NewServer(frontend.ServerConfig{
 		DataSourceGetter: func(context.Context) internal.DataSource { return lds },
 		TemplateFS:       template.TrustedFSFromEmbed(static.FS),
@@ -306,9 +408,12 @@ func newServer(getters []fetch.ModuleGetter, localModules []frontend.LocalModule
 		LocalMode:        true,
 		LocalModules:     localModules,
 		ThirdPartyFS:     thirdparty.FS,
+		Reloader:         reloader,
+		VulndbClient:     vc,
+		Playground:       play,
 	})
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	for _, g := range getters {
 		p, fsys := g.SourceFS()
@@ -316,7 +421,70 @@ func newServer(getters []fetch.ModuleGetter, localModules []frontend.LocalModule
 			server.InstallFS(p, fsys)
 		}
 	}
-	return server, nil
+	return server, lds, nil
+}
+
+// watchInterval is how often watchModules checks for changed modules.
+const watchInterval = time.Second
+
+// watchModules periodically re-fetches the modules in lds that have changed,
+// and tells open pages to reload once the changes have settled. At that time
+// it also logs the problems in the documentation of the changed modules as
+// warnings.
+func watchModules(ctx context.Context, lds *fetchdatasource.FetchDataSource, reloader *frontend.Reloader) {
+	ticker := time.NewTicker(watchInterval)
+	defer ticker.Stop()
+	pending := map[internal.Modver]bool{}
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		changed, err := lds.RefetchChanged(ctx)
+		if err != nil {
+			log.Errorf(ctx, "watching modules: %v", err)
+			continue
+		}
+		if len(changed) > 0 {
+			log.Infof(ctx, "re-fetched changed modules %v", changed)
+			// A module that was modified in the last couple of seconds keeps
+			// reporting that it has changed, because file modification
+			// times are too coarse to be sure that the edit is complete.
+			// Wait until it is quiet before reloading pages, so that they
+			// reload only once per edit.
+			for _, mv := range changed {
+				pending[mv] = true
+			}
+		} else if len(pending) > 0 {
+			for mv := range pending {
+				logDocProblems(ctx, lds, mv)
+			}
+			pending = map[internal.Modver]bool{}
+			reloader.Notify()
+		}
+	}
+}
+
+// logDocProblems logs the problems in the documentation of the module
+// version as warnings, with positions relative to the module root.
+func logDocProblems(ctx context.Context, lds *fetchdatasource.FetchDataSource, mv internal.Modver) {
+	problems, err := lds.GetDocProblems(ctx, mv.Path, mv.Version)
+	if err != nil {
+		log.Errorf(ctx, "checking documentation: %v", err)
+		return
+	}
+	var pkgPaths []string
+	for p := range problems {
+		pkgPaths = append(pkgPaths, p)
+	}
+	sort.Strings(pkgPaths)
+	for _, p := range pkgPaths {
+		dir := internal.Suffix(p, mv.Path)
+		for _, dp := range problems[p] {
+			log.Warningf(ctx, "%s: %s:%d: %s", mv.Path, filepath.Join(filepath.FromSlash(dir), dp.Filename), dp.Line, dp.Message)
+		}
+	}
 }
 
 func defaultCacheDir() (string, error) {
@@ -9,9 +9,11 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"os/exec"
 	"path"
 	"path/filepath"
 	"regexp"
+	"strings"
 	"testing"
 
 	"golang.org/x/net/html"
@@ -34,6 +36,7 @@ var (
 
 func TestServer(t *testing.T) {
 	testenv.MustHaveExecPath(t, "go") // for local modules
+	testenv.MustHaveExecPath(t, "git")
 
 	repoPath := func(fn string) string { return filepath.Join("..", "..", "..", fn) }
 
@@ -50,7 +53,117 @@ func TestServer(t *testing.T) {
 module example.com/testmod
 -- a.go --
 package a
+-- a_test.go --
+package a
+
+import "testing"
+
+func TestA(t *testing.T) {}
+
+func FuzzA(f *testing.F) {}
+-- testdata/fuzz/FuzzA/seed --
+go test fuzz v1
+`)
+	// A module with a package that has a function only on Linux.
+	platformModule, _ := testhelper.WriteTxtarToTempDir(t, `
+-- go.mod --
+module example.com/platform
+-- a.go --
+// Package a has platform-specific API.
+package a
+-- a_linux.go --
+//go:build linux
+
+package a
+
+// L is only on Linux.
+func L() {}
+`)
+	// A working copy of example.com/single, whose latest release is in the
+	// test proxy, with changes to the API of package pkg.
+	workingCopy, _ := testhelper.WriteTxtarToTempDir(t, `
+-- go.mod --
+module example.com/single
+-- pkg/file1.go --
+// Package pkg is a sample package.
+package pkg
+
+import "time"
+
+const Version = "v1.1.0"
+
+func F(t time.Time) T { return 0 }
+
+func H() {}
+-- pkg/file2.go --
+package pkg
+
+var V = Version
+
+type T int
 `)
+	// A module that requires a release of example.com/single. The
+	// requirements are replaced by directories so that they can be loaded
+	// without a proxy.
+	dependent, _ := testhelper.WriteTxtarToTempDir(t, `
+-- go.mod --
+module example.com/dependent
+
+require (
+	example.com/single v1.0.0
+	example.com/basic v1.1.0 // indirect
+)
+
+replace example.com/single => ./single
+
+replace example.com/basic => ./basic
+-- a.go --
+package a
+-- single/go.mod --
+module example.com/single
+-- basic/go.mod --
+module example.com/basic
+`)
+	vulnDBDir, _ := testhelper.WriteTxtarToTempDir(t, `
+-- index/db.json --
+{"modified": "2024-01-01T00:00:00Z"}
+-- index/modules.json --
+[{"path": "example.com/single", "vulns": [{"id": "GO-2024-0001", "modified": "2024-01-01T00:00:00Z", "fixed": "1.2.0"}]}]
+-- index/vulns.json --
+[{"id": "GO-2024-0001", "modified": "2024-01-01T00:00:00Z"}]
+-- ID/GO-2024-0001.json --
+{
+  "id": "GO-2024-0001",
+  "modified": "2024-01-01T00:00:00Z",
+  "published": "2024-01-01T00:00:00Z",
+  "summary": "Everything is broken in example.com/single",
+  "details": "Package pkg is broken.",
+  "affected": [{
+    "package": {"name": "example.com/single", "ecosystem": "Go"},
+    "ranges": [{"type": "SEMVER", "events": [{"introduced": "0"}, {"fixed": "1.2.0"}]}],
+    "ecosystem_specific": {"imports": [{"path": "example.com/single/pkg"}]}
+  }]
+}
+`)
+	gitRepo, _ := testhelper.WriteTxtarToTempDir(t, `
+-- go.mod --
+module example.com/gitmod
+-- a.go --
+// Package a is on a branch.
+package a
+`)
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"add", "."},
+		{"-c", "user.name=Joe Random", "-c", "user.email=joe@example.com", "commit", "-q", "-m", "first"},
+		{"branch", "feature"},
+	} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = gitRepo
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v: %s", args, err, out)
+		}
+	}
 	cacheDir := repoPath("internal/fetch/testdata/modcache")
 	testModules := proxytest.LoadTestModules(repoPath("internal/proxy/testdata"))
 	prox, teardown := proxytest.SetupTestClient(t, testModules)
@@ -92,6 +205,117 @@ package a
 				in(".Documentation", hasText("There is no documentation for this package.")),
 				sourceLinks(path.Join(filepath.ToSlash(abs(localModule)), "example.com/testmod"), "a.go")),
 		},
+		{
+			"local imported by",
+			cfg(func(c *ServerConfig) {
+				c.UseCache = false
+			}),
+			"example.com/testmod?tab=importedby",
+			http.StatusOK,
+			in(".ImportedBy", hasText("No known importers")),
+		},
+		{
+			"local tests",
+			cfg(func(c *ServerConfig) {
+				c.UseCache = false
+			}),
+			"example.com/testmod?tab=tests",
+			http.StatusOK,
+			in(".Tests",
+				in(`[data-test-id="UnitTests-summary"]`, hasText("1 test, 0 benchmarks and 1 fuzz test with 1 seed corpus file.")),
+				hasText("TestA"),
+				hasText("FuzzA")),
+		},
+		{
+			"local doc health",
+			cfg(func(c *ServerConfig) {
+				c.UseCache = false
+			}),
+			"example.com/testmod?tab=dochealth",
+			http.StatusOK,
+			in(".DocHealth",
+				in(`[data-test-id="UnitDocHealth-summary"]`, hasText("1 problem in 1 package.")),
+				hasText("package a has no package comment")),
+		},
+		{
+			"local build contexts",
+			cfg(func(c *ServerConfig) {
+				c.Paths = []string{platformModule}
+			}),
+			"example.com/platform?tab=buildcontexts",
+			http.StatusOK,
+			in(".BuildContexts",
+				in("h2", hasText("Symbol availability across build contexts")),
+				in(`[data-test-id="UnitBuildContexts-scope"]`, hasText("merges the documentation of the package in all 4 build contexts")),
+				in(`[data-test-id="UnitBuildContexts-summary"]`, hasText("1 of 1 symbol is not in every build context.")),
+				in(`[data-test-id="UnitBuildContexts-files"]`, hasText("//go:build linux"))),
+		},
+		{
+			"local build contexts link",
+			cfg(func(c *ServerConfig) {
+				c.Paths = []string{platformModule}
+			}),
+			"example.com/platform",
+			http.StatusOK,
+			in(`[data-test-id="UnitBuildContext-compare"]`,
+				href("?tab=buildcontexts"),
+				hasText("Symbol availability in all contexts")),
+		},
+		{
+			"local api diff",
+			cfg(func(c *ServerConfig) {
+				c.Paths = []string{workingCopy}
+			}),
+			"example.com/single/pkg?tab=apidiff",
+			http.StatusOK,
+			in(".APIDiff",
+				in("h2", hasText("API changes since v1.0.0")),
+				in("h2 a", href("/example.com/single@v1.0.0/pkg")),
+				in(`[data-test-id="UnitAPIDiff-majorVersion"]`, hasText("example.com/single/v2")),
+				in(".APIDiff-old", hasText(regexp.QuoteMeta("func G() int"))),
+				in(".APIDiff-new", hasText(regexp.QuoteMeta("func F(t time.Time) T"))),
+				hasText(regexp.QuoteMeta("func H()"))),
+		},
+		{
+			"local dependencies",
+			cfg(func(c *ServerConfig) {
+				c.Paths = []string{dependent}
+				c.UseListedMods = false
+			}),
+			"example.com/dependent?tab=dependencies",
+			http.StatusOK,
+			in(".Dependencies",
+				in(".Dependencies-listItem a", href("/example.com/single@v1.0.0")),
+				hasText("1 indirect requirement")),
+		},
+		{
+			"proxy required by",
+			cfg(func(c *ServerConfig) {
+				c.Paths = []string{dependent}
+				c.UseListedMods = false
+			}),
+			"example.com/single@v1.0.0?tab=dependencies",
+			http.StatusOK,
+			in(".Dependencies", hasText("example.com/dependent@")),
+		},
+		{
+			"git branch",
+			cfg(func(c *ServerConfig) {
+				c.GitRepos = []string{gitRepo}
+			}),
+			"example.com/gitmod@feature",
+			http.StatusOK,
+			in(".Documentation", hasText("Package a is on a branch.")),
+		},
+		{
+			"branch of module not in git",
+			cfg(func(c *ServerConfig) {
+				c.GitRepos = []string{gitRepo}
+			}),
+			"example.com/testmod@feature",
+			http.StatusBadRequest,
+			in(".Error-message", hasText("feature is not a valid semantic version.")),
+		},
 		{
 			"modcache",
 			cfg(nil),
@@ -122,6 +346,25 @@ package a
 			http.StatusOK,
 			hasText("G is new in v1.1.0"),
 		},
+		{
+			"proxy versions",
+			cfg(nil),
+			"example.com/retractions?tab=versions",
+			http.StatusOK,
+			in(".Versions",
+				hasText("v1.2.0"),
+				hasText("v1.0.0"),
+				hasText("retracted")),
+		},
+		{
+			"modcache versions",
+			cfg(func(c *ServerConfig) {
+				c.Proxy = nil
+			}),
+			"modcache.com?tab=versions",
+			http.StatusOK,
+			in(".Versions", hasText("v1.0.0")),
+		},
 		{
 			"proxy unsupported",
 			cfg(func(c *ServerConfig) {
@@ -143,16 +386,27 @@ package a
 			),
 		},
 		{
-			"no symbol search",
+			"package search",
 			cfg(func(c *ServerConfig) {
 				c.UseLocalStdlib = false
 			}),
-			"search?q=A", // using a capital letter should not cause symbol search
+			"search?q=A&m=package",
 			http.StatusOK,
 			in(".SearchResults",
 				hasText("example.com/testmod"),
 			),
 		},
+		{
+			"symbol search",
+			cfg(func(c *ServerConfig) {
+				c.UseLocalStdlib = false
+			}),
+			"search?q=V", // using a capital letter causes symbol search
+			http.StatusOK,
+			in(".SearchResults",
+				hasText("modcache.com"),
+			),
+		},
 		{
 			"search not found",
 			cfg(func(c *ServerConfig) {
@@ -174,14 +428,52 @@ package a
 			),
 		},
 		{
-			"search unsupported",
+			"search modcache",
 			cfg(func(c *ServerConfig) {
 				c.Paths = nil
 				c.UseLocalStdlib = false
 			}),
-			"search?q=zzz",
-			http.StatusFailedDependency,
-			hasText("page is not supported"),
+			"search?q=pgio",
+			http.StatusOK,
+			in(".SearchResults",
+				hasText("github.com/jackc/pgio"),
+			),
+		},
+		{
+			"vulns",
+			cfg(func(c *ServerConfig) {
+				c.VulnDBDir = vulnDBDir
+			}),
+			"vuln/GO-2024-0001",
+			http.StatusOK,
+			hasText("Package pkg is broken."),
+		},
+		{
+			"vulns list",
+			cfg(func(c *ServerConfig) {
+				c.VulnDBDir = vulnDBDir
+			}),
+			"vuln/list",
+			http.StatusOK,
+			hasText("GO-2024-0001"),
+		},
+		{
+			"proxy with vulns",
+			cfg(func(c *ServerConfig) {
+				c.VulnDBDir = vulnDBDir
+			}),
+			"example.com/single/pkg",
+			http.StatusOK,
+			in(".go-Message--alert", hasText("GO-2024-0001")),
+		},
+		{
+			"proxy versions with vulns",
+			cfg(func(c *ServerConfig) {
+				c.VulnDBDir = vulnDBDir
+			}),
+			"example.com/single/pkg?tab=versions",
+			http.StatusOK,
+			in(".Versions", hasText("GO-2024-0001")),
 		},
 		{
 			"vulns unsupported",
@@ -195,10 +487,15 @@ package a
 		// See also golang/go#58923.
 	} {
 		t.Run(test.name, func(t *testing.T) {
-			server, err := BuildServer(ctx, test.cfg)
+			server, lds, err := buildServer(ctx, test.cfg)
 			if err != nil {
 				t.Fatal(err)
 			}
+			// Search doesn't wait for modules to be indexed. Indexing the
+			// local standard library takes too long to wait for.
+			if strings.HasPrefix(test.url, "search?") && !test.cfg.UseLocalStdlib {
+				lds.WaitForIndex()
+			}
 			mux := http.NewServeMux()
 			server.Install(mux.Handle, nil, nil)
 
@@ -228,3 +525,36 @@ func sourceLinks(dir, filename string) htmlcheck.Checker {
 		in(".UnitFiles-titleLink a", href(filesPath)),
 		in(".UnitFiles-fileList a", href(filesPath+filename)))
 }
+
+func TestBuildServerPlaygroundAddr(t *testing.T) {
+	testenv.MustHaveExecPath(t, "go") // for local modules
+
+	localModule, _ := testhelper.WriteTxtarToTempDir(t, `
+-- go.mod --
+module example.com/testmod
+-- a.go --
+package a
+`)
+	for _, test := range []struct {
+		addr       string
+		remotePlay bool
+		wantErr    bool
+	}{
+		{"localhost:8080", false, false},
+		{"127.0.0.1:0", false, false},
+		{":8080", false, true},
+		{"0.0.0.0:8080", false, true},
+		{":8080", true, false},
+	} {
+		cfg := ServerConfig{
+			Paths:            []string{localModule},
+			HTTPAddr:         test.addr,
+			RemotePlayground: test.remotePlay,
+			PlayDir:          t.TempDir(),
+		}
+		_, err := BuildServer(context.Background(), cfg)
+		if gotErr := err != nil; gotErr != test.wantErr {
+			t.Errorf("addr %q, remote_play=%t: got error %v, want error: %t", test.addr, test.remotePlay, err, test.wantErr)
+		}
+	}
+}
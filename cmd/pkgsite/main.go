@@ -45,6 +45,88 @@
 // processed. If you clone the repo yourself (https://go.googlesource.com/go),
 // you can provide its location with the -gorepo flag to save a little time.
 //
+// With -watch, pkgsite checks local modules for changes every second. When a
+// module changes, pkgsite re-fetches it and reloads any open pages, so edited
+// doc comments appear as soon as they are saved. It also logs warnings for
+// problems in the documentation of the changed module, like exported symbols
+// without doc comments and doc links to symbols that don't exist. The same
+// problems are listed on the "Doc health" page of each module.
+//
+// With -export, pkgsite writes the documentation it would serve to a
+// directory as a static site, instead of running a server:
+//
+//	pkgsite -export /tmp/docs
+//
+// Every package of every module that pkgsite can list, other than the standard
+// library, is exported: the local modules, their dependencies unless
+// -list=false is passed, and the contents of the module cache with -cache. The
+// site can be browsed from the filesystem or published on any static file
+// server. Links to packages that were not exported point to pkg.go.dev.
+//
+// With -bundle, pkgsite instead writes the documentation of the same modules
+// to a single file for reading offline. If the file name ends in .html, it is
+// one self-contained HTML page. Otherwise it is a docset for Dash and Zeal,
+// with a search index, in a gzipped tar file:
+//
+//	pkgsite -bundle mymodule.docset.tgz
+//
+// A running pkgsite serves the same bundles for any module it knows about, at
+// /download?module=<module>@<version> for a docset and at
+// /download?module=<module>@<version>&format=html for an HTML page. The module
+// parameter may be repeated to bundle several modules together.
+//
+// With -git, pkgsite serves the module at the root of a local git repository,
+// which may be bare, at any branch, tag or commit, without publishing it to a
+// proxy. As with -cache and -proxy, pkgsite then won't look for a module in the
+// current directory:
+//
+//	pkgsite -git ~/repos/cue
+//
+// Then, for example, http://localhost:8080/cuelang.org/go@my-branch shows the
+// documentation at the head of my-branch. Commits without a semantic version
+// tag are given pseudo-versions, as the go command does.
+//
+// Vulnerability information is shown if you pass -vulndb with the path to a
+// local mirror of the Go vulnerability database. The directory must have the
+// same layout as https://vuln.go.dev, with uncompressed JSON files (for
+// example, index/db.json, index/modules.json and ID/GO-2022-0001.json).
+// Package pages and the versions tab then report known vulnerabilities, and
+// the database can be browsed at /vuln/, all without network access.
+//
+// Before tagging a release, use the "API changes" link on the page of a local
+// package (or add ?tab=apidiff to its URL) to compare its exported API with
+// that of the latest release, which is fetched with -cache or -proxy. The page
+// lists the symbols that were added, removed or changed, and says when the
+// changes are incompatible enough to need a new major version.
+//
+// By default, documentation is shown for linux/amd64, windows/amd64,
+// darwin/amd64 and js/wasm. Use -contexts to choose other build contexts,
+// including ones with build tags, as a space-separated list of GOOS/GOARCH or
+// GOOS/GOARCH/TAGS elements, where TAGS is a comma-separated list:
+//
+//	pkgsite -contexts 'linux/arm64 linux/arm64/integration windows/amd64'
+//
+// The first context is the one shown by default, and the others can be chosen
+// from the "Rendered for" menu on each package page.
+//
+// The header of each package page shows the earliest Go release whose standard
+// library has every symbol the package uses, computed from the API files in
+// $GOROOT/api. Use -goapi to read them from another directory, such as the api
+// directory of a newer Go checkout.
+//
+// The "Run" and "Share" buttons of examples use a playground that runs on the
+// local machine: programs are built with the local go command, in a temporary
+// module that requires the module of the example and uses the local copies of
+// local modules. Without -proxy, dependencies must already be in the module
+// cache. Programs are stopped after a few seconds or when they write too much
+// output, but they are not otherwise sandboxed: they run with the privileges of
+// pkgsite, so only run examples you trust. For the same reason, the local
+// playground is only available when -http is a loopback address like the
+// default, and only runs programs sent by pkgsite's own pages. Shared programs
+// are stored in -playdir. Pass -remote_play to send programs to
+// https://go.dev/play instead, as pkg.go.dev does; it is required to listen on
+// other addresses.
+//
 // [workspace]: https://go.dev/ref/mod#workspaces
 package main
 
@@ -55,15 +137,19 @@ import (
 	"net"
 	"net/http"
 	"os"
+	"path/filepath"
+	"runtime"
 	"strings"
 	"time"
 
 	"golang.org/x/pkgsite/cmd/internal/pkgsite"
+	"golang.org/x/pkgsite/internal"
 	"golang.org/x/pkgsite/internal/browser"
 	"golang.org/x/pkgsite/internal/log"
 	"golang.org/x/pkgsite/internal/middleware/timeout"
 	"golang.org/x/pkgsite/internal/proxy"
 	"golang.org/x/pkgsite/internal/stdlib"
+	"golang.org/x/pkgsite/internal/symbol"
 )
 
 const defaultAddr = "localhost:8080" // default webserver address
@@ -73,6 +159,10 @@ var (
 	goRepoPath = flag.String("gorepo", "", "path to Go repo on local filesystem")
 	useProxy   = flag.Bool("proxy", false, "fetch from GOPROXY if not found locally")
 	openFlag   = flag.Bool("open", false, "open a browser window to the server's address")
+	exportDir  = flag.String("export", "", "write a static site to this directory instead of serving")
+	bundleFile = flag.String("bundle", "", "write the documentation to this docset (.tgz) or HTML (.html) file instead of serving")
+	contexts   = flag.String("contexts", "", "space-separated list of GOOS/GOARCH[/TAGS] build contexts to show documentation for")
+	goAPIDir   = flag.String("goapi", "", "directory of Go API files used to compute minimum Go versions (default GOROOT/api)")
 	// other flags are bound to ServerConfig below
 )
 
@@ -85,6 +175,14 @@ func main() {
 	flag.BoolVar(&serverCfg.UseListedMods, "list", true, "for each path, serve all modules in build list")
 	flag.BoolVar(&serverCfg.DevMode, "dev", false, "enable developer mode (reload templates on each page load, serve non-minified JS/CSS, etc.)")
 	flag.StringVar(&serverCfg.DevModeStaticDir, "static", "static", "path to folder containing static files served")
+	flag.BoolVar(&serverCfg.Watch, "watch", false, "watch local modules for changes, and reload open pages when they change")
+	flag.StringVar(&serverCfg.VulnDBDir, "vulndb", "", "path to a local copy of the Go vulnerability database")
+	flag.BoolVar(&serverCfg.RemotePlayground, "remote_play", false, "run and share examples with the Go playground at go.dev/play instead of locally")
+	flag.StringVar(&serverCfg.PlayDir, "playdir", "", "directory to store shared playground programs in (default: a pkgsite directory in the user cache directory)")
+	flag.Func("git", "serve the module in this git repository at any branch, tag or commit (may be repeated)", func(s string) error {
+		serverCfg.GitRepos = append(serverCfg.GitRepos, s)
+		return nil
+	})
 	serverCfg.UseLocalStdlib = true
 	serverCfg.GoRepoPath = *goRepoPath
 
@@ -92,7 +190,7 @@ func main() {
 		out := flag.CommandLine.Output()
 		fmt.Fprintf(out, "usage: %s [flags] [PATHS ...]\n", os.Args[0])
 		fmt.Fprintf(out, "    where each PATHS is a single path or a comma-separated list\n")
-		fmt.Fprintf(out, "    (default is current directory if neither -cache nor -proxy is provided)\n")
+		fmt.Fprintf(out, "    (default is current directory if none of -cache, -proxy or -git is provided)\n")
 		flag.PrintDefaults()
 	}
 	flag.Parse()
@@ -118,16 +216,51 @@ func main() {
 		stdlib.SetGoRepoPath(*goRepoPath)
 	}
 
+	if *contexts != "" {
+		bcs, err := internal.ParseBuildContexts(*contexts)
+		if err != nil {
+			die("-contexts: %v", err)
+		}
+		internal.BuildContexts = bcs
+	}
+
+	apiDir := *goAPIDir
+	if apiDir == "" {
+		apiDir = filepath.Join(runtime.GOROOT(), "api")
+	}
+	if av, err := symbol.LoadStdlibAPIVersions(apiDir); err == nil {
+		serverCfg.LoadOptions.StdlibAPIVersions = av
+	} else if *goAPIDir != "" {
+		die("-goapi: %v", err)
+	}
+	// Render the documentation of each package once, when it is fetched,
+	// rather than on every page load.
+	serverCfg.LoadOptions.PrerenderDocs = true
+
 	ctx := context.Background()
-	server, err := pkgsite.BuildServer(ctx, serverCfg)
-	if err != nil {
-		die(err.Error())
+	if *exportDir != "" {
+		if err := pkgsite.Export(ctx, serverCfg, *exportDir); err != nil {
+			die(err.Error())
+		}
+		return
+	}
+	if *bundleFile != "" {
+		if err := pkgsite.Bundle(ctx, serverCfg, *bundleFile); err != nil {
+			die(err.Error())
+		}
+		return
 	}
 
 	addr := *httpAddr
 	if addr == "" {
 		addr = ":http"
 	}
+	serverCfg.HTTPAddr = addr
+
+	server, err := pkgsite.BuildServer(ctx, serverCfg)
+	if err != nil {
+		die(err.Error())
+	}
 
 	ln, err := net.Listen("tcp", addr)
 	if err != nil {
@@ -20,9 +20,12 @@ import (
 	"go.opencensus.io/plugin/ochttp"
 	octrace "go.opencensus.io/trace"
 	"golang.org/x/pkgsite/cmd/internal/cmdconfig"
+	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/checksum"
 	"golang.org/x/pkgsite/internal/config"
 	"golang.org/x/pkgsite/internal/config/serverconfig"
 	"golang.org/x/pkgsite/internal/dcensus"
+	"golang.org/x/pkgsite/internal/fetch"
 	"golang.org/x/pkgsite/internal/index"
 	"golang.org/x/pkgsite/internal/log"
 	"golang.org/x/pkgsite/internal/middleware"
@@ -30,6 +33,7 @@ import (
 	"golang.org/x/pkgsite/internal/proxy"
 	"golang.org/x/pkgsite/internal/queue/gcpqueue"
 	"golang.org/x/pkgsite/internal/source"
+	"golang.org/x/pkgsite/internal/symbol"
 	"golang.org/x/pkgsite/internal/trace"
 	"golang.org/x/pkgsite/internal/worker"
 )
@@ -53,6 +57,18 @@ func main() {
 		log.Fatal(ctx, err)
 	}
 	cfg.Dump(os.Stdout)
+	if len(cfg.BuildContexts) > 0 {
+		internal.BuildContexts = cfg.BuildContexts
+	}
+	// The server loads the documentation templates, so the documentation of
+	// fetched packages can be rendered and stored for the frontend.
+	loadOpts := fetch.LoadOptions{PrerenderDocs: true}
+	if cfg.GoAPIDir != "" {
+		loadOpts.StdlibAPIVersions, err = symbol.LoadStdlibAPIVersions(cfg.GoAPIDir)
+		if err != nil {
+			log.Warningf(ctx, "not computing minimum Go versions: %v", err)
+		}
+	}
 
 	if cfg.UseProfiler {
 		if err := profiler.Start(profiler.Config{}); err != nil {
@@ -82,6 +98,16 @@ func main() {
 		Transport: &ochttp.Transport{},
 		Timeout:   config.SourceTimeout,
 	})
+	var verifier *checksum.Verifier
+	if cfg.ChecksumDB != "off" {
+		verifier, err = checksum.NewVerifier(cfg.ChecksumDB, &http.Client{
+			Transport: &ochttp.Transport{},
+			Timeout:   time.Minute,
+		})
+		if err != nil {
+			log.Fatal(ctx, err)
+		}
+	}
 	expg := cmdconfig.ExperimentGetter(ctx, cfg)
 	fetchQueue, err := gcpqueue.New(ctx, cfg, queueName, *workers, expg,
 		func(ctx context.Context, modulePath, version string) (int, error) {
@@ -89,6 +115,9 @@ func main() {
 				ProxyClient:  proxyClient,
 				SourceClient: sourceClient,
 				DB:           db,
+				GitRepos:     cfg.GitRepos,
+				Verifier:     verifier,
+				LoadOptions:  loadOpts,
 			}
 			code, _, err := f.FetchAndUpdateState(ctx, modulePath, version, cfg.AppVersionLabel())
 			return code, err
@@ -109,6 +138,8 @@ func main() {
 		IndexClient:          indexClient,
 		ProxyClient:          proxyClient,
 		SourceClient:         sourceClient,
+		Verifier:             verifier,
+		LoadOptions:          loadOpts,
 		RedisCacheClient:     redisCacheClient,
 		RedisBetaCacheClient: redisBetaCacheClient,
 		Queue:                fetchQueue,
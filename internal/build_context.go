@@ -4,44 +4,63 @@
 
 package internal
 
-import "fmt"
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
 
 // A BuildContext describes a build context for the Go tool: information needed
 // to build a Go package. For our purposes, we only care about the information
 // that affects documentation generated from the package.
 type BuildContext struct {
 	GOOS, GOARCH string
+
+	// Tags is a sorted, comma-separated list of additional build tags, as
+	// passed to the go command's -tags flag. It is empty for most build
+	// contexts.
+	Tags string
 }
 
 // String returns a string formatted representation of the build context.
 func (b BuildContext) String() string {
-	return fmt.Sprintf("%s/%s", b.GOOS, b.GOARCH)
+	if b.Tags == "" {
+		return fmt.Sprintf("%s/%s", b.GOOS, b.GOARCH)
+	}
+	return fmt.Sprintf("%s/%s/%s", b.GOOS, b.GOARCH, b.Tags)
 }
 
 // Match reports whether its receiver, which acts like a pattern, matches its
 // target, an ordinary BuildContext. In addition to the usual values, a pattern
 // can have an empty GOOS or GOARCH, which means "match anything."
+//
+// Build tags must be asked for: a pattern with no tags matches only targets
+// with no tags, unless the pattern is the zero BuildContext.
 func (pattern BuildContext) Match(target BuildContext) bool {
 	match := func(pat, targ string) bool { return pat == "" || targ == All || pat == targ }
 
-	return match(pattern.GOOS, target.GOOS) && match(pattern.GOARCH, target.GOARCH)
+	tagsMatch := pattern.Tags == target.Tags || target.GOOS == All || pattern == BuildContext{}
+	return match(pattern.GOOS, target.GOOS) && match(pattern.GOARCH, target.GOARCH) && tagsMatch
 }
 
 // All represents all values for a build context element (GOOS or GOARCH).
 const All = "all"
 
 var (
-	BuildContextAll     = BuildContext{All, All}
-	BuildContextLinux   = BuildContext{"linux", "amd64"}
-	BuildContextWindows = BuildContext{"windows", "amd64"}
-	BuildContextDarwin  = BuildContext{"darwin", "amd64"}
-	BuildContextJS      = BuildContext{"js", "wasm"}
+	BuildContextAll     = BuildContext{GOOS: All, GOARCH: All}
+	BuildContextLinux   = BuildContext{GOOS: "linux", GOARCH: "amd64"}
+	BuildContextWindows = BuildContext{GOOS: "windows", GOARCH: "amd64"}
+	BuildContextDarwin  = BuildContext{GOOS: "darwin", GOARCH: "amd64"}
+	BuildContextJS      = BuildContext{GOOS: "js", GOARCH: "wasm"}
 )
 
 // BuildContexts are the build contexts we check when loading a package (see
 // internal/fetch/load.go).
 // We store documentation for all of the listed contexts.
 // The order determines which environment's docs we will show as the default.
+//
+// Programs may replace the default list, typically with the result of
+// ParseBuildContexts, before loading or serving any packages.
 var BuildContexts = []BuildContext{
 	BuildContextLinux,
 	BuildContextWindows,
@@ -79,20 +98,106 @@ func CompareBuildContexts(c1, c2 BuildContext) int {
 
 // BuildContext returns the BuildContext for d.
 func (d *Documentation) BuildContext() BuildContext {
-	return BuildContext{GOOS: d.GOOS, GOARCH: d.GOARCH}
+	return BuildContext{GOOS: d.GOOS, GOARCH: d.GOARCH, Tags: d.Tags}
 }
 
 // DocumentationForBuildContext returns the first Documentation the list that
 // matches the BuildContext, or nil if none does. A Documentation matches if its
-// GOOS and GOARCH fields are the same as those of the BuildContext, or if the
+// GOOS, GOARCH and Tags fields are the same as those of the BuildContext, or if the
 // Documentation field is "all", or if the BuildContext field is empty. That is,
 // empty BuildContext fields act as wildcards. So the zero BuildContext will
 // match the first element of docs, if there is one.
 func DocumentationForBuildContext(docs []*Documentation, bc BuildContext) *Documentation {
 	for _, d := range docs {
-		if bc.Match(BuildContext{d.GOOS, d.GOARCH}) {
+		if bc.Match(d.BuildContext()) {
 			return d
 		}
 	}
 	return nil
 }
+
+// KnownGOOS and KnownGOARCH are the values of GOOS and GOARCH that a build
+// context may have. They match the goos and goarch types in the database.
+var (
+	KnownGOOS = []string{
+		"aix", "android", "darwin", "dragonfly", "freebsd", "illumos", "ios",
+		"js", "linux", "netbsd", "openbsd", "plan9", "solaris", "wasip1",
+		"windows",
+	}
+	KnownGOARCH = []string{
+		"386", "amd64", "arm", "arm64", "mips", "mips64", "mips64le", "mipsle",
+		"ppc64", "ppc64le", "riscv64", "s390x", "wasm",
+	}
+)
+
+// ParseBuildContexts parses a space-separated list of build contexts. Each
+// build context has the form GOOS/GOARCH or GOOS/GOARCH/TAGS, where TAGS is a
+// comma-separated list of build tags, as in
+//
+//	linux/amd64 windows/amd64 linux/amd64/integration,nocgo
+//
+// That is the form produced by BuildContext.String. The tags of each returned
+// BuildContext are sorted, without duplicates.
+func ParseBuildContexts(s string) (_ []BuildContext, err error) {
+	known := func(vals []string, v string) bool {
+		for _, k := range vals {
+			if k == v {
+				return true
+			}
+		}
+		return false
+	}
+	var bcs []BuildContext
+	seen := map[BuildContext]bool{}
+	for _, f := range strings.Fields(s) {
+		parts := strings.Split(f, "/")
+		if len(parts) < 2 || len(parts) > 3 {
+			return nil, fmt.Errorf("build context %q: want GOOS/GOARCH or GOOS/GOARCH/TAGS", f)
+		}
+		bc := BuildContext{GOOS: parts[0], GOARCH: parts[1]}
+		if !known(KnownGOOS, bc.GOOS) {
+			return nil, fmt.Errorf("build context %q: unknown GOOS %q", f, bc.GOOS)
+		}
+		if !known(KnownGOARCH, bc.GOARCH) {
+			return nil, fmt.Errorf("build context %q: unknown GOARCH %q", f, bc.GOARCH)
+		}
+		if len(parts) == 3 {
+			tags := map[string]bool{}
+			for _, t := range strings.Split(parts[2], ",") {
+				if !isBuildTag(t) {
+					return nil, fmt.Errorf("build context %q: invalid build tag %q", f, t)
+				}
+				tags[t] = true
+			}
+			var ts []string
+			for t := range tags {
+				ts = append(ts, t)
+			}
+			sort.Strings(ts)
+			bc.Tags = strings.Join(ts, ",")
+		}
+		if seen[bc] {
+			return nil, fmt.Errorf("build context %q listed more than once", bc)
+		}
+		seen[bc] = true
+		bcs = append(bcs, bc)
+	}
+	if len(bcs) == 0 {
+		return nil, fmt.Errorf("no build contexts in %q", s)
+	}
+	return bcs, nil
+}
+
+// isBuildTag reports whether t is a valid build tag: a non-empty sequence of
+// letters, digits, underscores and dots.
+func isBuildTag(t string) bool {
+	if t == "" {
+		return false
+	}
+	for _, r := range t {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_' || r == '.') {
+			return false
+		}
+	}
+	return true
+}
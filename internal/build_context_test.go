@@ -4,7 +4,11 @@
 
 package internal
 
-import "testing"
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
 
 func TestCompareBuildContexts(t *testing.T) {
 	check := func(c1, c2 BuildContext, want int) {
@@ -35,5 +39,62 @@ func TestCompareBuildContexts(t *testing.T) {
 	}
 
 	// Special cases.
-	check(BuildContext{"?", "?"}, BuildContexts[len(BuildContexts)-1], 1) // unknown is last
+	check(BuildContext{GOOS: "?", GOARCH: "?"}, BuildContexts[len(BuildContexts)-1], 1) // unknown is last
+}
+
+func TestMatchTags(t *testing.T) {
+	tagged := BuildContext{GOOS: "linux", GOARCH: "amd64", Tags: "integration"}
+	for _, test := range []struct {
+		pattern, target BuildContext
+		want            bool
+	}{
+		{BuildContext{}, tagged, true},
+		{BuildContext{GOOS: "linux"}, tagged, false},
+		{BuildContext{GOOS: "linux"}, BuildContextLinux, true},
+		{BuildContext{GOOS: "linux", Tags: "integration"}, tagged, true},
+		{BuildContext{Tags: "integration"}, BuildContextLinux, false},
+		{tagged, BuildContextAll, true},
+	} {
+		if got := test.pattern.Match(test.target); got != test.want {
+			t.Errorf("%v.Match(%v) = %t, want %t", test.pattern, test.target, got, test.want)
+		}
+	}
+}
+
+func TestParseBuildContexts(t *testing.T) {
+	got, err := ParseBuildContexts(" linux/amd64\twindows/arm64  linux/amd64/nocgo,integration,nocgo wasip1/wasm ios/arm64")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []BuildContext{
+		BuildContextLinux,
+		{GOOS: "windows", GOARCH: "arm64"},
+		{GOOS: "linux", GOARCH: "amd64", Tags: "integration,nocgo"},
+		{GOOS: "wasip1", GOARCH: "wasm"},
+		{GOOS: "ios", GOARCH: "arm64"},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("mismatch (-want +got):\n%s", diff)
+	}
+	for _, bc := range got {
+		got2, err := ParseBuildContexts(bc.String())
+		if err != nil || len(got2) != 1 || got2[0] != bc {
+			t.Errorf("ParseBuildContexts(%q) = %v, %v; want [%v]", bc.String(), got2, err, bc)
+		}
+	}
+
+	for _, bad := range []string{
+		"",
+		"linux",
+		"linux/amd64/a/b",
+		"beos/amd64",
+		"linux/z80",
+		"linux/amd64/a-b",
+		"linux/amd64/a,",
+		"linux/amd64 linux/amd64",
+	} {
+		if _, err := ParseBuildContexts(bad); err == nil {
+			t.Errorf("ParseBuildContexts(%q): got nil error, want error", bad)
+		}
+	}
 }
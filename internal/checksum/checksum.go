@@ -0,0 +1,242 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package checksum verifies module zips and go.mod files against a checksum
+// database, such as sum.golang.org.
+package checksum
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+
+	"golang.org/x/mod/sumdb"
+	"golang.org/x/mod/sumdb/dirhash"
+	"golang.org/x/pkgsite/internal/derrors"
+	"golang.org/x/pkgsite/internal/log"
+)
+
+// knownDBs maps the names of well-known checksum databases to their verifier
+// keys, so that they can be configured by name alone, as with GOSUMDB.
+var knownDBs = map[string]string{
+	"sum.golang.org": "sum.golang.org+033de0ae+Ac4zctda0e5eza+HJyk9SxEdh+s3Ho18i1m3kATpbxGSg",
+}
+
+// A Verifier checks module contents against a checksum database.
+//
+// A Verifier caches every record and tile that it reads, and every failed
+// lookup, for as long as it is in use. A long-running process should call
+// ForFetch to get a Verifier for each fetch, so that memory use doesn't grow
+// with every module and a transient error doesn't fail every retry.
+type Verifier struct {
+	client    *sumdb.Client
+	clientOps *clientOps
+}
+
+// NewVerifier returns a Verifier for the checksum database described by db,
+// which has the same form as the GOSUMDB environment variable: a database
+// name, optionally followed by "+" and a verifier key, optionally followed by
+// a space and the URL of the database. The key may only be omitted for a
+// well-known database, and the URL defaults to "https://" followed by the
+// name.
+//
+// Requests to the database are made with httpClient.
+func NewVerifier(db string, httpClient *http.Client) (_ *Verifier, err error) {
+	defer derrors.Wrap(&err, "checksum.NewVerifier(%q)", db)
+
+	key, u, _ := strings.Cut(strings.TrimSpace(db), " ")
+	if !strings.Contains(key, "+") {
+		k, ok := knownDBs[key]
+		if !ok {
+			return nil, fmt.Errorf("missing verifier key for unknown checksum database %q", key)
+		}
+		key = k
+	}
+	name, _, _ := strings.Cut(key, "+")
+	u = strings.TrimSpace(u)
+	if u == "" {
+		u = "https://" + name
+	}
+	ops := &clientOps{
+		url:        strings.TrimSuffix(u, "/"),
+		key:        key,
+		name:       name,
+		httpClient: httpClient,
+	}
+	return &Verifier{client: sumdb.NewClient(ops), clientOps: ops}, nil
+}
+
+// ForFetch returns a Verifier for the same checksum database as v, with an
+// empty cache. It shares only the latest signed tree with v, so that every
+// fetch checks that the database is consistent with what was seen before.
+func (v *Verifier) ForFetch() *Verifier {
+	return &Verifier{client: sumdb.NewClient(v.clientOps), clientOps: v.clientOps}
+}
+
+// VerifyZip checks the hash of the module zip zr against the checksum
+// database. It returns an error wrapping derrors.ChecksumMismatch if they
+// differ.
+func (v *Verifier) VerifyZip(modulePath, version string, zr *zip.Reader) (err error) {
+	defer derrors.Wrap(&err, "VerifyZip(%q, %q)", modulePath, version)
+
+	h, err := HashZip(zr)
+	if err != nil {
+		return err
+	}
+	return v.verify(modulePath, version, h)
+}
+
+// VerifyMod checks the hash of the go.mod file contents goMod against the
+// checksum database. It returns an error wrapping derrors.ChecksumMismatch if
+// they differ.
+func (v *Verifier) VerifyMod(modulePath, version string, goMod []byte) (err error) {
+	defer derrors.Wrap(&err, "VerifyMod(%q, %q)", modulePath, version)
+
+	h, err := HashMod(goMod)
+	if err != nil {
+		return err
+	}
+	return v.verify(modulePath, version+"/go.mod", h)
+}
+
+// Lines returns the go.sum lines for the module version, as recorded in the
+// checksum database: the line for the module zip followed by the line for its
+// go.mod file.
+func (v *Verifier) Lines(modulePath, version string) (_ []string, err error) {
+	defer derrors.Wrap(&err, "Lines(%q, %q)", modulePath, version)
+
+	zipLines, err := v.lookup(modulePath, version)
+	if err != nil {
+		return nil, err
+	}
+	modLines, err := v.lookup(modulePath, version+"/go.mod")
+	if err != nil {
+		return nil, err
+	}
+	return append(zipLines, modLines...), nil
+}
+
+func (v *Verifier) verify(modulePath, vers, hash string) error {
+	lines, err := v.lookup(modulePath, vers)
+	if err != nil {
+		return err
+	}
+	want := fmt.Sprintf("%s %s %s", modulePath, vers, hash)
+	for _, l := range lines {
+		if l == want {
+			return nil
+		}
+	}
+	return fmt.Errorf("%s %s: hash %s does not match checksum database %q: %w",
+		modulePath, vers, hash, lines, derrors.ChecksumMismatch)
+}
+
+func (v *Verifier) lookup(modulePath, vers string) ([]string, error) {
+	lines, err := v.client.Lookup(modulePath, vers)
+	if err != nil {
+		// sumdb.Client.Lookup does not wrap its errors, so look for
+		// sumdb.ErrSecurity in the message.
+		if strings.Contains(err.Error(), sumdb.ErrSecurity.Error()) {
+			return nil, fmt.Errorf("%v: %w", err, derrors.ChecksumMismatch)
+		}
+		return nil, err
+	}
+	return lines, nil
+}
+
+// HashZip returns the go.sum hash of the module zip zr.
+func HashZip(zr *zip.Reader) (string, error) {
+	var names []string
+	files := map[string]*zip.File{}
+	for _, f := range zr.File {
+		names = append(names, f.Name)
+		files[f.Name] = f
+	}
+	return dirhash.Hash1(names, func(name string) (io.ReadCloser, error) {
+		return files[name].Open()
+	})
+}
+
+// HashMod returns the go.sum hash of a go.mod file with contents goMod.
+func HashMod(goMod []byte) (string, error) {
+	return dirhash.Hash1([]string{"go.mod"}, func(string) (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(goMod)), nil
+	})
+}
+
+// clientOps implements sumdb.ClientOps. The latest signed tree is kept in
+// memory, and shared by the Verifiers for every fetch. Nothing is cached
+// beyond what each sumdb.Client caches itself.
+type clientOps struct {
+	url        string
+	key        string
+	name       string
+	httpClient *http.Client
+
+	mu     sync.Mutex
+	latest []byte
+}
+
+func (o *clientOps) ReadRemote(path string) (_ []byte, err error) {
+	defer derrors.Wrap(&err, "ReadRemote(%q)", path)
+
+	resp, err := o.httpClient.Get(o.url + path)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s: %q", resp.Status, data)
+	}
+	return data, nil
+}
+
+func (o *clientOps) ReadConfig(file string) ([]byte, error) {
+	switch file {
+	case "key":
+		return []byte(o.key), nil
+	case o.name + "/latest":
+		o.mu.Lock()
+		defer o.mu.Unlock()
+		return o.latest, nil
+	}
+	return nil, fmt.Errorf("unknown config file %q", file)
+}
+
+func (o *clientOps) WriteConfig(file string, old, new []byte) error {
+	if file != o.name+"/latest" {
+		return fmt.Errorf("unknown config file %q", file)
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if !bytes.Equal(old, o.latest) {
+		return sumdb.ErrWriteConflict
+	}
+	o.latest = new
+	return nil
+}
+
+func (o *clientOps) ReadCache(file string) ([]byte, error) {
+	return nil, os.ErrNotExist
+}
+
+func (o *clientOps) WriteCache(file string, data []byte) {}
+
+func (o *clientOps) Log(msg string) {
+	log.Debugf(context.Background(), "checksum database: %s", msg)
+}
+
+func (o *clientOps) SecurityError(msg string) {
+	log.Errorf(context.Background(), "checksum database: %s", msg)
+}
@@ -0,0 +1,87 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package checksum
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/mod/sumdb"
+	"golang.org/x/mod/sumdb/note"
+)
+
+func TestNewVerifier(t *testing.T) {
+	for _, test := range []struct {
+		db, wantURL, wantName string
+	}{
+		{"sum.golang.org", "https://sum.golang.org", "sum.golang.org"},
+		{"sum.golang.org https://sum.example.com/", "https://sum.example.com", "sum.golang.org"},
+		{
+			"sum.example.test+01234567+AbCdEfGhIjKlMnOpQrStUvWxYz0123456789AbCdEfGh http://localhost:8080",
+			"http://localhost:8080", "sum.example.test",
+		},
+	} {
+		v, err := NewVerifier(test.db, http.DefaultClient)
+		if err != nil {
+			t.Fatalf("%q: %v", test.db, err)
+		}
+		ops := v.clientOps
+		if ops.url != test.wantURL || ops.name != test.wantName {
+			t.Errorf("%q: got URL %q, name %q; want %q, %q", test.db, ops.url, ops.name, test.wantURL, test.wantName)
+		}
+	}
+	if _, err := NewVerifier("sum.example.test", http.DefaultClient); err == nil {
+		t.Error("unknown database without a key: got nil error, want error")
+	}
+}
+
+func TestForFetchRetriesFailedLookup(t *testing.T) {
+	const (
+		modulePath = "example.com/m"
+		vers       = "v1.0.0"
+	)
+	skey, vkey, err := note.GenerateKey(rand.Reader, "sum.example.test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The first lookup fails, as if the database were briefly unavailable.
+	var failed atomic.Bool
+	gosum := func(path, v string) ([]byte, error) {
+		if !failed.Swap(true) {
+			return nil, errors.New("unavailable")
+		}
+		return []byte(fmt.Sprintf("%[1]s %[2]s h1:zip=\n%[1]s %[2]s/go.mod h1:mod=\n", path, v)), nil
+	}
+	srv := sumdb.NewServer(sumdb.NewTestServer(skey, gosum))
+	mux := http.NewServeMux()
+	for _, p := range sumdb.ServerPaths {
+		mux.Handle(p, srv)
+	}
+	s := httptest.NewServer(mux)
+	defer s.Close()
+
+	v, err := NewVerifier(vkey+" "+s.URL, s.Client())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := v.ForFetch().Lines(modulePath, vers); err == nil {
+		t.Fatal("first fetch: got nil error, want error")
+	}
+	// A Verifier for a new fetch doesn't remember the failure.
+	got, err := v.ForFetch().Lines(modulePath, vers)
+	if err != nil {
+		t.Fatalf("second fetch: %v", err)
+	}
+	want := []string{modulePath + " " + vers + " h1:zip=", modulePath + " " + vers + "/go.mod h1:mod="}
+	if !cmp.Equal(got, want) {
+		t.Errorf("second fetch: got %q, want %q", got, want)
+	}
+}
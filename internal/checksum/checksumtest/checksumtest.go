@@ -0,0 +1,80 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package checksumtest supports testing with a checksum database.
+package checksumtest
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/rand"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"golang.org/x/mod/sumdb"
+	"golang.org/x/mod/sumdb/note"
+	"golang.org/x/pkgsite/internal/checksum"
+	"golang.org/x/pkgsite/internal/derrors"
+	"golang.org/x/pkgsite/internal/proxy/proxytest"
+	"golang.org/x/pkgsite/internal/testing/testhelper"
+)
+
+// dbName is the name of the test checksum database.
+const dbName = "sum.example.test"
+
+// SetupTestVerifier creates a local checksum database for testing, whose
+// records are the go.sum lines of the given modules, as served by
+// proxytest.
+//
+// It returns a Verifier for the database and a function for tearing down
+// the database after the test is completed.
+func SetupTestVerifier(t *testing.T, modules []*proxytest.Module) (*checksum.Verifier, func()) {
+	t.Helper()
+	skey, vkey, err := note.GenerateKey(rand.Reader, dbName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gosum := func(path, vers string) ([]byte, error) {
+		m := proxytest.FindModule(modules, path, vers)
+		if m == nil {
+			return nil, fmt.Errorf("%s@%s: %w", path, vers, derrors.NotFound)
+		}
+		return goSumLines(m)
+	}
+	srv := sumdb.NewServer(sumdb.NewTestServer(skey, gosum))
+	mux := http.NewServeMux()
+	for _, p := range sumdb.ServerPaths {
+		mux.Handle(p, srv)
+	}
+	httpClient, s, serverClose := testhelper.SetupTestClientAndServer(mux)
+	v, err := checksum.NewVerifier(vkey+" "+s.URL, httpClient)
+	if err != nil {
+		serverClose()
+		t.Fatal(err)
+	}
+	return v, serverClose
+}
+
+// goSumLines returns the go.sum lines for m.
+func goSumLines(m *proxytest.Module) ([]byte, error) {
+	z, err := m.Zip()
+	if err != nil {
+		return nil, err
+	}
+	zr, err := zip.NewReader(bytes.NewReader(z), int64(len(z)))
+	if err != nil {
+		return nil, err
+	}
+	zipHash, err := checksum.HashZip(zr)
+	if err != nil {
+		return nil, err
+	}
+	modHash, err := checksum.HashMod([]byte(m.GoMod()))
+	if err != nil {
+		return nil, err
+	}
+	return []byte(fmt.Sprintf("%s %s %s\n%s %s/go.mod %s\n",
+		m.ModulePath, m.Version, zipHash, m.ModulePath, m.Version, modHash)), nil
+}
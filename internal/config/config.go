@@ -12,6 +12,8 @@ import (
 	"io"
 	"strings"
 	"time"
+
+	"golang.org/x/pkgsite/internal"
 )
 
 // AppVersionFormat is the expected format of the app version timestamp.
@@ -115,6 +117,24 @@ type Config struct {
 
 	// VulnDB is the URL of the Go vulnerability DB.
 	VulnDB string
+
+	// GitRepos maps module paths to local git repositories that the worker
+	// fetches those modules from, instead of the proxy.
+	GitRepos map[string]string
+
+	// BuildContexts are the build contexts to load packages in and to show
+	// documentation for. If empty, internal.BuildContexts is left alone.
+	BuildContexts []internal.BuildContext
+
+	// ChecksumDB is the checksum database that the worker verifies module
+	// zips and go.mod files against, in the form of the GOSUMDB environment
+	// variable. If it is "off", modules are not verified.
+	ChecksumDB string
+
+	// GoAPIDir is the directory of Go API files, like the api directory of a
+	// Go installation, that the worker uses to compute the minimum Go version
+	// of each package. If it is empty, minimum Go versions are not computed.
+	GoAPIDir string
 }
 
 // MonitoredResource represents the resource that is running the current binary.
@@ -15,12 +15,15 @@ import (
 	"net/http"
 	"os"
 	"path"
+	"path/filepath"
+	"runtime"
 	"strconv"
 	"strings"
 	"time"
 
 	"cloud.google.com/go/storage"
 	"golang.org/x/net/context/ctxhttp"
+	"golang.org/x/pkgsite/internal"
 	"golang.org/x/pkgsite/internal/config"
 	"golang.org/x/pkgsite/internal/derrors"
 	"golang.org/x/pkgsite/internal/log"
@@ -168,9 +171,19 @@ func Init(ctx context.Context) (_ *config.Config, err error) {
 		ServeStats:            os.Getenv("GO_DISCOVERY_SERVE_STATS") == "true",
 		DisableErrorReporting: os.Getenv("GO_DISCOVERY_DISABLE_ERROR_REPORTING") == "true",
 		VulnDB:                GetEnv("GO_DISCOVERY_VULN_DB", "https://storage.googleapis.com/go-vulndb"),
+		GitRepos:              parseGitRepos(os.Getenv("GO_DISCOVERY_GIT_REPOS")),
+		ChecksumDB:            GetEnv("GO_DISCOVERY_CHECKSUM_DB", "sum.golang.org"),
+		GoAPIDir:              GetEnv("GO_DISCOVERY_GO_API_DIR", filepath.Join(runtime.GOROOT(), "api")),
 	}
 	log.SetLevel(cfg.LogLevel)
 
+	if bcs := os.Getenv("GO_DISCOVERY_BUILD_CONTEXTS"); bcs != "" {
+		cfg.BuildContexts, err = internal.ParseBuildContexts(bcs)
+		if err != nil {
+			return nil, fmt.Errorf("GO_DISCOVERY_BUILD_CONTEXTS: %v", err)
+		}
+	}
+
 	bucket := os.Getenv("GO_DISCOVERY_CONFIG_BUCKET")
 	configDynamic := os.Getenv("GO_DISCOVERY_CONFIG_DYNAMIC")
 	exclude := os.Getenv("GO_DISCOVERY_EXCLUDED_FILENAME")
@@ -375,6 +388,23 @@ func gceMetadata(ctx context.Context, name string) (_ string, err error) {
 	return string(bytes), nil
 }
 
+// parseGitRepos parses a comma-separated list of modulePath=directory pairs.
+// Malformed elements are ignored.
+func parseGitRepos(s string) map[string]string {
+	var m map[string]string
+	for _, p := range parseCommaList(s) {
+		modulePath, dir, ok := strings.Cut(p, "=")
+		if !ok || modulePath == "" || dir == "" {
+			continue
+		}
+		if m == nil {
+			m = map[string]string{}
+		}
+		m[modulePath] = dir
+	}
+	return m
+}
+
 func parseCommaList(s string) []string {
 	var a []string
 	for _, p := range strings.Split(s, ",") {
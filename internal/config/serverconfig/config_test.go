@@ -98,6 +98,23 @@ func TestParseCommaList(t *testing.T) {
 	}
 }
 
+func TestParseGitRepos(t *testing.T) {
+	for _, test := range []struct {
+		in   string
+		want map[string]string
+	}{
+		{"", nil},
+		{"example.com/a=/repos/a", map[string]string{"example.com/a": "/repos/a"}},
+		{"example.com/a=/repos/a, example.com/b=/repos/b", map[string]string{"example.com/a": "/repos/a", "example.com/b": "/repos/b"}},
+		{"example.com/a,=/repos/b,example.com/c=", nil},
+	} {
+		got := parseGitRepos(test.in)
+		if !cmp.Equal(got, test.want) {
+			t.Errorf("%q: got %#v, want %#v", test.in, got, test.want)
+		}
+	}
+}
+
 func TestEnvAndApp(t *testing.T) {
 	for _, test := range []struct {
 		serviceID string
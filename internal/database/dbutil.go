@@ -175,6 +175,7 @@ func ResetDB(ctx context.Context, db *DB) error {
 			TRUNCATE paths CASCADE;
 			TRUNCATE symbol_names CASCADE;
 			TRUNCATE imports_unique;
+			TRUNCATE symbol_references_unique;
 			TRUNCATE latest_module_versions;`); err != nil {
 			return err
 		}
@@ -184,6 +185,9 @@ func ResetDB(ctx context.Context, db *DB) error {
 		if _, err := tx.Exec(ctx, `TRUNCATE excluded_prefixes;`); err != nil {
 			return err
 		}
+		if _, err := tx.Exec(ctx, `TRUNCATE alternative_module_paths;`); err != nil {
+			return err
+		}
 		return nil
 	}); err != nil {
 		return fmt.Errorf("error resetting test DB: %v", err)
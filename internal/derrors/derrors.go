@@ -50,6 +50,11 @@ var (
 	// any module, up to the max size allowed by the proxy.
 	ModuleTooLarge = errors.New("module too large")
 
+	// ChecksumMismatch indicates that the module zip or go.mod file served by
+	// the proxy does not match the checksum database, or that the checksum
+	// database itself is misbehaving.
+	ChecksumMismatch = errors.New("checksum mismatch")
+
 	// SheddingLoad indicates that the server is overloaded and cannot process the
 	// module at this time.
 	SheddingLoad = errors.New("shedding load")
@@ -134,6 +139,7 @@ var codes = []struct {
 	{AlternativeModule, 491},
 	{ModuleTooLarge, 492},
 	{Cleaned, 493},
+	{ChecksumMismatch, 494},
 
 	{ProxyTimedOut, 550}, // not a real code
 	{ProxyError, 551},    // not a real code
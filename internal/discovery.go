@@ -14,6 +14,7 @@ import (
 	"golang.org/x/mod/module"
 	"golang.org/x/pkgsite/internal/derrors"
 	"golang.org/x/pkgsite/internal/licenses"
+	"golang.org/x/pkgsite/internal/minhash"
 	"golang.org/x/pkgsite/internal/source"
 	"golang.org/x/pkgsite/internal/stdlib"
 )
@@ -146,6 +147,20 @@ func V1Path(fullPath, modulePath string) string {
 	return path.Join(SeriesPathForModule(modulePath), Suffix(fullPath, modulePath))
 }
 
+// DefaultPackageName returns the name that the package with the given import
+// path most likely has, for use when the package itself is not at hand: the
+// last element of the path, ignoring a major version suffix like "/v2", and
+// without an extension like ".v2", a "go-" prefix or hyphens. For example,
+// "gopkg.in/yaml.v3" => "yaml" and "example.com/go-foo-bar/v2" => "foo_bar".
+func DefaultPackageName(importPath string) string {
+	dir, base := path.Split(importPath)
+	if dir != "" && len(base) > 1 && base[0] == 'v' && strings.Trim(base[1:], "0123456789") == "" {
+		base = path.Base(dir)
+	}
+	base = strings.TrimSuffix(base, path.Ext(base))
+	return strings.ReplaceAll(strings.TrimPrefix(base, "go-"), "-", "_")
+}
+
 // A Module is a specific, reproducible build of a module.
 type Module struct {
 	ModuleInfo
@@ -153,6 +168,17 @@ type Module struct {
 	// that may be contained in nested subdirectories.
 	Licenses []*licenses.License
 	Units    []*Unit
+	// GoMod holds the directives of the module's go.mod file. It is nil if
+	// the module has no go.mod file.
+	GoMod *GoModDirectives
+	// GoSum holds the go.sum lines for the module version, as recorded in the
+	// checksum database it was verified against. It is nil if the module was
+	// not verified.
+	GoSum []string
+	// MinHash summarizes the contents of the module's .go files, so that
+	// modules that are forks or copies of others can be recognized. It is nil
+	// if the module is too small to compare.
+	MinHash minhash.Signature
 }
 
 // Packages returns all of the units for a module that are packages.
@@ -96,3 +96,23 @@ func TestV1Path(t *testing.T) {
 		}
 	}
 }
+
+func TestDefaultPackageName(t *testing.T) {
+	for _, test := range []struct {
+		in, want string
+	}{
+		{"fmt", "fmt"},
+		{"math/rand/v2", "rand"},
+		{"example.com/m", "m"},
+		{"example.com/m/v3", "m"},
+		{"v2", "v2"},
+		{"example.com/vx", "vx"},
+		{"gopkg.in/yaml.v3", "yaml"},
+		{"github.com/mattn/go-sqlite3", "sqlite3"},
+		{"example.com/go-foo-bar/v2", "foo_bar"},
+	} {
+		if got := DefaultPackageName(test.in); got != test.want {
+			t.Errorf("DefaultPackageName(%q) = %q, want %q", test.in, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,302 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package docbundle packages the documentation of module versions for
+// reading offline, as a Dash or Zeal docset or as a single HTML file.
+//
+// The documentation is rendered by package dochtml, from the documentation
+// stored by a data source. Links between the packages of a bundle are
+// rewritten to stay in the bundle, and links to other packages point to
+// pkg.go.dev.
+package docbundle
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/derrors"
+	"golang.org/x/pkgsite/internal/godoc"
+	"golang.org/x/pkgsite/internal/log"
+)
+
+// externalSite is where links to packages that are not in a bundle point.
+const externalSite = "https://pkg.go.dev"
+
+// A Package is the documentation of a package in a bundle.
+type Package struct {
+	Path       string
+	Name       string
+	Synopsis   string
+	ModulePath string
+	Version    string
+	// Body is the HTML of the documentation, as rendered by dochtml.
+	Body string
+}
+
+// Load returns the documentation of the packages of the given module
+// version, sorted by path. The version may be a query like "latest".
+// Packages without documentation, or whose documentation can't be displayed
+// because of their licenses, are omitted.
+func Load(ctx context.Context, ds internal.DataSource, modulePath, version string) (_ []*Package, err error) {
+	defer derrors.Wrap(&err, "docbundle.Load(%q, %q)", modulePath, version)
+
+	um, err := ds.GetUnitMeta(ctx, modulePath, modulePath, version)
+	if err != nil {
+		return nil, err
+	}
+	root, err := ds.GetUnit(ctx, um, internal.WithMain, internal.BuildContext{})
+	if err != nil {
+		return nil, err
+	}
+	// The subdirectories of the module root are the other units of the
+	// module.
+	paths := []string{root.Path}
+	for _, pm := range root.Subdirectories {
+		if pm.Path != root.Path {
+			paths = append(paths, pm.Path)
+		}
+	}
+	var pkgs []*Package
+	for _, path := range paths {
+		pum, err := ds.GetUnitMeta(ctx, path, um.ModulePath, um.Version)
+		if err != nil {
+			return nil, err
+		}
+		if !pum.IsPackage() {
+			continue
+		}
+		u, err := ds.GetUnit(ctx, pum, internal.WithMain, internal.BuildContext{})
+		if err != nil {
+			return nil, err
+		}
+		if !u.IsRedistributable || len(u.Documentation) == 0 || u.Documentation[0].Source == nil {
+			continue
+		}
+		parts, err := godoc.RenderFromUnit(ctx, u, internal.BuildContext{})
+		if err != nil {
+			log.Errorf(ctx, "docbundle: skipping %s: %v", path, err)
+			continue
+		}
+		pkgs = append(pkgs, &Package{
+			Path:       u.Path,
+			Name:       u.Name,
+			Synopsis:   u.Documentation[0].Synopsis,
+			ModulePath: u.ModulePath,
+			Version:    u.Version,
+			Body:       parts.Body.String(),
+		})
+	}
+	sort.Slice(pkgs, func(i, j int) bool { return pkgs[i].Path < pkgs[j].Path })
+	return pkgs, nil
+}
+
+// dashTypes maps the kinds of declarations in the data-kind attributes of
+// the HTML rendered by dochtml to the entry types of a docset.
+var dashTypes = map[string]string{
+	"constant": "Constant",
+	"variable": "Variable",
+	"function": "Function",
+	"type":     "Type",
+	"method":   "Method",
+	"field":    "Field",
+}
+
+// A bundle is a set of packages whose documentation is being rewritten.
+type bundle struct {
+	pkgs []*Package
+	// inBundle holds the paths of the packages.
+	inBundle map[string]bool
+	// link returns the link to the documentation of the package path, at
+	// the fragment frag, from the documentation of the package from. Both
+	// packages are in the bundle.
+	link func(from, path, frag string) string
+	// id returns the id to use for the element with the given id in the
+	// documentation of the package path.
+	id func(path, id string) string
+}
+
+func newBundle(pkgs []*Package, link func(from, path, frag string) string, id func(path, id string) string) *bundle {
+	b := &bundle{link: link, id: id, inBundle: map[string]bool{}}
+	for _, p := range pkgs {
+		// The first of several packages with the same path wins.
+		if !b.inBundle[p.Path] {
+			b.inBundle[p.Path] = true
+			b.pkgs = append(b.pkgs, p)
+		}
+	}
+	return b
+}
+
+// rewrite parses the documentation of p and rewrites its ids and links. It
+// returns the rewritten HTML and an entry for each declaration, with a path
+// of the form "#id".
+func (b *bundle) rewrite(p *Package) (string, []indexEntry, error) {
+	ctxNode := &html.Node{Type: html.ElementNode, Data: "body", DataAtom: atom.Body}
+	nodes, err := html.ParseFragment(strings.NewReader(p.Body), ctxNode)
+	if err != nil {
+		return "", nil, fmt.Errorf("%s: %v", p.Path, err)
+	}
+	var (
+		entries []indexEntry
+		seen    = map[string]bool{}
+		buf     bytes.Buffer
+	)
+	for _, n := range nodes {
+		walkHTML(n, func(n *html.Node) {
+			if n.Type != html.ElementNode {
+				return
+			}
+			id, kind := attrValue(n, "id"), attrValue(n, "data-kind")
+			// The ids of types appear both in their headings and in their
+			// declarations.
+			if typ := dashTypes[kind]; typ != "" && id != "" && !seen[id] {
+				seen[id] = true
+				entries = append(entries, indexEntry{Name: id, Type: typ, Path: "#" + id})
+			}
+			for i, a := range n.Attr {
+				switch a.Key {
+				case "id":
+					n.Attr[i].Val = b.id(p.Path, a.Val)
+				case "href":
+					n.Attr[i].Val = b.rewriteLink(p.Path, a.Val)
+				}
+			}
+		})
+		if err := html.Render(&buf, n); err != nil {
+			return "", nil, err
+		}
+	}
+	return buf.String(), entries, nil
+}
+
+// rewriteLink returns the link to use in place of link in the documentation of
+// the package from.
+func (b *bundle) rewriteLink(from, link string) string {
+	u, err := url.Parse(link)
+	if err != nil || u.Scheme != "" || u.Host != "" {
+		return link
+	}
+	if u.Path == "" {
+		return b.link(from, from, u.Fragment)
+	}
+	if !strings.HasPrefix(u.Path, "/") {
+		return link
+	}
+	if path := importPath(u.Path); b.inBundle[path] {
+		return b.link(from, path, u.Fragment)
+	}
+	return externalSite + link
+}
+
+// importPath returns the import path of the package at the URL path p, which
+// may include a version, as in "/example.com/m@v1.0.0/p".
+func importPath(p string) string {
+	p = strings.TrimPrefix(p, "/")
+	modulePath, rest, ok := strings.Cut(p, "@")
+	if !ok {
+		return p
+	}
+	if _, inner, ok := strings.Cut(rest, "/"); ok {
+		return modulePath + "/" + inner
+	}
+	return modulePath
+}
+
+// BaseName returns a file name, without an extension, for a bundle with the
+// given name.
+func BaseName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if 'a' <= r && r <= 'z' || 'A' <= r && r <= 'Z' || '0' <= r && r <= '9' || r == '.' || r == '-' {
+			return r
+		}
+		return '_'
+	}, name)
+}
+
+func walkHTML(n *html.Node, f func(*html.Node)) {
+	f(n)
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		walkHTML(c, f)
+	}
+}
+
+func attrValue(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+// styles is the style sheet of the pages of a bundle. It styles the classes
+// used by dochtml.
+const styles = `body {
+  font-family: -apple-system, BlinkMacSystemFont, "Segoe UI", Helvetica, Arial, sans-serif;
+  line-height: 1.5;
+  margin: 1rem auto;
+  max-width: 60rem;
+  padding: 0 1rem;
+  color: #202224;
+}
+a {
+  color: #007d9c;
+  text-decoration: none;
+}
+a:hover {
+  text-decoration: underline;
+}
+pre, code {
+  font-family: Menlo, Consolas, monospace;
+  font-size: 0.875rem;
+}
+pre {
+  background: #f8f8f8;
+  border: 1px solid #dadce0;
+  border-radius: 0.3rem;
+  overflow-x: auto;
+  padding: 0.625rem;
+}
+.comment {
+  color: #006600;
+}
+.Documentation-index ul,
+.Documentation-examplesList {
+  padding-left: 1.5rem;
+}
+.Documentation-sinceVersion,
+.Documentation-source,
+.Documentation-idLink {
+  color: #6e6e6e;
+  font-size: 0.875rem;
+  font-weight: normal;
+}
+.Documentation-idLink {
+  visibility: hidden;
+}
+h2:hover .Documentation-idLink,
+h3:hover .Documentation-idLink,
+h4:hover .Documentation-idLink {
+  visibility: visible;
+}
+.Documentation-deprecatedTag {
+  background: #dadce0;
+  border-radius: 0.125rem;
+  font-size: 0.75rem;
+  padding: 0.125rem 0.25rem;
+}
+.Documentation-exampleButtonsContainer {
+  display: none;
+}
+.Bundle-module {
+  color: #6e6e6e;
+}
+`
@@ -0,0 +1,171 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package docbundle
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/safehtml/template"
+	"golang.org/x/pkgsite/internal/godoc/dochtml"
+	"golang.org/x/pkgsite/internal/testing/fakedatasource"
+	"golang.org/x/pkgsite/internal/testing/sample"
+)
+
+func TestMain(m *testing.M) {
+	dochtml.LoadTemplates(template.TrustedFSFromTrustedSource(template.TrustedSourceFromConstant("../../static")))
+	os.Exit(m.Run())
+}
+
+var testPackages = []*Package{
+	{
+		Path:       "example.com/m/p",
+		Name:       "p",
+		Synopsis:   "Package p does things.",
+		ModulePath: "example.com/m",
+		Version:    "v1.0.0",
+		Body: `<h4 id="T" data-kind="type">type <a href="https://example.com/src/p.go#L3">T</a> <a href="#T">¶</a></h4>` +
+			`<pre>type <span id="T" data-kind="type">T</span> struct {<span id="T.F" data-kind="field">F</span> <a href="/example.com/m@v1.0.0/q#Q">q.Q</a>; R <a href="/io#Reader">io.Reader</a>}</pre>` +
+			`<h4 id="T.M" data-kind="method">func (T) M</h4>` +
+			`<p>See <a href="/example.com/m@v1.0.0/q">package q</a> and <a href="#hdr-Usage">Usage</a>.</p>`,
+	},
+	{
+		Path:       "example.com/m/q",
+		Name:       "q",
+		ModulePath: "example.com/m",
+		Version:    "v1.0.0",
+		Body:       `<pre>var <span id="Q" data-kind="variable">Q</span> int</pre>`,
+	},
+}
+
+func TestLoad(t *testing.T) {
+	ctx := context.Background()
+	ds := fakedatasource.New()
+	ds.MustInsertModule(ctx, sample.Module(sample.ModulePath, sample.VersionString, "foo", "bar"))
+	pkgs, err := Load(ctx, ds, sample.ModulePath, "latest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var paths []string
+	for _, p := range pkgs {
+		paths = append(paths, p.Path)
+		if p.Version != sample.VersionString || !strings.Contains(p.Body, `id="V"`) {
+			t.Errorf("%s: got version %q and body\n%s", p.Path, p.Version, p.Body)
+		}
+	}
+	want := []string{sample.ModulePath + "/bar", sample.ModulePath + "/foo"}
+	if diff := cmp.Diff(want, paths); diff != "" {
+		t.Errorf("paths mismatch (-want, +got):\n%s", diff)
+	}
+}
+
+func TestWriteDocset(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteDocset(&buf, "example.com/m@v1.0.0", testPackages); err != nil {
+		t.Fatal(err)
+	}
+	files := readTGZ(t, &buf)
+	const dir = "example.com_m_v1.0.0.docset/Contents/"
+	for _, f := range []string{"Info.plist", "Resources/Documents/index.html", "Resources/Documents/style.css"} {
+		if _, ok := files[dir+f]; !ok {
+			t.Errorf("missing %s", f)
+		}
+	}
+
+	page := files[dir+"Resources/Documents/example.com/m/p/index.html"]
+	for _, want := range []string{
+		`<link rel="stylesheet" href="../../../style.css">`,
+		`<a href="https://example.com/src/p.go#L3">`,
+		`<a href="#T">`,
+		`<a href="../../../example.com/m/q/index.html#Q">`,
+		`<a href="https://pkg.go.dev/io#Reader">`,
+		`<a href="../../../example.com/m/q/index.html">`,
+		`<a href="#hdr-Usage">`,
+	} {
+		if !strings.Contains(page, want) {
+			t.Errorf("page of p does not contain %s:\n%s", want, page)
+		}
+	}
+
+	var got []indexEntry
+	for _, r := range readTable(t, []byte(files[dir+"Resources/docSet.dsidx"]), 2) {
+		got = append(got, indexEntry{Name: r[2].(string), Type: r[3].(string), Path: r[4].(string)})
+	}
+	want := []indexEntry{
+		{"example.com/m/p", "Package", "example.com/m/p/index.html"},
+		{"T", "Type", "example.com/m/p/index.html#T"},
+		{"T.F", "Field", "example.com/m/p/index.html#T.F"},
+		{"T.M", "Method", "example.com/m/p/index.html#T.M"},
+		{"example.com/m/q", "Package", "example.com/m/q/index.html"},
+		{"Q", "Variable", "example.com/m/q/index.html#Q"},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("search index mismatch (-want, +got):\n%s", diff)
+	}
+}
+
+func TestWriteHTML(t *testing.T) {
+	var buf bytes.Buffer
+	// The duplicate package is omitted.
+	if err := WriteHTML(&buf, "example.com/m@v1.0.0", append(testPackages, testPackages[1])); err != nil {
+		t.Fatal(err)
+	}
+	got := buf.String()
+	for _, want := range []string{
+		`<a href="#example.com/m/p">example.com/m/p</a> &mdash; Package p does things.`,
+		`<section id="example.com/m/p">`,
+		`<h4 id="example.com/m/p.T" data-kind="type">`,
+		`<a href="#example.com/m/p.T">`,
+		`<span id="example.com/m/p.T.F" data-kind="field">`,
+		`<a href="#example.com/m/q.Q">`,
+		`<a href="#example.com/m/q">package q</a>`,
+		`<a href="https://pkg.go.dev/io#Reader">`,
+		`<a href="#example.com/m/p.hdr-Usage">`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("missing %s", want)
+		}
+	}
+	if n := strings.Count(got, `<section id="example.com/m/q">`); n != 1 {
+		t.Errorf("got %d sections for q, want 1", n)
+	}
+	if strings.Contains(got, "<link") {
+		t.Errorf("HTML file links to other files:\n%s", got)
+	}
+}
+
+// readTGZ returns the contents of the files in the gzipped tar file r.
+func readTGZ(t *testing.T, r io.Reader) map[string]string {
+	t.Helper()
+	gr, err := gzip.NewReader(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr := tar.NewReader(gr)
+	files := map[string]string{}
+	for {
+		h, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		files[h.Name] = string(data)
+	}
+	return files
+}
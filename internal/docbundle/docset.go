@@ -0,0 +1,174 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package docbundle
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/net/html"
+)
+
+// WriteDocset writes a docset for Dash and Zeal, named name, with the
+// documentation of pkgs, to w as a gzipped tar file. The docset is in a
+// directory named BaseName(name) + ".docset".
+//
+// Each package is documented at Contents/Resources/Documents/<path>/index.html,
+// and the search index has an entry for each package and each declaration.
+// If several packages have the same path, only the first is written.
+func WriteDocset(w io.Writer, name string, pkgs []*Package) (err error) {
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("docbundle.WriteDocset(%q): %v", name, err)
+		}
+	}()
+
+	dir := BaseName(name) + ".docset/Contents/"
+	docs := dir + "Resources/Documents/"
+	gw := gzip.NewWriter(w)
+	tw := tar.NewWriter(gw)
+	writeFile := func(file string, data []byte) error {
+		if err := tw.WriteHeader(&tar.Header{
+			Name:     file,
+			Mode:     0o644,
+			Size:     int64(len(data)),
+			ModTime:  time.Unix(0, 0),
+			Typeflag: tar.TypeReg,
+		}); err != nil {
+			return err
+		}
+		_, err := tw.Write(data)
+		return err
+	}
+
+	b := newBundle(pkgs,
+		func(from, path, frag string) string {
+			link := ""
+			if path != from {
+				link = strings.Repeat("../", strings.Count(from, "/")+1) + path + "/index.html"
+			}
+			if frag != "" {
+				link += "#" + frag
+			}
+			return link
+		},
+		func(path, id string) string { return id })
+
+	var entries []indexEntry
+	for _, p := range b.pkgs {
+		body, pentries, err := b.rewrite(p)
+		if err != nil {
+			return err
+		}
+		file := p.Path + "/index.html"
+		entries = append(entries, indexEntry{Name: p.Path, Type: "Package", Path: file})
+		for _, e := range pentries {
+			e.Path = file + e.Path
+			entries = append(entries, e)
+		}
+		var page bytes.Buffer
+		writePageStart(&page, "package "+p.Name, strings.Repeat("../", strings.Count(p.Path, "/")+1)+"style.css")
+		writePackageHeader(&page, p)
+		page.WriteString(body)
+		page.WriteString("</body>\n</html>\n")
+		if err := writeFile(docs+file, page.Bytes()); err != nil {
+			return err
+		}
+	}
+
+	var index bytes.Buffer
+	writePageStart(&index, name, "style.css")
+	fmt.Fprintf(&index, "<h1>%s</h1>\n", html.EscapeString(name))
+	writePackageList(&index, b.pkgs, func(p *Package) string { return p.Path + "/index.html" })
+	index.WriteString("</body>\n</html>\n")
+	if err := writeFile(docs+"index.html", index.Bytes()); err != nil {
+		return err
+	}
+	if err := writeFile(docs+"style.css", []byte(styles)); err != nil {
+		return err
+	}
+	db, err := writeSearchIndex(entries)
+	if err != nil {
+		return err
+	}
+	if err := writeFile(dir+"Resources/docSet.dsidx", db); err != nil {
+		return err
+	}
+	if err := writeFile(dir+"Info.plist", infoPlist(name)); err != nil {
+		return err
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gw.Close()
+}
+
+// infoPlist returns the Info.plist file of a docset named name.
+func infoPlist(name string) []byte {
+	id := strings.ToLower(BaseName(name))
+	return []byte(fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+  <key>CFBundleIdentifier</key>
+  <string>%s</string>
+  <key>CFBundleName</key>
+  <string>%s</string>
+  <key>DocSetPlatformFamily</key>
+  <string>go</string>
+  <key>isDashDocset</key>
+  <true/>
+  <key>isJavaScriptEnabled</key>
+  <false/>
+  <key>dashIndexFilePath</key>
+  <string>index.html</string>
+</dict>
+</plist>
+`, html.EscapeString(id), html.EscapeString(name)))
+}
+
+// writePageStart writes the start of an HTML page with the given title and
+// style sheet, up to and including the body tag.
+func writePageStart(buf *bytes.Buffer, title, styleSheet string) {
+	fmt.Fprintf(buf, `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>%s</title>
+`, html.EscapeString(title))
+	if styleSheet != "" {
+		fmt.Fprintf(buf, "<link rel=\"stylesheet\" href=\"%s\">\n", html.EscapeString(styleSheet))
+	} else {
+		fmt.Fprintf(buf, "<style>\n%s</style>\n", styles)
+	}
+	buf.WriteString("</head>\n<body>\n")
+}
+
+// writePackageHeader writes the heading of the documentation of p.
+func writePackageHeader(buf *bytes.Buffer, p *Package) {
+	fmt.Fprintf(buf, "<h1>package %s</h1>\n<pre>import %s</pre>\n<p class=\"Bundle-module\">%s@%s</p>\n",
+		html.EscapeString(p.Name), html.EscapeString(strconv.Quote(p.Path)),
+		html.EscapeString(p.ModulePath), html.EscapeString(p.Version))
+}
+
+// writePackageList writes a list of links to the documentation of pkgs, at
+// the URLs returned by url.
+func writePackageList(buf *bytes.Buffer, pkgs []*Package, url func(*Package) string) {
+	buf.WriteString("<ul>\n")
+	for _, p := range pkgs {
+		fmt.Fprintf(buf, "<li><a href=\"%s\">%s</a>", html.EscapeString(url(p)), html.EscapeString(p.Path))
+		if p.Synopsis != "" {
+			fmt.Fprintf(buf, " &mdash; %s", html.EscapeString(p.Synopsis))
+		}
+		buf.WriteString("</li>\n")
+	}
+	buf.WriteString("</ul>\n")
+}
@@ -0,0 +1,63 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package docbundle
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+
+	"golang.org/x/net/html"
+)
+
+// WriteHTML writes a single self-contained HTML file, titled title, with the
+// documentation of pkgs to w.
+//
+// The documentation of each package is in a section whose id is the package
+// path. The ids in the documentation are prefixed with the package path and a
+// period, so the documentation of the method T.M of package example.com/p is
+// at #example.com/p.T.M. If several packages have the same path, only the
+// first is written.
+func WriteHTML(w io.Writer, title string, pkgs []*Package) (err error) {
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("docbundle.WriteHTML(%q): %v", title, err)
+		}
+	}()
+
+	b := newBundle(pkgs,
+		func(from, path, frag string) string {
+			if frag == "" {
+				return "#" + path
+			}
+			return "#" + htmlID(path, frag)
+		},
+		htmlID)
+
+	var buf bytes.Buffer
+	writePageStart(&buf, title, "")
+	fmt.Fprintf(&buf, "<h1>%s</h1>\n<nav>\n", html.EscapeString(title))
+	writePackageList(&buf, b.pkgs, func(p *Package) string { return "#" + p.Path })
+	buf.WriteString("</nav>\n")
+	for _, p := range b.pkgs {
+		body, _, err := b.rewrite(p)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(&buf, "<hr>\n<section id=\"%s\">\n", html.EscapeString(p.Path))
+		writePackageHeader(&buf, p)
+		buf.WriteString(body)
+		buf.WriteString("</section>\n")
+	}
+	buf.WriteString("</body>\n</html>\n")
+	_, err = w.Write(buf.Bytes())
+	return err
+}
+
+// htmlID returns the id in a single HTML file of the element with the given id
+// in the documentation of the package path.
+func htmlID(path, id string) string {
+	return path + "." + id
+}
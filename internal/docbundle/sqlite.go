@@ -0,0 +1,227 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package docbundle
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// This file writes the search index of a docset, which is an SQLite database
+// with a single table:
+//
+//	CREATE TABLE searchIndex(id INTEGER PRIMARY KEY, name TEXT, type TEXT, path TEXT)
+//
+// pkgsite doesn't depend on an SQLite driver, and the database is written
+// once and never updated, so it is written directly in the SQLite file format
+// described at https://www.sqlite.org/fileformat.html. Page 1 holds the schema
+// table, and page 2 is the root of the b-tree of searchIndex.
+
+const (
+	pageSize = 4096
+	// maxPayload is the size of the largest record stored entirely in a
+	// b-tree leaf page. Larger records would need overflow pages, which are
+	// not supported.
+	maxPayload = pageSize - 35
+
+	leafHeaderSize     = 8
+	interiorHeaderSize = 12
+
+	leafTablePage     = 0x0d
+	interiorTablePage = 0x05
+)
+
+const searchIndexSchema = "CREATE TABLE searchIndex(id INTEGER PRIMARY KEY, name TEXT, type TEXT, path TEXT)"
+
+// An indexEntry is a row of the searchIndex table: a named item of the given
+// type, documented at path, relative to the Documents directory of the
+// docset.
+type indexEntry struct {
+	Name, Type, Path string
+}
+
+// writeSearchIndex returns the contents of an SQLite database whose
+// searchIndex table holds entries, in order.
+func writeSearchIndex(entries []indexEntry) ([]byte, error) {
+	var leaves []*btreePage
+	leaf := &btreePage{}
+	for i, e := range entries {
+		rowid := int64(i + 1)
+		rec := record(nil, e.Name, e.Type, e.Path)
+		if len(rec) > maxPayload {
+			return nil, fmt.Errorf("search index entry for %q is too large", e.Name)
+		}
+		cell := appendVarint(nil, uint64(len(rec)))
+		cell = appendVarint(cell, uint64(rowid))
+		cell = append(cell, rec...)
+		if !leaf.fits(cell, leafHeaderSize) {
+			leaves = append(leaves, leaf)
+			leaf = &btreePage{}
+			leaf.fits(cell, leafHeaderSize)
+		}
+		leaf.cells = append(leaf.cells, cell)
+		leaf.maxKey = rowid
+	}
+	leaves = append(leaves, leaf)
+
+	// Build the interior levels of the tree, bottom up, until there is a
+	// single root.
+	level := leaves
+	for len(level) > 1 {
+		var parents []*btreePage
+		parent := &btreePage{interior: true}
+		for _, child := range level {
+			// The child will be the right-most pointer of the parent unless
+			// another child follows it, in which case it gets a cell.
+			if parent.right != nil {
+				cell := binary.BigEndian.AppendUint32(nil, 0) // page number, set below
+				cell = appendVarint(cell, uint64(parent.right.maxKey))
+				if parent.fits(cell, interiorHeaderSize) {
+					parent.cells = append(parent.cells, cell)
+					parent.children = append(parent.children, parent.right)
+				} else {
+					parents = append(parents, parent)
+					parent = &btreePage{interior: true}
+				}
+			}
+			parent.right = child
+			parent.maxKey = child.maxKey
+		}
+		level = append(parents, parent)
+	}
+	root := level[0]
+
+	// Number the pages: the root is page 2, as recorded in the schema, and
+	// the rest follow in breadth-first order.
+	pages := []*btreePage{root}
+	for i := 0; i < len(pages); i++ {
+		p := pages[i]
+		p.number = uint32(i + 2)
+		if p.interior {
+			pages = append(pages, p.children...)
+			pages = append(pages, p.right)
+		}
+	}
+
+	buf := make([]byte, pageSize*(len(pages)+1))
+	writeHeader(buf, uint32(len(pages)+1))
+	schema := &btreePage{}
+	rec := record("table", "searchIndex", "searchIndex", int64(2), searchIndexSchema)
+	cell := appendVarint(nil, uint64(len(rec)))
+	cell = appendVarint(cell, 1)
+	schema.cells = append(schema.cells, append(cell, rec...))
+	schema.write(buf[:pageSize], 100)
+	for _, p := range pages {
+		off := int(p.number-1) * pageSize
+		p.write(buf[off:off+pageSize], 0)
+	}
+	return buf, nil
+}
+
+// A btreePage is a page of a table b-tree.
+type btreePage struct {
+	number   uint32
+	interior bool
+	cells    [][]byte
+	// maxKey is the largest rowid in the page and its descendants.
+	maxKey int64
+	// For interior pages, children are the pages to the left of each
+	// cell, and right is the right-most child.
+	children []*btreePage
+	right    *btreePage
+	size     int // total size of cells and cell pointers
+}
+
+// fits reports whether cell can be added to the page, whose header has the
+// given size, and if so adds its size to the page's.
+func (p *btreePage) fits(cell []byte, headerSize int) bool {
+	if headerSize+p.size+len(cell)+2 > pageSize {
+		return false
+	}
+	p.size += len(cell) + 2
+	return true
+}
+
+// write writes the page to buf. The page header starts at offset start,
+// which is 100 for page 1 and 0 for the others.
+func (p *btreePage) write(buf []byte, start int) {
+	typ, headerSize := byte(leafTablePage), leafHeaderSize
+	if p.interior {
+		typ, headerSize = interiorTablePage, interiorHeaderSize
+		for i, c := range p.cells {
+			binary.BigEndian.PutUint32(c, p.children[i].number)
+		}
+		binary.BigEndian.PutUint32(buf[start+8:], p.right.number)
+	}
+	buf[start] = typ
+	binary.BigEndian.PutUint16(buf[start+3:], uint16(len(p.cells)))
+	// Cells are stored from the end of the page, in order.
+	end := len(buf)
+	for i, c := range p.cells {
+		end -= len(c)
+		copy(buf[end:], c)
+		binary.BigEndian.PutUint16(buf[start+headerSize+2*i:], uint16(end))
+	}
+	binary.BigEndian.PutUint16(buf[start+5:], uint16(end))
+}
+
+// writeHeader writes the database header for a database of n pages to buf.
+func writeHeader(buf []byte, n uint32) {
+	copy(buf, "SQLite format 3\x00")
+	binary.BigEndian.PutUint16(buf[16:], pageSize)
+	buf[18] = 1  // file format write version: legacy
+	buf[19] = 1  // file format read version: legacy
+	buf[21] = 64 // maximum embedded payload fraction
+	buf[22] = 32 // minimum embedded payload fraction
+	buf[23] = 32 // leaf payload fraction
+
+	binary.BigEndian.PutUint32(buf[24:], 1)       // file change counter
+	binary.BigEndian.PutUint32(buf[28:], n)       // database size in pages
+	binary.BigEndian.PutUint32(buf[40:], 1)       // schema cookie
+	binary.BigEndian.PutUint32(buf[44:], 4)       // schema format number
+	binary.BigEndian.PutUint32(buf[56:], 1)       // text encoding: UTF-8
+	binary.BigEndian.PutUint32(buf[92:], 1)       // version-valid-for, equal to the change counter
+	binary.BigEndian.PutUint32(buf[96:], 3040000) // SQLite version that wrote the file
+}
+
+// record returns a record holding vals, which are nil, strings or int64s.
+// The column of an INTEGER PRIMARY KEY is an alias for the rowid, and is
+// stored as NULL.
+func record(vals ...any) []byte {
+	var header, body []byte
+	for _, v := range vals {
+		switch v := v.(type) {
+		case nil:
+			header = append(header, 0)
+		case string:
+			header = appendVarint(header, uint64(len(v))*2+13)
+			body = append(body, v...)
+		case int64:
+			// Only small non-negative integers are stored.
+			header = append(header, 4)
+			body = binary.BigEndian.AppendUint32(body, uint32(v))
+		default:
+			panic(fmt.Sprintf("record: unsupported type %T", v))
+		}
+	}
+	// The header size includes the varint holding it, which is a single
+	// byte for headers as short as these.
+	b := appendVarint(nil, uint64(len(header)+1))
+	b = append(b, header...)
+	return append(b, body...)
+}
+
+// appendVarint appends x, which must be less than 1<<56, to b in SQLite's
+// variable-length integer encoding.
+func appendVarint(b []byte, x uint64) []byte {
+	var tmp [8]byte
+	i := len(tmp) - 1
+	tmp[i] = byte(x & 0x7f)
+	for x >>= 7; x > 0; x >>= 7 {
+		i--
+		tmp[i] = byte(x&0x7f) | 0x80
+	}
+	return append(b, tmp[i:]...)
+}
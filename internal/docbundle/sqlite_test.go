@@ -0,0 +1,171 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package docbundle
+
+import (
+	"encoding/binary"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/pkgsite/internal/testenv"
+)
+
+func TestWriteSearchIndex(t *testing.T) {
+	for _, n := range []int{0, 1, 100, 20000} {
+		n := n
+		t.Run(fmt.Sprint(n), func(t *testing.T) {
+			var want []indexEntry
+			for i := 0; i < n; i++ {
+				want = append(want, indexEntry{
+					Name: fmt.Sprintf("Symbol%d", i),
+					Type: "Function",
+					Path: fmt.Sprintf("example.com/m/p%d/index.html#Symbol%d", i%10, i),
+				})
+			}
+			db, err := writeSearchIndex(want)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(db)%pageSize != 0 || binary.BigEndian.Uint32(db[28:]) != uint32(len(db)/pageSize) {
+				t.Fatalf("database of %d bytes has %d pages in its header", len(db), binary.BigEndian.Uint32(db[28:]))
+			}
+			schema := readTable(t, db, 1)
+			if len(schema) != 1 || schema[0][5] != searchIndexSchema || schema[0][4] != int64(2) {
+				t.Fatalf("got schema %v", schema)
+			}
+			var got []indexEntry
+			for i, r := range readTable(t, db, 2) {
+				if r[0] != int64(i+1) {
+					t.Fatalf("row %d has rowid %v", i, r[0])
+				}
+				got = append(got, indexEntry{Name: r[2].(string), Type: r[3].(string), Path: r[4].(string)})
+			}
+			if diff := cmp.Diff(want, got); diff != "" {
+				t.Errorf("mismatch (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+// TestWriteSearchIndexSQLite checks that SQLite itself can read the search
+// index, rather than only the readTable function of this test.
+func TestWriteSearchIndexSQLite(t *testing.T) {
+	testenv.MustHaveExecPath(t, "sqlite3")
+
+	for _, n := range []int{0, 1, 100, 20000} {
+		t.Run(fmt.Sprint(n), func(t *testing.T) {
+			var entries []indexEntry
+			for i := 0; i < n; i++ {
+				entries = append(entries, indexEntry{
+					Name: fmt.Sprintf("Symbol%d", i),
+					Type: "Function",
+					Path: fmt.Sprintf("example.com/m/p%d/index.html#Symbol%d", i%10, i),
+				})
+			}
+			db, err := writeSearchIndex(entries)
+			if err != nil {
+				t.Fatal(err)
+			}
+			file := filepath.Join(t.TempDir(), "docSet.dsidx")
+			if err := os.WriteFile(file, db, 0o644); err != nil {
+				t.Fatal(err)
+			}
+			query := func(q string) string {
+				t.Helper()
+				out, err := exec.Command("sqlite3", "-readonly", file, q).CombinedOutput()
+				if err != nil {
+					t.Fatalf("sqlite3 %q: %v: %s", q, err, out)
+				}
+				return strings.TrimSpace(string(out))
+			}
+			if got := query("PRAGMA integrity_check"); got != "ok" {
+				t.Fatalf("integrity_check: %s", got)
+			}
+			if got, want := query("SELECT count(*), coalesce(max(id), 0) FROM searchIndex"), fmt.Sprintf("%d|%d", n, n); got != want {
+				t.Errorf("got count and max id %q, want %q", got, want)
+			}
+			if n > 0 {
+				i := n / 2
+				e := entries[i]
+				got := query(fmt.Sprintf("SELECT name, type, path FROM searchIndex WHERE id = %d", i+1))
+				if want := e.Name + "|" + e.Type + "|" + e.Path; got != want {
+					t.Errorf("row %d: got %q, want %q", i+1, got, want)
+				}
+				got = query(fmt.Sprintf("SELECT id FROM searchIndex WHERE name = '%s'", e.Name))
+				if want := fmt.Sprint(i + 1); got != want {
+					t.Errorf("id of %s: got %q, want %q", e.Name, got, want)
+				}
+			}
+		})
+	}
+}
+
+// readTable returns the rows of the table b-tree rooted at page number root of
+// db, in order. The first value of each row is its rowid.
+func readTable(t *testing.T, db []byte, root uint32) [][]any {
+	t.Helper()
+	page := db[int(root-1)*pageSize : int(root)*pageSize]
+	start := 0
+	if root == 1 {
+		start = 100
+	}
+	ncells := int(binary.BigEndian.Uint16(page[start+3:]))
+	var rows [][]any
+	switch page[start] {
+	case interiorTablePage:
+		for i := 0; i < ncells; i++ {
+			off := binary.BigEndian.Uint16(page[start+interiorHeaderSize+2*i:])
+			rows = append(rows, readTable(t, db, binary.BigEndian.Uint32(page[off:]))...)
+		}
+		rows = append(rows, readTable(t, db, binary.BigEndian.Uint32(page[start+8:]))...)
+	case leafTablePage:
+		for i := 0; i < ncells; i++ {
+			cell := page[binary.BigEndian.Uint16(page[start+leafHeaderSize+2*i:]):]
+			size, n := readVarint(cell)
+			rowid, m := readVarint(cell[n:])
+			rec := cell[n+m : n+m+int(size)]
+			row := []any{int64(rowid)}
+			hsize, n := readVarint(rec)
+			header, body := rec[n:hsize], rec[hsize:]
+			for len(header) > 0 {
+				typ, n := readVarint(header)
+				header = header[n:]
+				switch {
+				case typ == 0:
+					row = append(row, nil)
+				case typ == 4:
+					row = append(row, int64(binary.BigEndian.Uint32(body)))
+					body = body[4:]
+				case typ >= 13 && typ%2 == 1:
+					l := (typ - 13) / 2
+					row = append(row, string(body[:l]))
+					body = body[l:]
+				default:
+					t.Fatalf("page %d: unexpected serial type %d", root, typ)
+				}
+			}
+			rows = append(rows, row)
+		}
+	default:
+		t.Fatalf("page %d has type %#x", root, page[start])
+	}
+	return rows
+}
+
+func readVarint(b []byte) (uint64, int) {
+	var x uint64
+	for i, c := range b {
+		x = x<<7 | uint64(c&0x7f)
+		if c&0x80 == 0 {
+			return x, i + 1
+		}
+	}
+	panic("bad varint")
+}
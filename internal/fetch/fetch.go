@@ -12,6 +12,7 @@ import (
 	"io/fs"
 	"net/http"
 	"strings"
+	"sync"
 
 	"golang.org/x/mod/modfile"
 	"golang.org/x/pkgsite/internal"
@@ -48,13 +49,20 @@ type FetchResult struct {
 type LazyModule struct {
 	internal.ModuleInfo
 	UnitMetas        []*internal.UnitMeta
+	GoMod            *internal.GoModDirectives
+	GoSum            []string
 	goModPath        string
 	requestedVersion string
 	failedPackages   []*internal.PackageVersionState
 	licenseDetector  *licenses.Detector
 	contentDir       fs.FS
 	godocModInfo     *godoc.ModuleInfo
+	mg               ModuleGetter
+	loadOpts         LoadOptions
 	Error            error
+
+	typeRelationsOnce sync.Once
+	typeRelations     map[string]*typeRelations // by package path
 }
 
 // FetchModule queries the proxy or the Go repo for the requested module
@@ -63,24 +71,39 @@ type LazyModule struct {
 //
 // Even if err is non-nil, the result may contain useful information, like the go.mod path.
 func FetchModule(ctx context.Context, modulePath, requestedVersion string, mg ModuleGetter) (fr *FetchResult) {
-	lm := FetchLazyModule(ctx, modulePath, requestedVersion, mg)
+	return FetchModuleWithKnownAlternative(ctx, modulePath, requestedVersion, mg, "", LoadOptions{})
+}
+
+// FetchModuleWithKnownAlternative is like FetchModule, but modulePath is known
+// to be an alternative to the module knownAlternative, if that is not empty.
+// If the module has no go.mod file to declare its own path, fetching stops
+// with a derrors.AlternativeModule error before its packages are processed.
+// The packages are loaded according to opts.
+func FetchModuleWithKnownAlternative(ctx context.Context, modulePath, requestedVersion string, mg ModuleGetter, knownAlternative string, opts LoadOptions) (fr *FetchResult) {
+	lm, err := fetchLazyModule(ctx, modulePath, requestedVersion, mg, knownAlternative, opts)
+	if err != nil {
+		lm.Error = err
+	}
 	return lm.fetchResult(ctx)
 }
 
 // FetchLazyModule queries the proxy or the Go repo for the requested module
 // version, downloads the module zip, and does just enough processing to produce
-// UnitMetas for all the modules. The full units are computed as needed.
-func FetchLazyModule(ctx context.Context, modulePath, requestedVersion string, mg ModuleGetter) *LazyModule {
-	lm, err := fetchLazyModule(ctx, modulePath, requestedVersion, mg)
+// UnitMetas for all the modules. The full units are computed as needed, and
+// their packages are loaded according to opts.
+func FetchLazyModule(ctx context.Context, modulePath, requestedVersion string, mg ModuleGetter, opts LoadOptions) *LazyModule {
+	lm, err := fetchLazyModule(ctx, modulePath, requestedVersion, mg, "", opts)
 	if err != nil {
 		lm.Error = err
 	}
 	return lm
 }
 
-func fetchLazyModule(ctx context.Context, modulePath, requestedVersion string, mg ModuleGetter) (*LazyModule, error) {
+func fetchLazyModule(ctx context.Context, modulePath, requestedVersion string, mg ModuleGetter, knownAlternative string, opts LoadOptions) (*LazyModule, error) {
 	lm := &LazyModule{
 		requestedVersion: requestedVersion,
+		mg:               mg,
+		loadOpts:         opts,
 	}
 	lm.ModuleInfo.ModulePath = modulePath
 
@@ -126,16 +149,23 @@ func fetchLazyModule(ctx context.Context, modulePath, requestedVersion string, m
 	if err != nil {
 		return lm, err
 	}
+	if cg, ok := mg.(ChecksumModuleGetter); ok {
+		lm.GoSum, err = cg.GoSum(ctx, modulePath, lm.ModuleInfo.Version)
+		if err != nil {
+			return lm, err
+		}
+	}
 
-	// If there is no go.mod file in the zip, try other ways to detect
-	// alternative modules:
-	// 1. Compare the module path to a list of known alternative module paths.
-	// 2. Compare the zip signature to a list of known ones to see if this is a
-	//    fork. The intent is to avoid processing certain known large modules, not
-	//    to find every fork.
+	// Forks without a go.mod file can be huge, so reject the known ones before
+	// processing their packages:
+	// 1. Compare the module path to the known alternative module paths.
+	// 2. Compare the zip signature to a list of known ones of large modules.
+	// Other forks are recognized when the module is inserted into the
+	// database, by the similarity of its contents to those of other modules;
+	// see Module.MinHash.
 	if !lm.ModuleInfo.HasGoMod {
-		if modPath := knownAlternativeFor(modulePath); modPath != "" {
-			return lm, fmt.Errorf("known alternative to %s: %w", modPath, derrors.AlternativeModule)
+		if knownAlternative != "" {
+			return lm, fmt.Errorf("known alternative to %s: %w", knownAlternative, derrors.AlternativeModule)
 		}
 		forkedModule, err := forkedFrom(contentDir, modulePath, lm.ModuleInfo.Version)
 		if err != nil {
@@ -167,9 +197,13 @@ func fetchLazyModule(ctx context.Context, modulePath, requestedVersion string, m
 		return lm, err
 	}
 	if goModBytes != nil {
-		if err := processGoModFile(goModBytes, &lm.ModuleInfo); err != nil {
+		directives, err := processGoModFile(goModBytes, &lm.ModuleInfo)
+		if err != nil {
 			return lm, fmt.Errorf("%v: %w", err, derrors.BadModule)
 		}
+		if lm.HasGoMod {
+			lm.GoMod = directives
+		}
 	}
 
 	return lm, nil
@@ -201,7 +235,8 @@ func (lm *LazyModule) unit(ctx context.Context, unitMeta *internal.UnitMeta) (*i
 	if !unitMeta.IsPackage() {
 		return moduleUnit(lm.ModulePath, unitMeta, nil, readme, lm.licenseDetector), nil, nil
 	}
-	pkg, pvs, err := extractPackage(ctx, lm.ModulePath, unitMeta.Path, lm.contentDir, lm.licenseDetector, lm.SourceInfo, lm.godocModInfo)
+	pkg, pvs, err := extractPackage(ctx, lm.ModulePath, unitMeta.Path, lm.contentDir, lm.licenseDetector, lm.SourceInfo, lm.godocModInfo,
+		lm.packageTypeRelations(ctx, unitMeta.Path), lm.loadOpts)
 	if err != nil || (pvs != nil && pvs.Status != 200) {
 		// pvs can be non-nil even if err is non-nil.
 		return nil, pvs, err
@@ -211,6 +246,33 @@ func (lm *LazyModule) unit(ctx context.Context, unitMeta *internal.UnitMeta) (*i
 	return u, pvs, nil
 }
 
+// ContentDir returns the contents of the module, or nil if they could not be
+// read.
+func (lm *LazyModule) ContentDir() fs.FS {
+	return lm.contentDir
+}
+
+// packageTypeRelations returns the typeRelations of the package with the
+// given path. The relations of all the packages of the module are computed
+// the first time it is called, which may read the modules that the module
+// requires; see newDependencyGetter.
+func (lm *LazyModule) packageTypeRelations(ctx context.Context, pkgPath string) *typeRelations {
+	lm.typeRelationsOnce.Do(func() {
+		var pkgPaths []string
+		for _, um := range lm.UnitMetas {
+			if um.IsPackage() {
+				pkgPaths = append(pkgPaths, um.Path)
+			}
+		}
+		var deps *dependencyGetter
+		if lm.GoMod != nil && lm.mg != nil {
+			deps = newDependencyGetter(lm.mg, lm.GoMod)
+		}
+		lm.typeRelations = moduleTypeRelations(ctx, lm.ModulePath, lm.contentDir, pkgPaths, deps)
+	})
+	return lm.typeRelations[pkgPath]
+}
+
 func (lm *LazyModule) fetchResult(ctx context.Context) *FetchResult {
 	fr := &FetchResult{
 		ModulePath:       lm.ModulePath,
@@ -218,6 +280,8 @@ func (lm *LazyModule) fetchResult(ctx context.Context) *FetchResult {
 		ResolvedVersion:  lm.ModuleInfo.Version,
 		Module: &internal.Module{
 			ModuleInfo: lm.ModuleInfo,
+			GoMod:      lm.GoMod,
+			GoSum:      lm.GoSum,
 		},
 		HasGoMod:  lm.HasGoMod,
 		GoModPath: lm.goModPath,
@@ -231,6 +295,13 @@ func (lm *LazyModule) fetchResult(ctx context.Context) *FetchResult {
 		return fr
 	}
 	fr.Module.Licenses = lm.licenseDetector.AllLicenses()
+	// The signature only affects how the module is ranked in search, so
+	// don't fail if it can't be computed.
+	if sig, err := moduleMinHash(lm.contentDir); err != nil {
+		log.Errorf(ctx, "moduleMinHash(%q, %q): %v", lm.ModulePath, lm.ModuleInfo.Version, err)
+	} else {
+		fr.Module.MinHash = sig
+	}
 	// We need to set HasGoMod here rather than on the ModuleInfo when
 	// it's created because the ModuleInfo that goes on the units shouldn't
 	// have HasGoMod set on it.
@@ -316,16 +387,52 @@ func hasGoModFile(contentDir fs.FS) bool {
 	return err == nil && !info.IsDir()
 }
 
-// processGoModFile populates mod with information extracted from the contents of the go.mod file.
-func processGoModFile(goModBytes []byte, mod *internal.ModuleInfo) (err error) {
+// processGoModFile populates mod with information extracted from the contents
+// of the go.mod file, and returns the file's directives.
+func processGoModFile(goModBytes []byte, mod *internal.ModuleInfo) (_ *internal.GoModDirectives, err error) {
 	defer derrors.Wrap(&err, "processGoModFile")
 
 	mf, err := modfile.Parse("go.mod", goModBytes, nil)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	mod.Deprecated, mod.DeprecationComment = extractDeprecatedComment(mf)
-	return nil
+	return goModDirectives(mf), nil
+}
+
+// goModDirectives returns the require, replace, exclude and retract
+// directives of mf.
+func goModDirectives(mf *modfile.File) *internal.GoModDirectives {
+	d := &internal.GoModDirectives{}
+	for _, r := range mf.Require {
+		d.Requires = append(d.Requires, &internal.ModuleRequire{
+			ModulePath: r.Mod.Path,
+			Version:    r.Mod.Version,
+			Indirect:   r.Indirect,
+		})
+	}
+	for _, r := range mf.Replace {
+		d.Replaces = append(d.Replaces, &internal.ModuleReplace{
+			OldPath:    r.Old.Path,
+			OldVersion: r.Old.Version,
+			NewPath:    r.New.Path,
+			NewVersion: r.New.Version,
+		})
+	}
+	for _, e := range mf.Exclude {
+		d.Excludes = append(d.Excludes, &internal.ModuleExclude{
+			ModulePath: e.Mod.Path,
+			Version:    e.Mod.Version,
+		})
+	}
+	for _, r := range mf.Retract {
+		d.Retracts = append(d.Retracts, &internal.ModuleRetract{
+			Low:       r.Low,
+			High:      r.High,
+			Rationale: r.Rationale,
+		})
+	}
+	return d
 }
 
 // extractDeprecatedComment looks for "Deprecated" comments in the line comments
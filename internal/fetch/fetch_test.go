@@ -135,6 +135,13 @@ func TestFetchModule(t *testing.T) {
 					opts := []cmp.Option{
 						cmpopts.IgnoreFields(internal.Documentation{}, "Source"),
 						cmpopts.IgnoreFields(internal.PackageVersionState{}, "Error"),
+						// The go.mod directives are checked by TestGoModDirectives.
+						cmpopts.IgnoreFields(internal.Module{}, "GoMod"),
+						// Test functions are checked by TestLoadTestFuncs, and
+						// documentation problems by godoc.TestLint.
+						cmpopts.IgnoreFields(internal.Unit{}, "Tests", "SymbolReferences", "DocProblems"),
+						// The MinHash signature is checked by TestModuleMinHash.
+						cmpopts.IgnoreFields(internal.Module{}, "MinHash"),
 						cmp.AllowUnexported(source.Info{}),
 						cmpopts.EquateEmpty(),
 					}
@@ -195,13 +202,6 @@ func TestFetchModule_Errors(t *testing.T) {
 			wantGoModPath: "canonical",
 			wantHasGoMod:  true,
 		},
-		{
-			name:          "known alternative",
-			mod:           moduleKnownAlternative,
-			wantErr:       derrors.AlternativeModule,
-			wantGoModPath: "github.com/msopentech/azure-sdk-for-go",
-			wantHasGoMod:  false,
-		},
 		{
 			name:          "empty module",
 			mod:           moduleEmpty,
@@ -243,6 +243,45 @@ func TestFetchModule_Errors(t *testing.T) {
 	}
 }
 
+func TestFetchModuleWithKnownAlternative(t *testing.T) {
+	ctx := context.Background()
+	const modulePath = "github.com/fork/azure-sdk-for-go"
+	for _, test := range []struct {
+		name    string
+		files   map[string]string
+		wantErr error
+	}{
+		{
+			name:    "no go.mod",
+			files:   map[string]string{"p/p.go": "package p"},
+			wantErr: derrors.AlternativeModule,
+		},
+		{
+			name:  "go.mod",
+			files: map[string]string{"go.mod": "module " + modulePath, "p/p.go": "package p"},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			proxyClient, teardownProxy := proxytest.SetupTestClient(t, []*proxytest.Module{{
+				ModulePath: modulePath,
+				Version:    sample.VersionString,
+				Files:      test.files,
+			}})
+			defer teardownProxy()
+			mg := NewProxyModuleGetter(proxyClient, source.NewClientForTesting())
+			got := FetchModuleWithKnownAlternative(ctx, modulePath, sample.VersionString, mg, "github.com/Azure/azure-sdk-for-go", LoadOptions{})
+			if !errors.Is(got.Error, test.wantErr) || (test.wantErr == nil && got.Error != nil) {
+				t.Fatalf("got error %v, want %v", got.Error, test.wantErr)
+			}
+			// A known alternative is rejected before its packages are
+			// processed.
+			if gotUnits := got.Module != nil && len(got.Module.Units) > 0; gotUnits != (test.wantErr == nil) {
+				t.Errorf("got units: %t, want units: %t", gotUnits, test.wantErr == nil)
+			}
+		})
+	}
+}
+
 func TestExtractDeprecatedComment(t *testing.T) {
 	for _, test := range []struct {
 		name        string
@@ -293,3 +332,48 @@ func TestExtractDeprecatedComment(t *testing.T) {
 		}
 	}
 }
+
+func TestGoModDirectives(t *testing.T) {
+	const in = `
+		module m
+
+		require (
+			a.com v1.0.0
+			b.com v1.2.0 // indirect
+		)
+
+		replace a.com => ../a
+
+		replace c.com v1.0.0 => d.com v1.1.0
+
+		exclude b.com v1.1.0
+
+		retract (
+			v1.0.1 // bad
+			[v1.0.2, v1.0.5]
+		)
+	`
+	var mi internal.ModuleInfo
+	got, err := processGoModFile([]byte(in), &mi)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &internal.GoModDirectives{
+		Requires: []*internal.ModuleRequire{
+			{ModulePath: "a.com", Version: "v1.0.0"},
+			{ModulePath: "b.com", Version: "v1.2.0", Indirect: true},
+		},
+		Replaces: []*internal.ModuleReplace{
+			{OldPath: "a.com", NewPath: "../a"},
+			{OldPath: "c.com", OldVersion: "v1.0.0", NewPath: "d.com", NewVersion: "v1.1.0"},
+		},
+		Excludes: []*internal.ModuleExclude{{ModulePath: "b.com", Version: "v1.1.0"}},
+		Retracts: []*internal.ModuleRetract{
+			{Low: "v1.0.1", High: "v1.0.1", Rationale: "bad"},
+			{Low: "v1.0.2", High: "v1.0.5"},
+		},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -19,6 +19,7 @@ import (
 	"io"
 	"io/fs"
 	"os"
+	"os/exec"
 	"path"
 	"path/filepath"
 	"sort"
@@ -27,7 +28,9 @@ import (
 
 	"golang.org/x/mod/modfile"
 	"golang.org/x/mod/module"
+	"golang.org/x/mod/semver"
 	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/checksum"
 	"golang.org/x/pkgsite/internal/derrors"
 	"golang.org/x/pkgsite/internal/fuzzy"
 	"golang.org/x/pkgsite/internal/log"
@@ -75,6 +78,24 @@ type SearchableModuleGetter interface {
 	Search(ctx context.Context, q string, limit int) ([]*internal.SearchResult, error)
 }
 
+// ListableModuleGetter is an additional interface that may be implemented by
+// ModuleGetters that can enumerate the modules they serve.
+type ListableModuleGetter interface {
+	// ListModules returns the module versions served by the getter, sorted by
+	// module path and then from the latest version to the earliest, as
+	// version.Later orders them.
+	ListModules(ctx context.Context) ([]internal.Modver, error)
+}
+
+// VersionListingModuleGetter is an additional interface that may be
+// implemented by ModuleGetters that can enumerate the versions of a module.
+type VersionListingModuleGetter interface {
+	// Versions returns the versions of the module that the getter can serve,
+	// in no particular order. It returns an error wrapping derrors.NotFound if
+	// the getter does not know about the module.
+	Versions(ctx context.Context, modulePath string) ([]string, error)
+}
+
 // VolatileModuleGetter is an additional interface that may be implemented by
 // ModuleGetters to support invalidating content.
 type VolatileModuleGetter interface {
@@ -82,13 +103,44 @@ type VolatileModuleGetter interface {
 	HasChanged(context.Context, internal.ModuleInfo) (bool, error)
 }
 
+// ChecksumModuleGetter is an additional interface that may be implemented by
+// ModuleGetters that verify the modules they serve against a checksum
+// database.
+type ChecksumModuleGetter interface {
+	// GoSum returns the go.sum lines for the module version, as recorded in
+	// the checksum database. It returns nil if the getter does not verify
+	// modules.
+	GoSum(ctx context.Context, path, version string) ([]string, error)
+}
+
+// RefResolvingModuleGetter is an additional interface that may be implemented
+// by ModuleGetters that resolve any ref of a version control system, such as
+// a branch name or a commit hash, to a module version.
+type RefResolvingModuleGetter interface {
+	// ResolvesRefs reports whether the getter resolves refs for the
+	// module or package at path.
+	ResolvesRefs(path string) bool
+}
+
 type proxyModuleGetter struct {
-	prox *proxy.Client
-	src  *source.Client
+	prox     *proxy.Client
+	src      *source.Client
+	verifier *checksum.Verifier // if nil, modules are not verified
 }
 
 func NewProxyModuleGetter(p *proxy.Client, s *source.Client) ModuleGetter {
-	return &proxyModuleGetter{p, s}
+	return &proxyModuleGetter{prox: p, src: s}
+}
+
+// NewVerifiedProxyModuleGetter returns a ModuleGetter like
+// NewProxyModuleGetter that also verifies every module zip and go.mod file
+// against the checksum database of v. Modules that fail verification are
+// rejected with an error wrapping derrors.ChecksumMismatch.
+//
+// The getter caches its lookups in the checksum database for as long as it
+// lives, so it should be used for a single fetch.
+func NewVerifiedProxyModuleGetter(p *proxy.Client, s *source.Client, v *checksum.Verifier) ModuleGetter {
+	return &proxyModuleGetter{prox: p, src: s, verifier: v.ForFetch()}
 }
 
 // Info returns basic information about the module.
@@ -98,7 +150,16 @@ func (g *proxyModuleGetter) Info(ctx context.Context, path, version string) (*pr
 
 // Mod returns the contents of the module's go.mod file.
 func (g *proxyModuleGetter) Mod(ctx context.Context, path, version string) ([]byte, error) {
-	return g.prox.Mod(ctx, path, version)
+	data, err := g.prox.Mod(ctx, path, version)
+	if err != nil {
+		return nil, err
+	}
+	if g.verifier != nil {
+		if err := g.verifier.VerifyMod(path, version, data); err != nil {
+			return nil, err
+		}
+	}
+	return data, nil
 }
 
 // ContentDir returns an FS for the module's contents. The FS should match the format
@@ -108,9 +169,29 @@ func (g *proxyModuleGetter) ContentDir(ctx context.Context, path, version string
 	if err != nil {
 		return nil, err
 	}
+	if g.verifier != nil {
+		if err := g.verifier.VerifyZip(path, version, zr); err != nil {
+			return nil, err
+		}
+	}
 	return fs.Sub(zr, path+"@"+version)
 }
 
+// GoSum returns the go.sum lines for the module version from the checksum
+// database, or nil if the getter does not verify modules.
+func (g *proxyModuleGetter) GoSum(ctx context.Context, path, version string) ([]string, error) {
+	if g.verifier == nil {
+		return nil, nil
+	}
+	return g.verifier.Lines(path, version)
+}
+
+// Versions returns the versions that the proxy lists for the module.
+// The list does not include pseudo-versions.
+func (g *proxyModuleGetter) Versions(ctx context.Context, modulePath string) ([]string, error) {
+	return g.prox.Versions(ctx, modulePath)
+}
+
 // SourceInfo gets information about a module's repo and source files by calling source.ModuleInfo.
 func (g *proxyModuleGetter) SourceInfo(ctx context.Context, path, version string) (*source.Info, error) {
 	return source.ModuleInfo(ctx, g.src, path, version)
@@ -171,10 +252,15 @@ func (g *directoryModuleGetter) checkPath(path string) error {
 }
 
 // Info returns basic information about the module.
-func (g *directoryModuleGetter) Info(ctx context.Context, path, version string) (*proxy.VersionInfo, error) {
+func (g *directoryModuleGetter) Info(ctx context.Context, path, vers string) (*proxy.VersionInfo, error) {
 	if err := g.checkPath(path); err != nil {
 		return nil, err
 	}
+	// The directory holds only the working copy, so don't pretend to serve
+	// other versions, which may be available from a later getter.
+	if semver.IsValid(vers) && vers != LocalVersion {
+		return nil, fmt.Errorf("%s@%s: directory %q has only version %s: %w", path, vers, g.dir, LocalVersion, derrors.NotFound)
+	}
 	return &proxy.VersionInfo{
 		Version: LocalVersion,
 		Time:    LocalCommitTime,
@@ -215,6 +301,11 @@ func (g *directoryModuleGetter) SourceFS() (string, fs.FS) {
 	return g.fileServingPath(), os.DirFS(g.dir)
 }
 
+// ListModules returns the single module in the directory.
+func (g *directoryModuleGetter) ListModules(ctx context.Context) ([]internal.Modver, error) {
+	return []internal.Modver{{Path: g.modulePath, Version: LocalVersion}}, nil
+}
+
 func (g *directoryModuleGetter) fileServingPath() string {
 	return path.Join(filepath.ToSlash(g.dir), g.modulePath)
 }
@@ -336,7 +427,7 @@ func (g *goPackagesModuleGetter) findModule(path string) (*packages.Module, erro
 // For invalidation of locally edited modules, the time of the resulting
 // version is set to the latest mtime of a file referenced by any compiled file
 // in the module.
-func (g *goPackagesModuleGetter) Info(ctx context.Context, modulePath, version string) (*proxy.VersionInfo, error) {
+func (g *goPackagesModuleGetter) Info(ctx context.Context, modulePath, vers string) (*proxy.VersionInfo, error) {
 	m, err := g.findModule(modulePath)
 	if err != nil {
 		return nil, err
@@ -345,6 +436,9 @@ func (g *goPackagesModuleGetter) Info(ctx context.Context, modulePath, version s
 	if m.Version != "" {
 		v = m.Version
 	}
+	if semver.IsValid(vers) && vers != v {
+		return nil, fmt.Errorf("%s@%s: only version %s is loaded: %w", modulePath, vers, v, derrors.NotFound)
+	}
 	// Note: if we ever support loading dependencies out of the module cache, we
 	// may have a valid m.Time to use here.
 	var t time.Time
@@ -445,6 +539,21 @@ func (g *goPackagesModuleGetter) SourceInfo(ctx context.Context, modulePath, _ s
 	return source.FilesInfo(p), nil
 }
 
+// ListModules returns the modules of the loaded packages. There is one
+// version of each, and g.modules is sorted by path, so they are sorted as
+// ListableModuleGetter requires.
+func (g *goPackagesModuleGetter) ListModules(ctx context.Context) ([]internal.Modver, error) {
+	var mvs []internal.Modver
+	for _, m := range g.modules {
+		v := LocalVersion
+		if m.Version != "" {
+			v = m.Version
+		}
+		mvs = append(mvs, internal.Modver{Path: m.Path, Version: v})
+	}
+	return mvs, nil
+}
+
 // Open implements the fs.FS interface, matching the path name to a loaded
 // module.
 func (g *goPackagesModuleGetter) Open(name string) (fs.File, error) {
@@ -719,27 +828,98 @@ func (g *modCacheModuleGetter) SourceFS() (string, fs.FS) {
 	return filepath.ToSlash(g.dir), os.DirFS(g.dir)
 }
 
+// ListModules returns every module version in the cache that has a zip file.
+// The cache is walked in the order of escaped module paths, which differs from
+// the order of the paths themselves, so the result is sorted again.
+func (g *modCacheModuleGetter) ListModules(ctx context.Context) (_ []internal.Modver, err error) {
+	defer derrors.Wrap(&err, "modCacheModuleGetter.ListModules")
+
+	root := filepath.Join(g.dir, "cache", "download")
+	var mvs []internal.Modver
+	err = filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if !d.IsDir() || d.Name() != "@v" {
+			return nil
+		}
+		rel, err := filepath.Rel(root, filepath.Dir(p))
+		if err != nil {
+			return err
+		}
+		modulePath, err := module.UnescapePath(filepath.ToSlash(rel))
+		if err != nil {
+			// Not a module directory; ignore it.
+			return fs.SkipDir
+		}
+		zips, err := filepath.Glob(filepath.Join(p, "*.zip"))
+		if err != nil {
+			return err
+		}
+		var versions []string
+		for _, z := range zips {
+			v, err := module.UnescapeVersion(strings.TrimSuffix(filepath.Base(z), ".zip"))
+			if err != nil {
+				continue
+			}
+			versions = append(versions, v)
+		}
+		sort.Slice(versions, func(i, j int) bool {
+			return version.Later(versions[i], versions[j])
+		})
+		for _, v := range versions {
+			mvs = append(mvs, internal.Modver{Path: modulePath, Version: v})
+		}
+		return fs.SkipDir
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(mvs, func(i, j int) bool {
+		return mvs[i].Path < mvs[j].Path
+	})
+	return mvs, nil
+}
+
 // latestVersion gets the latest version that is in the directory.
 func (g *modCacheModuleGetter) latestVersion(modulePath string) (_ string, err error) {
 	defer derrors.Wrap(&err, "modCacheModuleGetter.latestVersion(%q)", modulePath)
 
-	dir, err := g.moduleDir(modulePath)
+	versions, err := g.Versions(context.Background(), modulePath)
 	if err != nil {
 		return "", err
 	}
+	return version.LatestOf(versions), nil
+}
+
+// Versions returns the versions of the module that have a zip file in the
+// cache.
+func (g *modCacheModuleGetter) Versions(ctx context.Context, modulePath string) (_ []string, err error) {
+	defer derrors.Wrap(&err, "modCacheModuleGetter.Versions(%q)", modulePath)
+
+	dir, err := g.moduleDir(modulePath)
+	if err != nil {
+		return nil, err
+	}
 	zips, err := filepath.Glob(filepath.Join(dir, "*.zip"))
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 	if len(zips) == 0 {
-		return "", fmt.Errorf("no zips in %q for module %q: %w", g.dir, modulePath, derrors.NotFound)
+		return nil, fmt.Errorf("no zips in %q for module %q: %w", g.dir, modulePath, derrors.NotFound)
 	}
 	var versions []string
 	for _, z := range zips {
-		vers := strings.TrimSuffix(filepath.Base(z), ".zip")
+		vers, err := module.UnescapeVersion(strings.TrimSuffix(filepath.Base(z), ".zip"))
+		if err != nil {
+			continue
+		}
 		versions = append(versions, vers)
 	}
-	return version.LatestOf(versions), nil
+	return versions, nil
 }
 
 func (g *modCacheModuleGetter) readFile(path, version, suffix string) (_ []byte, err error) {
@@ -792,3 +972,303 @@ func (g *modCacheModuleGetter) moduleDir(modulePath string) (string, error) {
 func (g *modCacheModuleGetter) String() string {
 	return fmt.Sprintf("FSProxy(%s)", g.dir)
 }
+
+// A gitModuleGetter gets a module from a local git repository, which may be
+// bare or have a working tree. Like the go command with GOPROXY=direct, it can
+// serve the module at any commit, named by a semantic version tag, a branch,
+// another tag, a commit hash or a pseudo-version. Commits that are not tagged
+// with a semantic version are given pseudo-versions.
+//
+// The module must be at the root of the repository.
+type gitModuleGetter struct {
+	modulePath string
+	dir        string // absolute path to the repository
+}
+
+// NewGitModuleGetter returns a ModuleGetter that reads a module from the git
+// repository in dir. If modulePath is empty, it is read from the go.mod file
+// at HEAD.
+func NewGitModuleGetter(ctx context.Context, modulePath, dir string) (_ *gitModuleGetter, err error) {
+	defer derrors.Wrap(&err, "NewGitModuleGetter(%q, %q)", modulePath, dir)
+
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	g := &gitModuleGetter{modulePath: modulePath, dir: abs}
+	if modulePath == "" {
+		goModBytes, err := g.git(ctx, "show", "HEAD:go.mod")
+		if err != nil {
+			return nil, fmt.Errorf("cannot obtain module path for %q (%v): %w", dir, err, derrors.BadModule)
+		}
+		g.modulePath = modfile.ModulePath(goModBytes)
+		if g.modulePath == "" {
+			return nil, fmt.Errorf("go.mod in %q has no module path: %w", dir, derrors.BadModule)
+		}
+	}
+	return g, nil
+}
+
+func (g *gitModuleGetter) checkPath(path string) error {
+	if path != g.modulePath {
+		return fmt.Errorf("given module path %q does not match %q for repository %q: %w",
+			path, g.modulePath, g.dir, derrors.NotFound)
+	}
+	return nil
+}
+
+// ResolvesRefs reports whether path is in the module of the repository, whose
+// branches, tags and commits are all versions.
+func (g *gitModuleGetter) ResolvesRefs(path string) bool {
+	return path == g.modulePath || strings.HasPrefix(path, g.modulePath+"/")
+}
+
+// Info returns basic information about the module at the commit named by vers.
+func (g *gitModuleGetter) Info(ctx context.Context, path, vers string) (_ *proxy.VersionInfo, err error) {
+	defer derrors.Wrap(&err, "gitModuleGetter.Info(%q, %q)", path, vers)
+
+	if err := g.checkPath(path); err != nil {
+		return nil, err
+	}
+	resolved, hash, err := g.resolve(ctx, vers)
+	if err != nil {
+		return nil, err
+	}
+	t, err := g.commitTime(ctx, hash)
+	if err != nil {
+		return nil, err
+	}
+	if resolved == "" {
+		resolved, err = g.pseudoVersion(ctx, hash, t)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &proxy.VersionInfo{Version: resolved, Time: t}, nil
+}
+
+// Mod returns the contents of the module's go.mod file.
+// If the file does not exist, it returns a synthesized one.
+func (g *gitModuleGetter) Mod(ctx context.Context, path, vers string) (_ []byte, err error) {
+	defer derrors.Wrap(&err, "gitModuleGetter.Mod(%q, %q)", path, vers)
+
+	if err := g.checkPath(path); err != nil {
+		return nil, err
+	}
+	_, hash, err := g.resolve(ctx, vers)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := g.git(ctx, "cat-file", "-e", hash+":go.mod"); err != nil {
+		return []byte(fmt.Sprintf("module %s\n", g.modulePath)), nil
+	}
+	return g.git(ctx, "show", hash+":go.mod")
+}
+
+// ContentDir returns an fs.FS for the module's contents, read from the tree of
+// the commit with git archive. As in a module zip file, the contents of
+// subdirectories that hold other modules are omitted.
+func (g *gitModuleGetter) ContentDir(ctx context.Context, path, vers string) (_ fs.FS, err error) {
+	defer derrors.Wrap(&err, "gitModuleGetter.ContentDir(%q, %q)", path, vers)
+
+	if err := g.checkPath(path); err != nil {
+		return nil, err
+	}
+	_, hash, err := g.resolve(ctx, vers)
+	if err != nil {
+		return nil, err
+	}
+	data, err := g.git(ctx, "archive", "--format=zip", hash)
+	if err != nil {
+		return nil, err
+	}
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, err
+	}
+	return withoutNestedModules(zr)
+}
+
+// withoutNestedModules returns a zip.Reader with the files of zr, except for
+// those in directories with a go.mod file other than the top-level one.
+func withoutNestedModules(zr *zip.Reader) (*zip.Reader, error) {
+	var nested []string
+	for _, f := range zr.File {
+		if dir, file := path.Split(f.Name); file == "go.mod" && dir != "" {
+			nested = append(nested, dir)
+		}
+	}
+	if len(nested) == 0 {
+		return zr, nil
+	}
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, f := range zr.File {
+		skip := false
+		for _, dir := range nested {
+			if strings.HasPrefix(f.Name, dir) {
+				skip = true
+				break
+			}
+		}
+		if skip {
+			continue
+		}
+		if err := zw.Copy(f); err != nil {
+			return nil, err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+}
+
+// SourceInfo returns nil, because the repository's location on a code host
+// is not known.
+func (g *gitModuleGetter) SourceInfo(ctx context.Context, _, _ string) (*source.Info, error) {
+	return nil, nil
+}
+
+// SourceFS is unimplemented for git repositories, because a commit's files
+// need not be present in the filesystem.
+func (g *gitModuleGetter) SourceFS() (string, fs.FS) {
+	return "", nil
+}
+
+// Versions returns the semantic version tags of the repository that are valid
+// for the module path.
+func (g *gitModuleGetter) Versions(ctx context.Context, modulePath string) (_ []string, err error) {
+	defer derrors.Wrap(&err, "gitModuleGetter.Versions(%q)", modulePath)
+
+	if err := g.checkPath(modulePath); err != nil {
+		return nil, err
+	}
+	return g.tags(ctx)
+}
+
+// For testing.
+func (g *gitModuleGetter) String() string {
+	return fmt.Sprintf("Git(%s)", g.dir)
+}
+
+// resolve returns the commit hash that vers refers to. If vers resolves to a
+// semantic version tag, resolve also returns the tag; otherwise it returns ""
+// for the version, and the caller should compute a pseudo-version.
+func (g *gitModuleGetter) resolve(ctx context.Context, vers string) (resolved, hash string, err error) {
+	ref := vers
+	switch {
+	case vers == version.Latest:
+		tags, err := g.tags(ctx)
+		if err != nil {
+			return "", "", err
+		}
+		if len(tags) == 0 {
+			ref = "HEAD"
+		} else {
+			resolved = version.LatestOf(tags)
+			ref = "refs/tags/" + resolved
+		}
+	case version.IsPseudo(vers):
+		rev, err := module.PseudoVersionRev(vers)
+		if err != nil {
+			return "", "", fmt.Errorf("%v: %w", err, derrors.InvalidArgument)
+		}
+		resolved, ref = vers, rev
+	case semver.IsValid(vers):
+		if err := module.CheckPathMajor(vers, g.pathMajor()); err != nil {
+			return "", "", fmt.Errorf("%v: %w", err, derrors.NotFound)
+		}
+		resolved, ref = vers, "refs/tags/"+vers
+	}
+	out, err := g.git(ctx, "rev-parse", "--verify", "--quiet", "--end-of-options", ref+"^{commit}")
+	if err != nil {
+		return "", "", fmt.Errorf("%s: unknown revision: %w", vers, derrors.NotFound)
+	}
+	hash = strings.TrimSpace(string(out))
+	if resolved == "" {
+		// A branch or other ref whose commit has a semantic version tag
+		// resolves to that version, as with the go command.
+		out, err := g.git(ctx, "tag", "--points-at", hash)
+		if err != nil {
+			return "", "", err
+		}
+		if tags := g.validTags(out); len(tags) > 0 {
+			resolved = version.LatestOf(tags)
+		}
+	}
+	return resolved, hash, nil
+}
+
+// pseudoVersion returns a pseudo-version for the commit, based on the highest
+// semantic version tag that is an ancestor of it.
+func (g *gitModuleGetter) pseudoVersion(ctx context.Context, hash string, t time.Time) (string, error) {
+	out, err := g.git(ctx, "tag", "--merged", hash)
+	if err != nil {
+		return "", err
+	}
+	older := ""
+	for _, v := range g.validTags(out) {
+		if semver.Compare(v, older) > 0 {
+			older = v
+		}
+	}
+	major := module.PathMajorPrefix(g.pathMajor())
+	return module.PseudoVersion(major, older, t, hash[:12]), nil
+}
+
+// tags returns the semantic version tags of the repository that are valid for
+// the module path.
+func (g *gitModuleGetter) tags(ctx context.Context) ([]string, error) {
+	out, err := g.git(ctx, "tag", "--list")
+	if err != nil {
+		return nil, err
+	}
+	return g.validTags(out), nil
+}
+
+// validTags returns the tags in the output of git tag that are semantic
+// versions that are valid for the module path.
+func (g *gitModuleGetter) validTags(out []byte) []string {
+	var tags []string
+	for _, tag := range strings.Fields(string(out)) {
+		if semver.IsValid(tag) && semver.Canonical(tag) == tag && !version.IsPseudo(tag) &&
+			module.CheckPathMajor(tag, g.pathMajor()) == nil {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+func (g *gitModuleGetter) pathMajor() string {
+	_, pathMajor, _ := module.SplitPathVersion(g.modulePath)
+	return pathMajor
+}
+
+// commitTime returns the commit time of the commit, in UTC.
+func (g *gitModuleGetter) commitTime(ctx context.Context, hash string) (time.Time, error) {
+	out, err := g.git(ctx, "show", "--no-patch", "--no-notes", "--format=%cI", hash)
+	if err != nil {
+		return time.Time{}, err
+	}
+	t, err := time.Parse(time.RFC3339, strings.TrimSpace(string(out)))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("parsing time output %q: %v", out, err)
+	}
+	return t.UTC(), nil
+}
+
+// git runs git with the given arguments in the repository, and returns its
+// standard output.
+func (g *gitModuleGetter) git(ctx context.Context, args ...string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = g.dir
+	out, err := cmd.Output()
+	if err != nil {
+		if ee, ok := err.(*exec.ExitError); ok {
+			return nil, fmt.Errorf("running git %s: %v: %s", args[0], err, ee.Stderr)
+		}
+		return nil, fmt.Errorf("running git %s: %v", args[0], err)
+	}
+	return out, nil
+}
@@ -10,13 +10,19 @@ import (
 	"io"
 	"io/fs"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/google/go-cmp/cmp"
+	"golang.org/x/mod/module"
+	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/checksum/checksumtest"
 	"golang.org/x/pkgsite/internal/derrors"
 	"golang.org/x/pkgsite/internal/proxy"
+	"golang.org/x/pkgsite/internal/proxy/proxytest"
 	"golang.org/x/pkgsite/internal/testenv"
 	"golang.org/x/pkgsite/internal/testing/testhelper"
 	"golang.org/x/pkgsite/internal/version"
@@ -31,6 +37,14 @@ func TestDirectoryModuleGetterEmpty(t *testing.T) {
 		t.Errorf("got %q, want %q", g.modulePath, want)
 	}
 
+	got, err := g.ListModules(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []internal.Modver{{Path: "example.com/testmod", Version: LocalVersion}}; !cmp.Equal(got, want) {
+		t.Errorf("ListModules() = %v, want %v", got, want)
+	}
+
 	_, err = NewDirectoryModuleGetter("", "testdata/no_go_mod")
 	if !errors.Is(err, derrors.BadModule) {
 		t.Errorf("got %v, want BadModule", err)
@@ -154,6 +168,22 @@ func TestGoPackagesModuleGetter(t *testing.T) {
 					}
 				})
 			}
+
+			t.Run("list", func(t *testing.T) {
+				// The modules are sorted by path, although go.work lists
+				// foo.com/foo first.
+				got, err := g.ListModules(ctx)
+				if err != nil {
+					t.Fatal(err)
+				}
+				want := []internal.Modver{
+					{Path: "bar.com/bar", Version: LocalVersion},
+					{Path: "foo.com/foo", Version: LocalVersion},
+				}
+				if diff := cmp.Diff(want, got); diff != "" {
+					t.Errorf("ListModules() mismatch [-want +got]:\n%s", diff)
+				}
+			})
 		})
 	}
 }
@@ -329,4 +359,268 @@ func TestFSProxyGetter(t *testing.T) {
 			t.Errorf("got %v, want NotFound", err)
 		}
 	})
+	t.Run("list", func(t *testing.T) {
+		got, err := g.ListModules(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := []internal.Modver{
+			{Path: "github.com/jackc/pgio", Version: "v1.0.0"},
+			{Path: "modcache.com", Version: "v1.0.0"},
+		}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("mismatch (-want, +got):\n%s", diff)
+		}
+	})
+	t.Run("versions", func(t *testing.T) {
+		got, err := g.Versions(ctx, modulePath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := []string{vers}; !cmp.Equal(got, want) {
+			t.Errorf("got %v, want %v", got, want)
+		}
+
+		if _, err := g.Versions(ctx, "nozip.com"); !errors.Is(err, derrors.NotFound) {
+			t.Errorf("got %v, want NotFound", err)
+		}
+	})
+}
+
+func TestModCacheGetterListModules(t *testing.T) {
+	// The cache holds escaped paths and versions, which sort differently:
+	// "a!b" (for aB) comes before "a.b", but "a.b" comes before "aB".
+	dir := t.TempDir()
+	for _, mv := range []internal.Modver{
+		{Path: "example.com/aB", Version: "v1.0.0"},
+		{Path: "example.com/a.b", Version: "v1.2.0"},
+		{Path: "example.com/a.b", Version: "v1.10.0"},
+		{Path: "example.com/a.b", Version: "v1.11.0-pre"},
+		{Path: "example.com/a.b", Version: "v1.9.0-RC"},
+	} {
+		ep, err := module.EscapePath(mv.Path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ev, err := module.EscapeVersion(mv.Version)
+		if err != nil {
+			t.Fatal(err)
+		}
+		vdir := filepath.Join(dir, "cache", "download", filepath.FromSlash(ep), "@v")
+		if err := os.MkdirAll(vdir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(vdir, ev+".zip"), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	g, err := NewModCacheGetter(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := g.ListModules(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []internal.Modver{
+		{Path: "example.com/a.b", Version: "v1.10.0"},
+		{Path: "example.com/a.b", Version: "v1.2.0"},
+		{Path: "example.com/a.b", Version: "v1.11.0-pre"},
+		{Path: "example.com/a.b", Version: "v1.9.0-RC"},
+		{Path: "example.com/aB", Version: "v1.0.0"},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("ListModules() mismatch (-want, +got):\n%s", diff)
+	}
+}
+
+func TestGitModuleGetter(t *testing.T) {
+	testenv.MustHaveExecPath(t, "git")
+	ctx := context.Background()
+
+	dir, _ := testhelper.WriteTxtarToTempDir(t, `
+-- go.mod --
+module example.com/gitmod
+-- a.go --
+package a
+-- nested/go.mod --
+module example.com/gitmod/nested
+-- nested/n.go --
+package nested
+`)
+	commitTime := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	git := func(args ...string) string {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		date := commitTime.Format(time.RFC3339)
+		cmd.Env = append(os.Environ(),
+			"GIT_AUTHOR_NAME=Joe Random", "GIT_AUTHOR_EMAIL=joe@example.com", "GIT_AUTHOR_DATE="+date,
+			"GIT_COMMITTER_NAME=Joe Random", "GIT_COMMITTER_EMAIL=joe@example.com", "GIT_COMMITTER_DATE="+date)
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %v: %v: %s", args, err, out)
+		}
+		return strings.TrimSpace(string(out))
+	}
+	git("init", "-q")
+	git("symbolic-ref", "HEAD", "refs/heads/main")
+	git("add", ".")
+	git("commit", "-q", "-m", "first")
+	git("tag", "v1.0.0")
+	git("tag", "not-a-version")
+	git("checkout", "-q", "-b", "feature")
+	if err := os.WriteFile(filepath.Join(dir, "b.go"), []byte("package a\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	git("add", ".")
+	git("commit", "-q", "-m", "second")
+	hash := git("rev-parse", "HEAD")
+	git("checkout", "-q", "main")
+	pseudo := "v1.0.1-0.20240102030405-" + hash[:12]
+
+	bare := t.TempDir()
+	git("clone", "-q", "--bare", dir, bare)
+
+	for name, repo := range map[string]string{"worktree": dir, "bare": bare} {
+		g, err := NewGitModuleGetter(ctx, "", repo)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := "example.com/gitmod"; g.modulePath != want {
+			t.Fatalf("got module path %q, want %q", g.modulePath, want)
+		}
+		t.Run(name, func(t *testing.T) {
+			for _, test := range []struct {
+				vers, want string
+			}{
+				{version.Latest, "v1.0.0"},
+				{"v1.0.0", "v1.0.0"},
+				{"main", "v1.0.0"},
+				{"not-a-version", "v1.0.0"},
+				{"feature", pseudo},
+				{hash[:8], pseudo},
+				{pseudo, pseudo},
+			} {
+				got, err := g.Info(ctx, "example.com/gitmod", test.vers)
+				if err != nil {
+					t.Fatal(err)
+				}
+				want := &proxy.VersionInfo{Version: test.want, Time: commitTime}
+				if !cmp.Equal(got, want) {
+					t.Errorf("Info(%q) = %+v, want %+v", test.vers, got, want)
+				}
+			}
+			for _, vers := range []string{"v1.1.0", "v2.0.0", "nosuchbranch"} {
+				if _, err := g.Info(ctx, "example.com/gitmod", vers); !errors.Is(err, derrors.NotFound) {
+					t.Errorf("Info(%q): got %v, want NotFound", vers, err)
+				}
+			}
+			if _, err := g.Info(ctx, "example.com/other", "v1.0.0"); !errors.Is(err, derrors.NotFound) {
+				t.Errorf("other module: got %v, want NotFound", err)
+			}
+			for path, want := range map[string]bool{
+				"example.com/gitmod":       true,
+				"example.com/gitmod/pkg":   true,
+				"example.com/gitmodule":    false,
+				"example.com/other/gitmod": false,
+			} {
+				if got := g.ResolvesRefs(path); got != want {
+					t.Errorf("ResolvesRefs(%q) = %t, want %t", path, got, want)
+				}
+			}
+
+			fsys, err := g.ContentDir(ctx, "example.com/gitmod", "feature")
+			if err != nil {
+				t.Fatal(err)
+			}
+			var files []string
+			if err := fs.WalkDir(fsys, ".", func(path string, d fs.DirEntry, err error) error {
+				if err == nil && !d.IsDir() {
+					files = append(files, path)
+				}
+				return err
+			}); err != nil {
+				t.Fatal(err)
+			}
+			if want := []string{"a.go", "b.go", "go.mod"}; !cmp.Equal(files, want) {
+				t.Errorf("ContentDir files: got %v, want %v", files, want)
+			}
+
+			mod, err := g.Mod(ctx, "example.com/gitmod", "v1.0.0")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got, want := string(mod), "module example.com/gitmod\n"; got != want {
+				t.Errorf("Mod: got %q, want %q", got, want)
+			}
+
+			vs, err := g.Versions(ctx, "example.com/gitmod")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := []string{"v1.0.0"}; !cmp.Equal(vs, want) {
+				t.Errorf("Versions: got %v, want %v", vs, want)
+			}
+		})
+	}
+}
+
+func TestVerifiedProxyModuleGetter(t *testing.T) {
+	ctx := context.Background()
+	good := &proxytest.Module{
+		ModulePath: "example.com/verified",
+		Version:    "v1.0.0",
+		Files: map[string]string{
+			"go.mod": "module example.com/verified",
+			"a.go":   "package a",
+		},
+	}
+	badZip := &proxytest.Module{
+		ModulePath: "example.com/badzip",
+		Version:    "v1.0.0",
+		Files:      map[string]string{"a.go": "package a"},
+	}
+	badMod := &proxytest.Module{
+		ModulePath: "example.com/badmod",
+		Version:    "v1.0.0",
+		Files: map[string]string{
+			"go.mod": "module example.com/badmod",
+			"a.go":   "package a",
+		},
+	}
+	// The checksum database has different contents for the bad modules than
+	// the proxy serves.
+	verifier, teardownDB := checksumtest.SetupTestVerifier(t, []*proxytest.Module{
+		good,
+		badZip.ReplaceFile("a.go", "package a // original"),
+		badMod.ReplaceFile("go.mod", "module example.com/badmod\n\ngo 1.21"),
+	})
+	defer teardownDB()
+	prox, teardownProxy := proxytest.SetupTestClient(t, []*proxytest.Module{good, badZip, badMod})
+	defer teardownProxy()
+	g := NewVerifiedProxyModuleGetter(prox, nil, verifier)
+
+	fr := FetchModule(ctx, good.ModulePath, good.Version, g)
+	if fr.Error != nil {
+		t.Fatal(fr.Error)
+	}
+	if got, want := len(fr.Module.GoSum), 2; got != want {
+		t.Fatalf("got %d go.sum lines, want %d: %q", got, want, fr.Module.GoSum)
+	}
+	for i, prefix := range []string{"example.com/verified v1.0.0 h1:", "example.com/verified v1.0.0/go.mod h1:"} {
+		if got := fr.Module.GoSum[i]; !strings.HasPrefix(got, prefix) {
+			t.Errorf("go.sum line %d = %q, want prefix %q", i, got, prefix)
+		}
+	}
+
+	for _, m := range []*proxytest.Module{badZip, badMod} {
+		fr := FetchModule(ctx, m.ModulePath, m.Version, g)
+		if !errors.Is(fr.Error, derrors.ChecksumMismatch) {
+			t.Errorf("%s: got error %v, want ChecksumMismatch", m.ModulePath, fr.Error)
+		}
+		if want := derrors.ToStatus(derrors.ChecksumMismatch); fr.Status != want {
+			t.Errorf("%s: got status %d, want %d", m.ModulePath, fr.Status, want)
+		}
+	}
 }
@@ -0,0 +1,770 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fetch
+
+import (
+	"context"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/log"
+	"golang.org/x/pkgsite/internal/stdlib"
+	"golang.org/x/pkgsite/internal/trace"
+	"golang.org/x/pkgsite/internal/version"
+)
+
+const (
+	// maxTypeCheckedPackages is the largest number of packages in a module
+	// for which type relations are computed.
+	maxTypeCheckedPackages = 1000
+
+	// maxImplementedBy is the largest number of implementing types recorded
+	// for an interface.
+	maxImplementedBy = 100
+
+	// maxDependencyModules is the largest number of required modules that
+	// are read to resolve the types that a module embeds from them.
+	maxDependencyModules = 10
+
+	// maxDependencyTime is the longest time spent reading the modules that
+	// a module requires.
+	maxDependencyTime = 30 * time.Second
+)
+
+// stdlibInterfacePackages are the standard library packages whose interfaces
+// are checked against the types of every module, in addition to the
+// predeclared error interface.
+var stdlibInterfacePackages = []string{
+	"container/heap",
+	"context",
+	"database/sql",
+	"database/sql/driver",
+	"encoding",
+	"encoding/json",
+	"encoding/xml",
+	"flag",
+	"fmt",
+	"hash",
+	"image",
+	"image/color",
+	"image/draw",
+	"io",
+	"io/fs",
+	"net",
+	"net/http",
+	"sort",
+}
+
+// typeRelations holds the interfaces implemented by the types of a package,
+// the types that implement the interfaces of the package, and the members
+// promoted to the types of the package from their embedded types. The maps
+// are keyed by the names of the package's types.
+//
+// The values of implements and implementedBy are sorted lists of types written
+// as an import path followed by a dot and a name, except for the predeclared
+// "error". The values of promotedMethods and promotedFields are sorted lists
+// of exported members, each written as its declaring type, in the same form,
+// followed by a dot and the member's name. The types in incomplete embed a
+// type that could not be resolved, so some of their promoted members may be
+// missing.
+type typeRelations struct {
+	implements      map[string][]string
+	implementedBy   map[string][]string
+	promotedMethods map[string][]string
+	promotedFields  map[string][]string
+	incomplete      map[string]bool
+}
+
+// moduleTypeRelations type-checks the packages with the given import paths,
+// read from contentDir, and returns the typeRelations for each of them. Only
+// the first build context is considered, and function bodies are ignored.
+//
+// The interfaces considered are those declared in the module and in
+// stdlibInterfacePackages. Packages of other modules are read with deps, if
+// it is non-nil, but only those that declare types embedded by the packages
+// being checked; others are replaced by empty packages. Type errors are
+// ignored, so a package whose dependencies cannot be resolved still has
+// relations for the types that do not depend on them.
+func moduleTypeRelations(ctx context.Context, modulePath string, contentDir fs.FS, pkgPaths []string, deps *dependencyGetter) map[string]*typeRelations {
+	_, span := trace.StartSpan(ctx, "fetch.moduleTypeRelations")
+	defer span.End()
+
+	if len(pkgPaths) > maxTypeCheckedPackages {
+		log.Infof(ctx, "%s: not computing type relations for %d packages", modulePath, len(pkgPaths))
+		return nil
+	}
+	imp := &moduleImporter{
+		modulePath: modulePath,
+		contentDir: contentDir,
+		deps:       deps,
+		pkgPaths:   map[string]bool{},
+		fset:       token.NewFileSet(),
+		files:      map[string][]*ast.File{},
+		embedded:   map[string]bool{},
+		pkgs:       map[string]*types.Package{},
+	}
+	for _, p := range pkgPaths {
+		imp.pkgPaths[p] = true
+	}
+	// Parse every package first, so that all the packages that the module
+	// embeds types from are known before any of them is imported.
+	for _, p := range pkgPaths {
+		files, err := imp.parseFiles(contentDir, modulePath, p)
+		if err != nil {
+			log.Debugf(ctx, "moduleTypeRelations: %v", err)
+			continue
+		}
+		imp.files[p] = files
+	}
+	var pkgs []*types.Package
+	for _, p := range pkgPaths {
+		pkg, err := imp.importPackage(ctx, p)
+		if err != nil {
+			log.Debugf(ctx, "moduleTypeRelations: %v", err)
+			continue
+		}
+		pkgs = append(pkgs, pkg)
+	}
+
+	// Collect the interfaces, indexed by their first method.
+	ifaces := map[string][]*types.TypeName{}
+	addInterfaces := func(pkg *types.Package) {
+		for _, name := range pkg.Scope().Names() {
+			if tn := candidateInterface(pkg.Scope().Lookup(name)); tn != nil {
+				m := tn.Type().Underlying().(*types.Interface).Method(0).Name()
+				ifaces[m] = append(ifaces[m], tn)
+			}
+		}
+	}
+	for _, pkg := range pkgs {
+		addInterfaces(pkg)
+	}
+	if modulePath != stdlib.ModulePath {
+		for _, p := range stdlibInterfacePackages {
+			pkg, err := importStdlib(ctx, p)
+			if err != nil {
+				log.Debugf(ctx, "moduleTypeRelations: %v", err)
+				continue
+			}
+			addInterfaces(pkg)
+		}
+	}
+	errorType := types.Universe.Lookup("error").(*types.TypeName)
+	ifaces["Error"] = append(ifaces["Error"], errorType)
+
+	rels := map[string]*typeRelations{}
+	relationsFor := func(pkgPath string) *typeRelations {
+		r := rels[pkgPath]
+		if r == nil {
+			r = &typeRelations{
+				implements:      map[string][]string{},
+				implementedBy:   map[string][]string{},
+				promotedMethods: map[string][]string{},
+				promotedFields:  map[string][]string{},
+				incomplete:      map[string]bool{},
+			}
+			rels[pkgPath] = r
+		}
+		return r
+	}
+	for _, pkg := range pkgs {
+		for _, name := range pkg.Scope().Names() {
+			tn, ok := pkg.Scope().Lookup(name).(*types.TypeName)
+			if !ok || !tn.Exported() || tn.IsAlias() {
+				continue
+			}
+			named, ok := tn.Type().(*types.Named)
+			if !ok || named.TypeParams().Len() > 0 || types.IsInterface(named) {
+				continue
+			}
+			if _, ok := named.Underlying().(*types.Pointer); ok {
+				// Named pointer types have no methods.
+				continue
+			}
+			ptr := types.NewPointer(named)
+			ms := types.NewMethodSet(ptr)
+			if methods := promotedMethods(ms); len(methods) > 0 {
+				relationsFor(pkg.Path()).promotedMethods[name] = methods
+			}
+			if fields := promotedFields(named); len(fields) > 0 {
+				relationsFor(pkg.Path()).promotedFields[name] = fields
+			}
+			if embedsInvalidType(named) {
+				relationsFor(pkg.Path()).incomplete[name] = true
+			}
+			seen := map[*types.TypeName]bool{}
+			for i := 0; i < ms.Len(); i++ {
+				for _, itn := range ifaces[ms.At(i).Obj().Name()] {
+					if seen[itn] {
+						continue
+					}
+					seen[itn] = true
+					iface := itn.Type().Underlying().(*types.Interface)
+					if !types.Implements(named, iface) && !types.Implements(ptr, iface) {
+						continue
+					}
+					r := relationsFor(pkg.Path())
+					r.implements[name] = append(r.implements[name], qualifiedTypeName(itn))
+					if itn.Pkg() != nil && imp.pkgPaths[itn.Pkg().Path()] {
+						r := relationsFor(itn.Pkg().Path())
+						r.implementedBy[itn.Name()] = append(r.implementedBy[itn.Name()], qualifiedTypeName(tn))
+					}
+				}
+			}
+		}
+	}
+	for _, r := range rels {
+		for _, names := range r.implements {
+			sort.Strings(names)
+		}
+		for n, names := range r.implementedBy {
+			sort.Strings(names)
+			if len(names) > maxImplementedBy {
+				r.implementedBy[n] = names[:maxImplementedBy]
+			}
+		}
+	}
+	return rels
+}
+
+// promotedMethods returns the exported methods in ms that are promoted from
+// embedded types, as described for typeRelations.
+func promotedMethods(ms *types.MethodSet) []string {
+	var methods []string
+	for i := 0; i < ms.Len(); i++ {
+		sel := ms.At(i)
+		if len(sel.Index()) < 2 || !sel.Obj().Exported() {
+			continue
+		}
+		recv := sel.Obj().Type().(*types.Signature).Recv()
+		if recv == nil {
+			continue
+		}
+		if tn := namedTypeName(recv.Type()); tn != nil {
+			methods = append(methods, qualifiedTypeName(tn)+"."+sel.Obj().Name())
+		}
+	}
+	sort.Strings(methods)
+	return methods
+}
+
+// promotedFields returns the exported fields of the struct type named that
+// are promoted from its embedded types, as described for typeRelations.
+func promotedFields(named *types.Named) []string {
+	var (
+		fields  []string
+		visited = map[*types.TypeName]bool{}
+		visit   func(owner *types.TypeName, t types.Type, depth int)
+	)
+	visit = func(owner *types.TypeName, t types.Type, depth int) {
+		st, ok := t.Underlying().(*types.Struct)
+		if !ok {
+			return
+		}
+		for i := 0; i < st.NumFields(); i++ {
+			f := st.Field(i)
+			if depth > 0 && f.Exported() {
+				// Check that the field is not shadowed or ambiguous.
+				obj, index, _ := types.LookupFieldOrMethod(named, true, named.Obj().Pkg(), f.Name())
+				if obj == f && len(index) > 1 {
+					fields = append(fields, qualifiedTypeName(owner)+"."+f.Name())
+				}
+			}
+			if !f.Embedded() {
+				continue
+			}
+			if tn := namedTypeName(f.Type()); tn != nil && !visited[tn] {
+				visited[tn] = true
+				visit(tn, tn.Type(), depth+1)
+			}
+		}
+	}
+	visited[named.Obj()] = true
+	visit(named.Obj(), named, 0)
+	sort.Strings(fields)
+	return fields
+}
+
+// embedsInvalidType reports whether the struct type named, or a struct type
+// embedded in it, embeds a type that could not be resolved.
+func embedsInvalidType(named *types.Named) bool {
+	visited := map[types.Type]bool{}
+	var visit func(t types.Type) bool
+	visit = func(t types.Type) bool {
+		if visited[t] {
+			return false
+		}
+		visited[t] = true
+		st, ok := t.Underlying().(*types.Struct)
+		if !ok {
+			return false
+		}
+		for i := 0; i < st.NumFields(); i++ {
+			f := st.Field(i)
+			if !f.Embedded() {
+				continue
+			}
+			ft := f.Type()
+			if p, ok := ft.(*types.Pointer); ok {
+				ft = p.Elem()
+			}
+			if ft == types.Typ[types.Invalid] || visit(ft) {
+				return true
+			}
+		}
+		return false
+	}
+	return visit(named)
+}
+
+// namedTypeName returns the type name of t, or of the type t points to, if
+// it is a named type.
+func namedTypeName(t types.Type) *types.TypeName {
+	if p, ok := t.(*types.Pointer); ok {
+		t = p.Elem()
+	}
+	if n, ok := t.(*types.Named); ok {
+		return n.Origin().Obj()
+	}
+	return nil
+}
+
+// candidateInterface returns obj as a *types.TypeName if it is an exported,
+// non-generic interface with at least one method whose types could all be
+// resolved. Otherwise it returns nil.
+func candidateInterface(obj types.Object) *types.TypeName {
+	tn, ok := obj.(*types.TypeName)
+	if !ok || !tn.Exported() || tn.IsAlias() {
+		return nil
+	}
+	named, ok := tn.Type().(*types.Named)
+	if !ok || named.TypeParams().Len() > 0 {
+		return nil
+	}
+	iface, ok := named.Underlying().(*types.Interface)
+	if !ok || !iface.IsMethodSet() || iface.NumMethods() == 0 {
+		return nil
+	}
+	for i := 0; i < iface.NumMethods(); i++ {
+		if hasInvalidType(iface.Method(i).Type().(*types.Signature)) {
+			return nil
+		}
+	}
+	return tn
+}
+
+func hasInvalidType(sig *types.Signature) bool {
+	for _, tup := range []*types.Tuple{sig.Params(), sig.Results()} {
+		for i := 0; i < tup.Len(); i++ {
+			if tup.At(i).Type() == types.Typ[types.Invalid] {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func qualifiedTypeName(tn *types.TypeName) string {
+	if tn.Pkg() == nil {
+		return tn.Name()
+	}
+	return tn.Pkg().Path() + "." + tn.Name()
+}
+
+// typeCheckConfig returns the configuration for type-checking packages to
+// compute type relations. Errors are ignored.
+func typeCheckConfig(imp types.Importer) *types.Config {
+	return &types.Config{
+		Importer:         imp,
+		IgnoreFuncBodies: true,
+		FakeImportC:      true,
+		Error:            func(error) {},
+	}
+}
+
+// A moduleImporter imports the packages of a module from its contents.
+// Standard library packages are imported with importStdlib. Packages of
+// other modules are read with deps if the packages being checked embed their
+// types, and are replaced by empty ones otherwise.
+type moduleImporter struct {
+	modulePath string
+	contentDir fs.FS
+	deps       *dependencyGetter // nil if other modules are not read
+	pkgPaths   map[string]bool   // import paths of the module's packages
+	fset       *token.FileSet
+	files      map[string][]*ast.File    // parsed files of the module's packages
+	embedded   map[string]bool           // import paths of packages whose types are embedded
+	pkgs       map[string]*types.Package // nil while being type-checked
+}
+
+// importPackage returns the type-checked package with the given import path.
+func (imp *moduleImporter) importPackage(ctx context.Context, importPath string) (*types.Package, error) {
+	if importPath == "unsafe" {
+		return types.Unsafe, nil
+	}
+	if pkg, ok := imp.pkgs[importPath]; ok {
+		if pkg == nil {
+			return nil, fmt.Errorf("import cycle through %q", importPath)
+		}
+		return pkg, nil
+	}
+	var (
+		files []*ast.File
+		err   error
+	)
+	switch {
+	case imp.pkgPaths[importPath]:
+		files = imp.files[importPath]
+		if files == nil {
+			return nil, fmt.Errorf("%s: no files", importPath)
+		}
+	case imp.modulePath != stdlib.ModulePath && stdlib.Contains(importPath):
+		if pkg, err := importStdlib(ctx, importPath); err == nil {
+			return pkg, nil
+		}
+		return fakePackage(importPath), nil
+	default:
+		if imp.deps == nil || !imp.embedded[importPath] {
+			return fakePackage(importPath), nil
+		}
+		modulePath, contentDir := imp.deps.module(ctx, importPath)
+		if contentDir == nil {
+			return fakePackage(importPath), nil
+		}
+		files, err = imp.parseFiles(contentDir, modulePath, importPath)
+		if err != nil {
+			return fakePackage(importPath), nil
+		}
+	}
+
+	imp.pkgs[importPath] = nil
+	importer := importerFunc(func(p string) (*types.Package, error) { return imp.importPackage(ctx, p) })
+	pkg, _ := typeCheckConfig(importer).Check(importPath, imp.fset, files, nil)
+	imp.pkgs[importPath] = pkg
+	return pkg, nil
+}
+
+// parseFiles parses the non-test .go files of the package with the given
+// import path, in the module with the given path and contents, that match
+// the first build context. It records the packages whose types the files
+// embed.
+func (imp *moduleImporter) parseFiles(contentDir fs.FS, modulePath, importPath string) (_ []*ast.File, err error) {
+	innerPath := importPath
+	if modulePath != stdlib.ModulePath {
+		innerPath = rel(importPath, modulePath)
+	}
+	files, err := readGoFiles(contentDir, innerPath)
+	if err != nil {
+		return nil, err
+	}
+	afs, err := parseGoFiles(imp.fset, path.Join(modulePath, innerPath), files)
+	if err != nil {
+		return nil, err
+	}
+	if len(afs) == 0 {
+		return nil, fmt.Errorf("%s: no files for %s", importPath, internal.BuildContexts[0])
+	}
+	for _, p := range embeddedImports(afs) {
+		imp.embedded[p] = true
+	}
+	return afs, nil
+}
+
+// readGoFiles returns the contents of the non-test .go files in the
+// directory dir of contentDir that match the first build context, keyed by
+// file name.
+func readGoFiles(contentDir fs.FS, dir string) (map[string][]byte, error) {
+	entries, err := fs.ReadDir(contentDir, dir)
+	if err != nil {
+		return nil, err
+	}
+	files := map[string][]byte{}
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		b, err := readFSFile(contentDir, path.Join(dir, name), MaxFileSize)
+		if err != nil {
+			return nil, err
+		}
+		files[name] = b
+	}
+	return matchingFiles(internal.BuildContexts[0], files)
+}
+
+// parseGoFiles parses files, which are in the directory dir, in order of
+// name, so that type-checking them is deterministic.
+func parseGoFiles(fset *token.FileSet, dir string, files map[string][]byte) ([]*ast.File, error) {
+	var names []string
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var afs []*ast.File
+	for _, name := range names {
+		f, err := parser.ParseFile(fset, path.Join(dir, name), files[name], parser.SkipObjectResolution)
+		if err != nil {
+			return nil, err
+		}
+		afs = append(afs, f)
+	}
+	return afs, nil
+}
+
+// embeddedImports returns the import paths of the packages whose types are
+// embedded in the struct and interface types declared in files.
+func embeddedImports(files []*ast.File) []string {
+	var paths []string
+	for _, f := range files {
+		names := map[string]string{}
+		for _, is := range f.Imports {
+			p, err := strconv.Unquote(is.Path.Value)
+			if err != nil {
+				continue
+			}
+			name := internal.DefaultPackageName(p)
+			if is.Name != nil {
+				name = is.Name.Name
+			}
+			names[name] = p
+		}
+		ast.Inspect(f, func(n ast.Node) bool {
+			var fields *ast.FieldList
+			switch n := n.(type) {
+			case *ast.StructType:
+				fields = n.Fields
+			case *ast.InterfaceType:
+				fields = n.Methods
+			default:
+				return true
+			}
+			for _, field := range fields.List {
+				if len(field.Names) > 0 {
+					continue
+				}
+				t := field.Type
+				if st, ok := t.(*ast.StarExpr); ok {
+					t = st.X
+				}
+				switch x := t.(type) {
+				case *ast.IndexExpr:
+					t = x.X
+				case *ast.IndexListExpr:
+					t = x.X
+				}
+				if sel, ok := t.(*ast.SelectorExpr); ok {
+					if id, ok := sel.X.(*ast.Ident); ok && names[id.Name] != "" {
+						paths = append(paths, names[id.Name])
+					}
+				}
+			}
+			return true
+		})
+	}
+	return paths
+}
+
+// A dependencyGetter reads the modules that a module requires.
+type dependencyGetter struct {
+	mg       ModuleGetter
+	goMod    *internal.GoModDirectives
+	deadline time.Time        // no modules are read after this time
+	dirs     map[string]fs.FS // by module path; nil if the module could not be read
+}
+
+// newDependencyGetter returns a dependencyGetter that reads the modules
+// required by goMod with mg.
+//
+// Reading a module downloads its zip, and the contents are not shared with
+// other dependencyGetters. So each module whose type relations are computed
+// costs up to maxDependencyModules downloads, and up to maxDependencyTime,
+// in the worker and again in every process that serves the module from a
+// LazyModule, such as a FetchDataSource.
+func newDependencyGetter(mg ModuleGetter, goMod *internal.GoModDirectives) *dependencyGetter {
+	return &dependencyGetter{
+		mg:       mg,
+		goMod:    goMod,
+		deadline: time.Now().Add(maxDependencyTime),
+		dirs:     map[string]fs.FS{},
+	}
+}
+
+// module returns the path and contents of the required module that provides
+// the package with the given import path. It returns a nil fs.FS if there is
+// no such module, if it is replaced by a directory, or if it could not be
+// read.
+func (g *dependencyGetter) module(ctx context.Context, importPath string) (string, fs.FS) {
+	var req *internal.ModuleRequire
+	for _, r := range g.goMod.Requires {
+		if (importPath == r.ModulePath || strings.HasPrefix(importPath, r.ModulePath+"/")) &&
+			(req == nil || len(r.ModulePath) > len(req.ModulePath)) {
+			req = r
+		}
+	}
+	if req == nil {
+		return "", nil
+	}
+	if dir, ok := g.dirs[req.ModulePath]; ok {
+		return req.ModulePath, dir
+	}
+	if len(g.dirs) >= maxDependencyModules || time.Now().After(g.deadline) {
+		return "", nil
+	}
+	getPath, getVersion := req.ModulePath, req.Version
+	for _, r := range g.goMod.Replaces {
+		if r.OldPath == req.ModulePath && (r.OldVersion == "" || r.OldVersion == req.Version) {
+			getPath, getVersion = r.NewPath, r.NewVersion
+		}
+	}
+	var dir fs.FS
+	if getVersion != "" {
+		ctx, cancel := context.WithDeadline(ctx, g.deadline)
+		defer cancel()
+		var err error
+		dir, err = g.mg.ContentDir(ctx, getPath, getVersion)
+		if err != nil {
+			log.Debugf(ctx, "reading dependency %s@%s: %v", getPath, getVersion, err)
+			dir = nil
+		}
+	}
+	g.dirs[req.ModulePath] = dir
+	return req.ModulePath, dir
+}
+
+// fakePackage returns an empty package standing in for one that could not be
+// imported.
+func fakePackage(importPath string) *types.Package {
+	pkg := types.NewPackage(importPath, internal.DefaultPackageName(importPath))
+	pkg.MarkComplete()
+	return pkg
+}
+
+// stdlibImporter type-checks standard library packages from the sources
+// returned by stdlibSourceLocked. Packages are shared by all modules, so they are
+// type-checked at most once.
+var stdlibImporter = struct {
+	mu      sync.Mutex
+	fset    *token.FileSet
+	src     fs.FS     // nil until found
+	srcErr  error     // the error from the last attempt to find src
+	srcTime time.Time // the time of that attempt
+	pkgs    map[string]*types.Package
+	errs    map[string]error
+}{
+	fset: token.NewFileSet(),
+	pkgs: map[string]*types.Package{},
+	errs: map[string]error{},
+}
+
+// stdlibSourceRetryInterval is how long stdlibSourceLocked waits before trying
+// again to find the standard library sources, after failing.
+const stdlibSourceRetryInterval = 10 * time.Minute
+
+// stdlibSourceLocked returns the sources of the standard library, laid out as
+// in GOROOT/src: the src directory of the GOROOT of the running program if it
+// has one, as in development, and otherwise the latest release read with the
+// stdlib zip getter, as in a deployed worker. Without them, the interfaces of
+// stdlibInterfacePackages are missing from every module, so failures are
+// logged as errors.
+func stdlibSourceLocked(ctx context.Context) (fs.FS, error) {
+	si := &stdlibImporter
+	if si.src != nil {
+		return si.src, nil
+	}
+	if si.srcErr != nil && time.Since(si.srcTime) < stdlibSourceRetryInterval {
+		return nil, si.srcErr
+	}
+	src := filepath.Join(build.Default.GOROOT, "src")
+	if fi, err := os.Stat(filepath.Join(src, "fmt")); err == nil && fi.IsDir() {
+		si.src = os.DirFS(src)
+		return si.src, nil
+	}
+	si.src, si.srcErr = NewStdlibZipModuleGetter().ContentDir(ctx, stdlib.ModulePath, version.Latest)
+	si.srcTime = time.Now()
+	if si.srcErr != nil {
+		si.srcErr = fmt.Errorf("no standard library sources in %s, and reading the stdlib zip: %w", src, si.srcErr)
+		log.Errorf(ctx, "standard library interfaces will be missing: %v", si.srcErr)
+		return nil, si.srcErr
+	}
+	return si.src, nil
+}
+
+// importStdlib returns the type-checked standard library package with the
+// given import path.
+func importStdlib(ctx context.Context, importPath string) (*types.Package, error) {
+	stdlibImporter.mu.Lock()
+	defer stdlibImporter.mu.Unlock()
+	return importStdlibLocked(ctx, importPath)
+}
+
+// An importerFunc is a types.Importer that calls itself.
+type importerFunc func(string) (*types.Package, error)
+
+func (f importerFunc) Import(path string) (*types.Package, error) { return f(path) }
+
+func importStdlibLocked(ctx context.Context, importPath string) (*types.Package, error) {
+	si := &stdlibImporter
+	if importPath == "unsafe" {
+		return types.Unsafe, nil
+	}
+	if pkg, ok := si.pkgs[importPath]; ok {
+		if pkg == nil {
+			return nil, fmt.Errorf("import cycle through %q", importPath)
+		}
+		return pkg, nil
+	}
+	if err := si.errs[importPath]; err != nil {
+		return nil, err
+	}
+	// Errors finding the sources are not remembered for each package, so
+	// that the packages are imported once the sources are found.
+	src, err := stdlibSourceLocked(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("importStdlib(%q): %w", importPath, err)
+	}
+	pkg, err := checkStdlibPackage(ctx, src, importPath)
+	if err != nil {
+		si.errs[importPath] = err
+		return nil, err
+	}
+	return pkg, nil
+}
+
+func checkStdlibPackage(ctx context.Context, src fs.FS, importPath string) (*types.Package, error) {
+	si := &stdlibImporter
+	dir := importPath
+	if _, err := fs.Stat(src, dir); err != nil {
+		// Dependencies of the standard library are vendored.
+		dir = path.Join("vendor", importPath)
+	}
+	files, err := readGoFiles(src, dir)
+	if err != nil {
+		return nil, fmt.Errorf("importStdlib(%q): %v", importPath, err)
+	}
+	afs, err := parseGoFiles(si.fset, dir, files)
+	if err != nil {
+		return nil, fmt.Errorf("importStdlib(%q): %v", importPath, err)
+	}
+	si.pkgs[importPath] = nil
+	imp := importerFunc(func(p string) (*types.Package, error) { return importStdlibLocked(ctx, p) })
+	pkg, _ := typeCheckConfig(imp).Check(importPath, si.fset, afs, nil)
+	si.pkgs[importPath] = pkg
+	return pkg, nil
+}
@@ -0,0 +1,179 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fetch
+
+import (
+	"context"
+	"io/fs"
+	"testing"
+	"testing/fstest"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/proxy/proxytest"
+	"golang.org/x/pkgsite/internal/source"
+)
+
+func TestModuleTypeRelations(t *testing.T) {
+	fsys := fstest.MapFS{
+		"go.mod": {Data: []byte("module example.com/m")},
+		"shape/shape.go": {Data: []byte(`package shape
+
+import "example.com/other"
+
+type Shape interface {
+	Area() float64
+}
+
+type Other interface {
+	Foreign() other.T
+}
+`)},
+		"square/square.go": {Data: []byte(`package square
+
+import (
+	"fmt"
+	"io"
+)
+
+type Square struct{ side float64 }
+
+func (s Square) Area() float64 { return s.side * s.side }
+
+func (s *Square) String() string { return fmt.Sprint(s.side) }
+
+type Err string
+
+func (e Err) Error() string { return string(e) }
+
+type unexported struct{}
+
+func (unexported) Area() float64 { return 0 }
+
+type Reader struct {
+	*io.LimitedReader
+	Square
+	N int // shadows io.LimitedReader.N
+}
+`)},
+	}
+	got := moduleTypeRelations(context.Background(), "example.com/m", fsys,
+		[]string{"example.com/m/shape", "example.com/m/square"}, nil)
+
+	want := map[string]*typeRelations{
+		"example.com/m/shape": {
+			implements: map[string][]string{},
+			implementedBy: map[string][]string{
+				"Shape": {"example.com/m/square.Reader", "example.com/m/square.Square"},
+			},
+			promotedMethods: map[string][]string{},
+			promotedFields:  map[string][]string{},
+			incomplete:      map[string]bool{},
+		},
+		"example.com/m/square": {
+			implements: map[string][]string{
+				"Err":    {"error"},
+				"Reader": {"example.com/m/shape.Shape", "fmt.Stringer", "io.Reader"},
+				"Square": {"example.com/m/shape.Shape", "fmt.Stringer"},
+			},
+			implementedBy: map[string][]string{},
+			promotedMethods: map[string][]string{
+				"Reader": {
+					"example.com/m/square.Square.Area",
+					"example.com/m/square.Square.String",
+					"io.LimitedReader.Read",
+				},
+			},
+			promotedFields: map[string][]string{
+				"Reader": {"io.LimitedReader.R"},
+			},
+			incomplete: map[string]bool{},
+		},
+	}
+	if diff := cmp.Diff(want, got, cmp.AllowUnexported(typeRelations{})); diff != "" {
+		t.Errorf("mismatch (-want, +got):\n%s", diff)
+	}
+}
+
+func TestModuleTypeRelationsDependencies(t *testing.T) {
+	proxyClient, teardownProxy := proxytest.SetupTestClient(t, []*proxytest.Module{{
+		ModulePath: "example.com/dep",
+		Version:    "v1.0.0",
+		Files: map[string]string{
+			"go.mod": "module example.com/dep",
+			"dep.go": `package dep
+
+type Base struct{ Inner }
+
+func (Base) Hello() {}
+
+type Inner struct{}
+
+func (*Inner) Bye() {}
+`,
+		},
+	}})
+	defer teardownProxy()
+	ctx := context.Background()
+	mg := NewProxyModuleGetter(proxyClient, source.NewClientForTesting())
+	goMod := &internal.GoModDirectives{Requires: []*internal.ModuleRequire{
+		{ModulePath: "example.com/dep", Version: "v1.0.0"},
+		{ModulePath: "example.com/gone", Version: "v1.0.0"},
+	}}
+	fsys := fstest.MapFS{
+		"go.mod": {Data: []byte("module example.com/m")},
+		"p/p.go": {Data: []byte(`package p
+
+import (
+	"example.com/dep"
+	"example.com/gone"
+)
+
+type A struct{ *dep.Base }
+
+type B struct{ gone.T }
+`)},
+	}
+	for _, test := range []struct {
+		name           string
+		deps           *dependencyGetter
+		wantPromoted   map[string][]string
+		wantIncomplete map[string]bool
+	}{
+		{
+			name: "dependencies",
+			deps: newDependencyGetter(mg, goMod),
+			wantPromoted: map[string][]string{
+				"A": {"example.com/dep.Base.Hello", "example.com/dep.Inner.Bye"},
+			},
+			wantIncomplete: map[string]bool{"B": true},
+		},
+		{
+			name:           "deadline passed",
+			deps:           &dependencyGetter{mg: mg, goMod: goMod, dirs: map[string]fs.FS{}},
+			wantPromoted:   map[string][]string{},
+			wantIncomplete: map[string]bool{"A": true, "B": true},
+		},
+		{
+			name:           "no dependencies",
+			wantPromoted:   map[string][]string{},
+			wantIncomplete: map[string]bool{"A": true, "B": true},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			rels := moduleTypeRelations(ctx, "example.com/m", fsys, []string{"example.com/m/p"}, test.deps)
+			r := rels["example.com/m/p"]
+			if r == nil {
+				t.Fatal("no relations")
+			}
+			if diff := cmp.Diff(test.wantPromoted, r.promotedMethods); diff != "" {
+				t.Errorf("promotedMethods mismatch (-want, +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(test.wantIncomplete, r.incomplete); diff != "" {
+				t.Errorf("incomplete mismatch (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
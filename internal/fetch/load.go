@@ -25,8 +25,10 @@ import (
 	"golang.org/x/pkgsite/internal"
 	"golang.org/x/pkgsite/internal/derrors"
 	"golang.org/x/pkgsite/internal/godoc"
+	"golang.org/x/pkgsite/internal/log"
 	"golang.org/x/pkgsite/internal/source"
 	"golang.org/x/pkgsite/internal/stdlib"
+	"golang.org/x/pkgsite/internal/symbol"
 	"golang.org/x/pkgsite/internal/trace"
 )
 
@@ -49,8 +51,11 @@ func (bpe *BadPackageError) Error() string { return bpe.Err.Error() }
 //
 // If a package is fine except that its documentation is too large, loadPackage
 // returns a goPackage whose err field is a non-nil error with godoc.ErrTooLarge in its chain.
+//
+// If rels is non-nil, it is recorded in the documentation for every build
+// context.
 func loadPackage(ctx context.Context, contentDir fs.FS, goFilePaths []string, innerPath string,
-	sourceInfo *source.Info, modInfo *godoc.ModuleInfo) (_ *goPackage, err error) {
+	sourceInfo *source.Info, modInfo *godoc.ModuleInfo, rels *typeRelations, opts LoadOptions) (_ *goPackage, err error) {
 	defer derrors.Wrap(&err, "loadPackage(ctx, zipGoFiles, %q, sourceInfo, modInfo)", innerPath)
 	ctx, span := trace.StartSpan(ctx, "fetch.loadPackage")
 	defer span.End()
@@ -72,6 +77,7 @@ func loadPackage(ctx context.Context, contentDir fs.FS, goFilePaths []string, in
 		importPath = innerPath
 	}
 	v1path := internal.V1Path(importPath, modulePath)
+	tests := loadTestFuncs(contentDir, innerPath, files)
 
 	var pkg *goPackage
 	// Parse the package for each build context.
@@ -79,7 +85,7 @@ func loadPackage(ctx context.Context, contentDir fs.FS, goFilePaths []string, in
 	// track of those to avoid duplication.
 	docsByFiles := map[string]*internal.Documentation{}
 	for _, bc := range internal.BuildContexts {
-		mfiles, err := matchingFiles(bc.GOOS, bc.GOARCH, files)
+		mfiles, err := matchingFiles(bc, files)
 		if err != nil {
 			return nil, err
 		}
@@ -91,6 +97,7 @@ func loadPackage(ctx context.Context, contentDir fs.FS, goFilePaths []string, in
 			doc2 := *doc
 			doc2.GOOS = bc.GOOS
 			doc2.GOARCH = bc.GOARCH
+			doc2.Tags = bc.Tags
 			doc2.API = nil
 			for _, s := range doc.API {
 				s2 := *s
@@ -103,9 +110,9 @@ func loadPackage(ctx context.Context, contentDir fs.FS, goFilePaths []string, in
 			pkg.docs = append(pkg.docs, &doc2)
 			continue
 		}
-		name, imports, synopsis, source, api, err := loadPackageForBuildContext(ctx,
-			mfiles, innerPath, sourceInfo, modInfo)
-		for _, s := range api {
+		lp, err := loadPackageForBuildContext(ctx,
+			mfiles, innerPath, sourceInfo, modInfo, rels, opts.StdlibAPIVersions)
+		for _, s := range lp.api {
 			s.GOOS = bc.GOOS
 			s.GOARCH = bc.GOARCH
 		}
@@ -121,20 +128,28 @@ func loadPackage(ctx context.Context, contentDir fs.FS, goFilePaths []string, in
 			// The doc for this build context is too large. To keep things
 			// simple, return a single package with this error that will be used
 			// for all build contexts, and ignore the others.
-			return &goPackage{
-				err:     err,
-				path:    importPath,
-				v1path:  v1path,
-				name:    name,
-				imports: imports,
+			pkg := &goPackage{
+				err:         err,
+				path:        importPath,
+				v1path:      v1path,
+				name:        lp.name,
+				imports:     lp.imports,
+				symbolRefs:  lp.refs,
+				tests:       tests,
+				docProblems: lp.problems,
 				docs: []*internal.Documentation{{
-					GOOS:     internal.All,
-					GOARCH:   internal.All,
-					Synopsis: synopsis,
-					Source:   source,
-					API:      api,
+					GOOS:         internal.All,
+					GOARCH:       internal.All,
+					Synopsis:     lp.synopsis,
+					Source:       lp.source,
+					API:          lp.api,
+					MinGoVersion: lp.minGoVersion,
 				}},
-			}, nil
+			}
+			if opts.PrerenderDocs {
+				prerenderDocs(ctx, pkg.docs, innerPath, sourceInfo, modInfo)
+			}
+			return pkg, nil
 		case err != nil:
 			// Serious error. Fail.
 			return nil, err
@@ -142,26 +157,31 @@ func loadPackage(ctx context.Context, contentDir fs.FS, goFilePaths []string, in
 			// No error.
 			if pkg == nil {
 				pkg = &goPackage{
-					path:    importPath,
-					v1path:  v1path,
-					name:    name,
-					imports: imports, // Use the imports from the first successful build context.
+					path:        importPath,
+					v1path:      v1path,
+					name:        lp.name,
+					imports:     lp.imports, // Use the imports from the first successful build context.
+					symbolRefs:  lp.refs,
+					tests:       tests,
+					docProblems: lp.problems,
 				}
 			}
 			// All the build contexts should use the same package name. Although
 			// it's technically legal for different build tags to result in different
 			// package names, it's not something we support.
-			if name != pkg.name {
+			if lp.name != pkg.name {
 				return nil, &BadPackageError{
-					Err: fmt.Errorf("more than one package name (%q and %q)", pkg.name, name),
+					Err: fmt.Errorf("more than one package name (%q and %q)", pkg.name, lp.name),
 				}
 			}
 			doc := &internal.Documentation{
-				GOOS:     bc.GOOS,
-				GOARCH:   bc.GOARCH,
-				Synopsis: synopsis,
-				Source:   source,
-				API:      api,
+				GOOS:         bc.GOOS,
+				GOARCH:       bc.GOARCH,
+				Tags:         bc.Tags,
+				Synopsis:     lp.synopsis,
+				Source:       lp.source,
+				API:          lp.api,
+				MinGoVersion: lp.minGoVersion,
 			}
 			docsByFiles[filesKey] = doc
 			pkg.docs = append(pkg.docs, doc)
@@ -174,14 +194,61 @@ func loadPackage(ctx context.Context, contentDir fs.FS, goFilePaths []string, in
 		pkg.docs = pkg.docs[:1]
 		pkg.docs[0].GOOS = internal.All
 		pkg.docs[0].GOARCH = internal.All
+		pkg.docs[0].Tags = ""
 		for _, s := range pkg.docs[0].API {
 			s.GOOS = internal.All
 			s.GOARCH = internal.All
 		}
 	}
+	if pkg != nil && opts.PrerenderDocs {
+		prerenderDocs(ctx, pkg.docs, innerPath, sourceInfo, modInfo)
+	}
 	return pkg, nil
 }
 
+// LoadOptions are optional parameters for loading the packages of a module.
+type LoadOptions struct {
+	// PrerenderDocs controls whether the documentation of packages is
+	// rendered to HTML when they are loaded, so that it doesn't have to be
+	// rendered for each request. The dochtml templates must be loaded when
+	// it is set.
+	PrerenderDocs bool
+
+	// StdlibAPIVersions, if non-nil, is used to compute the minimum Go
+	// version required by each package, from the standard library symbols
+	// it uses.
+	StdlibAPIVersions *symbol.StdlibAPIVersions
+}
+
+// prerenderDocs sets the Rendered field of each of docs. The documentation is
+// rendered from its encoded source, exactly as it would be when serving it.
+// Failures are logged, and the documentation is left to be rendered when it
+// is served.
+func prerenderDocs(ctx context.Context, docs []*internal.Documentation, innerPath string, sourceInfo *source.Info, modInfo *godoc.ModuleInfo) {
+	ctx, span := trace.StartSpan(ctx, "fetch.prerenderDocs")
+	defer span.End()
+	for i, doc := range docs {
+		docPkg, err := godoc.DecodePackage(doc.Source)
+		if err != nil {
+			log.Errorf(ctx, "prerenderDocs(%q): %v", innerPath, err)
+			continue
+		}
+		// Requests without a build context are served the first
+		// documentation, so render it for them.
+		bc := doc.BuildContext()
+		if i == 0 {
+			bc = internal.BuildContext{}
+		}
+		// Render the documentation as the frontend would, without the
+		// module's packages, which docPkg records itself.
+		mi := &godoc.ModuleInfo{ModulePath: modInfo.ModulePath, ResolvedVersion: modInfo.ResolvedVersion}
+		doc.Rendered, err = docPkg.Prerender(ctx, innerPath, sourceInfo, mi, bc)
+		if err != nil {
+			log.Errorf(ctx, "prerenderDocs(%q): %v", innerPath, err)
+		}
+	}
+}
+
 // loadPackageMeta loads only the parts of a package that are needed to load a
 // packageMeta.
 func loadPackageMeta(ctx context.Context, contentDir fs.FS, goFilePaths []string, innerPath string, modInfo *godoc.ModuleInfo) (_ *packageMeta, err error) {
@@ -213,7 +280,7 @@ func loadPackageMeta(ctx context.Context, contentDir fs.FS, goFilePaths []string
 	// in the file and then run the logic in loadPackageName on the collection of
 	// package name values.
 	for _, bc := range internal.BuildContexts {
-		mfiles, err := matchingFiles(bc.GOOS, bc.GOARCH, files)
+		mfiles, err := matchingFiles(bc, files)
 		if err != nil {
 			return nil, err
 		}
@@ -265,34 +332,66 @@ func mapKeyForFiles(files map[string][]byte) string {
 // httpPost allows package fetch tests to stub out playground URL fetches.
 var httpPost = http.Post
 
+// A loadedPackage is a package loaded for one build context by
+// loadPackageForBuildContext.
+type loadedPackage struct {
+	name     string
+	imports  []string
+	refs     map[string][]string // symbols referred to in each imported package outside the standard library
+	synopsis string
+	source   []byte // the serialized source (AST)
+	api      []*internal.Symbol
+	// minGoVersion is the minimum Go version that the package's use of the
+	// standard library requires.
+	minGoVersion string
+	problems     []*internal.DocProblem // problems with the documentation
+}
+
 // loadPackageForBuildContext loads a Go package made of .go files in
 // files, which should match some build context.
 // modulePath is stdlib.ModulePath for the Go standard library and the
 // module path for all other modules. innerPath is the path of the Go package
 // directory relative to the module root. The files argument must contain only
 // .go files that have been verified to be of reasonable size and that match
-// the build context.
-//
-// It returns the package name, list of imports, the package synopsis, and the
-// serialized source (AST) for the package.
+// the build context. If rels is non-nil, it is recorded in the documentation.
+// If av is non-nil, it is used to compute the minimum Go version.
 //
 // It returns an error with NotFound in its chain if the directory doesn't
 // contain a Go package or all .go files have been excluded by constraints. A
 // *BadPackageError error is returned if the directory contains .go files but do
 // not make up a valid package.
 //
-// If it returns an error with ErrTooLarge in its chain, the other return values
-// are still valid.
-func loadPackageForBuildContext(ctx context.Context, files map[string][]byte, innerPath string, sourceInfo *source.Info, modInfo *godoc.ModuleInfo) (
-	name string, imports []string, synopsis string, source []byte, api []*internal.Symbol, err error) {
+// If it returns an error with ErrTooLarge in its chain, the loadedPackage is
+// still valid.
+func loadPackageForBuildContext(ctx context.Context, files map[string][]byte, innerPath string, sourceInfo *source.Info, modInfo *godoc.ModuleInfo, rels *typeRelations, av *symbol.StdlibAPIVersions) (_ loadedPackage, err error) {
 	modulePath := modInfo.ModulePath
 	defer derrors.Wrap(&err, "loadPackageWithBuildContext(files, %q, %q, %+v)", innerPath, modulePath, sourceInfo)
 
 	packageName, goFiles, fset, err := loadFilesWithBuildContext(innerPath, files)
 	if err != nil {
-		return "", nil, "", nil, nil, err
+		return loadedPackage{}, err
 	}
+	lp := loadedPackage{name: packageName}
+	// Compute the minimum Go version and the symbol references before the
+	// AST is modified below.
+	var nonTestFiles []*ast.File
+	for name, f := range goFiles {
+		if !strings.HasSuffix(name, "_test.go") {
+			nonTestFiles = append(nonTestFiles, f)
+		}
+	}
+	if av != nil && modulePath != stdlib.ModulePath {
+		lp.minGoVersion = av.MinGoVersion(nonTestFiles)
+	}
+	lp.refs = symbolReferences(nonTestFiles)
 	docPkg := godoc.NewPackage(fset, modInfo.ModulePackages)
+	if rels != nil {
+		docPkg.Implements = rels.implements
+		docPkg.ImplementedBy = rels.implementedBy
+		docPkg.PromotedMethods = rels.promotedMethods
+		docPkg.PromotedFields = rels.promotedFields
+		docPkg.PromotedIncomplete = rels.incomplete
+	}
 	for _, pf := range goFiles {
 		removeNodes := true
 		// Don't strip the seemingly unexported functions from the builtin package;
@@ -304,16 +403,21 @@ func loadPackageForBuildContext(ctx context.Context, files map[string][]byte, in
 	}
 
 	// Encode first, because Render messes with the AST.
-	src, err := docPkg.Encode(ctx)
+	lp.source, err = docPkg.Encode(ctx)
 	if err != nil {
-		return "", nil, "", nil, nil, err
+		return loadedPackage{}, err
 	}
 
-	synopsis, imports, api, err = docPkg.DocInfo(ctx, innerPath, sourceInfo, modInfo)
+	// Lint before DocInfo, which modifies the AST.
+	lp.problems, err = docPkg.Lint(innerPath, modInfo)
 	if err != nil {
-		return "", nil, "", nil, nil, err
+		return loadedPackage{}, err
 	}
-	return packageName, imports, synopsis, src, api, err
+	lp.synopsis, lp.imports, lp.api, err = docPkg.DocInfo(ctx, innerPath, sourceInfo, modInfo)
+	if err != nil {
+		return loadedPackage{}, err
+	}
+	return lp, nil
 }
 
 // loadFilesWithBuildContext loads all the given Go files at innerPath. It
@@ -407,15 +511,21 @@ func loadPackageName(innerPath string, files map[string][]byte) (pkgName string,
 }
 
 // matchingFiles returns a map from file names to their contents, read from zipGoFiles.
-// It includes only those files that match the build context determined by goos and goarch.
-func matchingFiles(goos, goarch string, allFiles map[string][]byte) (matchedFiles map[string][]byte, err error) {
-	defer derrors.Wrap(&err, "matchingFiles(%q, %q, zipGoFiles)", goos, goarch)
+// It includes only those files that match the build context bc.
+func matchingFiles(bc internal.BuildContext, allFiles map[string][]byte) (matchedFiles map[string][]byte, err error) {
+	defer derrors.Wrap(&err, "matchingFiles(%q, zipGoFiles)", bc)
+
+	var tags []string
+	if bc.Tags != "" {
+		tags = strings.Split(bc.Tags, ",")
+	}
 
 	// bctx is used to make decisions about which of the .go files are included
 	// by build constraints.
 	bctx := &build.Context{
-		GOOS:        goos,
-		GOARCH:      goarch,
+		GOOS:        bc.GOOS,
+		GOARCH:      bc.GOARCH,
+		BuildTags:   tags,
 		CgoEnabled:  true,
 		Compiler:    build.Default.Compiler,
 		ReleaseTags: build.Default.ReleaseTags,
@@ -5,9 +5,16 @@
 package fetch
 
 import (
+	"context"
+	"path/filepath"
+	"strings"
 	"testing"
+	"testing/fstest"
 
 	"github.com/google/go-cmp/cmp"
+	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/godoc"
+	"golang.org/x/pkgsite/internal/symbol"
 	"golang.org/x/pkgsite/internal/testing/testhelper"
 )
 
@@ -28,21 +35,31 @@ func TestMatchingFiles(t *testing.T) {
 		"plain.go":   plainGoBody,
 	}
 
+	integrationGoBody := `
+		//go:build integration
+
+		package plain
+		func Setup() {}`
+
+	integrationContents := map[string]string{
+		"plain.go":       plainGoBody,
+		"integration.go": integrationGoBody,
+	}
+
 	jsContents := map[string]string{
 		"README.md":  "THIS IS A README",
 		"LICENSE.md": testhelper.MITLicense,
 		"js.go":      jsGoBody,
 	}
 	for _, test := range []struct {
-		name         string
-		goos, goarch string
-		contents     map[string]string
-		want         map[string][]byte
+		name     string
+		bc       internal.BuildContext
+		contents map[string]string
+		want     map[string][]byte
 	}{
 		{
 			name:     "plain-linux",
-			goos:     "linux",
-			goarch:   "amd64",
+			bc:       internal.BuildContext{GOOS: "linux", GOARCH: "amd64"},
 			contents: plainContents,
 			want: map[string][]byte{
 				"plain.go": []byte(plainGoBody),
@@ -50,8 +67,7 @@ func TestMatchingFiles(t *testing.T) {
 		},
 		{
 			name:     "plain-js",
-			goos:     "js",
-			goarch:   "wasm",
+			bc:       internal.BuildContext{GOOS: "js", GOARCH: "wasm"},
 			contents: plainContents,
 			want: map[string][]byte{
 				"plain.go": []byte(plainGoBody),
@@ -59,27 +75,42 @@ func TestMatchingFiles(t *testing.T) {
 		},
 		{
 			name:     "wasm-linux",
-			goos:     "linux",
-			goarch:   "amd64",
+			bc:       internal.BuildContext{GOOS: "linux", GOARCH: "amd64"},
 			contents: jsContents,
 			want:     map[string][]byte{},
 		},
 		{
 			name:     "wasm-js",
-			goos:     "js",
-			goarch:   "wasm",
+			bc:       internal.BuildContext{GOOS: "js", GOARCH: "wasm"},
 			contents: jsContents,
 			want: map[string][]byte{
 				"js.go": []byte(jsGoBody),
 			},
 		},
+		{
+			name:     "untagged",
+			bc:       internal.BuildContext{GOOS: "linux", GOARCH: "amd64"},
+			contents: integrationContents,
+			want: map[string][]byte{
+				"plain.go": []byte(plainGoBody),
+			},
+		},
+		{
+			name:     "tagged",
+			bc:       internal.BuildContext{GOOS: "linux", GOARCH: "amd64", Tags: "integration,nocgo"},
+			contents: integrationContents,
+			want: map[string][]byte{
+				"plain.go":       []byte(plainGoBody),
+				"integration.go": []byte(integrationGoBody),
+			},
+		},
 	} {
 		t.Run(test.name, func(t *testing.T) {
 			files := map[string][]byte{}
 			for n, c := range test.contents {
 				files[n] = []byte(c)
 			}
-			got, err := matchingFiles(test.goos, test.goarch, files)
+			got, err := matchingFiles(test.bc, files)
 			if err != nil {
 				t.Fatal(err)
 			}
@@ -89,3 +120,89 @@ func TestMatchingFiles(t *testing.T) {
 		})
 	}
 }
+
+func TestLoadPackageMinGoVersion(t *testing.T) {
+	av, err := symbol.LoadStdlibAPIVersions(filepath.Join("..", "symbol", "testdata", "api"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	contentDir := fstest.MapFS{
+		"p/p.go": {Data: []byte(`
+			package p
+
+			import "strings"
+
+			func Before(s string) string {
+				b, _, _ := strings.Cut(s, ".")
+				return b
+			}`)},
+		// Test files are ignored.
+		"p/p_test.go": {Data: []byte(`
+			package p
+
+			import "slices"
+
+			var _ = slices.Contains[[]int]`)},
+	}
+	modInfo := &godoc.ModuleInfo{ModulePath: "example.com/m", ResolvedVersion: "v1.0.0"}
+	pkg, err := loadPackage(context.Background(), contentDir, []string{"p/p.go", "p/p_test.go"}, "p", nil, modInfo, nil, LoadOptions{StdlibAPIVersions: av})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pkg.docs) == 0 {
+		t.Fatal("no documentation")
+	}
+	for _, doc := range pkg.docs {
+		if got, want := doc.MinGoVersion, "go1.18"; got != want {
+			t.Errorf("%s: got %q, want %q", doc.BuildContext(), got, want)
+		}
+	}
+}
+
+func TestLoadPackagePrerender(t *testing.T) {
+	contentDir := fstest.MapFS{
+		"p/p.go": {Data: []byte(`
+			// Package p is for testing.
+			package p
+
+			// F is a function.
+			func F() {}`)},
+		"p/p_windows.go": {Data: []byte(`
+			package p
+
+			// W is only on Windows.
+			func W() {}`)},
+	}
+	modInfo := &godoc.ModuleInfo{ModulePath: "example.com/m", ResolvedVersion: "v1.0.0"}
+	pkg, err := loadPackage(context.Background(), contentDir, []string{"p/p.go", "p/p_windows.go"}, "p", nil, modInfo, nil, LoadOptions{PrerenderDocs: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pkg.docs) < 2 {
+		t.Fatalf("got %d docs, want one for each build context", len(pkg.docs))
+	}
+	for i, doc := range pkg.docs {
+		r := doc.Rendered
+		if r == nil {
+			t.Fatalf("%s: not rendered", doc.BuildContext())
+		}
+		// The first documentation is rendered for requests without a
+		// build context.
+		wantQuery := ""
+		if i > 0 {
+			wantQuery = "?GOOS=" + doc.GOOS
+		}
+		if r.LinkQuery != wantQuery {
+			t.Errorf("%s: got link query %q, want %q", doc.BuildContext(), r.LinkQuery, wantQuery)
+		}
+		if r.RendererVersion != godoc.RendererVersion {
+			t.Errorf("%s: got renderer version %q, want %q", doc.BuildContext(), r.RendererVersion, godoc.RendererVersion)
+		}
+		if !strings.Contains(r.Body, `id="F"`) {
+			t.Errorf("%s: body does not document F", doc.BuildContext())
+		}
+		if got, want := strings.Contains(r.Body, `id="W"`), doc.GOOS == "windows"; got != want {
+			t.Errorf("%s: body documents W: got %t, want %t", doc.BuildContext(), got, want)
+		}
+	}
+}
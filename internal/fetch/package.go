@@ -32,6 +32,9 @@ type goPackage struct {
 	path              string
 	name              string
 	imports           []string
+	symbolRefs        map[string][]string // see internal.Unit.SymbolReferences
+	tests             []*internal.TestFunc
+	docProblems       []*internal.DocProblem
 	isRedistributable bool
 	licenseMeta       []*licenses.Metadata // metadata of applicable licenses
 	// v1path is the package path of a package with major version 1 in a given
@@ -57,7 +60,10 @@ func rel(pkgPath, modulePath string) string {
 // It returns a packageVersionState representing the status of doing the work
 // of computing the package after the UnitMeta was computed. The packageVersionState
 // of a package that failed to have a UnitMeta produced was produced by extractPackageMetas.
-func extractPackage(ctx context.Context, modulePath, pkgPath string, contentDir fs.FS, d *licenses.Detector, sourceInfo *source.Info, modInfo *godoc.ModuleInfo) (*goPackage, *internal.PackageVersionState, error) {
+// If rels is non-nil, it is recorded in the package's documentation. The
+// package is loaded according to opts.
+func extractPackage(ctx context.Context, modulePath, pkgPath string, contentDir fs.FS, d *licenses.Detector, sourceInfo *source.Info, modInfo *godoc.ModuleInfo,
+	rels *typeRelations, opts LoadOptions) (*goPackage, *internal.PackageVersionState, error) {
 	innerPath := rel(pkgPath, modulePath)
 	f, err := contentDir.Open(innerPath)
 	if err != nil {
@@ -91,7 +97,7 @@ func extractPackage(ctx context.Context, modulePath, pkgPath string, contentDir
 		status error
 		errMsg string
 	)
-	pkg, err := loadPackage(ctx, contentDir, goFiles, innerPath, sourceInfo, modInfo)
+	pkg, err := loadPackage(ctx, contentDir, goFiles, innerPath, sourceInfo, modInfo, rels, opts)
 	if bpe := (*BadPackageError)(nil); errors.As(err, &bpe) {
 		log.Infof(ctx, "Error loading %s: %v", innerPath, err)
 		status = derrors.PackageInvalidContents
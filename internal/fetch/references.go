@@ -0,0 +1,179 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fetch
+
+import (
+	"go/ast"
+	"go/token"
+	"sort"
+	"strconv"
+	"strings"
+
+	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/stdlib"
+)
+
+// symbolReferences returns the exported symbols of other packages that files
+// refer to, as a map from import path to sorted symbol names. Qualified
+// identifiers like "pkg.Name" refer to package-level symbols. Uses of the
+// methods and fields of a type from another package are recorded as
+// "Type.Name" when the type is evident without type-checking: in method
+// expressions like "pkg.Type.Name", and on variables and parameters declared
+// with the type or initialized with a composite literal of it. Methods called
+// on the results of functions are not recorded. References to the standard
+// library are omitted.
+//
+// The name of an imported package is guessed from its import path unless the
+// import declares one.
+func symbolReferences(files []*ast.File) map[string][]string {
+	refs := map[string]map[string]bool{}
+	add := func(importPath, name string) {
+		if refs[importPath] == nil {
+			refs[importPath] = map[string]bool{}
+		}
+		refs[importPath][name] = true
+	}
+	for _, f := range files {
+		imports := importNames(f)
+		if len(imports) == 0 {
+			continue
+		}
+		ast.Inspect(f, func(n ast.Node) bool {
+			sel, ok := n.(*ast.SelectorExpr)
+			if !ok || !ast.IsExported(sel.Sel.Name) {
+				return true
+			}
+			var importPath, typeName string
+			switch x := sel.X.(type) {
+			case *ast.Ident:
+				// A non-nil Obj means the identifier was declared in the
+				// file, so it shadows any import.
+				if x.Obj == nil {
+					if p, ok := imports[x.Name]; ok {
+						add(p, sel.Sel.Name)
+					}
+					return true
+				}
+				importPath, typeName = varType(x.Obj, imports)
+			case *ast.SelectorExpr, *ast.ParenExpr:
+				// A method expression, like pkg.T.M or (*pkg.T).M.
+				importPath, typeName = importedType(x, imports)
+			}
+			if typeName != "" {
+				add(importPath, typeName+"."+sel.Sel.Name)
+			}
+			return true
+		})
+	}
+	if len(refs) == 0 {
+		return nil
+	}
+	m := map[string][]string{}
+	for importPath, names := range refs {
+		for n := range names {
+			m[importPath] = append(m[importPath], n)
+		}
+		sort.Strings(m[importPath])
+	}
+	return m
+}
+
+// varType returns the import path and name of the type of the variable obj,
+// if it is declared with an exported type of an imported package, or a
+// pointer to one, or initialized with a composite literal of that type.
+// Otherwise it returns empty strings.
+func varType(obj *ast.Object, imports map[string]string) (importPath, typeName string) {
+	if obj.Kind != ast.Var {
+		return "", ""
+	}
+	var value ast.Expr
+	switch d := obj.Decl.(type) {
+	case *ast.Field:
+		return importedType(d.Type, imports)
+	case *ast.ValueSpec:
+		if d.Type != nil {
+			return importedType(d.Type, imports)
+		}
+		for i, id := range d.Names {
+			if id.Obj == obj && i < len(d.Values) {
+				value = d.Values[i]
+			}
+		}
+	case *ast.AssignStmt:
+		if len(d.Lhs) != len(d.Rhs) {
+			return "", ""
+		}
+		for i, e := range d.Lhs {
+			if id, ok := e.(*ast.Ident); ok && id.Obj == obj {
+				value = d.Rhs[i]
+			}
+		}
+	}
+	if u, ok := value.(*ast.UnaryExpr); ok && u.Op == token.AND {
+		value = u.X
+	}
+	if cl, ok := value.(*ast.CompositeLit); ok {
+		return importedType(cl.Type, imports)
+	}
+	return "", ""
+}
+
+// importedType returns the import path and name of the type that e denotes,
+// if it is an exported type of an imported package like pkg.T, or a pointer
+// to one. Otherwise it returns empty strings.
+func importedType(e ast.Expr, imports map[string]string) (importPath, typeName string) {
+	if p, ok := e.(*ast.ParenExpr); ok {
+		e = p.X
+	}
+	if star, ok := e.(*ast.StarExpr); ok {
+		e = star.X
+	}
+	sel, ok := e.(*ast.SelectorExpr)
+	if !ok || !ast.IsExported(sel.Sel.Name) {
+		return "", ""
+	}
+	id, ok := sel.X.(*ast.Ident)
+	if !ok || id.Obj != nil {
+		return "", ""
+	}
+	importPath, ok = imports[id.Name]
+	if !ok {
+		return "", ""
+	}
+	return importPath, sel.Sel.Name
+}
+
+// importNames returns a map from the names that f uses for the packages
+// outside the standard library that it imports to their import paths.
+func importNames(f *ast.File) map[string]string {
+	names := map[string]string{}
+	for _, imp := range f.Imports {
+		p, err := strconv.Unquote(imp.Path.Value)
+		if err != nil || p == "C" || stdlib.Contains(p) {
+			continue
+		}
+		var name string
+		if imp.Name != nil {
+			name = imp.Name.Name
+		} else {
+			name = guessPackageName(p)
+		}
+		if name == "_" || name == "." {
+			continue
+		}
+		names[name] = p
+	}
+	return names
+}
+
+// guessPackageName returns the likely name of the package with the given
+// import path, following common conventions for naming repositories after
+// packages, like "go-yaml" and "yaml.v3" for package yaml.
+func guessPackageName(importPath string) string {
+	name := internal.DefaultPackageName(importPath)
+	name = strings.TrimPrefix(name, "go-")
+	name = strings.TrimSuffix(name, "-go")
+	return strings.ReplaceAll(name, "-", "_")
+}
@@ -0,0 +1,59 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fetch
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestSymbolReferences(t *testing.T) {
+	const src = `package p
+
+import (
+	"fmt"
+
+	"example.com/go-widget"
+	y "gopkg.in/yaml.v3"
+	"example.com/shadowed"
+	_ "example.com/blank"
+)
+
+var V = widget.New(widget.Option{})
+
+func F(shadowed int) {
+	fmt.Println(y.Marshal, shadowed)
+	var c widget.Config
+	c.Apply()
+	widget.unexported()
+	opt := &widget.Option{}
+	opt.Name = "o"
+	widget.Config.Reset(c)
+	(*widget.Config).Close(&c)
+	w := widget.New(opt)
+	w.Run()
+}
+
+func G(s *widget.Server, p y.Node, n int) {
+	s.Serve(p.Kind, n)
+}
+`
+	f, err := parser.ParseFile(token.NewFileSet(), "p.go", src, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := symbolReferences([]*ast.File{f})
+	want := map[string][]string{
+		"example.com/go-widget": {"Config", "Config.Apply", "Config.Close", "Config.Reset", "New", "Option", "Option.Name", "Server", "Server.Serve"},
+		"gopkg.in/yaml.v3":      {"Marshal", "Node", "Node.Kind"},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("mismatch (-want, +got):\n%s", diff)
+	}
+}
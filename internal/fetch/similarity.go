@@ -0,0 +1,62 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fetch
+
+// Summarizing module contents so that forks and copies of other modules can
+// be recognized.
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"io"
+	"io/fs"
+	"strings"
+
+	"golang.org/x/pkgsite/internal/minhash"
+)
+
+// minSimilarityFiles is the minimum number of distinct .go files that a module
+// must have for its contents to be compared with those of other modules.
+// Smaller modules are too likely to resemble others by chance.
+const minSimilarityFiles = 3
+
+// moduleMinHash returns the MinHash signature of the set of contents of the
+// .go files in fsys. File paths are ignored, so that copies that move or
+// rename files still resemble the original. It returns nil if fsys has fewer
+// than minSimilarityFiles distinct .go files.
+func moduleMinHash(fsys fs.FS) (minhash.Signature, error) {
+	hashes := map[uint64]bool{}
+	err := fs.WalkDir(fsys, ".", func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !strings.HasSuffix(path, ".go") {
+			return nil
+		}
+		f, err := fsys.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		h := sha256.New()
+		if _, err := io.Copy(h, io.LimitReader(f, MaxFileSize)); err != nil {
+			return err
+		}
+		hashes[binary.LittleEndian.Uint64(h.Sum(nil))] = true
+		return nil
+	})
+	if err != nil && !errors.Is(err, fs.ErrNotExist) { // we can get NotExist on an empty FS
+		return nil, err
+	}
+	if len(hashes) < minSimilarityFiles {
+		return nil, nil
+	}
+	var elements []uint64
+	for h := range hashes {
+		elements = append(elements, h)
+	}
+	return minhash.New(elements), nil
+}
@@ -0,0 +1,57 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fetch
+
+import (
+	"fmt"
+	"testing"
+	"testing/fstest"
+
+	"golang.org/x/pkgsite/internal/minhash"
+)
+
+func TestModuleMinHash(t *testing.T) {
+	// module returns a module whose package in dir has the files numbered lo
+	// through hi-1.
+	module := func(dir string, lo, hi int) fstest.MapFS {
+		fsys := fstest.MapFS{
+			"go.mod":  {Data: []byte("module example.com/" + dir)},
+			"LICENSE": {Data: []byte(dir)},
+		}
+		for i := lo; i < hi; i++ {
+			fsys[fmt.Sprintf("%s/f%d.go", dir, i)] = &fstest.MapFile{Data: []byte(fmt.Sprintf("package p\n\nconst C%d = %[1]d\n", i))}
+		}
+		return fsys
+	}
+	sig := func(fsys fstest.MapFS) minhash.Signature {
+		t.Helper()
+		s, err := moduleMinHash(fsys)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return s
+	}
+
+	orig := sig(module("a", 0, 20))
+	for _, test := range []struct {
+		name     string
+		fsys     fstest.MapFS
+		min, max float64
+	}{
+		{"same files elsewhere", module("b", 0, 20), 1, 1},
+		{"one file removed", module("b", 0, 19), 0.8, 1},
+		{"different files", module("a", 20, 40), 0, 0.2},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			if got := sig(test.fsys).Similarity(orig); got < test.min || got > test.max {
+				t.Errorf("got similarity %.2f, want between %.2f and %.2f", got, test.min, test.max)
+			}
+		})
+	}
+
+	if s := sig(module("a", 0, minSimilarityFiles-1)); s != nil {
+		t.Errorf("got a signature for %d files, want nil", minSimilarityFiles-1)
+	}
+}
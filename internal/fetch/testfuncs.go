@@ -0,0 +1,145 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fetch
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"path"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/pkgsite/internal"
+)
+
+// testFuncKinds maps the prefix of each kind of test function to its kind
+// and to the name of the type of its parameter in package testing.
+var testFuncKinds = []struct {
+	prefix string
+	kind   internal.TestKind
+	param  string
+}{
+	{"Test", internal.TestKindTest, "T"},
+	{"Benchmark", internal.TestKindBenchmark, "B"},
+	{"Fuzz", internal.TestKindFuzz, "F"},
+}
+
+// loadTestFuncs returns the tests, benchmarks and fuzz targets declared in the
+// _test.go files among files, which are the contents of the .go files in the
+// directory innerPath of contentDir. The seed corpus of each fuzz target is
+// counted from innerPath/testdata/fuzz.
+// Files that do not parse are skipped.
+func loadTestFuncs(contentDir fs.FS, innerPath string, files map[string][]byte) []*internal.TestFunc {
+	var tfs []*internal.TestFunc
+	fset := token.NewFileSet()
+	for name, src := range files {
+		if !strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		f, err := parser.ParseFile(fset, name, src, parser.SkipObjectResolution)
+		if err != nil {
+			continue
+		}
+		testingName := testingImportName(f)
+		if testingName == "" {
+			continue
+		}
+		for _, d := range f.Decls {
+			fd, ok := d.(*ast.FuncDecl)
+			if !ok || fd.Recv != nil {
+				continue
+			}
+			kind, ok := testFuncKind(fd, testingName)
+			if !ok {
+				continue
+			}
+			tf := &internal.TestFunc{Name: fd.Name.Name, Kind: kind, Filename: name}
+			if kind == internal.TestKindFuzz {
+				tf.CorpusSize = corpusSize(contentDir, path.Join(innerPath, "testdata", "fuzz", tf.Name))
+			}
+			tfs = append(tfs, tf)
+		}
+	}
+	internal.SortTestFuncs(tfs)
+	return tfs
+}
+
+// testFuncKind reports whether fd is a function that "go test" runs, and if
+// so, its kind. testingName is the name that the file of fd uses for package
+// testing.
+func testFuncKind(fd *ast.FuncDecl, testingName string) (internal.TestKind, bool) {
+	for _, k := range testFuncKinds {
+		if !isTestName(fd.Name.Name, k.prefix) {
+			continue
+		}
+		t := fd.Type
+		if t.TypeParams != nil || t.Results != nil || len(t.Params.List) != 1 || len(t.Params.List[0].Names) > 1 {
+			return "", false
+		}
+		star, ok := t.Params.List[0].Type.(*ast.StarExpr)
+		if !ok {
+			return "", false
+		}
+		sel, ok := star.X.(*ast.SelectorExpr)
+		if !ok {
+			return "", false
+		}
+		if x, ok := sel.X.(*ast.Ident); !ok || x.Name != testingName || sel.Sel.Name != k.param {
+			return "", false
+		}
+		return k.kind, true
+	}
+	return "", false
+}
+
+// isTestName reports whether name is prefix, or prefix followed by a
+// character that is not a lower-case letter, as "go test" requires.
+func isTestName(name, prefix string) bool {
+	if !strings.HasPrefix(name, prefix) {
+		return false
+	}
+	if len(name) == len(prefix) {
+		return true
+	}
+	r, _ := utf8.DecodeRuneInString(name[len(prefix):])
+	return !unicode.IsLower(r)
+}
+
+// testingImportName returns the name by which f refers to package testing,
+// or the empty string if f does not import it.
+func testingImportName(f *ast.File) string {
+	for _, imp := range f.Imports {
+		if p, err := strconv.Unquote(imp.Path.Value); err != nil || p != "testing" {
+			continue
+		}
+		if imp.Name == nil {
+			return "testing"
+		}
+		if imp.Name.Name != "_" && imp.Name.Name != "." {
+			return imp.Name.Name
+		}
+	}
+	return ""
+}
+
+// corpusSize returns the number of files in the directory dir of contentDir,
+// or zero if there is no such directory.
+func corpusSize(contentDir fs.FS, dir string) int {
+	entries, err := fs.ReadDir(contentDir, dir)
+	if err != nil {
+		return 0
+	}
+	n := 0
+	for _, e := range entries {
+		if e.Type().IsRegular() {
+			n++
+		}
+	}
+	return n
+}
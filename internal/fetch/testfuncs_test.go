@@ -0,0 +1,70 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fetch
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/pkgsite/internal"
+)
+
+func TestLoadTestFuncs(t *testing.T) {
+	files := map[string][]byte{
+		"p.go": []byte(`
+			package p
+
+			import "testing"
+
+			func TestNotInTestFile(t *testing.T) {}`),
+		"p_test.go": []byte(`
+			package p
+
+			import "testing"
+
+			func TestA(t *testing.T) {}
+			func Test(t *testing.T) {}
+			func Test_b(t *testing.T) {}
+			func Testc(t *testing.T) {}
+			func TestMain(m *testing.M) {}
+			func TestWrongParam(b *testing.B) {}
+			func TestResult(t *testing.T) error { return nil }
+			func BenchmarkA(b *testing.B) {}
+			func FuzzA(f *testing.F) {}
+			func FuzzNoCorpus(f *testing.F) {}
+
+			type s struct{}
+
+			func (s) TestMethod(t *testing.T) {}`),
+		"x_test.go": []byte(`
+			package p_test
+
+			import tt "testing"
+
+			func TestA(t *tt.T) {}`),
+		"bad_test.go": []byte(`package p; func TestBad(`),
+	}
+	contentDir := fstest.MapFS{
+		"p/testdata/fuzz/FuzzA/1":      {Data: []byte("go test fuzz v1\n")},
+		"p/testdata/fuzz/FuzzA/2":      {Data: []byte("go test fuzz v1\n")},
+		"p/testdata/fuzz/FuzzA/sub/1":  {Data: []byte("go test fuzz v1\n")},
+		"p/testdata/fuzz/FuzzOther/1":  {Data: []byte("go test fuzz v1\n")},
+		"p/testdata/unrelated/FuzzA/1": {Data: []byte("data")},
+	}
+	got := loadTestFuncs(contentDir, "p", files)
+	want := []*internal.TestFunc{
+		{Name: "Test", Kind: internal.TestKindTest, Filename: "p_test.go"},
+		{Name: "TestA", Kind: internal.TestKindTest, Filename: "p_test.go"},
+		{Name: "TestA", Kind: internal.TestKindTest, Filename: "x_test.go"},
+		{Name: "Test_b", Kind: internal.TestKindTest, Filename: "p_test.go"},
+		{Name: "BenchmarkA", Kind: internal.TestKindBenchmark, Filename: "p_test.go"},
+		{Name: "FuzzA", Kind: internal.TestKindFuzz, Filename: "p_test.go", CorpusSize: 2},
+		{Name: "FuzzNoCorpus", Kind: internal.TestKindFuzz, Filename: "p_test.go"},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -40,10 +40,13 @@ func moduleUnit(modulePath string, unitMeta *internal.UnitMeta,
 	if pkg != nil {
 		unit.Name = pkg.name
 		unit.Imports = pkg.imports
+		unit.SymbolReferences = pkg.symbolRefs
+		unit.Tests = pkg.tests
+		unit.DocProblems = pkg.docProblems
 		unit.Documentation = pkg.docs
 		var bcs []internal.BuildContext
 		for _, d := range unit.Documentation {
-			bcs = append(bcs, internal.BuildContext{GOOS: d.GOOS, GOARCH: d.GOARCH})
+			bcs = append(bcs, d.BuildContext())
 		}
 		sort.Slice(bcs, func(i, j int) bool {
 			return internal.CompareBuildContexts(bcs[i], bcs[j]) < 0
@@ -0,0 +1,39 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fetchdatasource
+
+import (
+	"context"
+
+	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/derrors"
+)
+
+// GetDocProblems returns the documentation problems of the packages in the
+// given module version, fetching it if necessary.
+func (ds *FetchDataSource) GetDocProblems(ctx context.Context, modulePath, version string) (_ map[string][]*internal.DocProblem, err error) {
+	defer derrors.Wrap(&err, "FetchDataSource.GetDocProblems(%q, %q)", modulePath, version)
+
+	m, err := ds.getModule(ctx, modulePath, version)
+	if err != nil {
+		return nil, err
+	}
+	problems := map[string][]*internal.DocProblem{}
+	for _, um := range m.UnitMetas {
+		if !um.IsPackage() {
+			continue
+		}
+		u, err := ds.findUnit(ctx, m, um.Path)
+		if err != nil {
+			// Packages that can't be loaded have no documentation to
+			// check.
+			continue
+		}
+		if len(u.DocProblems) > 0 {
+			problems[um.Path] = u.DocProblems
+		}
+	}
+	return problems, nil
+}
@@ -4,7 +4,7 @@
 
 // Package fetchdatasource provides an internal.DataSource implementation
 // that fetches modules (rather than reading them from a database).
-// Search and other tabs are not supported.
+// Some tabs are not supported.
 package fetchdatasource
 
 import (
@@ -14,6 +14,8 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"golang.org/x/mod/semver"
@@ -29,8 +31,21 @@ import (
 // FetchDataSource implements the internal.DataSource interface, by trying a list of
 // fetch.ModuleGetters to fetch modules and caching the results.
 type FetchDataSource struct {
+	gen uint64 // generation of the latest cache entry; accessed atomically, so first for alignment
+
 	opts  Options
 	cache *lru.Cache[internal.Modver, cacheEntry]
+	index *searchIndex
+
+	indexMu       sync.Mutex // guards indexCond, indexRequests and indexDone
+	indexCond     *sync.Cond // signaled when indexRequests or indexDone changes; nil before the first search
+	indexRequests uint64     // number of calls to updateIndex
+	indexDone     uint64     // value of indexRequests when the last finished index pass started
+
+	// listed maps the path of each listed module to its latest version. It
+	// is used only by the index goroutine, and is nil until the modules
+	// have been listed.
+	listed map[string]string
 }
 
 // Options are parameters for creating a new FetchDataSource.
@@ -41,6 +56,8 @@ type Options struct {
 	// include a ProxyModuleGetter in Getters.
 	ProxyClientForLatest *proxy.Client
 	BypassLicenseCheck   bool
+	// LoadOptions control how the packages of fetched modules are loaded.
+	LoadOptions fetch.LoadOptions
 }
 
 // New creates a new FetchDataSource from the options.
@@ -54,14 +71,17 @@ func (o Options) New() *FetchDataSource {
 	return &FetchDataSource{
 		opts:  opts,
 		cache: cache,
+		index: newSearchIndex(),
 	}
 }
 
 // cacheEntry holds a fetched module or an error, if the fetch failed.
 type cacheEntry struct {
-	g      fetch.ModuleGetter
-	module *fetch.LazyModule
-	err    error
+	g       fetch.ModuleGetter
+	module  *fetch.LazyModule
+	err     error
+	gen     uint64    // distinguishes entries for the same module version
+	fetched time.Time // when the fetch started
 }
 
 const maxCachedModules = 100
@@ -78,9 +98,21 @@ func (ds *FetchDataSource) cacheGet(path, version string) (fetch.ModuleGetter, *
 	return nil, nil, nil
 }
 
-// cachePut puts information into the cache.
-func (ds *FetchDataSource) cachePut(g fetch.ModuleGetter, path, version string, m *fetch.LazyModule, err error) {
-	ds.cache.Put(internal.Modver{Path: path, Version: version}, cacheEntry{g, m, err})
+// cachePut puts information into the cache. Fetched is the time the fetch
+// started.
+func (ds *FetchDataSource) cachePut(g fetch.ModuleGetter, path, version string, m *fetch.LazyModule, err error, fetched time.Time) {
+	ds.cache.Put(internal.Modver{Path: path, Version: version}, cacheEntry{g, m, err, atomic.AddUint64(&ds.gen, 1), fetched})
+}
+
+// cacheGen returns the generation of the cache entry that cacheGet would
+// use, or 0 if there is none.
+func (ds *FetchDataSource) cacheGen(path, version string) uint64 {
+	for _, v := range []string{version, fetch.LocalVersion} {
+		if e, ok := ds.cache.Get(internal.Modver{Path: path, Version: v}); ok {
+			return e.gen
+		}
+	}
+	return 0
 }
 
 // getModule gets the module at the given path and version. It first checks the
@@ -111,6 +143,13 @@ func (ds *FetchDataSource) getModule(ctx context.Context, modulePath, vers strin
 	// There can be a benign race here, where two goroutines both fetch the same
 	// module. At worst some work will be duplicated, but if that turns out to
 	// be a problem we could use golang.org/x/sync/singleflight.
+	return ds.fetchAndCache(ctx, modulePath, vers)
+}
+
+// fetchAndCache fetches the module at the given path and version, and caches
+// the result.
+func (ds *FetchDataSource) fetchAndCache(ctx context.Context, modulePath, vers string) (*fetch.LazyModule, error) {
+	start := time.Now()
 	m, g, err := ds.fetch(ctx, modulePath, vers)
 	if m != nil && ds.opts.ProxyClientForLatest != nil {
 		// Use the go.mod file at the raw latest version to fill in deprecation
@@ -124,16 +163,106 @@ func (ds *FetchDataSource) getModule(ctx context.Context, modulePath, vers strin
 
 	// Cache both successes and failures, but not cancellations.
 	if !errors.Is(err, context.Canceled) {
-		ds.cachePut(g, modulePath, vers, m, err)
+		ds.cachePut(g, modulePath, vers, m, err, start)
 		// Cache the resolved version of "latest" too. A useful optimization
 		// because the frontend redirects "latest", resulting in another fetch.
 		if m != nil && vers == version.Latest {
-			ds.cachePut(g, modulePath, m.Version, m, err)
+			ds.cachePut(g, modulePath, m.Version, m, err, start)
 		}
 	}
 	return m, err
 }
 
+// RefetchChanged re-fetches each cached module whose ModuleGetter implements
+// fetch.VolatileModuleGetter and reports that the module has changed since it
+// was fetched. That includes modules whose fetch failed, so that they are
+// fetched again once they are fixed. It returns the module versions that were
+// re-fetched. Errors checking whether a module has changed are logged, and the
+// module is not re-fetched.
+//
+// RefetchChanged is intended to be called periodically, so that edits to
+// local modules are picked up without waiting for a request.
+func (ds *FetchDataSource) RefetchChanged(ctx context.Context) (_ []internal.Modver, err error) {
+	defer derrors.Wrap(&err, "FetchDataSource.RefetchChanged")
+
+	var changed []internal.Modver
+	for mv, e := range ds.cache.Entries() {
+		v, ok := e.g.(fetch.VolatileModuleGetter)
+		if !ok {
+			continue
+		}
+		// The getter of a failed fetch records when the fetch started in
+		// place of the modification time that a successful fetch records.
+		info := internal.ModuleInfo{ModulePath: mv.Path, Version: mv.Version, CommitTime: e.fetched}
+		if e.module != nil {
+			info = e.module.ModuleInfo
+		}
+		hasChanged, err := v.HasChanged(ctx, info)
+		if err != nil {
+			log.Errorf(ctx, "FetchDataSource.RefetchChanged: checking %s: %v", mv, err)
+			continue
+		}
+		if !hasChanged {
+			continue
+		}
+		if _, err := ds.fetchAndCache(ctx, mv.Path, mv.Version); err != nil {
+			// Keep going: the error is cached, and will be shown to the user
+			// when they visit the module.
+			log.Errorf(ctx, "FetchDataSource.RefetchChanged: %s: %v", mv, err)
+		}
+		changed = append(changed, mv)
+	}
+	sort.Slice(changed, func(i, j int) bool {
+		return changed[i].String() < changed[j].String()
+	})
+	return changed, nil
+}
+
+// ListModules returns the module versions served by the configured getters that
+// implement fetch.ListableModuleGetter, sorted as that interface requires. If
+// more than one getter serves a module version, it is listed once.
+func (ds *FetchDataSource) ListModules(ctx context.Context) (_ []internal.Modver, err error) {
+	defer derrors.Wrap(&err, "FetchDataSource.ListModules")
+
+	seen := map[internal.Modver]bool{}
+	var mvs []internal.Modver
+	for _, g := range ds.opts.Getters {
+		l, ok := g.(fetch.ListableModuleGetter)
+		if !ok {
+			continue
+		}
+		gmvs, err := l.ListModules(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, mv := range gmvs {
+			if !seen[mv] {
+				seen[mv] = true
+				mvs = append(mvs, mv)
+			}
+		}
+	}
+	sort.Slice(mvs, func(i, j int) bool {
+		if mvs[i].Path != mvs[j].Path {
+			return mvs[i].Path < mvs[j].Path
+		}
+		return version.Later(mvs[j].Version, mvs[i].Version)
+	})
+	return mvs, nil
+}
+
+// GetModuleUnitMetas returns the UnitMetas of all the units in the module with
+// the given path and version.
+func (ds *FetchDataSource) GetModuleUnitMetas(ctx context.Context, modulePath, vers string) (_ []*internal.UnitMeta, err error) {
+	defer derrors.Wrap(&err, "FetchDataSource.GetModuleUnitMetas(%q, %q)", modulePath, vers)
+
+	m, err := ds.getModule(ctx, modulePath, vers)
+	if err != nil {
+		return nil, err
+	}
+	return m.UnitMetas, nil
+}
+
 // fetch fetches a module using the configured ModuleGetters.
 // It tries each getter in turn until it finds one that has the module.
 func (ds *FetchDataSource) fetch(ctx context.Context, modulePath, version string) (_ *fetch.LazyModule, g fetch.ModuleGetter, err error) {
@@ -143,7 +272,7 @@ func (ds *FetchDataSource) fetch(ctx context.Context, modulePath, version string
 		log.Infof(ctx, "FetchDataSource: fetched %s@%s using %T in %s with error %v", modulePath, version, g, time.Since(start), err)
 	}()
 	for _, g := range ds.opts.Getters {
-		m := fetch.FetchLazyModule(ctx, modulePath, version, g)
+		m := fetch.FetchLazyModule(ctx, modulePath, version, g, ds.opts.LoadOptions)
 		if m.Error == nil {
 			if ds.opts.BypassLicenseCheck {
 				m.IsRedistributable = true
@@ -224,16 +353,19 @@ func (ds *FetchDataSource) GetUnit(ctx context.Context, um *internal.UnitMeta, f
 	} else {
 		u2.Documentation = nil
 	}
+	// Use the import graph as it is, rather than waiting to bring it up to
+	// date.
+	u2.NumImportedBy = len(ds.index.importedBy(um.Path, um.ModulePath))
 	return &u2, nil
 }
 
 // findUnit returns the unit with the given path in m, or nil if none.
 func (ds *FetchDataSource) findUnit(ctx context.Context, m *fetch.LazyModule, path string) (*internal.Unit, error) {
 	unit, err := m.Unit(ctx, path)
-	ds.populateUnitSubdirectories(unit, m)
 	if err != nil {
 		return nil, err
 	}
+	ds.populateUnitSubdirectories(unit, m)
 	if ds.opts.BypassLicenseCheck {
 		unit.IsRedistributable = true
 	} else {
@@ -339,6 +471,43 @@ func (ds *FetchDataSource) getLatestMajorVersion(ctx context.Context, fullPath,
 	}
 }
 
+// GetImportedBy returns the paths of up to limit packages that import
+// pkgPath, other than those in the module modulePath. It considers the
+// packages of the latest version of every module that the getters can list,
+// and of the modules that have been fetched recently. Modules are indexed in
+// the background, so only those indexed so far are considered.
+func (ds *FetchDataSource) GetImportedBy(ctx context.Context, pkgPath, modulePath string, limit int) (_ []string, err error) {
+	defer derrors.Wrap(&err, "FetchDataSource.GetImportedBy(%q, %q)", pkgPath, modulePath)
+
+	ds.updateIndex()
+	paths := ds.index.importedBy(pkgPath, modulePath)
+	if len(paths) > limit {
+		paths = paths[:limit]
+	}
+	return paths, nil
+}
+
+// GetImportedByCount returns the number of packages that import pkgPath,
+// other than those in the module modulePath, as GetImportedBy does. It does
+// not wait for modules to be indexed either.
+func (ds *FetchDataSource) GetImportedByCount(ctx context.Context, pkgPath, modulePath string) (_ int, err error) {
+	defer derrors.Wrap(&err, "FetchDataSource.GetImportedByCount(%q, %q)", pkgPath, modulePath)
+
+	ds.updateIndex()
+	return len(ds.index.importedBy(pkgPath, modulePath)), nil
+}
+
+// ResolvesRefs reports whether any of the getters that resolve version
+// control refs serves the module or package at path.
+func (ds *FetchDataSource) ResolvesRefs(path string) bool {
+	for _, g := range ds.opts.Getters {
+		if rg, ok := g.(fetch.RefResolvingModuleGetter); ok && rg.ResolvesRefs(path) {
+			return true
+		}
+	}
+	return false
+}
+
 // GetNestedModules is not implemented.
 func (ds *FetchDataSource) GetNestedModules(ctx context.Context, modulePath string) ([]*internal.ModuleInfo, error) {
 	return nil, nil
@@ -349,38 +518,61 @@ func (*FetchDataSource) GetModuleReadme(ctx context.Context, modulePath, resolve
 	return nil, nil
 }
 
-// SearchSupport reports whether any of the configured Getters are searchable.
+// SearchSupport reports the search types supported by the FetchDataSource.
+// All modes are supported, using an index of the modules that the getters can
+// list and those that have been fetched.
 func (ds *FetchDataSource) SearchSupport() internal.SearchSupport {
-	for _, g := range ds.opts.Getters {
-		if _, ok := g.(fetch.SearchableModuleGetter); ok {
-			// Getters only support basic search.
-			return internal.BasicSearch
-		}
+	if len(ds.opts.Getters) == 0 {
+		return internal.NoSearch
 	}
-	return internal.NoSearch
+	return internal.FullSearch
 }
 
-// Search delegates search to any configured getters that support the
-// SearchableModuleGetter interface, merging their results.
+// Search searches for packages or symbols matching q.
+//
+// Packages are found by any configured getters that support the
+// SearchableModuleGetter interface, and by the FetchDataSource's search
+// index. Symbols are found only by the index. The index holds the latest
+// version of each module that the getters can list, and the modules that have
+// been fetched recently. Search does not wait for modules to be indexed.
 func (ds *FetchDataSource) Search(ctx context.Context, q string, opts internal.SearchOptions) (_ []*internal.SearchResult, err error) {
+	defer derrors.Wrap(&err, "FetchDataSource.Search(%q)", q)
+
+	ds.updateIndex()
 	var results []*internal.SearchResult
-	// Since results are potentially merged from multiple sources, we can't know
-	// a priori how many results will be used from any particular getter.
-	//
-	// Offset+MaxResults is an upper bound.
-	limit := opts.Offset + opts.MaxResults
-	for _, g := range ds.opts.Getters {
-		if s, ok := g.(fetch.SearchableModuleGetter); ok {
-			rs, err := s.Search(ctx, q, limit)
-			if err != nil {
-				return nil, err
+	if opts.SearchSymbols {
+		results = ds.index.search(q, true, opts.SymbolFilter)
+	} else {
+		// Since results are potentially merged from multiple sources, we can't know
+		// a priori how many results will be used from any particular getter.
+		//
+		// Offset+MaxResults is an upper bound.
+		limit := opts.Offset + opts.MaxResults
+		seen := map[string]bool{}
+		for _, g := range ds.opts.Getters {
+			if s, ok := g.(fetch.SearchableModuleGetter); ok {
+				rs, err := s.Search(ctx, q, limit)
+				if err != nil {
+					return nil, err
+				}
+				for _, r := range rs {
+					seen[r.PackagePath] = true
+				}
+				results = append(results, rs...)
+			}
+		}
+		for _, r := range ds.index.search(q, false, "") {
+			if !seen[r.PackagePath] {
+				results = append(results, r)
 			}
-			results = append(results, rs...)
 		}
 	}
-	sort.Slice(results, func(i, j int) bool {
+	sort.SliceStable(results, func(i, j int) bool {
 		return results[i].Score > results[j].Score
 	})
+	for _, r := range results {
+		r.NumResults = uint64(len(results))
+	}
 	if opts.Offset > 0 {
 		if len(results) < opts.Offset {
 			return nil, nil
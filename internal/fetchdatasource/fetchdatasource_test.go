@@ -10,8 +10,11 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"path/filepath"
 	"regexp"
+	"sort"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
@@ -561,8 +564,8 @@ func TestBuildConstraints(t *testing.T) {
 func TestCache(t *testing.T) {
 	ds := Options{}.New()
 	m1 := &fetch.LazyModule{}
-	ds.cachePut(nil, "m1", fetch.LocalVersion, m1, nil)
-	ds.cachePut(nil, "m2", "v1.0.0", nil, derrors.NotFound)
+	ds.cachePut(nil, "m1", fetch.LocalVersion, m1, nil, time.Time{})
+	ds.cachePut(nil, "m2", "v1.0.0", nil, derrors.NotFound, time.Time{})
 
 	for _, test := range []struct {
 		path, version string
@@ -580,3 +583,175 @@ func TestCache(t *testing.T) {
 		}
 	}
 }
+
+func TestRefetchChanged(t *testing.T) {
+	testenv.MustHaveExecPath(t, "go") // for the go packages module getter.
+	ctx := context.Background()
+
+	dir, _ := testhelper.WriteTxtarToTempDir(t, `
+-- go.mod --
+module example.com/changes
+-- a.go --
+// Package a is old.
+package a
+`)
+	// goPackagesModuleGetter doesn't trust very recent modification times, so
+	// move them into the past.
+	setMtime := func(file string, d time.Duration) {
+		t.Helper()
+		mtime := time.Now().Add(-d)
+		if err := os.Chtimes(filepath.Join(dir, file), mtime, mtime); err != nil {
+			t.Fatal(err)
+		}
+	}
+	setMtime("a.go", time.Hour)
+
+	g, err := fetch.NewGoPackagesModuleGetter(ctx, dir, "./...")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ds := Options{Getters: []fetch.ModuleGetter{g}, BypassLicenseCheck: true}.New()
+
+	synopsis := func() string {
+		t.Helper()
+		um, err := ds.GetUnitMeta(ctx, "example.com/changes", "example.com/changes", fetch.LocalVersion)
+		if err != nil {
+			t.Fatal(err)
+		}
+		u, err := ds.GetUnit(ctx, um, internal.AllFields, internal.BuildContext{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return u.Documentation[0].Synopsis
+	}
+	refetch := func() []internal.Modver {
+		t.Helper()
+		changed, err := ds.RefetchChanged(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return changed
+	}
+
+	if got, want := synopsis(), "Package a is old."; got != want {
+		t.Errorf("got synopsis %q, want %q", got, want)
+	}
+	if got := refetch(); len(got) != 0 {
+		t.Errorf("before change: got %v, want none", got)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "a.go"), []byte("// Package a is new.\npackage a\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	setMtime("a.go", time.Minute)
+	want := []internal.Modver{{Path: "example.com/changes", Version: fetch.LocalVersion}}
+	if got := refetch(); !cmp.Equal(got, want) {
+		t.Errorf("after change: got %v, want %v", got, want)
+	}
+	if got := refetch(); len(got) != 0 {
+		t.Errorf("after refetch: got %v, want none", got)
+	}
+	if got, want := synopsis(), "Package a is new."; got != want {
+		t.Errorf("got synopsis %q, want %q", got, want)
+	}
+}
+
+// volatileGetter is a fetch.VolatileModuleGetter whose fetches fail while it
+// is broken, and that reports the given change or error for any module.
+type volatileGetter struct {
+	fetch.ModuleGetter
+	broken     bool
+	changed    bool
+	changedErr error
+}
+
+func (g *volatileGetter) Info(ctx context.Context, path, version string) (*proxy.VersionInfo, error) {
+	if g.broken {
+		return nil, errors.New("broken")
+	}
+	return g.ModuleGetter.Info(ctx, path, version)
+}
+
+func (g *volatileGetter) HasChanged(context.Context, internal.ModuleInfo) (bool, error) {
+	return g.changed, g.changedErr
+}
+
+func TestRefetchChangedErrors(t *testing.T) {
+	testenv.MustHaveExecPath(t, "go") // for the go packages module getter.
+	ctx := context.Background()
+
+	newGetter := func(modulePath string) *volatileGetter {
+		t.Helper()
+		dir, _ := testhelper.WriteTxtarToTempDir(t, fmt.Sprintf(`
+-- go.mod --
+module %s
+-- a.go --
+package a
+`, modulePath))
+		g, err := fetch.NewGoPackagesModuleGetter(ctx, dir, "./...")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return &volatileGetter{ModuleGetter: g}
+	}
+	good := newGetter("example.com/good")
+	fixed := newGetter("example.com/fixed")
+	fixed.broken = true
+	ds := Options{Getters: []fetch.ModuleGetter{good, fixed}, BypassLicenseCheck: true}.New()
+
+	if _, err := ds.GetUnitMeta(ctx, "example.com/good", "example.com/good", fetch.LocalVersion); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ds.GetUnitMeta(ctx, "example.com/fixed", "example.com/fixed", fetch.LocalVersion); err == nil {
+		t.Fatal("got no error fetching the broken module")
+	}
+
+	// Failing to check one module doesn't keep the other from being
+	// re-fetched, even though its fetch failed.
+	good.changedErr = errors.New("bad")
+	fixed.broken = false
+	fixed.changed = true
+	changed, err := ds.RefetchChanged(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []internal.Modver{{Path: "example.com/fixed", Version: fetch.LocalVersion}}
+	if !cmp.Equal(changed, want) {
+		t.Errorf("got %v, want %v", changed, want)
+	}
+	if _, err := ds.GetUnitMeta(ctx, "example.com/fixed", "example.com/fixed", fetch.LocalVersion); err != nil {
+		t.Errorf("after fix: %v", err)
+	}
+}
+
+func TestListModules(t *testing.T) {
+	ctx, ds, teardown := setup(t, defaultTestModules, false)
+	defer teardown()
+
+	// The proxy getter can't list its modules, so only the local ones appear.
+	got, err := ds.ListModules(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []internal.Modver{
+		{Path: "github.com/my/module", Version: fetch.LocalVersion},
+		{Path: "github.com/no/license", Version: fetch.LocalVersion},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("ListModules mismatch (-want +got):\n%s", diff)
+	}
+
+	ums, err := ds.GetModuleUnitMetas(ctx, "github.com/my/module", fetch.LocalVersion)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var gotPaths []string
+	for _, um := range ums {
+		gotPaths = append(gotPaths, um.Path)
+	}
+	sort.Strings(gotPaths)
+	wantPaths := []string{"github.com/my/module", "github.com/my/module/bar", "github.com/my/module/foo"}
+	if diff := cmp.Diff(wantPaths, gotPaths); diff != "" {
+		t.Errorf("GetModuleUnitMetas mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,29 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fetchdatasource
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+
+	"golang.org/x/pkgsite/internal/derrors"
+)
+
+// GetModuleFS returns the contents of the given module version, fetching it
+// if necessary.
+func (ds *FetchDataSource) GetModuleFS(ctx context.Context, modulePath, version string) (_ fs.FS, err error) {
+	defer derrors.Wrap(&err, "FetchDataSource.GetModuleFS(%q, %q)", modulePath, version)
+
+	m, err := ds.getModule(ctx, modulePath, version)
+	if err != nil {
+		return nil, err
+	}
+	fsys := m.ContentDir()
+	if fsys == nil {
+		return nil, fmt.Errorf("no contents: %w", derrors.NotFound)
+	}
+	return fsys, nil
+}
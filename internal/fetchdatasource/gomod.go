@@ -0,0 +1,70 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fetchdatasource
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/derrors"
+	"golang.org/x/pkgsite/internal/version"
+)
+
+// GetGoModDirectives returns the go.mod directives of the given module
+// version, fetching it if necessary.
+func (ds *FetchDataSource) GetGoModDirectives(ctx context.Context, modulePath, vers string) (_ *internal.GoModDirectives, err error) {
+	defer derrors.Wrap(&err, "FetchDataSource.GetGoModDirectives(%q, %q)", modulePath, vers)
+
+	m, err := ds.getModule(ctx, modulePath, vers)
+	if err != nil {
+		return nil, err
+	}
+	if m.GoMod == nil {
+		return nil, fmt.Errorf("no go.mod file: %w", derrors.NotFound)
+	}
+	return m.GoMod, nil
+}
+
+// GetModuleDependents returns up to limit module versions whose go.mod files
+// require modulePath at vers, sorted by module path and then by version,
+// latest first. It considers the same modules as GetImportedBy, along with
+// any other versions of modules that have been fetched recently.
+func (ds *FetchDataSource) GetModuleDependents(ctx context.Context, modulePath, vers string, limit int) (_ []*internal.ModuleDependent, err error) {
+	defer derrors.Wrap(&err, "FetchDataSource.GetModuleDependents(%q, %q)", modulePath, vers)
+
+	ds.updateIndex()
+	goMods := ds.index.goModFiles()
+	for _, e := range ds.cache.Entries() {
+		if m := e.module; m != nil && m.GoMod != nil {
+			goMods[internal.Modver{Path: m.ModulePath, Version: m.Version}] = m.GoMod
+		}
+	}
+
+	var deps []*internal.ModuleDependent
+	for mv, gm := range goMods {
+		for _, r := range gm.Requires {
+			if r.ModulePath == modulePath && r.Version == vers {
+				deps = append(deps, &internal.ModuleDependent{
+					ModulePath: mv.Path,
+					Version:    mv.Version,
+					Indirect:   r.Indirect,
+				})
+				break
+			}
+		}
+	}
+	sort.Slice(deps, func(i, j int) bool {
+		if deps[i].ModulePath != deps[j].ModulePath {
+			return deps[i].ModulePath < deps[j].ModulePath
+		}
+		return version.Later(deps[i].Version, deps[j].Version)
+	})
+	if len(deps) > limit {
+		deps = deps[:limit]
+	}
+	return deps, nil
+}
@@ -0,0 +1,24 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fetchdatasource
+
+import (
+	"context"
+
+	"golang.org/x/pkgsite/internal/derrors"
+)
+
+// GetGoSum returns the go.sum lines for the given module version, fetching it
+// if necessary. It returns nil unless the module was fetched with a getter
+// that verifies modules against a checksum database.
+func (ds *FetchDataSource) GetGoSum(ctx context.Context, modulePath, version string) (_ []string, err error) {
+	defer derrors.Wrap(&err, "FetchDataSource.GetGoSum(%q, %q)", modulePath, version)
+
+	m, err := ds.getModule(ctx, modulePath, version)
+	if err != nil {
+		return nil, err
+	}
+	return m.GoSum, nil
+}
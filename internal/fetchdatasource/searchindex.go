@@ -0,0 +1,645 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fetchdatasource
+
+import (
+	"context"
+	"go/ast"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/fetch"
+	"golang.org/x/pkgsite/internal/godoc"
+	"golang.org/x/pkgsite/internal/log"
+	"golang.org/x/pkgsite/internal/version"
+)
+
+// A searchIndex is an in-memory inverted index of the packages and symbols
+// in a set of modules, along with the imports between those packages. It
+// supports search and the imported-by page for a FetchDataSource, for which
+// there is no database to query.
+type searchIndex struct {
+	mu      sync.Mutex
+	modules map[string]*indexedModule // keyed by module path
+	// postings maps each term to the documents containing it, and the weight
+	// of the term in each.
+	postings map[string]map[*searchDoc]float64
+	numDocs  int
+	// importers maps a package path to the packages that import it, and the
+	// module path of each.
+	importers map[string]map[string]string
+}
+
+// An indexedModule is a module version whose contents are in a searchIndex.
+// It holds only what the index needs, not the module itself, so that the
+// modules can be evicted from the FetchDataSource's cache.
+type indexedModule struct {
+	version string
+	// gen is the generation of the cache entry that was indexed, or 0 if
+	// the module was not in the cache.
+	gen     uint64
+	goMod   *internal.GoModDirectives
+	docs    []*searchDoc
+	imports map[string][]string // package path to imports
+}
+
+// A searchDoc is a package or symbol in a searchIndex.
+type searchDoc struct {
+	// result is returned, with a score, when the document matches a query.
+	// If result.SymbolName is empty, the document is a package.
+	result *internal.SearchResult
+	// text is the doc comment of the package or symbol. It is discarded once
+	// the document is indexed.
+	text  string
+	terms []string // the keys of searchIndex.postings that refer to this doc
+}
+
+// Weights of terms by where they appear.
+const (
+	nameWeight     = 3 // package or symbol name
+	pathWeight     = 2 // package import path
+	synopsisWeight = 2 // package synopsis
+	docWeight      = 1 // doc comment
+	// symbolPackageWeight is for the path and name of the package a symbol is
+	// in, so that queries like "json unmarshal" find json.Unmarshal.
+	symbolPackageWeight = 0.5
+)
+
+func newSearchIndex() *searchIndex {
+	return &searchIndex{
+		modules:   map[string]*indexedModule{},
+		postings:  map[string]map[*searchDoc]float64{},
+		importers: map[string]map[string]string{},
+	}
+}
+
+// has reports whether the index holds modulePath at vers. If gen is
+// non-zero, the indexed module must also have come from the cache entry with
+// that generation or a later one.
+func (x *searchIndex) has(modulePath, vers string, gen uint64) bool {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	im, ok := x.modules[modulePath]
+	return ok && im.version == vers && im.gen >= gen
+}
+
+// goModFiles returns the go.mod directives of the indexed modules that have
+// them.
+func (x *searchIndex) goModFiles() map[internal.Modver]*internal.GoModDirectives {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	gms := map[internal.Modver]*internal.GoModDirectives{}
+	for p, im := range x.modules {
+		if im.goMod != nil {
+			gms[internal.Modver{Path: p, Version: im.version}] = im.goMod
+		}
+	}
+	return gms
+}
+
+// paths returns the module paths in the index.
+func (x *searchIndex) paths() []string {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	var ps []string
+	for p := range x.modules {
+		ps = append(ps, p)
+	}
+	return ps
+}
+
+// put replaces the documents and imports for modulePath with those of im,
+// unless a later fetch of the same version has already been indexed.
+func (x *searchIndex) put(modulePath string, im *indexedModule) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	if old, ok := x.modules[modulePath]; ok && old.version == im.version && old.gen > im.gen {
+		return
+	}
+	x.removeLocked(modulePath)
+	for _, d := range im.docs {
+		for t, w := range d.weights() {
+			ds := x.postings[t]
+			if ds == nil {
+				ds = map[*searchDoc]float64{}
+				x.postings[t] = ds
+			}
+			ds[d] = w
+			d.terms = append(d.terms, t)
+		}
+		d.text = ""
+	}
+	x.numDocs += len(im.docs)
+	for from, tos := range im.imports {
+		for _, to := range tos {
+			if x.importers[to] == nil {
+				x.importers[to] = map[string]string{}
+			}
+			x.importers[to][from] = modulePath
+		}
+	}
+	x.modules[modulePath] = im
+}
+
+// remove removes the documents for modulePath.
+func (x *searchIndex) remove(modulePath string) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	x.removeLocked(modulePath)
+}
+
+func (x *searchIndex) removeLocked(modulePath string) {
+	im, ok := x.modules[modulePath]
+	if !ok {
+		return
+	}
+	for _, d := range im.docs {
+		for _, t := range d.terms {
+			delete(x.postings[t], d)
+			if len(x.postings[t]) == 0 {
+				delete(x.postings, t)
+			}
+		}
+	}
+	x.numDocs -= len(im.docs)
+	for from, tos := range im.imports {
+		for _, to := range tos {
+			delete(x.importers[to], from)
+			if len(x.importers[to]) == 0 {
+				delete(x.importers, to)
+			}
+		}
+	}
+	delete(x.modules, modulePath)
+}
+
+// importedBy returns the sorted paths of the packages that import pkgPath,
+// other than those in the module modulePath.
+func (x *searchIndex) importedBy(pkgPath, modulePath string) []string {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	var paths []string
+	for from, fromModule := range x.importers[pkgPath] {
+		if fromModule != modulePath {
+			paths = append(paths, from)
+		}
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// search returns the packages, or the symbols if symbols is true, that
+// contain every term of q, best match first. If symbolFilter is non-empty,
+// only symbols with that name, or a method or field with that name, match.
+//
+// Scores are in the range (0, 1), so that they can be compared with those
+// of fetch.SearchableModuleGetter.
+func (x *searchIndex) search(q string, symbols bool, symbolFilter string) []*internal.SearchResult {
+	terms := tokenize(q)
+	if len(terms) == 0 {
+		return nil
+	}
+	symbolFilter = strings.ToLower(symbolFilter)
+
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	scores := map[*searchDoc]float64{}
+	for i, t := range terms {
+		ds := x.postings[t]
+		idf := math.Log(1 + float64(x.numDocs)/float64(len(ds)+1))
+		for d, w := range ds {
+			if isSymbol := d.result.SymbolName != ""; isSymbol != symbols {
+				continue
+			}
+			if _, ok := scores[d]; ok || i == 0 {
+				scores[d] += w * idf
+			}
+		}
+		// Every term must match.
+		for d := range scores {
+			if _, ok := ds[d]; !ok {
+				delete(scores, d)
+			}
+		}
+	}
+	var results []*internal.SearchResult
+	for d, s := range scores {
+		if symbolFilter != "" {
+			name := strings.ToLower(d.result.SymbolName)
+			if name != symbolFilter && !strings.HasSuffix(name, "."+symbolFilter) {
+				continue
+			}
+		}
+		r := *d.result
+		// Prefer exact matches of the whole name.
+		if strings.EqualFold(r.SymbolName, q) || (!symbols && (strings.EqualFold(r.Name, q) || r.PackagePath == q)) {
+			s *= 2
+		}
+		r.Score = s / (1 + s)
+		results = append(results, &r)
+	}
+	sort.Slice(results, func(i, j int) bool {
+		ri, rj := results[i], results[j]
+		if ri.Score != rj.Score {
+			return ri.Score > rj.Score
+		}
+		if ri.PackagePath != rj.PackagePath {
+			return ri.PackagePath < rj.PackagePath
+		}
+		return ri.SymbolName < rj.SymbolName
+	})
+	return results
+}
+
+// weights returns the terms of d, with the weight of each.
+func (d *searchDoc) weights() map[string]float64 {
+	ws := map[string]float64{}
+	add := func(s string, w float64) {
+		for _, t := range tokenize(s) {
+			ws[t] = math.Max(ws[t], w)
+		}
+	}
+	r := d.result
+	if r.SymbolName == "" {
+		add(r.Name, nameWeight)
+		add(r.PackagePath, pathWeight)
+		add(r.Synopsis, synopsisWeight)
+	} else {
+		add(r.SymbolName, nameWeight)
+		add(r.PackagePath, symbolPackageWeight)
+		add(r.Name, symbolPackageWeight)
+	}
+	add(d.text, docWeight)
+	return ws
+}
+
+// updateIndex asks the background indexer to bring the search index up to
+// date, starting it if necessary, and returns without waiting for it. Callers
+// use whatever has been indexed so far. It returns a number identifying the
+// request, for WaitForIndex.
+func (ds *FetchDataSource) updateIndex() uint64 {
+	ds.indexMu.Lock()
+	defer ds.indexMu.Unlock()
+	if ds.indexCond == nil {
+		ds.indexCond = sync.NewCond(&ds.indexMu)
+		// The indexed modules outlive the request that started indexing them.
+		go ds.indexLoop(context.Background())
+	}
+	ds.indexRequests++
+	ds.indexCond.Broadcast()
+	return ds.indexRequests
+}
+
+// WaitForIndex brings the search index up to date, waiting until every module
+// that the getters can list, and every module in the cache, has been indexed.
+// Search and the imported-by page do not wait for the index, so this is
+// useful only for callers that need complete results, like tests.
+func (ds *FetchDataSource) WaitForIndex() {
+	n := ds.updateIndex()
+	ds.indexMu.Lock()
+	defer ds.indexMu.Unlock()
+	for ds.indexDone < n {
+		ds.indexCond.Wait()
+	}
+}
+
+// indexLoop runs indexPass whenever updateIndex has been called since the
+// last pass started. It never returns.
+func (ds *FetchDataSource) indexLoop(ctx context.Context) {
+	for {
+		ds.indexMu.Lock()
+		for ds.indexDone == ds.indexRequests {
+			ds.indexCond.Wait()
+		}
+		n := ds.indexRequests
+		ds.indexMu.Unlock()
+
+		ds.indexPass(ctx)
+
+		ds.indexMu.Lock()
+		ds.indexDone = n
+		ds.indexCond.Broadcast()
+		ds.indexMu.Unlock()
+	}
+}
+
+// indexPass brings the search index up to date. It indexes one version of
+// every module in the cache, preferring the listed version and then the
+// latest, and the latest version of every module that the getters can list,
+// fetching it if necessary. It drops modules that are no longer in the cache
+// unless the getters list them.
+//
+// Modules in the cache are indexed before listed ones, so that modules that
+// users have visited become searchable first. Each module version is indexed
+// at most once per pass.
+func (ds *FetchDataSource) indexPass(ctx context.Context) {
+	if ds.listed == nil {
+		ds.listed = ds.listModulesToIndex(ctx)
+	}
+	var listedPaths []string
+	for p := range ds.listed {
+		listedPaths = append(listedPaths, p)
+	}
+	sort.Strings(listedPaths)
+
+	start := time.Now()
+	done := map[internal.Modver]bool{}
+	for {
+		mv, e, ok := ds.nextToIndex(listedPaths, done)
+		if !ok {
+			break
+		}
+		done[mv] = true
+		if e.module == nil {
+			m, err := ds.getModule(ctx, mv.Path, mv.Version)
+			if err != nil {
+				log.Errorf(ctx, "indexing %s: %v", mv, err)
+			}
+			e = cacheEntry{module: m, gen: ds.cacheGen(mv.Path, mv.Version)}
+		}
+		ds.indexModule(ctx, mv.Path, mv.Version, e.module, e.gen)
+	}
+	if len(done) > 0 {
+		log.Infof(ctx, "FetchDataSource: indexed %d modules in %s", len(done), time.Since(start))
+	}
+
+	cached := ds.cachedToIndex()
+	for _, modulePath := range ds.index.paths() {
+		if _, ok := ds.listed[modulePath]; !ok {
+			if _, ok := cached[modulePath]; !ok {
+				ds.index.remove(modulePath)
+			}
+		}
+	}
+}
+
+// listModulesToIndex returns the latest version of each module that the
+// getters can list, keyed by module path. If the modules can't be listed, it
+// logs the error and returns nil, so that the next pass tries again.
+func (ds *FetchDataSource) listModulesToIndex(ctx context.Context) map[string]string {
+	mvs, err := ds.ListModules(ctx)
+	if err != nil {
+		log.Errorf(ctx, "FetchDataSource: listing modules to index: %v", err)
+		return nil
+	}
+	listed := map[string]string{}
+	for _, mv := range mvs {
+		// ListModules lists the latest version of each module first.
+		if _, ok := listed[mv.Path]; !ok {
+			listed[mv.Path] = mv.Version
+		}
+	}
+	return listed
+}
+
+// nextToIndex returns the next module version for indexPass to index, and
+// its cache entry if it comes from the cache. It skips the module versions in
+// done, and those already in the index. It reports false if there are none
+// left.
+func (ds *FetchDataSource) nextToIndex(listedPaths []string, done map[internal.Modver]bool) (internal.Modver, cacheEntry, bool) {
+	for modulePath, e := range ds.cachedToIndex() {
+		mv := internal.Modver{Path: modulePath, Version: e.module.Version}
+		if !done[mv] && !ds.index.has(mv.Path, mv.Version, e.gen) {
+			return mv, e, true
+		}
+	}
+	for _, modulePath := range listedPaths {
+		mv := internal.Modver{Path: modulePath, Version: ds.listed[modulePath]}
+		if !done[mv] && !ds.index.has(mv.Path, mv.Version, 0) {
+			return mv, cacheEntry{}, true
+		}
+	}
+	return internal.Modver{}, cacheEntry{}, false
+}
+
+// cachedToIndex returns the cache entry of the version of each cached module
+// that belongs in the index, keyed by module path: the listed version if the
+// module is listed, and otherwise the latest version, most recently fetched.
+func (ds *FetchDataSource) cachedToIndex() map[string]cacheEntry {
+	want := map[string]cacheEntry{}
+	for _, e := range ds.cache.Entries() {
+		m := e.module
+		if m == nil {
+			continue
+		}
+		if v, ok := ds.listed[m.ModulePath]; ok && v != m.Version {
+			continue
+		}
+		w, ok := want[m.ModulePath]
+		if !ok || version.Later(m.Version, w.module.Version) || (m.Version == w.module.Version && e.gen > w.gen) {
+			want[m.ModulePath] = e
+		}
+	}
+	return want
+}
+
+// indexModule adds the packages, symbols and imports of m, which may be nil,
+// to the search index as modulePath at vers. The cache entry for m has
+// generation gen.
+func (ds *FetchDataSource) indexModule(ctx context.Context, modulePath, vers string, m *fetch.LazyModule, gen uint64) {
+	im := &indexedModule{
+		version: vers,
+		gen:     gen,
+		imports: map[string][]string{},
+	}
+	if m != nil {
+		im.goMod = m.GoMod
+		for _, um := range m.UnitMetas {
+			if !um.IsPackage() {
+				continue
+			}
+			u, err := ds.findUnit(ctx, m, um.Path)
+			if err != nil {
+				log.Errorf(ctx, "indexing %s: %v", um.Path, err)
+				continue
+			}
+			im.docs = append(im.docs, unitSearchDocs(m, u)...)
+			im.imports[u.Path] = u.Imports
+		}
+	}
+	ds.index.put(modulePath, im)
+}
+
+// unitSearchDocs returns the search documents for the package u and its
+// symbols.
+func unitSearchDocs(m *fetch.LazyModule, u *internal.Unit) []*searchDoc {
+	pkg := &internal.SearchResult{
+		Name:        u.Name,
+		PackagePath: u.Path,
+		ModulePath:  m.ModulePath,
+		Version:     m.Version,
+		CommitTime:  m.CommitTime,
+	}
+	for _, l := range u.Licenses {
+		pkg.Licenses = append(pkg.Licenses, l.Types...)
+	}
+	d := matchingDoc(u.Documentation, internal.BuildContext{})
+	if d == nil {
+		return []*searchDoc{{result: pkg}}
+	}
+	pkg.Synopsis = d.Synopsis
+
+	var (
+		pkgDoc  string
+		symDocs map[string]string
+	)
+	if gp, err := godoc.DecodePackage(d.Source); err == nil {
+		pkgDoc, symDocs = docComments(gp.Files)
+	}
+	docs := []*searchDoc{{result: pkg, text: pkgDoc}}
+	addSymbol := func(sm *internal.SymbolMeta) {
+		r := *pkg
+		r.SymbolName = sm.Name
+		r.SymbolKind = sm.Kind
+		r.SymbolSynopsis = sm.Synopsis
+		r.SymbolGOOS = d.GOOS
+		r.SymbolGOARCH = d.GOARCH
+		docs = append(docs, &searchDoc{result: &r, text: symDocs[sm.Name]})
+	}
+	for _, s := range d.API {
+		addSymbol(&s.SymbolMeta)
+		for _, c := range s.Children {
+			addSymbol(c)
+		}
+	}
+	return docs
+}
+
+// docComments returns the package comment and the doc comments of the
+// declarations in files, keyed by symbol name as in internal.SymbolMeta.Name.
+func docComments(files []*godoc.File) (string, map[string]string) {
+	var pkgDoc []string
+	docs := map[string]string{}
+	add := func(name string, groups ...*ast.CommentGroup) {
+		for _, g := range groups {
+			if g != nil {
+				docs[name] += g.Text()
+				return
+			}
+		}
+	}
+	for _, f := range files {
+		if strings.HasSuffix(f.Name, "_test.go") {
+			continue
+		}
+		if f.AST.Doc != nil {
+			pkgDoc = append(pkgDoc, f.AST.Doc.Text())
+		}
+		for _, decl := range f.AST.Decls {
+			switch decl := decl.(type) {
+			case *ast.FuncDecl:
+				name := decl.Name.Name
+				if decl.Recv != nil && len(decl.Recv.List) > 0 {
+					name = receiverTypeName(decl.Recv.List[0].Type) + "." + name
+				}
+				add(name, decl.Doc)
+			case *ast.GenDecl:
+				// A lone spec is documented by the comment on its declaration.
+				var declDoc *ast.CommentGroup
+				if len(decl.Specs) == 1 {
+					declDoc = decl.Doc
+				}
+				for _, spec := range decl.Specs {
+					switch spec := spec.(type) {
+					case *ast.ValueSpec:
+						for _, n := range spec.Names {
+							add(n.Name, spec.Doc, declDoc)
+						}
+					case *ast.TypeSpec:
+						add(spec.Name.Name, spec.Doc, declDoc)
+						var fields *ast.FieldList
+						switch t := spec.Type.(type) {
+						case *ast.StructType:
+							fields = t.Fields
+						case *ast.InterfaceType:
+							fields = t.Methods
+						}
+						if fields == nil {
+							continue
+						}
+						for _, field := range fields.List {
+							for _, n := range field.Names {
+								add(spec.Name.Name+"."+n.Name, field.Doc, field.Comment)
+							}
+						}
+					}
+				}
+			}
+		}
+	}
+	return strings.Join(pkgDoc, "\n"), docs
+}
+
+// receiverTypeName returns the name of the type of a method receiver.
+func receiverTypeName(expr ast.Expr) string {
+	for {
+		switch e := expr.(type) {
+		case *ast.StarExpr:
+			expr = e.X
+		case *ast.IndexExpr:
+			expr = e.X
+		case *ast.IndexListExpr:
+			expr = e.X
+		case *ast.Ident:
+			return e.Name
+		default:
+			return ""
+		}
+	}
+}
+
+// tokenize splits s into lower-case search terms. Words are separated by
+// anything other than letters and digits. A mixed-case word is also split
+// where its case changes, so "UnmarshalJSON" yields "unmarshaljson",
+// "unmarshal" and "json".
+func tokenize(s string) []string {
+	var terms []string
+	seen := map[string]bool{}
+	add := func(t string) {
+		t = strings.ToLower(t)
+		if t != "" && !seen[t] {
+			seen[t] = true
+			terms = append(terms, t)
+		}
+	}
+	for _, word := range strings.FieldsFunc(s, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		add(word)
+		parts := splitCamelCase(word)
+		if len(parts) > 1 {
+			for _, p := range parts {
+				add(p)
+			}
+		}
+	}
+	return terms
+}
+
+// splitCamelCase splits a word where its case changes: before an upper-case
+// letter that follows a lower-case letter or digit, and before the last
+// letter of a run of upper-case letters that is followed by a lower-case one.
+func splitCamelCase(word string) []string {
+	rs := []rune(word)
+	var parts []string
+	start := 0
+	for i := 1; i < len(rs); i++ {
+		prev, cur := rs[i-1], rs[i]
+		split := unicode.IsUpper(cur) && (unicode.IsLower(prev) || unicode.IsDigit(prev))
+		if unicode.IsUpper(prev) && unicode.IsUpper(cur) && i+1 < len(rs) && unicode.IsLower(rs[i+1]) {
+			split = true
+		}
+		if split {
+			parts = append(parts, string(rs[start:i]))
+			start = i
+		}
+	}
+	return append(parts, string(rs[start:]))
+}
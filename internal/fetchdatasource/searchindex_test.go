@@ -0,0 +1,328 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fetchdatasource
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/fetch"
+	"golang.org/x/pkgsite/internal/lru"
+	"golang.org/x/pkgsite/internal/testing/testhelper"
+)
+
+func TestTokenize(t *testing.T) {
+	for _, test := range []struct {
+		in   string
+		want []string
+	}{
+		{"", nil},
+		{"json", []string{"json"}},
+		{"encoding/json", []string{"encoding", "json"}},
+		{"UnmarshalJSON", []string{"unmarshaljson", "unmarshal", "json"}},
+		{"JSONDecoder", []string{"jsondecoder", "json", "decoder"}},
+		{"Reader.Read", []string{"reader", "read"}},
+		{"Decode decodes the Decoder's input.", []string{"decode", "decodes", "the", "decoder", "s", "input"}},
+		{"sha256 Sum256", []string{"sha256", "sum256"}},
+	} {
+		got := tokenize(test.in)
+		if !cmp.Equal(got, test.want) {
+			t.Errorf("tokenize(%q) = %q, want %q", test.in, got, test.want)
+		}
+	}
+}
+
+func TestSearchIndex(t *testing.T) {
+	ctx := context.Background()
+	dir, _ := testhelper.WriteTxtarToTempDir(t, `
+-- go.mod --
+module example.com/shapes
+-- LICENSE --
+`+testhelper.MITLicense+`
+-- shapes.go --
+// Package shapes computes the geometry of polygons.
+package shapes
+
+// Area returns the area of the rectangle.
+func (r Rect) Area() float64 { return r.W * r.H }
+
+// A Rect is a rectangle.
+type Rect struct {
+	W, H float64
+}
+-- circle/circle.go --
+// Package circle describes round things.
+package circle
+
+// Pi is the ratio of a circle's circumference to its diameter.
+const Pi = 3.14
+
+// Area returns the area of a circle with radius r.
+func Area(r float64) float64 { return Pi * r * r }
+`)
+	g, err := fetch.NewDirectoryModuleGetter("", dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ds := Options{Getters: []fetch.ModuleGetter{g}}.New()
+	if got, want := ds.SearchSupport(), internal.FullSearch; got != want {
+		t.Fatalf("SearchSupport() = %v, want %v", got, want)
+	}
+
+	ds.WaitForIndex()
+
+	type result struct {
+		Path, Symbol string
+	}
+	for _, test := range []struct {
+		q       string
+		symbols bool
+		filter  string
+		want    []result
+	}{
+		{"shapes", false, "", []result{{"example.com/shapes", ""}, {"example.com/shapes/circle", ""}}},
+		{"polygons", false, "", []result{{"example.com/shapes", ""}}},
+		{"round", false, "", []result{{"example.com/shapes/circle", ""}}},
+		{"square", false, "", nil},
+		{"Area", true, "", []result{{"example.com/shapes/circle", "Area"}, {"example.com/shapes", "Rect.Area"}}},
+		{"circumference", true, "", []result{{"example.com/shapes/circle", "Pi"}}},
+		{"circle area", true, "", []result{{"example.com/shapes/circle", "Area"}}},
+		{"area", true, "area", []result{{"example.com/shapes/circle", "Area"}, {"example.com/shapes", "Rect.Area"}}},
+		{"shapes rect", true, "rect", []result{{"example.com/shapes", "Rect"}}},
+		{"W", true, "", []result{{"example.com/shapes", "Rect.W"}}},
+	} {
+		rs, err := ds.Search(ctx, test.q, internal.SearchOptions{MaxResults: 10, SearchSymbols: test.symbols, SymbolFilter: test.filter})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []result
+		for _, r := range rs {
+			got = append(got, result{r.PackagePath, r.SymbolName})
+			if r.NumResults != uint64(len(rs)) {
+				t.Errorf("%q: NumResults = %d, want %d", test.q, r.NumResults, len(rs))
+			}
+		}
+		if diff := cmp.Diff(test.want, got); diff != "" {
+			t.Errorf("Search(%q, symbols=%t, filter=%q) mismatch (-want +got):\n%s", test.q, test.symbols, test.filter, diff)
+		}
+	}
+
+	rs, err := ds.Search(ctx, "pi", internal.SearchOptions{SearchSymbols: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rs) != 1 {
+		t.Fatalf("got %d results, want 1", len(rs))
+	}
+	want := &internal.SearchResult{
+		Name:           "circle",
+		PackagePath:    "example.com/shapes/circle",
+		ModulePath:     "example.com/shapes",
+		Version:        fetch.LocalVersion,
+		Synopsis:       "Package circle describes round things.",
+		Licenses:       []string{"MIT"},
+		SymbolName:     "Pi",
+		SymbolKind:     internal.SymbolKindConstant,
+		SymbolSynopsis: "const Pi",
+		SymbolGOOS:     "all",
+		SymbolGOARCH:   "all",
+		NumResults:     1,
+	}
+	if diff := cmp.Diff(want, rs[0], cmpopts.IgnoreFields(internal.SearchResult{}, "Score", "CommitTime")); diff != "" {
+		t.Errorf("mismatch (-want +got):\n%s", diff)
+	}
+}
+
+// blockingListGetter is a ModuleGetter whose ListModules waits until release
+// is closed.
+type blockingListGetter struct {
+	fetch.ModuleGetter
+	release chan struct{}
+}
+
+func (g blockingListGetter) ListModules(ctx context.Context) ([]internal.Modver, error) {
+	<-g.release
+	return g.ModuleGetter.(fetch.ListableModuleGetter).ListModules(ctx)
+}
+
+func TestSearchWhileIndexing(t *testing.T) {
+	ctx := context.Background()
+	dir, _ := testhelper.WriteTxtarToTempDir(t, `
+-- go.mod --
+module example.com/shapes
+-- shapes.go --
+package shapes
+`)
+	dg, err := fetch.NewDirectoryModuleGetter("", dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := blockingListGetter{dg, make(chan struct{})}
+	ds := Options{Getters: []fetch.ModuleGetter{g}}.New()
+
+	search := func() int {
+		t.Helper()
+		rs, err := ds.Search(ctx, "shapes", internal.SearchOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return len(rs)
+	}
+	// Searches don't wait for the listed modules to be indexed, or for each
+	// other.
+	var wg sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := ds.Search(ctx, "shapes", internal.SearchOptions{}); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	if n := search(); n != 0 {
+		t.Errorf("while listing: got %d results, want 0", n)
+	}
+
+	close(g.release)
+	ds.WaitForIndex()
+	if n := search(); n != 1 {
+		t.Errorf("after listing: got %d results, want 1", n)
+	}
+}
+
+func TestSearchFetchedModules(t *testing.T) {
+	ctx, ds, teardown := setup(t, defaultTestModules, false)
+	defer teardown()
+
+	search := func() []string {
+		t.Helper()
+		ds.WaitForIndex()
+		rs, err := ds.Search(ctx, "sample", internal.SearchOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var paths []string
+		for _, r := range rs {
+			paths = append(paths, r.PackagePath+"@"+r.Version)
+		}
+		return paths
+	}
+
+	// Modules from the proxy can't be listed, so they are searchable only
+	// once they have been fetched.
+	if got := search(); len(got) != 0 {
+		t.Errorf("before fetch: got %v, want none", got)
+	}
+	for _, v := range []string{"v1.0.0", "v1.1.0"} {
+		if _, err := ds.GetUnitMeta(ctx, "example.com/basic", "example.com/basic", v); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want := []string{"example.com/basic@v1.1.0"}
+	if got := search(); !cmp.Equal(got, want) {
+		t.Errorf("after fetch: got %v, want %v", got, want)
+	}
+
+	// Modules that leave the cache leave the index.
+	ds.cache = lru.New[internal.Modver, cacheEntry](maxCachedModules)
+	if got := search(); len(got) != 0 {
+		t.Errorf("after eviction: got %v, want none", got)
+	}
+}
+
+func TestGetImportedBy(t *testing.T) {
+	ctx := context.Background()
+	var getters []fetch.ModuleGetter
+	for _, txtar := range []string{`
+-- go.mod --
+module example.com/a
+-- a.go --
+package a
+-- sub/sub.go --
+package sub
+
+import "example.com/a"
+`, `
+-- go.mod --
+module example.com/b
+-- b.go --
+package b
+
+import "example.com/a"
+-- c/c.go --
+package c
+
+import (
+	"example.com/a"
+	"example.com/a/sub"
+)
+`} {
+		dir, _ := testhelper.WriteTxtarToTempDir(t, txtar)
+		g, err := fetch.NewDirectoryModuleGetter("", dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		getters = append(getters, g)
+	}
+	// Hold up listing, to check that imported-by doesn't wait for indexing.
+	release := make(chan struct{})
+	getters[0] = blockingListGetter{getters[0], release}
+	ds := Options{Getters: getters}.New()
+	got, err := ds.GetImportedBy(ctx, "example.com/a", "example.com/a", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 0 {
+		t.Errorf("while listing: got %v, want none", got)
+	}
+	close(release)
+	ds.WaitForIndex()
+
+	for _, test := range []struct {
+		pkgPath, modulePath string
+		limit               int
+		want                []string
+		wantCount           int
+	}{
+		{"example.com/a", "example.com/a", 10, []string{"example.com/b", "example.com/b/c"}, 2},
+		{"example.com/a", "example.com/a", 1, []string{"example.com/b"}, 2},
+		{"example.com/a/sub", "example.com/a", 10, []string{"example.com/b/c"}, 1},
+		{"example.com/b", "example.com/b", 10, nil, 0},
+	} {
+		got, err := ds.GetImportedBy(ctx, test.pkgPath, test.modulePath, test.limit)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !cmp.Equal(got, test.want) {
+			t.Errorf("GetImportedBy(%q, %q, %d) = %v, want %v", test.pkgPath, test.modulePath, test.limit, got, test.want)
+		}
+		n, err := ds.GetImportedByCount(ctx, test.pkgPath, test.modulePath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n != test.wantCount {
+			t.Errorf("GetImportedByCount(%q, %q) = %d, want %d", test.pkgPath, test.modulePath, n, test.wantCount)
+		}
+	}
+
+	// Once the graph is built, units report their importers.
+	um, err := ds.GetUnitMeta(ctx, "example.com/a", "example.com/a", fetch.LocalVersion)
+	if err != nil {
+		t.Fatal(err)
+	}
+	u, err := ds.GetUnit(ctx, um, internal.AllFields, internal.BuildContext{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := u.NumImportedBy, 2; got != want {
+		t.Errorf("NumImportedBy = %d, want %d", got, want)
+	}
+}
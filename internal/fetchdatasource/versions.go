@@ -0,0 +1,274 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fetchdatasource
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/derrors"
+	"golang.org/x/pkgsite/internal/fetch"
+	"golang.org/x/pkgsite/internal/log"
+	"golang.org/x/pkgsite/internal/stdlib"
+	"golang.org/x/pkgsite/internal/version"
+	"golang.org/x/sync/errgroup"
+)
+
+const (
+	// maxTaggedVersions and maxPseudoVersions limit the number of versions
+	// returned by GetVersionsForPath, matching the database.
+	maxTaggedVersions = 800
+	maxPseudoVersions = 10
+)
+
+// GetVersionsForPath returns a list of tagged versions of the module
+// containing path and of its other major versions, sorted in descending
+// semver order with later major versions first, if any exist. If none, it
+// returns the 10 most recent pseudo-versions.
+//
+// The versions are those listed by the getters that implement
+// fetch.VersionListingModuleGetter, along with the versions of the modules
+// that have already been fetched. The other major versions are the modules
+// with the same series path that have been fetched, and those the getters list
+// versions for, up to the first major version after the module's own that
+// none of them knows about. Retraction and deprecation information comes from
+// the go.mod file of the latest version of each module. Unlike the database,
+// GetVersionsForPath does not check that path exists at every version, since
+// that would require fetching each of them.
+func (ds *FetchDataSource) GetVersionsForPath(ctx context.Context, path string) (_ []*internal.ModuleInfo, err error) {
+	defer derrors.Wrap(&err, "FetchDataSource.GetVersionsForPath(%q)", path)
+
+	m, err := ds.findModule(ctx, path, internal.UnknownModulePath, version.Latest)
+	if err != nil {
+		return nil, err
+	}
+	mods, err := ds.majorVersionModules(ctx, m.ModulePath)
+	if err != nil {
+		return nil, err
+	}
+
+	var tagged, pseudo []internal.Modver
+	for _, mod := range mods {
+		for v := range mod.getters {
+			mv := internal.Modver{Path: mod.modulePath, Version: v}
+			if version.IsPseudo(v) {
+				pseudo = append(pseudo, mv)
+			} else {
+				tagged = append(tagged, mv)
+			}
+		}
+	}
+	mvs := tagged
+	limit := maxTaggedVersions
+	if len(mvs) == 0 {
+		mvs = pseudo
+		limit = maxPseudoVersions
+	}
+	sort.Slice(mvs, func(i, j int) bool {
+		// Incompatible versions go last, as in the database.
+		if ii, ij := version.IsIncompatible(mvs[i].Version), version.IsIncompatible(mvs[j].Version); ii != ij {
+			return ij
+		}
+		if mvs[i].Path != mvs[j].Path {
+			return mods[mvs[i].Path].major > mods[mvs[j].Path].major
+		}
+		return version.Later(mvs[i].Version, mvs[j].Version)
+	})
+	if len(mvs) > limit {
+		mvs = mvs[:limit]
+	}
+
+	// Get the info for each version. Failures are logged and otherwise
+	// ignored: a version without a commit time can still be shown.
+	mis := make([]*internal.ModuleInfo, len(mvs))
+	eg, gctx := errgroup.WithContext(ctx)
+	eg.SetLimit(10)
+	for i, mv := range mvs {
+		i, mv := i, mv
+		eg.Go(func() error {
+			mod := mods[mv.Path]
+			mi := mod.fetched[mv.Version]
+			if mi == nil {
+				mi = &internal.ModuleInfo{ModulePath: mv.Path, Version: mv.Version}
+				if info, err := mod.getters[mv.Version].Info(gctx, mv.Path, mv.Version); err != nil {
+					log.Warningf(gctx, "GetVersionsForPath: %v", err)
+				} else {
+					mi.CommitTime = info.Time
+				}
+			}
+			mis[i] = mi
+			return nil
+		})
+	}
+	if err := eg.Wait(); err != nil {
+		return nil, err
+	}
+
+	// Apply the go.mod file of the latest version of each module to the
+	// versions of that module.
+	versions := map[string][]string{}
+	for _, mv := range mvs {
+		versions[mv.Path] = append(versions[mv.Path], mv.Version)
+	}
+	lmvs := map[string]*internal.LatestModuleVersions{}
+	for modulePath, vs := range versions {
+		lmvs[modulePath] = ds.latestModuleVersions(ctx, modulePath, vs, mods[modulePath].getters)
+	}
+	for _, mi := range mis {
+		if lmv := lmvs[mi.ModulePath]; lmv != nil {
+			lmv.PopulateModuleInfo(mi)
+		}
+	}
+	return mis, nil
+}
+
+// moduleVersions holds the versions of a module known to a FetchDataSource.
+type moduleVersions struct {
+	modulePath string
+	major      int
+	// getters holds the getter for each version.
+	getters map[string]fetch.ModuleGetter
+	// fetched holds the ModuleInfo of each version that has been fetched.
+	fetched map[string]*internal.ModuleInfo
+}
+
+// majorVersionModules returns the versions of modulePath and of the other
+// major versions of the same module, keyed by module path. The result always
+// has an entry for modulePath.
+func (ds *FetchDataSource) majorVersionModules(ctx context.Context, modulePath string) (map[string]*moduleVersions, error) {
+	mod, err := ds.moduleVersions(ctx, modulePath)
+	if err != nil {
+		return nil, err
+	}
+	mods := map[string]*moduleVersions{modulePath: mod}
+	seriesPath, major := internal.SeriesPathAndMajorVersion(modulePath)
+	if modulePath == stdlib.ModulePath || seriesPath == "" {
+		return mods, nil
+	}
+	add := func(p string) (bool, error) {
+		if _, ok := mods[p]; ok {
+			return true, nil
+		}
+		mod, err := ds.moduleVersions(ctx, p)
+		if err != nil {
+			return false, err
+		}
+		if len(mod.getters) == 0 {
+			return false, nil
+		}
+		mods[p] = mod
+		return true, nil
+	}
+
+	for mv := range ds.cache.Entries() {
+		if mv.Path != modulePath && internal.SeriesPathForModule(mv.Path) == seriesPath {
+			if _, err := add(mv.Path); err != nil {
+				return nil, err
+			}
+		}
+	}
+	// Look for major versions up to the first one after the module's own
+	// that no getter knows about, as getLatestMajorVersion does.
+	gopkgin := strings.HasPrefix(seriesPath, "gopkg.in/")
+	n := 1
+	if gopkgin {
+		n = 0
+	}
+	for ; ; n++ {
+		var p string
+		switch {
+		case gopkgin:
+			p = fmt.Sprintf("%s.v%d", seriesPath, n)
+		case n <= 1:
+			p = seriesPath
+		default:
+			p = fmt.Sprintf("%s/v%d", seriesPath, n)
+		}
+		found, err := add(p)
+		if err != nil {
+			// Other major versions are not essential.
+			log.Warningf(ctx, "GetVersionsForPath: %v", err)
+			break
+		}
+		if !found && n > major {
+			break
+		}
+	}
+	return mods, nil
+}
+
+// moduleVersions returns the versions of modulePath listed by the getters or
+// already fetched. Fetched modules come first, so that we use their
+// ModuleInfo rather than asking the getter again.
+func (ds *FetchDataSource) moduleVersions(ctx context.Context, modulePath string) (*moduleVersions, error) {
+	_, major := internal.SeriesPathAndMajorVersion(modulePath)
+	mod := &moduleVersions{
+		modulePath: modulePath,
+		major:      major,
+		getters:    map[string]fetch.ModuleGetter{},
+		fetched:    map[string]*internal.ModuleInfo{},
+	}
+	for mv, e := range ds.cache.Entries() {
+		if mv.Path != modulePath || e.module == nil {
+			continue
+		}
+		mi := e.module.ModuleInfo
+		mod.getters[mi.Version] = e.g
+		mod.fetched[mi.Version] = &mi
+	}
+	for _, g := range ds.opts.Getters {
+		vg, ok := g.(fetch.VersionListingModuleGetter)
+		if !ok {
+			continue
+		}
+		vs, err := vg.Versions(ctx, modulePath)
+		if errors.Is(err, derrors.NotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		for _, v := range vs {
+			if _, ok := mod.getters[v]; !ok {
+				mod.getters[v] = g
+			}
+		}
+	}
+	return mod, nil
+}
+
+// latestModuleVersions returns a LatestModuleVersions for modulePath, whose
+// go.mod file determines which versions are retracted and whether the module
+// is deprecated. As with the go command, only the go.mod file of the latest
+// version counts. If the proxy is available it is asked for the latest version;
+// otherwise, the go.mod file of the first of versions that has one is used.
+// latestModuleVersions returns nil if there is no go.mod file to use.
+func (ds *FetchDataSource) latestModuleVersions(ctx context.Context, modulePath string, versions []string, getters map[string]fetch.ModuleGetter) *internal.LatestModuleVersions {
+	if ds.opts.ProxyClientForLatest != nil {
+		// Ignore errors, as fetchAndCache does: the proxy may not know about
+		// the module.
+		if lmv, err := fetch.LatestModuleVersions(ctx, modulePath, ds.opts.ProxyClientForLatest, nil); err == nil && lmv != nil {
+			return lmv
+		}
+	}
+	for _, v := range versions {
+		modBytes, err := getters[v].Mod(ctx, modulePath, v)
+		if err != nil {
+			log.Warningf(ctx, "GetVersionsForPath: %v", err)
+			continue
+		}
+		lmv, err := internal.NewLatestModuleVersions(modulePath, v, v, "", modBytes)
+		if err != nil {
+			log.Warningf(ctx, "GetVersionsForPath: %s@%s: %v", modulePath, v, err)
+			continue
+		}
+		return lmv
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fetchdatasource
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/pkgsite/internal/fetch"
+	"golang.org/x/pkgsite/internal/proxy/proxytest"
+	"golang.org/x/pkgsite/internal/source"
+)
+
+func TestGetVersionsForPath(t *testing.T) {
+	ctx := context.Background()
+	client, teardown := proxytest.SetupTestClient(t, defaultTestModules)
+	defer teardown()
+	getters := []fetch.ModuleGetter{fetch.NewProxyModuleGetter(client, source.NewClientForTesting())}
+
+	type version struct {
+		// ModulePath is empty if it is the module path of the test.
+		ModulePath          string
+		Version             string
+		Retracted           bool
+		RetractionRationale string
+		Deprecated          bool
+		DeprecationComment  string
+	}
+	for _, withProxyForLatest := range []bool{false, true} {
+		opts := Options{Getters: getters}
+		if withProxyForLatest {
+			opts.ProxyClientForLatest = client
+		}
+		ds := opts.New()
+		for _, test := range []struct {
+			path, modulePath string
+			want             []version
+		}{
+			{
+				path:       "example.com/retractions",
+				modulePath: "example.com/retractions",
+				want: []version{
+					{Version: "v1.2.0", Retracted: true, RetractionRationale: "bad"},
+					{Version: "v1.1.0", Retracted: true, RetractionRationale: "worse"},
+					// v1.0.0 retracts itself, but only the latest go.mod counts.
+					{Version: "v1.0.0"},
+				},
+			},
+			{
+				path:       "example.com/deprecated",
+				modulePath: "example.com/deprecated",
+				want: []version{
+					{Version: "v1.1.0", Deprecated: true, DeprecationComment: "use something else"},
+					{Version: "v1.0.0", Deprecated: true, DeprecationComment: "use something else"},
+				},
+			},
+			{
+				path:       "example.com/symbols/hello",
+				modulePath: "example.com/symbols",
+				want:       []version{{Version: "v1.2.0"}, {Version: "v1.1.0"}, {Version: "v1.0.0"}},
+			},
+			{
+				// Other major versions are included, latest first.
+				path:       "rsc.io/quote/v3",
+				modulePath: "rsc.io/quote/v3",
+				want: []version{
+					{Version: "v3.1.0"},
+					{Version: "v3.0.0"},
+					{ModulePath: "rsc.io/quote", Version: "v1.5.0"},
+					{ModulePath: "rsc.io/quote", Version: "v1.4.0"},
+					{ModulePath: "rsc.io/quote", Version: "v1.3.0"},
+					{ModulePath: "rsc.io/quote", Version: "v1.2.0"},
+					{ModulePath: "rsc.io/quote", Version: "v1.1.0"},
+					{ModulePath: "rsc.io/quote", Version: "v1.0.0"},
+				},
+			},
+		} {
+			mis, err := ds.GetVersionsForPath(ctx, test.path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []version
+			for _, mi := range mis {
+				if mi.CommitTime.IsZero() {
+					t.Errorf("%s@%s: no commit time", test.path, mi.Version)
+				}
+				modulePath := mi.ModulePath
+				if modulePath == test.modulePath {
+					modulePath = ""
+				}
+				got = append(got, version{modulePath, mi.Version, mi.Retracted, mi.RetractionRationale, mi.Deprecated, mi.DeprecationComment})
+			}
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Errorf("%s, proxy for latest=%t: mismatch (-want +got):\n%s", test.path, withProxyForLatest, diff)
+			}
+		}
+	}
+}
@@ -0,0 +1,150 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package frontend
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/semver"
+	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/derrors"
+	"golang.org/x/pkgsite/internal/fetch"
+	"golang.org/x/pkgsite/internal/frontend/serrors"
+	"golang.org/x/pkgsite/internal/frontend/versions"
+	"golang.org/x/pkgsite/internal/symbol"
+	"golang.org/x/pkgsite/internal/version"
+)
+
+// APIDiffDetails contains the changes to the exported API of a package since
+// the latest release that precedes it. For a package in a local working copy,
+// that is the latest release of its module.
+type APIDiffDetails struct {
+	*symbol.APIDiff
+
+	// BaseVersion is the release that the package is compared with, or the
+	// empty string if there is none.
+	BaseVersion string
+
+	// BaseURL is the URL of the package at BaseVersion, or the empty string if
+	// the package is not in that version of its module.
+	BaseURL string
+
+	// Incompatible reports whether any of the changes is incompatible.
+	Incompatible bool
+
+	// NewModulePath is the module path that a release containing the changes
+	// must use, because they are incompatible. It is empty if the changes
+	// don't need a new major version, including when BaseVersion is a v0
+	// version, which carries no compatibility promise.
+	NewModulePath string
+}
+
+// fetchAPIDiffDetails returns the API changes for the package described by um.
+func fetchAPIDiffDetails(ctx context.Context, ds internal.DataSource, um *internal.UnitMeta) (_ *APIDiffDetails, err error) {
+	defer derrors.Wrap(&err, "fetchAPIDiffDetails(%q, %q, %q)", um.Path, um.ModulePath, um.Version)
+
+	// The database does not keep the API of each version of a unit.
+	if _, ok := ds.(internal.PostgresDB); ok {
+		return nil, serrors.DatasourceNotSupportedError()
+	}
+	vds, ok := ds.(internal.VersionsDataSource)
+	if !ok {
+		return nil, serrors.DatasourceNotSupportedError()
+	}
+	mis, err := vds.GetVersionsForPath(ctx, um.Path)
+	if err != nil {
+		return nil, err
+	}
+	var candidates []string
+	for _, mi := range mis {
+		v := mi.Version
+		if mi.ModulePath != um.ModulePath || mi.Retracted || version.IsPseudo(v) || v == um.Version || v == fetch.LocalVersion {
+			continue
+		}
+		// A local working copy has no version of its own, and comes after
+		// every release.
+		if um.Version != fetch.LocalVersion && semver.Compare(v, um.Version) > 0 {
+			continue
+		}
+		candidates = append(candidates, v)
+	}
+	details := &APIDiffDetails{
+		APIDiff:     &symbol.APIDiff{},
+		BaseVersion: version.LatestOf(candidates),
+	}
+	if details.BaseVersion == "" {
+		return details, nil
+	}
+
+	sh := internal.NewSymbolHistory()
+	if err := addAPISymbols(ctx, ds, um, sh); err != nil {
+		return nil, err
+	}
+	baseUM, err := ds.GetUnitMeta(ctx, um.Path, um.ModulePath, details.BaseVersion)
+	switch {
+	case errors.Is(err, derrors.NotFound):
+		// The package is new, so all of its symbols were added.
+	case err != nil:
+		return nil, err
+	default:
+		if err := addAPISymbols(ctx, ds, baseUM, sh); err != nil {
+			return nil, err
+		}
+		details.BaseURL = versions.ConstructUnitURL(um.Path, um.ModulePath, details.BaseVersion)
+	}
+	details.APIDiff = symbol.DiffAPI(sh, details.BaseVersion, um.Version)
+	details.Incompatible = details.APIDiff.Incompatible()
+	if details.Incompatible && semver.Major(details.BaseVersion) != "v0" {
+		details.NewModulePath = nextMajorModulePath(um.ModulePath, details.BaseVersion)
+	}
+	return details, nil
+}
+
+// addAPISymbols adds the symbols of the package described by um, in each of
+// its build contexts, to sh.
+func addAPISymbols(ctx context.Context, ds internal.DataSource, um *internal.UnitMeta, sh *internal.SymbolHistory) error {
+	u, err := ds.GetUnit(ctx, um, internal.WithMain, internal.BuildContext{})
+	if err != nil {
+		return err
+	}
+	for _, bc := range u.BuildContexts {
+		bu, err := ds.GetUnit(ctx, um, internal.WithMain, bc)
+		if err != nil {
+			return err
+		}
+		for _, d := range bu.Documentation {
+			for _, s := range d.API {
+				sh.AddSymbol(s.SymbolMeta, um.Version, d.BuildContext())
+				for _, c := range s.Children {
+					sh.AddSymbol(*c, um.Version, d.BuildContext())
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// nextMajorModulePath returns the module path for the major version after that
+// of v, which is a version of the module modulePath.
+func nextMajorModulePath(modulePath, v string) string {
+	prefix, pathMajor, ok := module.SplitPathVersion(modulePath)
+	if !ok {
+		return ""
+	}
+	n, err := strconv.Atoi(strings.TrimPrefix(semver.Major(v), "v"))
+	if err != nil {
+		return ""
+	}
+	if strings.HasPrefix(pathMajor, ".") {
+		// gopkg.in paths have the form gopkg.in/name.vN.
+		return fmt.Sprintf("%s.v%d", prefix, n+1)
+	}
+	return fmt.Sprintf("%s/v%d", prefix, n+1)
+}
@@ -0,0 +1,22 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package frontend
+
+import "testing"
+
+func TestNextMajorModulePath(t *testing.T) {
+	for _, test := range []struct {
+		modulePath, version, want string
+	}{
+		{"example.com/m", "v1.2.3", "example.com/m/v2"},
+		{"example.com/m/v2", "v2.0.0", "example.com/m/v3"},
+		{"gopkg.in/yaml.v3", "v3.0.1", "gopkg.in/yaml.v4"},
+		{"example.com/m", "v2.0.0+incompatible", "example.com/m/v3"},
+	} {
+		if got := nextMajorModulePath(test.modulePath, test.version); got != test.want {
+			t.Errorf("nextMajorModulePath(%q, %q) = %q, want %q", test.modulePath, test.version, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,178 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package frontend
+
+import (
+	"context"
+	"path"
+	"sort"
+
+	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/derrors"
+	"golang.org/x/pkgsite/internal/frontend/versions"
+	"golang.org/x/pkgsite/internal/godoc"
+	"golang.org/x/pkgsite/internal/godoc/dochtml"
+)
+
+// BuildContextsDetails merges the documentation of a package in each of its
+// build contexts, to show which symbols and files are in which contexts.
+type BuildContextsDetails struct {
+	// BuildContexts are the build contexts that the package has
+	// documentation for. If it is the same in all of them, there is only
+	// BuildContextAll.
+	BuildContexts []internal.BuildContext
+
+	// Symbols are the exported symbols of the package in any build context,
+	// sorted by name, with methods and fields after their types.
+	Symbols []*BuildContextsSymbol
+
+	// Files are the non-test .go files of the package in any build context,
+	// sorted by name.
+	Files []*BuildContextsFile
+
+	// NumPartial is the number of symbols that are not in every build
+	// context.
+	NumPartial int
+}
+
+// A BuildContextsSymbol is a symbol on the build contexts tab.
+type BuildContextsSymbol struct {
+	Name string
+	Kind internal.SymbolKind
+	// URL is the URL of the symbol's documentation, in the first build
+	// context that has it.
+	URL string
+	// In reports whether the symbol is in each of the build contexts of the
+	// BuildContextsDetails.
+	In []bool
+	// All reports whether the symbol is in every build context.
+	All bool
+}
+
+// A BuildContextsFile is a file on the build contexts tab.
+type BuildContextsFile struct {
+	Name string
+	// URL is the URL of the file's source, if known.
+	URL string
+	// Constraint is the expression of the file's //go:build line, or the
+	// empty string if it has none.
+	Constraint string
+	// In and All are as for BuildContextsSymbol.
+	In  []bool
+	All bool
+}
+
+// fetchBuildContextsDetails returns the symbols and files of the package
+// described by um in each of its build contexts.
+func fetchBuildContextsDetails(ctx context.Context, ds internal.DataSource, um *internal.UnitMeta) (_ *BuildContextsDetails, err error) {
+	defer derrors.Wrap(&err, "fetchBuildContextsDetails(%q, %q, %q)", um.Path, um.ModulePath, um.Version)
+
+	u, err := ds.GetUnit(ctx, um, internal.WithMain, internal.BuildContext{})
+	if err != nil {
+		return nil, err
+	}
+	details := &BuildContextsDetails{}
+	var (
+		symbols = map[string]*BuildContextsSymbol{}
+		files   = map[string]*BuildContextsFile{}
+	)
+	unitURL := versions.ConstructUnitURL(um.Path, um.ModulePath, um.Version)
+	innerPath := internal.Suffix(um.Path, um.ModulePath)
+	for _, bc := range u.BuildContexts {
+		bu, err := ds.GetUnit(ctx, um, internal.WithMain, bc)
+		if err != nil {
+			return nil, err
+		}
+		if len(bu.Documentation) == 0 || len(bu.Documentation[0].Source) == 0 {
+			continue
+		}
+		docPkg, err := godoc.DecodePackage(bu.Documentation[0].Source)
+		if err != nil {
+			return nil, err
+		}
+		i := len(details.BuildContexts)
+		details.BuildContexts = append(details.BuildContexts, bc)
+
+		constraints := docPkg.FileConstraints()
+		for _, name := range docPkg.FileNames() {
+			f := files[name]
+			if f == nil {
+				f = &BuildContextsFile{
+					Name:       name,
+					URL:        um.SourceInfo.FileURL(path.Join(innerPath, name)),
+					Constraint: constraints[name],
+				}
+				files[name] = f
+			}
+			f.In = setIn(f.In, i)
+		}
+
+		// DocInfo destroys docPkg, so call it last.
+		modInfo := &godoc.ModuleInfo{ModulePath: um.ModulePath, ResolvedVersion: um.Version}
+		_, _, api, err := docPkg.DocInfo(ctx, innerPath, um.SourceInfo, modInfo)
+		if err != nil {
+			return nil, err
+		}
+		for _, s := range api {
+			addBuildContextsSymbol(symbols, &s.SymbolMeta, i, unitURL+dochtml.LinkQuery(bc))
+			for _, c := range s.Children {
+				addBuildContextsSymbol(symbols, c, i, unitURL+dochtml.LinkQuery(bc))
+			}
+		}
+	}
+
+	n := len(details.BuildContexts)
+	for _, s := range symbols {
+		s.In, s.All = fillIn(s.In, n)
+		if !s.All {
+			details.NumPartial++
+		}
+		details.Symbols = append(details.Symbols, s)
+	}
+	sort.Slice(details.Symbols, func(i, j int) bool {
+		return details.Symbols[i].Name < details.Symbols[j].Name
+	})
+	for _, f := range files {
+		f.In, f.All = fillIn(f.In, n)
+		details.Files = append(details.Files, f)
+	}
+	sort.Slice(details.Files, func(i, j int) bool {
+		return details.Files[i].Name < details.Files[j].Name
+	})
+	return details, nil
+}
+
+// addBuildContextsSymbol records that the symbol sm is in the build context
+// with index i, whose documentation is at the URL docURL.
+func addBuildContextsSymbol(symbols map[string]*BuildContextsSymbol, sm *internal.SymbolMeta, i int, docURL string) {
+	s := symbols[sm.Name]
+	if s == nil {
+		s = &BuildContextsSymbol{Name: sm.Name, Kind: sm.Kind, URL: docURL + "#" + sm.Name}
+		symbols[sm.Name] = s
+	}
+	s.In = setIn(s.In, i)
+}
+
+// setIn sets in[i] to true, growing in if necessary.
+func setIn(in []bool, i int) []bool {
+	for len(in) <= i {
+		in = append(in, false)
+	}
+	in[i] = true
+	return in
+}
+
+// fillIn extends in to length n, and reports whether all of its elements are
+// true.
+func fillIn(in []bool, n int) ([]bool, bool) {
+	for len(in) < n {
+		in = append(in, false)
+	}
+	all := true
+	for _, b := range in {
+		all = all && b
+	}
+	return in, all
+}
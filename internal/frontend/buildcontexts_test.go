@@ -0,0 +1,106 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package frontend
+
+import (
+	"context"
+	"go/parser"
+	"go/token"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/godoc"
+	"golang.org/x/pkgsite/internal/testing/fakedatasource"
+	"golang.org/x/pkgsite/internal/testing/sample"
+)
+
+func TestFetchBuildContextsDetails(t *testing.T) {
+	ctx := context.Background()
+
+	// documentation returns the documentation for bc of a package with the
+	// given files.
+	documentation := func(bc internal.BuildContext, files map[string]string) *internal.Documentation {
+		fset := token.NewFileSet()
+		p := godoc.NewPackage(fset, nil)
+		for name, contents := range files {
+			f, err := parser.ParseFile(fset, name, contents, parser.ParseComments)
+			if err != nil {
+				t.Fatal(err)
+			}
+			p.AddFile(f, true)
+		}
+		src, err := p.Encode(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return &internal.Documentation{GOOS: bc.GOOS, GOARCH: bc.GOARCH, Source: src}
+	}
+
+	const (
+		common = `package foo
+
+// T is a type.
+type T int
+
+// M is a method.
+func (T) M() {}
+`
+		linux = `//go:build linux
+
+package foo
+
+// Splice is only on Linux.
+func Splice() {}
+`
+		windows = `//go:build windows
+
+package foo
+
+// Handle is only on Windows.
+type Handle uintptr
+`
+	)
+	m := sample.Module(sample.ModulePath, sample.VersionString, "foo")
+	u := m.Units[1]
+	u.BuildContexts = []internal.BuildContext{internal.BuildContextLinux, internal.BuildContextWindows}
+	u.Documentation = []*internal.Documentation{
+		documentation(internal.BuildContextLinux, map[string]string{"foo.go": common, "foo_linux.go": linux}),
+		documentation(internal.BuildContextWindows, map[string]string{"foo.go": common, "foo_windows.go": windows}),
+	}
+	ds := fakedatasource.New()
+	ds.MustInsertModule(ctx, m)
+	um, err := ds.GetUnitMeta(ctx, u.Path, u.ModulePath, u.Version)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := fetchBuildContextsDetails(ctx, ds, um)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	unitURL := "/" + u.ModulePath + "@" + u.Version + "/foo"
+	fileURL := func(name string) string {
+		return u.SourceInfo.FileURL(internal.Suffix(u.Path, u.ModulePath) + "/" + name)
+	}
+	want := &BuildContextsDetails{
+		BuildContexts: u.BuildContexts,
+		Symbols: []*BuildContextsSymbol{
+			{Name: "Handle", Kind: internal.SymbolKindType, URL: unitURL + "?GOOS=windows#Handle", In: []bool{false, true}},
+			{Name: "Splice", Kind: internal.SymbolKindFunction, URL: unitURL + "?GOOS=linux#Splice", In: []bool{true, false}},
+			{Name: "T", Kind: internal.SymbolKindType, URL: unitURL + "?GOOS=linux#T", In: []bool{true, true}, All: true},
+			{Name: "T.M", Kind: internal.SymbolKindMethod, URL: unitURL + "?GOOS=linux#T.M", In: []bool{true, true}, All: true},
+		},
+		Files: []*BuildContextsFile{
+			{Name: "foo.go", URL: fileURL("foo.go"), In: []bool{true, true}, All: true},
+			{Name: "foo_linux.go", URL: fileURL("foo_linux.go"), Constraint: "linux", In: []bool{true, false}},
+			{Name: "foo_windows.go", URL: fileURL("foo_windows.go"), Constraint: "windows", In: []bool{false, true}},
+		},
+		NumPartial: 2,
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("mismatch (-want, +got):\n%s", diff)
+	}
+}
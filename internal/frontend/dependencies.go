@@ -0,0 +1,104 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package frontend
+
+import (
+	"context"
+	"errors"
+
+	"golang.org/x/mod/module"
+	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/derrors"
+	"golang.org/x/pkgsite/internal/frontend/serrors"
+	"golang.org/x/pkgsite/internal/frontend/versions"
+)
+
+// DependenciesDetails contains the requirements of a module version, as listed
+// in its go.mod file, and the module versions that require it.
+type DependenciesDetails struct {
+	// ModulePath and Version identify the module version.
+	ModulePath, Version string
+
+	// HasGoMod reports whether the module version has a go.mod file. If not,
+	// the other fields are empty.
+	HasGoMod bool
+
+	// Requires are the direct requirements of the module.
+	Requires []*Dependency
+
+	// NumIndirect is the number of requirements marked "// indirect".
+	NumIndirect int
+
+	// Replaces and Excludes are the replace and exclude directives of the
+	// go.mod file.
+	Replaces []*internal.ModuleReplace
+	Excludes []*internal.ModuleExclude
+
+	// RequiredBy are the known module versions that require this one.
+	RequiredBy []*Dependency
+}
+
+// Dependency is a module version on the dependencies tab.
+type Dependency struct {
+	ModulePath, Version string
+	// URL is the URL of the module version's page.
+	URL string
+	// Indirect reports whether the requirement is marked "// indirect".
+	Indirect bool
+}
+
+// dependentsLimit is the maximum number of module versions listed as
+// requiring a module version.
+// Variable for testing.
+var dependentsLimit = 1000
+
+// fetchDependenciesDetails returns the requirements of the module containing
+// the unit described by um, and the module versions that require it.
+func fetchDependenciesDetails(ctx context.Context, ds internal.DataSource, um *internal.UnitMeta) (_ *DependenciesDetails, err error) {
+	defer derrors.Wrap(&err, "fetchDependenciesDetails(%q, %q)", um.ModulePath, um.Version)
+
+	dds, ok := ds.(internal.DependenciesDataSource)
+	if !ok {
+		return nil, serrors.DatasourceNotSupportedError()
+	}
+	details := &DependenciesDetails{ModulePath: um.ModulePath, Version: um.Version}
+	gm, err := dds.GetGoModDirectives(ctx, um.ModulePath, um.Version)
+	switch {
+	case errors.Is(err, derrors.NotFound):
+		// No go.mod file, so no requirements.
+	case err != nil:
+		return nil, err
+	default:
+		details.HasGoMod = true
+		for _, r := range gm.Requires {
+			if r.Indirect {
+				details.NumIndirect++
+				continue
+			}
+			details.Requires = append(details.Requires, newDependency(r.ModulePath, r.Version, false))
+		}
+		details.Replaces = gm.Replaces
+		details.Excludes = gm.Excludes
+	}
+
+	deps, err := dds.GetModuleDependents(ctx, um.ModulePath, um.Version, dependentsLimit)
+	if err != nil {
+		return nil, err
+	}
+	for _, d := range deps {
+		details.RequiredBy = append(details.RequiredBy, newDependency(d.ModulePath, d.Version, d.Indirect))
+	}
+	return details, nil
+}
+
+func newDependency(modulePath, version string, indirect bool) *Dependency {
+	d := &Dependency{ModulePath: modulePath, Version: version, Indirect: indirect}
+	// Requirements can name modules that pkgsite cannot serve, like those
+	// with invalid paths that are replaced with local directories.
+	if module.CheckPath(modulePath) == nil {
+		d.URL = versions.ConstructUnitURL(modulePath, modulePath, version)
+	}
+	return d
+}
@@ -59,7 +59,7 @@ func (s *Server) serveDetails(w http.ResponseWriter, r *http.Request, ds interna
 			Epage:  epage,
 		}
 	}
-	if !urlinfo.IsSupportedVersion(urlInfo.FullPath, urlInfo.RequestedVersion) {
+	if !resolvesRefs(ds, urlInfo.FullPath) && !urlinfo.IsSupportedVersion(urlInfo.FullPath, urlInfo.RequestedVersion) {
 		return serrors.InvalidVersionError(urlInfo.FullPath, urlInfo.RequestedVersion)
 	}
 	if urlPath := stdlibRedirectURL(urlInfo.FullPath); urlPath != "" {
@@ -72,6 +72,13 @@ func (s *Server) serveDetails(w http.ResponseWriter, r *http.Request, ds interna
 	return s.serveUnitPage(ctx, w, r, ds, urlInfo)
 }
 
+// resolvesRefs reports whether ds can serve the module or package at
+// fullPath at any ref, such as a branch of a git repository.
+func resolvesRefs(ds internal.DataSource, fullPath string) bool {
+	rds, ok := ds.(internal.RefResolvingDataSource)
+	return ok && rds.ResolvesRefs(fullPath)
+}
+
 func stdlibRedirectURL(fullPath string) string {
 	if !strings.HasPrefix(fullPath, stdlib.GitHubRepo) {
 		return ""
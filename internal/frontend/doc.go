@@ -7,8 +7,8 @@ package frontend
 import (
 	"context"
 	"fmt"
+	"net/url"
 	"path"
-	"sort"
 	"strings"
 
 	"golang.org/x/pkgsite/internal"
@@ -21,7 +21,7 @@ import (
 )
 
 func renderDocParts(ctx context.Context, u *internal.Unit, docPkg *godoc.Package,
-	nameToVersion map[string]string, bc internal.BuildContext) (_ *dochtml.Parts, err error) {
+	nameToVersion map[string]string, usedByLink func(string) string, bc internal.BuildContext) (_ *dochtml.Parts, err error) {
 	defer derrors.Wrap(&err, "renderDocParts")
 	defer stats.Elapsed(ctx, "renderDocParts")()
 
@@ -30,28 +30,90 @@ func renderDocParts(ctx context.Context, u *internal.Unit, docPkg *godoc.Package
 		ResolvedVersion: u.Version,
 		ModulePackages:  nil, // will be provided by docPkg
 	}
-	var innerPath string
+	return docPkg.Render(ctx, unitInnerPath(u), u.SourceInfo, modInfo, nameToVersion, usedByLink, bc)
+}
+
+// unitInnerPath returns the path of u relative to its module, as the godoc
+// package expects it.
+func unitInnerPath(u *internal.Unit) string {
 	if u.ModulePath == stdlib.ModulePath {
-		innerPath = u.Path
-	} else if u.Path != u.ModulePath {
-		innerPath = u.Path[len(u.ModulePath)+1:]
+		return u.Path
+	}
+	if u.Path != u.ModulePath {
+		return u.Path[len(u.ModulePath)+1:]
+	}
+	return ""
+}
+
+// renderedDocParts returns the documentation that was rendered for doc when
+// u was fetched, or nil if there is none or it must be rendered again.
+func renderedDocParts(ctx context.Context, u *internal.Unit, doc *internal.Documentation, usedByLink func(string) string, bc internal.BuildContext) *dochtml.Parts {
+	defer stats.Elapsed(ctx, "renderedDocParts")()
+
+	modInfo := &godoc.ModuleInfo{
+		ModulePath:      u.ModulePath,
+		ResolvedVersion: u.Version,
+	}
+	return godoc.RenderedParts(doc.Rendered, u.SourceInfo, modInfo, u.SymbolHistory, usedByLink, bc)
+}
+
+// rerenderDocParts renders doc, decoded into docPkg, as it is rendered when
+// u is fetched, and stores the result in ds so that later requests can serve
+// it. It returns the documentation for bc, or nil if bc is served other
+// HTML than the stored documentation, in which case docPkg is left intact.
+// Rendering destroys docPkg.
+func rerenderDocParts(ctx context.Context, ds internal.RenderedDocDataSource, u *internal.Unit, doc *internal.Documentation, docPkg *godoc.Package, usedByLink func(string) string, bc internal.BuildContext) (_ *dochtml.Parts, err error) {
+	defer derrors.Wrap(&err, "rerenderDocParts")
+	defer stats.Elapsed(ctx, "rerenderDocParts")()
+
+	// The first documentation is rendered for requests without a build
+	// context, as in fetch.prerenderDocs.
+	storedBC := doc.BuildContext()
+	if len(u.BuildContexts) > 0 && storedBC == u.BuildContexts[0] {
+		storedBC = internal.BuildContext{}
+	}
+	if dochtml.LinkQuery(bc) != dochtml.LinkQuery(storedBC) {
+		return nil, nil
+	}
+	modInfo := &godoc.ModuleInfo{
+		ModulePath:      u.ModulePath,
+		ResolvedVersion: u.Version,
+	}
+	r, err := docPkg.Prerender(ctx, unitInnerPath(u), u.SourceInfo, modInfo, storedBC)
+	if err != nil {
+		return nil, err
+	}
+	if err := ds.UpdateRenderedDoc(ctx, u.Path, u.ModulePath, u.Version, doc.BuildContext(), r); err != nil {
+		// The documentation will be rendered again by the next request.
+		log.Errorf(ctx, "rerenderDocParts(%q, %q, %q): %v", u.Path, u.ModulePath, u.Version, err)
+	}
+	return godoc.RenderedParts(r, u.SourceInfo, modInfo, u.SymbolHistory, usedByLink, bc), nil
+}
+
+// usedByLinkFunc returns a function that returns the URL of the list of the
+// importers of a package in the module modulePath that use one of its
+// symbols, relative to the package's page. It returns nil if ds doesn't know
+// which importers use each symbol, or if the module is the standard library,
+// whose uses are not recorded.
+func usedByLinkFunc(ds internal.DataSource, modulePath string) func(name string) string {
+	if _, ok := ds.(internal.SymbolUsedByDataSource); !ok || modulePath == stdlib.ModulePath {
+		return nil
+	}
+	return func(name string) string {
+		return "?" + url.Values{"tab": {"importedby"}, "symbol": {name}}.Encode()
 	}
-	return docPkg.Render(ctx, innerPath, u.SourceInfo, modInfo, nameToVersion, bc)
 }
 
-// sourceFiles returns the .go files for a package.
-func sourceFiles(u *internal.Unit, docPkg *godoc.Package) []*File {
+// sourceFiles returns the .go files for a package, given the names of its
+// non-test files in sorted order.
+func sourceFiles(u *internal.Unit, names []string) []*File {
 	var files []*File
-	for _, f := range docPkg.Files {
-		if strings.HasSuffix(f.Name, "_test.go") {
-			continue
-		}
+	for _, name := range names {
 		files = append(files, &File{
-			Name: f.Name,
-			URL:  u.SourceInfo.FileURL(path.Join(internal.Suffix(u.Path, u.ModulePath), f.Name)),
+			Name: name,
+			URL:  u.SourceInfo.FileURL(path.Join(internal.Suffix(u.Path, u.ModulePath), name)),
 		})
 	}
-	sort.Slice(files, func(i, j int) bool { return files[i].Name < files[j].Name })
 	return files
 }
 
@@ -0,0 +1,116 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package frontend
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/derrors"
+	"golang.org/x/pkgsite/internal/frontend/serrors"
+	"golang.org/x/pkgsite/internal/godoc"
+)
+
+// The formats, other than HTML, in which package documentation can be
+// served, and their content types.
+var docFormatContentTypes = map[string]string{
+	"markdown": "text/markdown; charset=utf-8",
+	"text":     "text/plain; charset=utf-8",
+	"json":     "application/json",
+}
+
+// docFormat returns the format in which r asks for the documentation of a
+// unit, or the empty string for HTML. The format is the value of the "format"
+// query parameter if there is one. Otherwise it is chosen from the media types
+// in the Accept header: the first one that is either HTML or one of the other
+// formats wins. Quality values are ignored.
+func docFormat(r *http.Request) string {
+	if f := r.FormValue("format"); f != "" {
+		return f
+	}
+	for _, mt := range strings.Split(r.Header.Get("Accept"), ",") {
+		mt, _, _ = strings.Cut(mt, ";")
+		switch strings.ToLower(strings.TrimSpace(mt)) {
+		case "text/html", "application/xhtml+xml", "text/*", "*/*":
+			return ""
+		case "text/markdown":
+			return "markdown"
+		case "text/plain":
+			return "text"
+		case "application/json":
+			return "json"
+		}
+	}
+	return ""
+}
+
+// varyOnAccept returns a handler that serves h with a Vary header for the
+// Accept header, from which docFormat chooses the format of the documentation
+// at a unit's URL.
+func varyOnAccept(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Accept")
+		h.ServeHTTP(w, r)
+	})
+}
+
+// serveDocFormat serves the documentation of the package described by um, in
+// the given format.
+func serveDocFormat(ctx context.Context, w http.ResponseWriter, ds internal.DataSource,
+	um *internal.UnitMeta, bc internal.BuildContext, format string) (err error) {
+	defer derrors.Wrap(&err, "serveDocFormat(%q, %q, %q, %q)", um.Path, um.ModulePath, um.Version, format)
+
+	contentType, ok := docFormatContentTypes[format]
+	if !ok {
+		return &serrors.ServerError{
+			Status: http.StatusBadRequest,
+			Err:    fmt.Errorf("unknown format %q; want markdown, text or json", format),
+		}
+	}
+	if !um.IsPackage() {
+		return &serrors.ServerError{
+			Status: http.StatusBadRequest,
+			Err:    errors.New("only packages have documentation"),
+		}
+	}
+	unit, err := ds.GetUnit(ctx, um, internal.WithMain, bc)
+	if err != nil {
+		return err
+	}
+	if !unit.IsRedistributable {
+		return &serrors.ServerError{
+			Status:       http.StatusNotFound,
+			ResponseText: "Documentation not displayed due to license restrictions.",
+		}
+	}
+	if len(unit.Documentation) == 0 || unit.Documentation[0].Source == nil {
+		return &serrors.ServerError{Status: http.StatusNotFound, ResponseText: "Documentation is missing."}
+	}
+	p, err := godoc.RenderTextFromUnit(unit)
+	if err != nil {
+		return err
+	}
+	p.MinGoVersion = unit.Documentation[0].MinGoVersion
+	var out []byte
+	switch format {
+	case "markdown":
+		out = p.Markdown()
+	case "text":
+		out = p.Text()
+	case "json":
+		out, err = json.Marshal(p)
+		if err != nil {
+			return err
+		}
+	}
+	w.Header().Set("Content-Type", contentType)
+	_, err = w.Write(out)
+	return err
+}
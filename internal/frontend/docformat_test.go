@@ -0,0 +1,134 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package frontend
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/safehtml/template"
+	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/testing/fakedatasource"
+	"golang.org/x/pkgsite/internal/testing/sample"
+	"golang.org/x/pkgsite/static"
+	thirdparty "golang.org/x/pkgsite/third_party"
+)
+
+func TestDocFormat(t *testing.T) {
+	for _, test := range []struct {
+		query, accept, want string
+	}{
+		{"", "", ""},
+		{"format=markdown", "", "markdown"},
+		{"format=json", "text/html", "json"},
+		{"", "text/html,application/xhtml+xml,*/*;q=0.8", ""},
+		{"", "text/markdown", "markdown"},
+		{"", "text/plain; charset=utf-8", "text"},
+		{"", "application/json", "json"},
+		{"", "image/png, application/json", "json"},
+		{"", "*/*, text/markdown", ""},
+	} {
+		r := httptest.NewRequest("GET", "/example.com/p?"+test.query, nil)
+		if test.accept != "" {
+			r.Header.Set("Accept", test.accept)
+		}
+		if got := docFormat(r); got != test.want {
+			t.Errorf("query %q, Accept %q: got %q, want %q", test.query, test.accept, got, test.want)
+		}
+	}
+}
+
+func TestServeDocFormat(t *testing.T) {
+	ctx := context.Background()
+	ds := fakedatasource.New()
+	m := sample.Module(sample.ModulePath, sample.VersionString, "foo")
+	m.Packages()[0].Documentation[0].MinGoVersion = "go1.21"
+	ds.MustInsertModule(ctx, m)
+	s, err := NewServer(ServerConfig{
+		DataSourceGetter: func(context.Context) internal.DataSource { return ds },
+		TemplateFS:       template.TrustedFSFromEmbed(static.FS),
+		StaticFS:         static.FS,
+		ThirdPartyFS:     thirdparty.FS,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	mux := http.NewServeMux()
+	s.Install(mux.Handle, nil, nil)
+
+	path := "/" + sample.ModulePath + "/foo"
+	for _, test := range []struct {
+		name        string
+		query       string
+		accept      string
+		wantStatus  int
+		wantType    string
+		wantContent []string
+	}{
+		{
+			name:        "markdown",
+			query:       "format=markdown",
+			wantStatus:  http.StatusOK,
+			wantType:    "text/markdown; charset=utf-8",
+			wantContent: []string{"# package p", "```go\nvar V int\n```"},
+		},
+		{
+			name:        "text",
+			accept:      "text/plain",
+			wantStatus:  http.StatusOK,
+			wantType:    "text/plain; charset=utf-8",
+			wantContent: []string{"Package p is a package.", "VARIABLES\n\nvar V int\n"},
+		},
+		{
+			name:        "json",
+			accept:      "application/json",
+			wantStatus:  http.StatusOK,
+			wantType:    "application/json",
+			wantContent: []string{`"MinGoVersion":"go1.21"`, `"Names":["V"]`},
+		},
+		{
+			name:       "unknown format",
+			query:      "format=pdf",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:        "html",
+			accept:      "text/html",
+			wantStatus:  http.StatusOK,
+			wantType:    "text/html; charset=utf-8",
+			wantContent: []string{"<!DOCTYPE html>"},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", path+"?"+test.query, nil)
+			if test.accept != "" {
+				r.Header.Set("Accept", test.accept)
+			}
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, r)
+			if w.Code != test.wantStatus {
+				t.Fatalf("got status %d, want %d", w.Code, test.wantStatus)
+			}
+			// Caches must not serve one format for a request for another.
+			if got := w.Header().Get("Vary"); got != "Accept" {
+				t.Errorf("got Vary %q, want %q", got, "Accept")
+			}
+			if test.wantType != "" {
+				if got := w.Header().Get("Content-Type"); got != test.wantType {
+					t.Errorf("got Content-Type %q, want %q", got, test.wantType)
+				}
+			}
+			body := w.Body.String()
+			for _, want := range test.wantContent {
+				if !strings.Contains(body, want) {
+					t.Errorf("body does not contain %q:\n%s", want, body)
+				}
+			}
+		})
+	}
+}
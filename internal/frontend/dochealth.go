@@ -0,0 +1,79 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package frontend
+
+import (
+	"context"
+	"path"
+	"sort"
+
+	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/derrors"
+	"golang.org/x/pkgsite/internal/frontend/serrors"
+	"golang.org/x/pkgsite/internal/frontend/versions"
+)
+
+// DocHealthDetails contains the problems found in the documentation of the
+// packages of a module version.
+type DocHealthDetails struct {
+	// ModulePath and Version identify the module version.
+	ModulePath, Version string
+
+	// Packages are the packages with problems, sorted by path.
+	Packages []*PackageDocHealth
+
+	// NumProblems is the number of problems in all the packages.
+	NumProblems int
+}
+
+// PackageDocHealth contains the documentation problems of a package.
+type PackageDocHealth struct {
+	Path string
+	// URL is the URL of the package's page.
+	URL      string
+	Problems []*DocHealthProblem
+}
+
+// A DocHealthProblem is a documentation problem on the doc health tab.
+type DocHealthProblem struct {
+	*internal.DocProblem
+	// SourceURL is the URL of the line with the problem, if known.
+	SourceURL string
+}
+
+// fetchDocHealthDetails returns the documentation problems of the packages in
+// the module containing the unit described by um.
+func fetchDocHealthDetails(ctx context.Context, ds internal.DataSource, um *internal.UnitMeta) (_ *DocHealthDetails, err error) {
+	defer derrors.Wrap(&err, "fetchDocHealthDetails(%q, %q)", um.ModulePath, um.Version)
+
+	dhs, ok := ds.(internal.DocHealthDataSource)
+	if !ok {
+		return nil, serrors.DatasourceNotSupportedError()
+	}
+	problems, err := dhs.GetDocProblems(ctx, um.ModulePath, um.Version)
+	if err != nil {
+		return nil, err
+	}
+	details := &DocHealthDetails{ModulePath: um.ModulePath, Version: um.Version}
+	for pkgPath, dps := range problems {
+		innerPath := internal.Suffix(pkgPath, um.ModulePath)
+		ph := &PackageDocHealth{
+			Path: pkgPath,
+			URL:  versions.ConstructUnitURL(pkgPath, um.ModulePath, um.Version),
+		}
+		for _, dp := range dps {
+			ph.Problems = append(ph.Problems, &DocHealthProblem{
+				DocProblem: dp,
+				SourceURL:  um.SourceInfo.LineURL(path.Join(innerPath, dp.Filename), dp.Line),
+			})
+		}
+		details.Packages = append(details.Packages, ph)
+		details.NumProblems += len(dps)
+	}
+	sort.Slice(details.Packages, func(i, j int) bool {
+		return details.Packages[i].Path < details.Packages[j].Path
+	})
+	return details, nil
+}
@@ -0,0 +1,108 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package frontend
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/derrors"
+	"golang.org/x/pkgsite/internal/docbundle"
+	"golang.org/x/pkgsite/internal/frontend/serrors"
+	"golang.org/x/pkgsite/internal/version"
+)
+
+// maxDownloadModules is the largest number of modules whose documentation
+// can be downloaded together.
+const maxDownloadModules = 10
+
+// serveDownload serves the documentation of one or more module versions for
+// reading offline. The modules are named by "module" query parameters of the
+// form <module>@<version>, where the version is optional and defaults to the
+// latest one. The "format" query parameter is "docset" (the default) for a
+// Dash or Zeal docset in a gzipped tar file, or "html" for a single HTML file.
+//
+// For example, /download?module=golang.org/x/text@v0.14.0&format=html.
+//
+// It is only served in local mode.
+func (s *Server) serveDownload(w http.ResponseWriter, r *http.Request, ds internal.DataSource) (err error) {
+	defer derrors.Wrap(&err, "serveDownload(%q)", r.URL.RawQuery)
+
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return &serrors.ServerError{Status: http.StatusMethodNotAllowed}
+	}
+	mods := r.URL.Query()["module"]
+	if len(mods) == 0 || len(mods) > maxDownloadModules {
+		return &serrors.ServerError{
+			Status: http.StatusBadRequest,
+			Err:    fmt.Errorf("want between 1 and %d module parameters, got %d", maxDownloadModules, len(mods)),
+		}
+	}
+	format := r.FormValue("format")
+	if format == "" {
+		format = "docset"
+	}
+	if format != "docset" && format != "html" {
+		return &serrors.ServerError{
+			Status: http.StatusBadRequest,
+			Err:    fmt.Errorf("unknown format %q; want docset or html", format),
+		}
+	}
+
+	var (
+		pkgs  []*docbundle.Package
+		names []string
+		seen  = map[string]bool{}
+	)
+	for _, m := range mods {
+		modulePath, requestedVersion, _ := strings.Cut(m, "@")
+		if requestedVersion == "" {
+			requestedVersion = version.Latest
+		}
+		if modulePath == "" || seen[modulePath] {
+			return &serrors.ServerError{
+				Status: http.StatusBadRequest,
+				Err:    fmt.Errorf("invalid or repeated module %q", m),
+			}
+		}
+		seen[modulePath] = true
+		mpkgs, err := docbundle.Load(r.Context(), ds, modulePath, requestedVersion)
+		if err != nil {
+			if errors.Is(err, derrors.NotFound) {
+				return &serrors.ServerError{Status: http.StatusNotFound, Err: err}
+			}
+			return err
+		}
+		if len(mpkgs) == 0 {
+			return &serrors.ServerError{
+				Status:       http.StatusNotFound,
+				ResponseText: fmt.Sprintf("%s has no documentation to download.", m),
+			}
+		}
+		pkgs = append(pkgs, mpkgs...)
+		names = append(names, modulePath+"@"+mpkgs[0].Version)
+	}
+
+	name := strings.Join(names, " ")
+	var buf bytes.Buffer
+	contentType, file := "application/gzip", docbundle.BaseName(name)+".docset.tgz"
+	if format == "html" {
+		contentType, file = "text/html; charset=utf-8", docbundle.BaseName(name)+".html"
+		err = docbundle.WriteHTML(&buf, name, pkgs)
+	} else {
+		err = docbundle.WriteDocset(&buf, name, pkgs)
+	}
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", file))
+	_, err = w.Write(buf.Bytes())
+	return err
+}
@@ -0,0 +1,132 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package frontend
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/safehtml/template"
+	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/testing/fakedatasource"
+	"golang.org/x/pkgsite/internal/testing/sample"
+	"golang.org/x/pkgsite/static"
+	thirdparty "golang.org/x/pkgsite/third_party"
+)
+
+func TestServeDownload(t *testing.T) {
+	ctx := context.Background()
+	ds := fakedatasource.New()
+	ds.MustInsertModule(ctx, sample.Module(sample.ModulePath, sample.VersionString, "foo"))
+	ds.MustInsertModule(ctx, sample.Module("example.com/other", "v0.2.0", "bar"))
+	s, err := NewServer(ServerConfig{
+		DataSourceGetter: func(context.Context) internal.DataSource { return ds },
+		TemplateFS:       template.TrustedFSFromEmbed(static.FS),
+		StaticFS:         static.FS,
+		ThirdPartyFS:     thirdparty.FS,
+		LocalMode:        true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	mux := http.NewServeMux()
+	s.Install(mux.Handle, nil, nil)
+
+	for _, test := range []struct {
+		name            string
+		query           string
+		wantStatus      int
+		wantType        string
+		wantDisposition string
+		wantContent     []string
+	}{
+		{
+			name:            "docset",
+			query:           "module=" + sample.ModulePath,
+			wantStatus:      http.StatusOK,
+			wantType:        "application/gzip",
+			wantDisposition: `attachment; filename="github.com_valid_module_name_v1.0.0.docset.tgz"`,
+		},
+		{
+			name:            "html",
+			query:           "module=" + sample.ModulePath + "@v1.0.0&module=example.com/other&format=html",
+			wantStatus:      http.StatusOK,
+			wantType:        "text/html; charset=utf-8",
+			wantDisposition: `attachment; filename="github.com_valid_module_name_v1.0.0_example.com_other_v0.2.0.html"`,
+			wantContent: []string{
+				`<section id="github.com/valid/module_name/foo">`,
+				`<section id="example.com/other/bar">`,
+			},
+		},
+		{
+			name:       "no module",
+			query:      "format=html",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "repeated module",
+			query:      "module=example.com/other&module=example.com/other@v0.2.0",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "unknown format",
+			query:      "module=example.com/other&format=pdf",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "unknown module",
+			query:      "module=example.com/unknown",
+			wantStatus: http.StatusNotFound,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, httptest.NewRequest("GET", "/download?"+test.query, nil))
+			if w.Code != test.wantStatus {
+				t.Fatalf("got status %d, want %d", w.Code, test.wantStatus)
+			}
+			if test.wantType != "" {
+				if got := w.Header().Get("Content-Type"); got != test.wantType {
+					t.Errorf("got Content-Type %q, want %q", got, test.wantType)
+				}
+			}
+			if got := w.Header().Get("Content-Disposition"); got != test.wantDisposition {
+				t.Errorf("got Content-Disposition %q, want %q", got, test.wantDisposition)
+			}
+			body := w.Body.String()
+			for _, want := range test.wantContent {
+				if !strings.Contains(body, want) {
+					t.Errorf("body does not contain %q:\n%s", want, body)
+				}
+			}
+		})
+	}
+}
+
+func TestServeDownloadNotLocal(t *testing.T) {
+	ctx := context.Background()
+	ds := fakedatasource.New()
+	ds.MustInsertModule(ctx, sample.Module(sample.ModulePath, sample.VersionString, "foo"))
+	s, err := NewServer(ServerConfig{
+		DataSourceGetter: func(context.Context) internal.DataSource { return ds },
+		TemplateFS:       template.TrustedFSFromEmbed(static.FS),
+		StaticFS:         static.FS,
+		ThirdPartyFS:     thirdparty.FS,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	mux := http.NewServeMux()
+	s.Install(mux.Handle, nil, nil)
+
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("GET", "/download?module="+sample.ModulePath, nil))
+	if got := w.Header().Get("Content-Disposition"); w.Code == http.StatusOK || got != "" {
+		t.Errorf("got status %d and Content-Disposition %q, want no download", w.Code, got)
+	}
+}
@@ -448,6 +448,46 @@ func TestServer404Redirect_NoLoop(t *testing.T) {
 	}
 }
 
+func TestServerSuggestsAlternative(t *testing.T) {
+	ctx := context.Background()
+	defer postgres.ResetTestDB(testDB, t)
+
+	const (
+		deprecatedPath = "example.com/deprecated"
+		forkPath       = "example.com/fork"
+		altPath        = "example.com/original"
+	)
+	postgres.MustInsertModule(ctx, t, testDB, sample.Module(deprecatedPath, sample.VersionString, "pkg"))
+	for _, a := range []*postgres.AlternativeModulePath{
+		{ModulePath: deprecatedPath, Alternative: altPath, Source: postgres.AlternativeSourceDeprecated},
+		{ModulePath: forkPath, Alternative: altPath, Source: postgres.AlternativeSourceAdmin},
+	} {
+		if err := testDB.InsertAlternativeModulePath(ctx, a); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	_, _, handler, _ := newTestServerWithFetch(t, nil, nil)
+	for _, test := range []struct {
+		name, path, selector string
+		status               int
+	}{
+		{"unit page", "/" + deprecatedPath + "/pkg", `[data-test-id="UnitHeader-alternativeBanner"]`, http.StatusOK},
+		{"404 page", "/" + forkPath + "/pkg", `[data-test-id="alternative-suggestion"]`, http.StatusNotFound},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, httptest.NewRequest("GET", test.path, nil))
+			if w.Code != test.status {
+				t.Fatalf("%q: got status code = %d, want %d", test.path, w.Code, test.status)
+			}
+			if err := checkBody(w.Result().Body, in(test.selector, in("a", href("/"+altPath)))); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
 func TestEmptyDirectoryBetweenNestedModulesRedirect(t *testing.T) {
 	ctx := context.Background()
 	defer postgres.ResetTestDB(testDB, t)
@@ -276,6 +276,11 @@ func resultFromFetchRequest(results []*fetchResult, fullPath, requestedVersion s
 			}
 			fr.responseText = h.String()
 			return fr, nil
+		case derrors.ToStatus(derrors.ChecksumMismatch):
+			fr.status = http.StatusNotFound
+			fr.responseText = fmt.Sprintf("%s could not be verified against the checksum database.",
+				displayPath(fullPath, requestedVersion))
+			return fr, nil
 		case derrors.ToStatus(derrors.BadModule):
 			// There are 3 categories of 490 errors that we see:
 			// - module contains 0 packages
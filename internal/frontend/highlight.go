@@ -0,0 +1,270 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package frontend
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/scanner"
+	"go/token"
+	"go/types"
+	"strconv"
+	"strings"
+
+	"github.com/google/safehtml"
+	"github.com/google/safehtml/uncheckedconversions"
+	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/frontend/versions"
+	"golang.org/x/pkgsite/internal/stdlib"
+)
+
+// A SourceLine is a line of a file shown in the source browser.
+type SourceLine struct {
+	Number int
+	ID     safehtml.Identifier // "L" followed by the number
+	Tokens []*SourceToken
+}
+
+// A SourceToken is a span of text on a SourceLine.
+type SourceToken struct {
+	Text string
+	// Class is the kind of Go token the text belongs to, one of "comment",
+	// "keyword", "string" and "number", or empty for anything else.
+	Class string
+	// Href is the URL of the documentation of the identifier, or empty.
+	Href string
+}
+
+// plainSourceLines splits src into lines without highlighting.
+func plainSourceLines(src string) []*SourceLine {
+	var sl sourceLines
+	sl.add(src, "", "")
+	return sl.result()
+}
+
+// highlightGo splits the Go source file src into lines of highlighted
+// tokens, linking identifiers to their documentation. The file belongs to
+// the package with the given import path, in the given version of the module
+// modulePath.
+func highlightGo(filename, src, importPath, modulePath, version string) []*SourceLine {
+	fset := token.NewFileSet()
+	links := map[int]string{}
+	// Link only files that parse, because offsets in a partial AST may not
+	// match the source.
+	if f, err := parser.ParseFile(fset, filename, src, 0); err == nil {
+		links = identLinks(fset, f, importPath, modulePath, version)
+	}
+
+	var (
+		sl   sourceLines
+		s    scanner.Scanner
+		prev int
+	)
+	file := fset.AddFile(filename, -1, len(src))
+	s.Init(file, []byte(src), nil, scanner.ScanComments)
+	for {
+		pos, tok, lit := s.Scan()
+		if tok == token.EOF {
+			break
+		}
+		if tok == token.ILLEGAL || (tok == token.SEMICOLON && lit != ";") {
+			// Skip illegal characters and automatically inserted semicolons.
+			continue
+		}
+		off := file.Offset(pos)
+		end := off + len(tok.String())
+		if lit != "" {
+			end = off + len(lit)
+		}
+		if off < prev || end > len(src) {
+			continue
+		}
+		sl.add(src[prev:off], "", "")
+		sl.add(src[off:end], tokenClass(tok), links[off])
+		prev = end
+	}
+	sl.add(src[prev:], "", "")
+	return sl.result()
+}
+
+func tokenClass(tok token.Token) string {
+	switch {
+	case tok == token.COMMENT:
+		return "comment"
+	case tok.IsKeyword():
+		return "keyword"
+	case tok == token.STRING || tok == token.CHAR:
+		return "string"
+	case tok == token.INT || tok == token.FLOAT || tok == token.IMAG:
+		return "number"
+	}
+	return ""
+}
+
+// sourceLines accumulates tokens into lines.
+type sourceLines struct {
+	lines []*SourceLine
+}
+
+// add appends text to the last line, starting new lines at each newline.
+func (sl *sourceLines) add(text, class, href string) {
+	for {
+		if len(sl.lines) == 0 {
+			sl.newLine()
+		}
+		line, rest, more := strings.Cut(text, "\n")
+		if line != "" {
+			last := sl.lines[len(sl.lines)-1]
+			last.Tokens = append(last.Tokens, &SourceToken{Text: line, Class: class, Href: href})
+		}
+		if !more {
+			return
+		}
+		sl.newLine()
+		text = rest
+	}
+}
+
+// result returns the lines, omitting the empty line after a final newline.
+func (sl *sourceLines) result() []*SourceLine {
+	if n := len(sl.lines); n > 1 && len(sl.lines[n-1].Tokens) == 0 {
+		return sl.lines[:n-1]
+	}
+	return sl.lines
+}
+
+func (sl *sourceLines) newLine() {
+	n := len(sl.lines) + 1
+	sl.lines = append(sl.lines, &SourceLine{
+		Number: n,
+		ID:     uncheckedconversions.IdentifierFromStringKnownToSatisfyTypeContract("L" + strconv.Itoa(n)),
+	})
+}
+
+// identLinks returns the URLs of the documentation of the identifiers in f,
+// keyed by their offsets in the file. Identifiers are linked if they are
+// qualified identifiers of imported packages, exported package-level
+// identifiers of the file's own package, or predeclared identifiers.
+//
+// Identifiers are resolved syntactically, so an exported identifier that is
+// not declared in f is assumed to be declared in another file of the package.
+func identLinks(fset *token.FileSet, f *ast.File, importPath, modulePath, version string) map[int]string {
+	links := map[int]string{}
+	add := func(id *ast.Ident, href string) {
+		links[fset.Position(id.Pos()).Offset] = href
+	}
+	pkgURL := func(p string) string {
+		if p == modulePath || strings.HasPrefix(p, modulePath+"/") ||
+			(modulePath == stdlib.ModulePath && stdlib.Contains(p)) {
+			return versions.ConstructUnitURL(p, modulePath, version)
+		}
+		return "/" + p
+	}
+	// The symbols of an external test package are not documented.
+	docURL := ""
+	if !strings.HasSuffix(f.Name.Name, "_test") {
+		docURL = pkgURL(importPath)
+	}
+
+	imports := map[string]string{}
+	for _, imp := range f.Imports {
+		p, err := strconv.Unquote(imp.Path.Value)
+		if err != nil || p == "C" {
+			continue
+		}
+		name := internal.DefaultPackageName(p)
+		if imp.Name != nil {
+			name = imp.Name.Name
+		}
+		imports[name] = p
+	}
+
+	for _, decl := range f.Decls {
+		fd, ok := decl.(*ast.FuncDecl)
+		if !ok || docURL == "" || !fd.Name.IsExported() {
+			continue
+		}
+		if fd.Recv == nil {
+			add(fd.Name, docURL+"#"+fd.Name.Name)
+		} else if recv := receiverName(fd.Recv.List[0].Type); ast.IsExported(recv) {
+			add(fd.Name, docURL+"#"+recv+"."+fd.Name.Name)
+		}
+	}
+
+	var visit func(n ast.Node) bool
+	visit = func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.ImportSpec:
+			if p, err := strconv.Unquote(n.Path.Value); err == nil && p != "C" {
+				links[fset.Position(n.Path.Pos()).Offset] = pkgURL(p)
+			}
+			return false
+		case *ast.SelectorExpr:
+			if id, ok := n.X.(*ast.Ident); ok && id.Obj == nil {
+				if p, ok := imports[id.Name]; ok {
+					add(id, pkgURL(p))
+					if n.Sel.IsExported() {
+						add(n.Sel, pkgURL(p)+"#"+n.Sel.Name)
+					}
+					return false
+				}
+			}
+			// Don't link field and method names.
+			ast.Inspect(n.X, visit)
+			return false
+		case *ast.KeyValueExpr:
+			// Don't link the field names of struct literals.
+			if _, ok := n.Key.(*ast.Ident); !ok {
+				ast.Inspect(n.Key, visit)
+			}
+			ast.Inspect(n.Value, visit)
+			return false
+		case *ast.Ident:
+			switch {
+			case n.Obj == nil && types.Universe.Lookup(n.Name) != nil:
+				add(n, "/builtin#"+n.Name)
+			case docURL == "" || !n.IsExported():
+			case n.Obj == nil || f.Scope.Lookup(n.Name) == n.Obj:
+				add(n, docURL+"#"+n.Name)
+			}
+		}
+		return true
+	}
+	for _, decl := range f.Decls {
+		if fd, ok := decl.(*ast.FuncDecl); ok {
+			// The names of functions and methods were linked above.
+			if fd.Recv != nil {
+				ast.Inspect(fd.Recv, visit)
+			}
+			ast.Inspect(fd.Type, visit)
+			if fd.Body != nil {
+				ast.Inspect(fd.Body, visit)
+			}
+			continue
+		}
+		ast.Inspect(decl, visit)
+	}
+	return links
+}
+
+// receiverName returns the name of the base type of a method receiver.
+func receiverName(expr ast.Expr) string {
+	for {
+		switch e := expr.(type) {
+		case *ast.StarExpr:
+			expr = e.X
+		case *ast.ParenExpr:
+			expr = e.X
+		case *ast.IndexExpr:
+			expr = e.X
+		case *ast.IndexListExpr:
+			expr = e.X
+		case *ast.Ident:
+			return e.Name
+		default:
+			return ""
+		}
+	}
+}
@@ -0,0 +1,91 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package frontend
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestHighlightGo(t *testing.T) {
+	const src = `package p
+
+import (
+	"io"
+
+	"example.com/m/inner"
+	other "example.com/x/v2"
+)
+
+// T is a type.
+type T struct {
+	Name string
+	r    io.Reader
+}
+
+func (t *T) Read(b []byte) (int, error) {
+	x := other.New(inner.Value)
+	_ = T{Name: x}
+	return Helper(t.r, 1), nil
+}
+`
+	lines := highlightGo("a.go", src, "example.com/m/p", "example.com/m", "v1.0.0")
+	if got, want := len(lines), 20; got != want {
+		t.Fatalf("got %d lines, want %d", got, want)
+	}
+
+	var text string
+	got := map[string]string{}
+	classes := map[string]string{}
+	for _, l := range lines {
+		for _, tok := range l.Tokens {
+			text += tok.Text
+			if tok.Href != "" {
+				got[tok.Text] = tok.Href
+			}
+			if tok.Class != "" {
+				classes[tok.Text] = tok.Class
+			}
+		}
+		text += "\n"
+	}
+	if text != src {
+		t.Errorf("tokens do not add up to the source:\n%s", text)
+	}
+
+	want := map[string]string{
+		`"io"`:                  "/io",
+		`"example.com/m/inner"`: "/example.com/m@v1.0.0/inner",
+		`"example.com/x/v2"`:    "/example.com/x/v2",
+		"T":                     "/example.com/m@v1.0.0/p#T",
+		"string":                "/builtin#string",
+		"io":                    "/io",
+		"Reader":                "/io#Reader",
+		"Read":                  "/example.com/m@v1.0.0/p#T.Read",
+		"byte":                  "/builtin#byte",
+		"int":                   "/builtin#int",
+		"error":                 "/builtin#error",
+		"other":                 "/example.com/x/v2",
+		"New":                   "/example.com/x/v2#New",
+		"inner":                 "/example.com/m@v1.0.0/inner",
+		"Value":                 "/example.com/m@v1.0.0/inner#Value",
+		"Helper":                "/example.com/m@v1.0.0/p#Helper",
+		"nil":                   "/builtin#nil",
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("links mismatch (-want, +got):\n%s", diff)
+	}
+	for text, class := range map[string]string{
+		"// T is a type.": "comment",
+		"func":            "keyword",
+		`"io"`:            "string",
+		"1":               "number",
+	} {
+		if classes[text] != class {
+			t.Errorf("%q: got class %q, want %q", text, classes[text], class)
+		}
+	}
+}
@@ -6,9 +6,13 @@ package frontend
 
 import (
 	"context"
+	"fmt"
+	"go/token"
+	"net/http"
 	"strings"
 
 	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/frontend/page"
 	"golang.org/x/pkgsite/internal/frontend/serrors"
 	"golang.org/x/pkgsite/internal/log"
 	"golang.org/x/pkgsite/internal/stdlib"
@@ -83,6 +87,14 @@ type ImportedByDetails struct {
 
 	// Total is the total number of importers.
 	Total int
+
+	// Symbol is the name of the exported symbol whose users are shown, or the
+	// empty string if all importers are shown.
+	Symbol string
+
+	// SymbolsSupported reports whether the users of individual symbols can be
+	// shown.
+	SymbolsSupported bool
 }
 
 var (
@@ -94,12 +106,20 @@ var (
 
 // fetchImportedByDetails fetches importers for the package version specified by
 // path and version from the database and returns a ImportedByDetails.
-func fetchImportedByDetails(ctx context.Context, ds internal.DataSource, pkgPath, modulePath string) (*ImportedByDetails, error) {
-	db, ok := ds.(internal.PostgresDB)
+// If symbol is non-empty, only the importers that refer to that exported
+// symbol of the package are returned.
+func fetchImportedByDetails(ctx context.Context, ds internal.DataSource, pkgPath, modulePath, symbol string) (*ImportedByDetails, error) {
+	db, ok := ds.(internal.ImportedByDataSource)
 	if !ok {
-		// The proxydatasource does not support the imported by page.
 		return nil, serrors.DatasourceNotSupportedError()
 	}
+	sdb, symbolsSupported := ds.(internal.SymbolUsedByDataSource)
+	if symbol != "" {
+		if !symbolsSupported {
+			return nil, serrors.DatasourceNotSupportedError()
+		}
+		return fetchSymbolUsedByDetails(ctx, sdb, pkgPath, modulePath, symbol)
+	}
 
 	importedBy, err := db.GetImportedBy(ctx, pkgPath, modulePath, importedByLimit)
 	if err != nil {
@@ -159,5 +179,48 @@ func fetchImportedByDetails(ctx context.Context, ds internal.DataSource, pkgPath
 		ImportedBy:           sections,
 		NumImportedByDisplay: display,
 		Total:                numImportedBy,
+		SymbolsSupported:     symbolsSupported,
+	}, nil
+}
+
+// fetchSymbolUsedByDetails returns an ImportedByDetails for the importers of
+// pkgPath that refer to symbol.
+func fetchSymbolUsedByDetails(ctx context.Context, db internal.SymbolUsedByDataSource, pkgPath, modulePath, symbol string) (*ImportedByDetails, error) {
+	if !isExportedSymbolName(symbol) {
+		return nil, &serrors.ServerError{
+			Status: http.StatusBadRequest,
+			Epage: &page.ErrorPage{
+				MessageData: fmt.Sprintf("%q is not an exported name, like F or Type.Method.", symbol),
+			},
+		}
+	}
+	usedBy, err := db.GetSymbolUsedBy(ctx, pkgPath, modulePath, symbol, importedByLimit)
+	if err != nil {
+		return nil, err
+	}
+	pr := message.NewPrinter(language.English)
+	display := pr.Sprint(len(usedBy))
+	if len(usedBy) >= importedByLimit {
+		usedBy = usedBy[:importedByLimit-1]
+		display = pr.Sprintf("more than %d", importedByLimit-1)
+	}
+	return &ImportedByDetails{
+		ModulePath:           modulePath,
+		ImportedBy:           Sections(usedBy, nextPrefixAccount),
+		NumImportedByDisplay: display,
+		Total:                len(usedBy),
+		Symbol:               symbol,
+		SymbolsSupported:     true,
 	}, nil
 }
+
+// isExportedSymbolName reports whether name is the name of an exported
+// package-level symbol, or of a method or field of an exported type, written
+// as "Type.Name".
+func isExportedSymbolName(name string) bool {
+	typeName, member, found := strings.Cut(name, ".")
+	if found && !(token.IsIdentifier(member) && token.IsExported(member)) {
+		return false
+	}
+	return token.IsIdentifier(typeName) && token.IsExported(typeName)
+}
@@ -106,6 +106,7 @@ func TestFetchImportedByDetails(t *testing.T) {
 			pkg: pkg3,
 			wantDetails: &ImportedByDetails{
 				NumImportedByDisplay: "0",
+				SymbolsSupported:     true,
 			},
 		},
 		{
@@ -114,6 +115,7 @@ func TestFetchImportedByDetails(t *testing.T) {
 				ImportedBy:           []*Section{{Prefix: pkg3.Path, NumLines: 0}},
 				NumImportedByDisplay: "0 (displaying 1 package, including internal and invalid packages)",
 				Total:                1,
+				SymbolsSupported:     true,
 			},
 		},
 		{
@@ -125,6 +127,7 @@ func TestFetchImportedByDetails(t *testing.T) {
 				},
 				NumImportedByDisplay: "0 (displaying 2 packages, including internal and invalid packages)",
 				Total:                2,
+				SymbolsSupported:     true,
 			},
 		},
 	}
@@ -163,12 +166,59 @@ func TestFetchImportedByDetails_ExceedsLimit(t *testing.T) {
 
 		NumImportedByDisplay: "0 (displaying more than 2 packages, including internal and invalid packages)",
 		Total:                3,
+		SymbolsSupported:     true,
 	}
 	checkFetchImportedByDetails(ctx, fds, t, m.Packages()[0], wantDetails)
 }
 
+func TestFetchImportedByDetails_Symbol(t *testing.T) {
+	fds := fakedatasource.New()
+	ctx := context.Background()
+
+	m := sample.Module("m.com/a", sample.VersionString, "foo")
+	fds.MustInsertModule(ctx, m)
+	for mod, symbols := range map[string][]string{
+		"m1.com/a": {"F", "T"},
+		"m2.com/a": {"T", "T.M"},
+	} {
+		m2 := sample.Module(mod, sample.VersionString, "p")
+		m2.Packages()[0].Imports = []string{"m.com/a/foo"}
+		m2.Packages()[0].SymbolReferences = map[string][]string{"m.com/a/foo": symbols}
+		fds.MustInsertModule(ctx, m2)
+	}
+	got, err := fetchImportedByDetails(ctx, fds, "m.com/a/foo", "m.com/a", "F")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &ImportedByDetails{
+		ModulePath:           "m.com/a",
+		ImportedBy:           []*Section{{Prefix: "m1.com/a/p"}},
+		NumImportedByDisplay: "1",
+		Total:                1,
+		Symbol:               "F",
+		SymbolsSupported:     true,
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("mismatch (-want +got):\n%s", diff)
+	}
+
+	got, err = fetchImportedByDetails(ctx, fds, "m.com/a/foo", "m.com/a", "T.M")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []*Section{{Prefix: "m2.com/a/p"}}; !cmp.Equal(got.ImportedBy, want) {
+		t.Errorf("used by T.M: got %v, want %v", got.ImportedBy, want)
+	}
+
+	for _, symbol := range []string{"f", "T.m", "t.M", "T.M.X", "T.", ".M"} {
+		if _, err := fetchImportedByDetails(ctx, fds, "m.com/a/foo", "m.com/a", symbol); err == nil {
+			t.Errorf("%q: got nil error for unexported symbol, want error", symbol)
+		}
+	}
+}
+
 func checkFetchImportedByDetails(ctx context.Context, ds internal.DataSource, t *testing.T, pkg *internal.Unit, wantDetails *ImportedByDetails) {
-	got, err := fetchImportedByDetails(ctx, ds, pkg.Path, pkg.ModulePath)
+	got, err := fetchImportedByDetails(ctx, ds, pkg.Path, pkg.ModulePath, "")
 	if err != nil {
 		t.Fatalf("fetchImportedByDetails(ctx, db, %q) = %v err = %v, want %v",
 			pkg.Path, got, err, wantDetails)
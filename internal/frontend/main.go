@@ -35,6 +35,10 @@ type MainDetails struct {
 	// NumImports is the number of imports for the package.
 	NumImports string
 
+	// MinGoVersion is the earliest Go release, like "go1.21", that provides
+	// every standard library symbol the package uses. It is empty if unknown.
+	MinGoVersion string
+
 	// CommitTime is time that this version was published, or the time that
 	// has elapsed since this version was committed if it was done so recently.
 	CommitTime string
@@ -73,8 +77,8 @@ type MainDetails struct {
 	// tag on the main unit page.
 	DocSynopsis string
 
-	// GOOS and GOARCH are the build context for the doc.
-	GOOS, GOARCH string
+	// GOOS, GOARCH and Tags are the build context for the doc.
+	GOOS, GOARCH, Tags string
 
 	// BuildContexts holds the values for build contexts available for the doc.
 	BuildContexts []internal.BuildContext
@@ -102,6 +106,10 @@ type MainDetails struct {
 
 	// IsRedistributable is whether the unit is redistributable.
 	IsRedistributable bool
+
+	// GoSum holds the go.sum lines for the module, if the unit is a module
+	// that was verified against a checksum database.
+	GoSum []string
 }
 
 // File is a source file for a package.
@@ -118,6 +126,10 @@ func fetchMainDetails(ctx context.Context, ds internal.DataSource, um *internal.
 	if err != nil {
 		return nil, err
 	}
+	if unit.SourceInfo == nil {
+		// serveUnitPage may have linked um to the source browser.
+		unit.SourceInfo = um.SourceInfo
+	}
 	subdirectories := getSubdirectories(um, unit.Subdirectories, requestedVersion)
 	if err != nil {
 		return nil, err
@@ -135,7 +147,8 @@ func fetchMainDetails(ctx context.Context, ds internal.DataSource, um *internal.
 		docLinks, modLinks []link
 		files              []*File
 		synopsis           string
-		goos, goarch       string
+		goos, goarch, tags string
+		minGoVersion       string
 		buildContexts      []internal.BuildContext
 	)
 
@@ -150,31 +163,57 @@ func fetchMainDetails(ctx context.Context, ds internal.DataSource, um *internal.
 		synopsis = doc.Synopsis
 		goos = doc.GOOS
 		goarch = doc.GOARCH
+		tags = doc.Tags
+		minGoVersion = doc.MinGoVersion
 		buildContexts = unit.BuildContexts
-		end := stats.Elapsed(ctx, "DecodePackage")
-		docPkg, err := godoc.DecodePackage(doc.Source)
-		end()
-		if err != nil {
-			if errors.Is(err, godoc.ErrInvalidEncodingType) {
-				// Instead of returning a 500, return a 404 so the user can
-				// reprocess the documentation.
-				log.Errorf(ctx, "fetchMainDetails(%q, %q, %q): %v", um.Path, um.ModulePath, um.Version, err)
-				return nil, serrors.ErrUnitNotFoundWithoutFetch
+		// Use the documentation rendered when the unit was fetched, if it
+		// is still valid. Otherwise, decode the source and render it.
+		usedByLink := usedByLinkFunc(ds, unit.ModulePath)
+		if parts := renderedDocParts(ctx, unit, doc, usedByLink, bc); parts != nil {
+			docParts = parts
+			files = sourceFiles(unit, doc.Rendered.Files)
+		} else {
+			end := stats.Elapsed(ctx, "DecodePackage")
+			docPkg, err := godoc.DecodePackage(doc.Source)
+			end()
+			if err != nil {
+				if errors.Is(err, godoc.ErrInvalidEncodingType) {
+					// Instead of returning a 500, return a 404 so the user can
+					// reprocess the documentation.
+					log.Errorf(ctx, "fetchMainDetails(%q, %q, %q): %v", um.Path, um.ModulePath, um.Version, err)
+					return nil, serrors.ErrUnitNotFoundWithoutFetch
+				}
+				return nil, err
+			}
+			// Get the file names first, because rendering destroys docPkg.
+			files = sourceFiles(unit, docPkg.FileNames())
+			// Store the documentation rendered again, so that later requests
+			// don't have to render it.
+			var parts *dochtml.Parts
+			if rds, ok := ds.(internal.RenderedDocDataSource); ok && len(doc.Source) > 0 {
+				parts, err = rerenderDocParts(ctx, rds, unit, doc, docPkg, usedByLink, bc)
+				if err != nil {
+					// Render it below, for the error page if there is one.
+					log.Errorf(ctx, "fetchMainDetails(%q, %q, %q): %v", um.Path, um.ModulePath, um.Version, err)
+					docPkg, err = godoc.DecodePackage(doc.Source)
+					if err != nil {
+						return nil, err
+					}
+				}
+			}
+			if parts != nil {
+				docParts = parts
+			} else {
+				docParts, err = getHTML(ctx, unit, docPkg, unit.SymbolHistory, usedByLink, bc)
+				// If err  is ErrTooLarge, then docBody will have an appropriate message.
+				if err != nil && !errors.Is(err, dochtml.ErrTooLarge) {
+					return nil, err
+				}
 			}
-			return nil, err
-		}
-
-		docParts, err = getHTML(ctx, unit, docPkg, unit.SymbolHistory, bc)
-		// If err  is ErrTooLarge, then docBody will have an appropriate message.
-		if err != nil && !errors.Is(err, dochtml.ErrTooLarge) {
-			return nil, err
 		}
 		for _, l := range docParts.Links {
 			docLinks = append(docLinks, link{Href: l.Href, Body: l.Text})
 		}
-		end = stats.Elapsed(ctx, "sourceFiles")
-		files = sourceFiles(unit, docPkg)
-		end()
 	}
 	// If the unit is not a module, fetch the module readme to extract its
 	// links.
@@ -194,6 +233,14 @@ func fetchMainDetails(ctx context.Context, ds internal.DataSource, um *internal.
 		}
 	}
 
+	var goSum []string
+	if cds, ok := ds.(internal.ChecksumDataSource); ok && unit.Path == unit.ModulePath {
+		goSum, err = cds.GetGoSum(ctx, unit.ModulePath, unit.Version)
+		if err != nil && !errors.Is(err, derrors.NotFound) {
+			return nil, err
+		}
+	}
+
 	versionType, err := version.ParseType(um.Version)
 	if err != nil {
 		return nil, err
@@ -216,18 +263,21 @@ func fetchMainDetails(ctx context.Context, ds internal.DataSource, um *internal.
 		DocSynopsis:       synopsis,
 		GOOS:              goos,
 		GOARCH:            goarch,
+		Tags:              tags,
 		BuildContexts:     buildContexts,
 		SourceFiles:       files,
 		RepositoryURL:     um.SourceInfo.RepoURL(),
 		SourceURL:         um.SourceInfo.DirectoryURL(internal.Suffix(um.Path, um.ModulePath)),
 		MobileOutline:     docParts.MobileOutline,
 		NumImports:        pr.Sprint(unit.NumImports),
+		MinGoVersion:      minGoVersion,
 		ImportedByCount:   pr.Sprint(unit.NumImportedBy),
 		IsPackage:         unit.IsPackage(),
 		ModFileURL:        um.SourceInfo.ModuleURL() + "/go.mod",
 		IsTaggedVersion:   isTaggedVersion,
 		IsStableVersion:   isStableVersion,
 		IsRedistributable: unit.IsRedistributable,
+		GoSum:             goSum,
 	}, nil
 }
 
@@ -269,11 +319,11 @@ func readmeContent(ctx context.Context, u *internal.Unit) (_ *Readme, err error)
 const missingDocReplacement = `<p>Documentation is missing.</p>`
 
 func getHTML(ctx context.Context, u *internal.Unit, docPkg *godoc.Package,
-	nameToVersion map[string]string, bc internal.BuildContext) (_ *dochtml.Parts, err error) {
+	nameToVersion map[string]string, usedByLink func(string) string, bc internal.BuildContext) (_ *dochtml.Parts, err error) {
 	defer derrors.Wrap(&err, "getHTML(%s)", u.Path)
 
 	if len(u.Documentation[0].Source) > 0 {
-		return renderDocParts(ctx, u, docPkg, nameToVersion, bc)
+		return renderDocParts(ctx, u, docPkg, nameToVersion, usedByLink, bc)
 	}
 	log.Errorf(ctx, "unit %s (%s@%s) missing documentation source", u.Path, u.ModulePath, u.Version)
 	return &dochtml.Parts{Body: template.MustParseAndExecuteToHTML(missingDocReplacement)}, nil
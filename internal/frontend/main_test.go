@@ -6,12 +6,17 @@ package frontend
 
 import (
 	"context"
+	"strings"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/google/safehtml/template"
 	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/godoc"
+	"golang.org/x/pkgsite/internal/godoc/dochtml"
 	"golang.org/x/pkgsite/internal/testing/fakedatasource"
 	"golang.org/x/pkgsite/internal/testing/sample"
+	"golang.org/x/pkgsite/static"
 )
 
 func TestGetImportedByCount(t *testing.T) {
@@ -61,3 +66,74 @@ func TestGetImportedByCount(t *testing.T) {
 		})
 	}
 }
+
+func TestFetchMainDetailsRendered(t *testing.T) {
+	dochtml.LoadTemplates(template.TrustedFSFromEmbed(static.FS))
+	ctx := context.Background()
+
+	for _, test := range []struct {
+		name            string
+		rendererVersion string
+		wantPrerendered bool
+	}{
+		{"current", godoc.RendererVersion, true},
+		{"stale", "stale", false},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			m := sample.Module(sample.ModulePath, sample.VersionString, "foo")
+			u := m.Units[1]
+			u.SymbolHistory = map[string]string{"F": sample.VersionString, "G": "v1.1.0"}
+			doc := u.Documentation[0]
+			doc.Rendered = &internal.RenderedDoc{
+				RendererVersion: test.rendererVersion,
+				SourceURL:       u.SourceInfo.ModuleURL(),
+				Body:            `<p>prerendered</p><!--since:F--><!--since:G-->`,
+				Links:           []internal.RenderedLink{{Href: "https://example.com", Text: "Example"}},
+				Files:           []string{"a.go", "b.go"},
+			}
+			ds := fakedatasource.New()
+			ds.MustInsertModule(ctx, m)
+			um, err := ds.GetUnitMeta(ctx, u.Path, u.ModulePath, u.Version)
+			if err != nil {
+				t.Fatal(err)
+			}
+			md, err := fetchMainDetails(ctx, ds, um, u.Version, false, internal.BuildContext{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			body := md.DocBody.String()
+			if got := strings.HasPrefix(body, "<p>prerendered</p>"); got != test.wantPrerendered {
+				t.Fatalf("served prerendered doc: got %t, want %t; body:\n%s", got, test.wantPrerendered, body)
+			}
+			if !test.wantPrerendered {
+				// The documentation rendered again is stored for later
+				// requests.
+				if got := doc.Rendered.RendererVersion; got != godoc.RendererVersion {
+					t.Errorf("stored renderer version: got %q, want %q", got, godoc.RendererVersion)
+				}
+				// The data source knows which importers use each symbol,
+				// so the symbols link to them.
+				if link := `href="?symbol=V&amp;tab=importedby"`; !strings.Contains(body, link) {
+					t.Errorf("body does not contain %s:\n%s", link, body)
+				}
+				return
+			}
+			// Only G was added after the earliest version.
+			wantBody := `<p>prerendered</p><span class="Documentation-sinceVersionLabel">added in</span>` + "\n" +
+				`<span class="Documentation-sinceVersionVersion">v1.1.0</span>`
+			if body != wantBody {
+				t.Errorf("got body\n%s\nwant\n%s", body, wantBody)
+			}
+			if want := []link{{Href: "https://example.com", Body: "Example"}}; !cmp.Equal(md.DocLinks, want) {
+				t.Errorf("got links %v, want %v", md.DocLinks, want)
+			}
+			var files []string
+			for _, f := range md.SourceFiles {
+				files = append(files, f.Name)
+			}
+			if !cmp.Equal(files, doc.Rendered.Files) {
+				t.Errorf("got files %v, want %v", files, doc.Rendered.Files)
+			}
+		})
+	}
+}
@@ -31,6 +31,10 @@ type BasePage struct {
 	// LocalMode indicates whether the server is running in local mode (i.e. ./cmd/pkgsite).
 	LocalMode bool
 
+	// LiveReload indicates whether the page should reload itself when the
+	// server reports that its content has changed.
+	LiveReload bool
+
 	// AppVersionLabel contains the current version of the app.
 	AppVersionLabel string
 
@@ -70,4 +74,8 @@ type ErrorPage struct {
 	TemplateName    string
 	MessageTemplate template.TrustedTemplate
 	MessageData     any
+
+	// AlternativePath is the path of a module to suggest instead of the
+	// requested one, if any.
+	AlternativePath string
 }
@@ -0,0 +1,99 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package frontend
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+)
+
+// A Reloader tells open pages to reload themselves when the content they
+// display has changed. It is used by cmd/pkgsite to refresh documentation
+// pages as local modules are edited.
+//
+// Pages connect to the Reloader with an EventSource. On connection, and each
+// time Notify is called, the Reloader sends a "reload" event whose data is a
+// generation number. A page reloads when it sees a generation different from
+// the first one it received, so that a notification that happens while the
+// page is reconnecting is not lost.
+type Reloader struct {
+	mu         sync.Mutex
+	generation int
+	clients    map[chan int]bool
+}
+
+// NewReloader returns a new Reloader.
+func NewReloader() *Reloader {
+	return &Reloader{clients: map[chan int]bool{}}
+}
+
+// Notify tells all connected pages to reload.
+func (r *Reloader) Notify() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.generation++
+	for c := range r.clients {
+		// Each channel holds at most one pending generation; a newer one
+		// replaces it.
+		select {
+		case <-c:
+		default:
+		}
+		c <- r.generation
+	}
+}
+
+func (r *Reloader) subscribe() (chan int, int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	c := make(chan int, 1)
+	r.clients[c] = true
+	return c, r.generation
+}
+
+func (r *Reloader) unsubscribe(c chan int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.clients, c)
+}
+
+// ServeHTTP serves a stream of server-sent events, as described in the
+// documentation for Reloader. The stream ends when the request's context is
+// done; browsers will reconnect automatically.
+func (r *Reloader) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+	c, gen := r.subscribe()
+	defer r.unsubscribe(c)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	send := func(gen int) error {
+		if _, err := fmt.Fprintf(w, "event: reload\ndata: %d\n\n", gen); err != nil {
+			return err
+		}
+		flusher.Flush()
+		return nil
+	}
+	// Ask the browser to reconnect quickly if the stream ends.
+	fmt.Fprint(w, "retry: 1000\n\n")
+	if err := send(gen); err != nil {
+		return
+	}
+	for {
+		select {
+		case <-req.Context().Done():
+			return
+		case gen := <-c:
+			if err := send(gen); err != nil {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,57 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package frontend
+
+import (
+	"bufio"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestReloader(t *testing.T) {
+	r := NewReloader()
+	r.Notify() // no clients yet; the generation still advances
+	ts := httptest.NewServer(r)
+	defer ts.Close()
+
+	res, err := http.Get(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	if got, want := res.Header.Get("Content-Type"), "text/event-stream"; got != want {
+		t.Errorf("Content-Type: got %q, want %q", got, want)
+	}
+	br := bufio.NewReader(res.Body)
+
+	// nextData returns the data of the next reload event.
+	nextData := func() string {
+		t.Helper()
+		sawEvent := false
+		for {
+			line, err := br.ReadString('\n')
+			if err != nil {
+				t.Fatal(err)
+			}
+			line = strings.TrimSuffix(line, "\n")
+			switch {
+			case line == "event: reload":
+				sawEvent = true
+			case sawEvent && strings.HasPrefix(line, "data: "):
+				return strings.TrimPrefix(line, "data: ")
+			}
+		}
+	}
+
+	if got, want := nextData(), "1"; got != want {
+		t.Errorf("first event: got %q, want %q", got, want)
+	}
+	r.Notify()
+	if got, want := nextData(), "2"; got != want {
+		t.Errorf("after Notify: got %q, want %q", got, want)
+	}
+}
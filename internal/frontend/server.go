@@ -24,6 +24,7 @@ import (
 	"golang.org/x/pkgsite/internal/config"
 	"golang.org/x/pkgsite/internal/derrors"
 	"golang.org/x/pkgsite/internal/experiment"
+	"golang.org/x/pkgsite/internal/fetch"
 	pagepkg "golang.org/x/pkgsite/internal/frontend/page"
 	"golang.org/x/pkgsite/internal/frontend/serrors"
 	"golang.org/x/pkgsite/internal/frontend/templates"
@@ -31,6 +32,7 @@ import (
 	"golang.org/x/pkgsite/internal/godoc/dochtml"
 	"golang.org/x/pkgsite/internal/licenses"
 	"golang.org/x/pkgsite/internal/log"
+	"golang.org/x/pkgsite/internal/lru"
 	"golang.org/x/pkgsite/internal/memory"
 	"golang.org/x/pkgsite/internal/middleware/stats"
 	"golang.org/x/pkgsite/internal/queue"
@@ -57,6 +59,10 @@ type Server struct {
 	reporter           derrors.Reporter
 	fileMux            *http.ServeMux
 	vulnClient         *vuln.Client
+	reloader           *Reloader
+	playground         http.Handler
+	sourceGetter       fetch.ModuleGetter
+	moduleFSCache      *lru.Cache[internal.Modver, fs.FS]
 	versionID          string
 	instanceID         string
 	depsDevHTTPClient  *http.Client
@@ -94,6 +100,16 @@ type ServerConfig struct {
 	Reporter          derrors.Reporter
 	VulndbClient      *vuln.Client
 	DepsDevHTTPClient *http.Client
+	// Reloader, if non-nil, is served at /_reload so that open pages can
+	// reload themselves when their content changes. See Reloader.
+	Reloader *Reloader
+	// Playground, if non-nil, serves the requests to run and share the
+	// programs of example playgrounds, at /play/compile, /play/share and
+	// /play/p/, instead of the Go playground.
+	Playground http.Handler
+	// SourceGetter, if non-nil, reads module contents for the source
+	// browser when the DataSource can't, as for the database.
+	SourceGetter fetch.ModuleGetter
 }
 
 // NewServer creates a new Server for the given database and template directory.
@@ -119,6 +135,10 @@ func NewServer(scfg ServerConfig) (_ *Server, err error) {
 		fileMux:           http.NewServeMux(),
 		vulnClient:        scfg.VulndbClient,
 		depsDevHTTPClient: scfg.DepsDevHTTPClient,
+		reloader:          scfg.Reloader,
+		playground:        scfg.Playground,
+		sourceGetter:      scfg.SourceGetter,
+		moduleFSCache:     lru.New[internal.Modver, fs.FS](maxCachedModuleFSs),
 	}
 	if s.depsDevHTTPClient == nil {
 		s.depsDevHTTPClient = http.DefaultClient
@@ -168,10 +188,23 @@ func (s *Server) Install(handle func(string, http.Handler), cacher Cacher, authV
 		// by the handlers it wraps. Be careful not to wrap the handler it returns
 		// with a handler that rewrites the URL in a way that could cause key
 		// collisions, like http.StripPrefix.
-		detailHandler = cacher.Cache("details", detailsTTL, authValues)(detailHandler)
+		// The cache stores only the bodies of responses, so it can't tell
+		// documentation served in other formats than HTML from the HTML
+		// page at the same URL.
+		uncachedDetailHandler := detailHandler
+		cachedDetailHandler := cacher.Cache("details", detailsTTL, authValues)(detailHandler)
+		detailHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if docFormat(r) != "" {
+				uncachedDetailHandler.ServeHTTP(w, r)
+				return
+			}
+			cachedDetailHandler.ServeHTTP(w, r)
+		})
 		searchHandler = cacher.Cache("search", searchTTL, authValues)(searchHandler)
 		vulnHandler = cacher.Cache("vuln", vulnTTL, authValues)(vulnHandler)
 	}
+	// Set the Vary header outside the cache, which doesn't store headers.
+	detailHandler = varyOnAccept(detailHandler)
 	// Each AppEngine instance is created in response to a start request, which
 	// is an empty HTTP GET request to /_ah/start when scaling is set to manual
 	// or basic, and /_ah/warmup when scaling is automatic and min_instances is
@@ -195,9 +228,15 @@ func (s *Server) Install(handle func(string, http.Handler), cacher Cacher, authV
 	if fetchHandler != nil {
 		handle("/fetch/", fetchHandler)
 	}
-	handle("/play/compile", http.HandlerFunc(s.proxyPlayground))
+	if s.playground != nil {
+		handle("/play/compile", s.playground)
+		handle("/play/share", s.playground)
+		handle("/play/p/", s.playground)
+	} else {
+		handle("/play/compile", http.HandlerFunc(s.proxyPlayground))
+		handle("/play/share", http.HandlerFunc(s.proxyPlayground))
+	}
 	handle("/play/fmt", http.HandlerFunc(s.handleFmt))
-	handle("/play/share", http.HandlerFunc(s.proxyPlayground))
 	handle("/search", searchHandler)
 	handle("/search-help", s.staticPageHandler("search-help", "Search Help"))
 	handle("/license-policy", s.licensePolicyHandler())
@@ -210,7 +249,16 @@ func (s *Server) Install(handle func(string, http.Handler), cacher Cacher, authV
 	}))
 	handle("/golang.org/x", s.staticPageHandler("subrepo", "Sub-repositories"))
 	handle("/files/", http.StripPrefix("/files", s.fileMux))
+	handle("/source/", s.errorHandler(s.serveSource))
+	if s.localMode {
+		// Bundling whole modules is too expensive to do on request for
+		// anyone on the internet, so only a local server does it.
+		handle("/download", s.errorHandler(s.serveDownload))
+	}
 	handle("/vuln/", vulnHandler)
+	if s.reloader != nil {
+		handle("/_reload", s.reloader)
+	}
 	handle("/opensearch.xml", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		serveFileFS(w, r, s.staticFS, "shared/opensearch.xml")
 	}))
@@ -467,6 +515,7 @@ func (s *Server) newBasePage(r *http.Request, title string) pagepkg.BasePage {
 		Experiments:        experiment.FromContext(r.Context()),
 		DevMode:            s.devMode,
 		LocalMode:          s.localMode,
+		LiveReload:         s.reloader != nil,
 		AppVersionLabel:    s.appVersionLabel,
 		GoogleTagManagerID: s.googleTagManagerID,
 		SearchPrompt:       searchPrompt,
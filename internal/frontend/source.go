@@ -0,0 +1,269 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package frontend
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"mime"
+	"net/http"
+	"path"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/derrors"
+	pagepkg "golang.org/x/pkgsite/internal/frontend/page"
+	"golang.org/x/pkgsite/internal/frontend/serrors"
+	"golang.org/x/pkgsite/internal/frontend/versions"
+	"golang.org/x/pkgsite/internal/source"
+	"golang.org/x/pkgsite/internal/stdlib"
+)
+
+// maxCachedModuleFSs is the number of module versions whose contents the
+// server keeps after reading them with its SourceGetter.
+const maxCachedModuleFSs = 10
+
+// maxSourceFileSize is the size of the largest file that the source browser
+// displays. Larger files can only be viewed raw.
+const maxSourceFileSize = 1 << 20
+
+// SourcePage contains the data for the source browser, which shows a file
+// or directory of a module version.
+type SourcePage struct {
+	pagepkg.BasePage
+
+	ModulePath string
+	Version    string
+
+	// Breadcrumbs link to the module root and to each directory
+	// containing the file or directory, which is last.
+	Breadcrumbs []*SourceEntry
+
+	// DocURL is the URL of the package or module documentation for the
+	// directory, or for the directory containing the file.
+	DocURL string
+
+	// IsDir reports whether the page shows a directory.
+	IsDir bool
+
+	// Entries are the contents of a directory.
+	Entries []*SourceEntry
+
+	// Lines are the lines of a file. They are empty if the file is too large
+	// or not text, in which case Message says why.
+	Lines   []*SourceLine
+	Message string
+
+	// RawURL is the URL of the unformatted contents of a file.
+	RawURL string
+}
+
+// A SourceEntry is a link to a file or directory in the source browser.
+type SourceEntry struct {
+	Name  string
+	URL   string
+	IsDir bool
+}
+
+// serveSource serves the file or directory of a module version named by a
+// path of the form /source/<module>@<version>/<file>. The file is shown with
+// line numbers, and Go files are highlighted, with identifiers linked to their
+// documentation. If the "raw" query parameter is set, the file is served as
+// is: as an image for image files, and as plain text otherwise.
+//
+// The source browser is available for the data sources that can read module
+// contents, and for others if the server has a SourceGetter. The unit page
+// links to it for modules whose repositories are not on a host that pkgsite
+// knows how to link to.
+func (s *Server) serveSource(w http.ResponseWriter, r *http.Request, ds internal.DataSource) (err error) {
+	defer derrors.Wrap(&err, "serveSource(%q)", r.URL.Path)
+
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return &serrors.ServerError{Status: http.StatusMethodNotAllowed}
+	}
+	modulePath, rest, ok := strings.Cut(strings.TrimPrefix(r.URL.Path, "/source/"), "@")
+	if !ok || modulePath == "" {
+		return &serrors.ServerError{
+			Status: http.StatusBadRequest,
+			Err:    errors.New("path must have the form /source/<module>@<version>/<file>"),
+		}
+	}
+	version, file, _ := strings.Cut(rest, "/")
+	file = strings.TrimSuffix(file, "/")
+	if file == "" {
+		file = "."
+	}
+	if version == "" || !fs.ValidPath(file) {
+		return &serrors.ServerError{Status: http.StatusBadRequest, Err: fmt.Errorf("invalid path %q", r.URL.Path)}
+	}
+	if !s.canReadModules(ds) {
+		return serrors.DatasourceNotSupportedError()
+	}
+	fsys, err := s.getModuleFS(r.Context(), ds, modulePath, version)
+	if err != nil {
+		if errors.Is(err, derrors.NotFound) {
+			return &serrors.ServerError{Status: http.StatusNotFound, Err: err}
+		}
+		return err
+	}
+	info, err := fs.Stat(fsys, file)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return &serrors.ServerError{Status: http.StatusNotFound, Err: err}
+		}
+		return err
+	}
+
+	si := source.SiteInfo(modulePath, version)
+	if _, ok := r.URL.Query()["raw"]; ok {
+		if info.IsDir() {
+			http.Redirect(w, r, si.DirectoryURL(file), http.StatusFound)
+			return nil
+		}
+		content, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return err
+		}
+		// Serve images, so that READMEs can show them, but nothing else
+		// that a browser could interpret.
+		ctype := mime.TypeByExtension(path.Ext(file))
+		if !strings.HasPrefix(ctype, "image/") || strings.HasPrefix(ctype, "image/svg") {
+			ctype = "text/plain; charset=utf-8"
+		}
+		w.Header().Set("Content-Type", ctype)
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(content))
+		return nil
+	}
+
+	dir := file
+	if !info.IsDir() {
+		dir = path.Dir(file)
+	}
+	importPath := path.Join(modulePath, dir)
+	if modulePath == stdlib.ModulePath && dir != "." {
+		importPath = dir
+	}
+	title := modulePath
+	if file != "." {
+		title = path.Join(modulePath, file)
+	}
+	page := &SourcePage{
+		BasePage:    s.newBasePage(r, title),
+		ModulePath:  modulePath,
+		Version:     version,
+		Breadcrumbs: sourceBreadcrumbs(si, modulePath, file),
+		DocURL:      versions.ConstructUnitURL(importPath, modulePath, version),
+		IsDir:       info.IsDir(),
+	}
+	if page.IsDir {
+		page.Entries, err = sourceEntries(fsys, si, file)
+		if err != nil {
+			return err
+		}
+	} else {
+		page.RawURL = si.RawURL(file)
+		if err := addSourceLines(page, fsys, file, importPath, info.Size()); err != nil {
+			return err
+		}
+	}
+	s.servePage(r.Context(), w, "source", page)
+	return nil
+}
+
+// canReadModules reports whether the server can read the contents of the
+// module versions served by ds.
+func (s *Server) canReadModules(ds internal.DataSource) bool {
+	_, ok := ds.(internal.ModuleFSDataSource)
+	return ok || s.sourceGetter != nil
+}
+
+// getModuleFS returns the contents of the module version, from ds if it can
+// read them, and from the server's SourceGetter otherwise.
+func (s *Server) getModuleFS(ctx context.Context, ds internal.DataSource, modulePath, version string) (fs.FS, error) {
+	if mds, ok := ds.(internal.ModuleFSDataSource); ok {
+		return mds.GetModuleFS(ctx, modulePath, version)
+	}
+	mv := internal.Modver{Path: modulePath, Version: version}
+	if fsys, ok := s.moduleFSCache.Get(mv); ok {
+		return fsys, nil
+	}
+	fsys, err := s.sourceGetter.ContentDir(ctx, modulePath, version)
+	if err != nil {
+		return nil, err
+	}
+	s.moduleFSCache.Put(mv, fsys)
+	return fsys, nil
+}
+
+// sourceBreadcrumbs returns the breadcrumbs for the file or directory of the
+// module, which is "." for the module root.
+func sourceBreadcrumbs(si *source.Info, modulePath, file string) []*SourceEntry {
+	crumbs := []*SourceEntry{{Name: modulePath, URL: si.ModuleURL(), IsDir: true}}
+	if file == "." {
+		return crumbs
+	}
+	elems := strings.Split(file, "/")
+	for i, e := range elems {
+		p := strings.Join(elems[:i+1], "/")
+		if i == len(elems)-1 {
+			crumbs = append(crumbs, &SourceEntry{Name: e, URL: si.FileURL(p)})
+		} else {
+			crumbs = append(crumbs, &SourceEntry{Name: e, URL: si.DirectoryURL(p), IsDir: true})
+		}
+	}
+	return crumbs
+}
+
+// sourceEntries returns the entries of the directory dir, with directories
+// first.
+func sourceEntries(fsys fs.FS, si *source.Info, dir string) ([]*SourceEntry, error) {
+	des, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+	var entries []*SourceEntry
+	for _, de := range des {
+		p := path.Join(dir, de.Name())
+		e := &SourceEntry{Name: de.Name(), URL: si.FileURL(p), IsDir: de.IsDir()}
+		if e.IsDir {
+			e.URL = si.DirectoryURL(p)
+		}
+		entries = append(entries, e)
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].IsDir && !entries[j].IsDir
+	})
+	return entries, nil
+}
+
+// addSourceLines sets the lines of page to those of file, which has the given
+// size, or explains why they are not shown.
+func addSourceLines(page *SourcePage, fsys fs.FS, file, importPath string, size int64) error {
+	if size > maxSourceFileSize {
+		page.Message = "This file is too large to display."
+		return nil
+	}
+	content, err := fs.ReadFile(fsys, file)
+	if err != nil {
+		return err
+	}
+	if !utf8.Valid(content) || bytes.IndexByte(content, 0) >= 0 {
+		page.Message = "This file is not a text file."
+		return nil
+	}
+	if strings.HasSuffix(file, ".go") {
+		page.Lines = highlightGo(file, string(content), importPath, page.ModulePath, page.Version)
+	} else {
+		page.Lines = plainSourceLines(string(content))
+	}
+	return nil
+}
@@ -0,0 +1,164 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package frontend
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/google/safehtml/template"
+	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/fetch"
+	"golang.org/x/pkgsite/internal/testing/fakedatasource"
+	"golang.org/x/pkgsite/internal/testing/testhelper"
+	"golang.org/x/pkgsite/static"
+	thirdparty "golang.org/x/pkgsite/third_party"
+)
+
+func TestServeSource(t *testing.T) {
+	ds := fakedatasource.New()
+	ds.SetModuleFS("example.com/m", "v1.0.0", fstest.MapFS{
+		"go.mod":      {Data: []byte("module example.com/m\n")},
+		"p/p.go":      {Data: []byte("package p\n\n// F is a function.\nfunc F() {}\n")},
+		"p/logo.png":  {Data: []byte("\x89PNG\r\n\x1a\n\x00")},
+		"p/page.html": {Data: []byte("<script>alert(1)</script>")},
+	})
+	s, err := NewServer(ServerConfig{
+		DataSourceGetter: func(context.Context) internal.DataSource { return ds },
+		TemplateFS:       template.TrustedFSFromEmbed(static.FS),
+		StaticFS:         static.FS,
+		ThirdPartyFS:     thirdparty.FS,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	mux := http.NewServeMux()
+	s.Install(mux.Handle, nil, nil)
+
+	for _, test := range []struct {
+		path        string
+		wantStatus  int
+		wantType    string
+		wantContent []string
+	}{
+		{
+			path:       "/source/example.com/m@v1.0.0",
+			wantStatus: http.StatusOK,
+			wantContent: []string{
+				`<a href="/source/example.com/m@v1.0.0/p">p/</a>`,
+				`<a href="/source/example.com/m@v1.0.0/go.mod">go.mod</a>`,
+			},
+		},
+		{
+			path:       "/source/example.com/m@v1.0.0/p/p.go",
+			wantStatus: http.StatusOK,
+			wantContent: []string{
+				`<span class="Source-line" id="L4">`,
+				`<a class="Source-ident" href="/example.com/m@v1.0.0/p#F">F</a>`,
+				`<span class="Source-comment">// F is a function.</span>`,
+				`<a href="/source/example.com/m@v1.0.0/p/p.go?raw=1" data-test-id="Source-raw">Raw</a>`,
+				`<a href="/example.com/m@v1.0.0/p">Documentation</a>`,
+			},
+		},
+		{
+			path:        "/source/example.com/m@v1.0.0/p/p.go?raw=1",
+			wantStatus:  http.StatusOK,
+			wantType:    "text/plain; charset=utf-8",
+			wantContent: []string{"func F() {}"},
+		},
+		{
+			path:       "/source/example.com/m@v1.0.0/p/logo.png?raw=1",
+			wantStatus: http.StatusOK,
+			wantType:   "image/png",
+		},
+		{
+			path:       "/source/example.com/m@v1.0.0/p/page.html?raw=1",
+			wantStatus: http.StatusOK,
+			wantType:   "text/plain; charset=utf-8",
+		},
+		{
+			path:        "/source/example.com/m@v1.0.0/p/logo.png",
+			wantStatus:  http.StatusOK,
+			wantContent: []string{"This file is not a text file."},
+		},
+		{path: "/source/example.com/m@v1.0.0/p/missing.go", wantStatus: http.StatusNotFound},
+		{path: "/source/example.com/m@v2.0.0/p/p.go", wantStatus: http.StatusNotFound},
+		{path: "/source/example.com/m", wantStatus: http.StatusBadRequest},
+	} {
+		t.Run(test.path, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, httptest.NewRequest("GET", test.path, nil))
+			if w.Code != test.wantStatus {
+				t.Fatalf("got status %d, want %d", w.Code, test.wantStatus)
+			}
+			if test.wantType != "" {
+				if got := w.Header().Get("Content-Type"); got != test.wantType {
+					t.Errorf("got Content-Type %q, want %q", got, test.wantType)
+				}
+			}
+			body := w.Body.String()
+			for _, want := range test.wantContent {
+				if !strings.Contains(body, want) {
+					t.Errorf("body does not contain %q", want)
+				}
+			}
+		})
+	}
+}
+
+func TestServeSourceGetter(t *testing.T) {
+	dir, _ := testhelper.WriteTxtarToTempDir(t, `
+-- go.mod --
+module example.com/m
+-- p/p.go --
+package p
+`)
+	g, err := fetch.NewDirectoryModuleGetter("", dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Hide the FakeDataSource's GetModuleFS method, as for the database.
+	ds := struct{ internal.DataSource }{fakedatasource.New()}
+	newServer := func(g fetch.ModuleGetter) *http.ServeMux {
+		s, err := NewServer(ServerConfig{
+			DataSourceGetter: func(context.Context) internal.DataSource { return ds },
+			TemplateFS:       template.TrustedFSFromEmbed(static.FS),
+			StaticFS:         static.FS,
+			ThirdPartyFS:     thirdparty.FS,
+			SourceGetter:     g,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		mux := http.NewServeMux()
+		s.Install(mux.Handle, nil, nil)
+		return mux
+	}
+
+	path := "/source/example.com/m@" + fetch.LocalVersion + "/p"
+	for _, test := range []struct {
+		name       string
+		getter     fetch.ModuleGetter
+		wantStatus int
+	}{
+		{"no getter", nil, http.StatusFailedDependency},
+		{"getter", g, http.StatusOK},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			newServer(test.getter).ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+			if w.Code != test.wantStatus {
+				t.Fatalf("got status %d, want %d", w.Code, test.wantStatus)
+			}
+			if w.Code == http.StatusOK && !strings.Contains(w.Body.String(), "p.go") {
+				t.Error("body does not list p.go")
+			}
+		})
+	}
+}
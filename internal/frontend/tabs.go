@@ -36,11 +36,16 @@ type TabSettings struct {
 }
 
 const (
-	tabMain       = ""
-	tabVersions   = "versions"
-	tabImports    = "imports"
-	tabImportedBy = "importedby"
-	tabLicenses   = "licenses"
+	tabMain          = ""
+	tabVersions      = "versions"
+	tabImports       = "imports"
+	tabImportedBy    = "importedby"
+	tabLicenses      = "licenses"
+	tabAPIDiff       = "apidiff"
+	tabDependencies  = "dependencies"
+	tabTests         = "tests"
+	tabDocHealth     = "dochealth"
+	tabBuildContexts = "buildcontexts"
 )
 
 var (
@@ -65,6 +70,26 @@ var (
 			Name:         tabLicenses,
 			TemplateName: "unit/licenses",
 		},
+		{
+			Name:         tabAPIDiff,
+			TemplateName: "unit/apidiff",
+		},
+		{
+			Name:         tabDependencies,
+			TemplateName: "unit/dependencies",
+		},
+		{
+			Name:         tabTests,
+			TemplateName: "unit/tests",
+		},
+		{
+			Name:         tabDocHealth,
+			TemplateName: "unit/dochealth",
+		},
+		{
+			Name:         tabBuildContexts,
+			TemplateName: "unit/buildcontexts",
+		},
 	}
 	unitTabLookup = make(map[string]TabSettings, len(unitTabs))
 )
@@ -90,9 +115,19 @@ func fetchDetailsForUnit(ctx context.Context, r *http.Request, tab string, ds in
 	case tabImports:
 		return fetchImportsDetails(ctx, ds, um.Path, um.ModulePath, um.Version)
 	case tabImportedBy:
-		return fetchImportedByDetails(ctx, ds, um.Path, um.ModulePath)
+		return fetchImportedByDetails(ctx, ds, um.Path, um.ModulePath, r.FormValue("symbol"))
 	case tabLicenses:
 		return fetchLicensesDetails(ctx, ds, um)
+	case tabAPIDiff:
+		return fetchAPIDiffDetails(ctx, ds, um)
+	case tabDependencies:
+		return fetchDependenciesDetails(ctx, ds, um)
+	case tabTests:
+		return fetchTestsDetails(ctx, ds, um)
+	case tabDocHealth:
+		return fetchDocHealthDetails(ctx, ds, um)
+	case tabBuildContexts:
+		return fetchBuildContextsDetails(ctx, ds, um)
 	}
 	return nil, fmt.Errorf("BUG: unable to fetch details: unknown tab %q", tab)
 }
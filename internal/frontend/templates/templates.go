@@ -58,11 +58,17 @@ func ParsePageTemplates(fsys template.TrustedFS) (map[string]*template.Template,
 		{"license-policy"},
 		{"search"},
 		{"search-help"},
+		{"source"},
 		{"subrepo"},
+		{"unit/apidiff", "unit"},
+		{"unit/buildcontexts", "unit"},
+		{"unit/dependencies", "unit"},
+		{"unit/dochealth", "unit"},
 		{"unit/importedby", "unit"},
 		{"unit/imports", "unit"},
 		{"unit/licenses", "unit"},
 		{"unit/main", "unit"},
+		{"unit/tests", "unit"},
 		{"unit/versions", "unit"},
 		{"vuln"},
 		{"vuln/main", "vuln"},
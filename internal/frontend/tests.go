@@ -0,0 +1,47 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package frontend
+
+import (
+	"context"
+
+	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/derrors"
+)
+
+// TestsDetails contains the tests, benchmarks and fuzz targets of a package.
+type TestsDetails struct {
+	Tests      []*internal.TestFunc
+	Benchmarks []*internal.TestFunc
+	FuzzTests  []*internal.TestFunc
+
+	// CorpusSize is the total number of seed corpus files of the fuzz
+	// targets.
+	CorpusSize int
+}
+
+// fetchTestsDetails returns the test functions of the package described by
+// um.
+func fetchTestsDetails(ctx context.Context, ds internal.DataSource, um *internal.UnitMeta) (_ *TestsDetails, err error) {
+	defer derrors.Wrap(&err, "fetchTestsDetails(%q, %q, %q)", um.Path, um.ModulePath, um.Version)
+
+	u, err := ds.GetUnit(ctx, um, internal.WithTests, internal.BuildContext{})
+	if err != nil {
+		return nil, err
+	}
+	details := &TestsDetails{}
+	for _, tf := range u.Tests {
+		switch tf.Kind {
+		case internal.TestKindTest:
+			details.Tests = append(details.Tests, tf)
+		case internal.TestKindBenchmark:
+			details.Benchmarks = append(details.Benchmarks, tf)
+		case internal.TestKindFuzz:
+			details.FuzzTests = append(details.FuzzTests, tf)
+			details.CorpusSize += tf.CorpusSize
+		}
+	}
+	return details, nil
+}
@@ -24,6 +24,7 @@ import (
 	"golang.org/x/pkgsite/internal/frontend/versions"
 	"golang.org/x/pkgsite/internal/log"
 	"golang.org/x/pkgsite/internal/middleware/stats"
+	"golang.org/x/pkgsite/internal/source"
 	"golang.org/x/pkgsite/internal/stdlib"
 	"golang.org/x/pkgsite/internal/version"
 	"golang.org/x/pkgsite/internal/vuln"
@@ -93,6 +94,11 @@ type UnitPage struct {
 	// (see static/frontend/unit/_header.tmpl).
 	RedirectedFromPath string
 
+	// AlternativePath is the path of a module to suggest instead of this one,
+	// because this module is known to be a fork or copy of it, or its
+	// deprecation comment recommends it.
+	AlternativePath string
+
 	// Details contains data specific to the type of page being rendered.
 	Details any
 
@@ -133,7 +139,17 @@ func (s *Server) serveUnitPage(ctx context.Context, w http.ResponseWriter, r *ht
 		if !ok || s.fetchServer == nil {
 			return serrors.DatasourceNotSupportedError()
 		}
-		return s.fetchServer.ServePathNotFoundPage(w, r, db, info.FullPath, info.ModulePath, info.RequestedVersion)
+		err = s.fetchServer.ServePathNotFoundPage(w, r, db, info.FullPath, info.ModulePath, info.RequestedVersion)
+		return withSuggestedAlternative(ctx, db, info.FullPath, err)
+	}
+	if um.SourceInfo == nil {
+		if s.canReadModules(ds) {
+			// Link to the source browser when the repository is on a host
+			// that we can't link to.
+			c := *um
+			c.SourceInfo = source.SiteInfo(um.ModulePath, um.Version)
+			um = &c
+		}
 	}
 
 	makeDepsDevURL := depsDevURLGenerator(ctx, s.depsDevHTTPClient, um)
@@ -142,8 +158,13 @@ func (s *Server) serveUnitPage(ctx context.Context, w http.ResponseWriter, r *ht
 	// affects the documentation and synopsis. Omitting both results in an empty
 	// build context, which will match the first (and preferred) build context.
 	// It's also okay to provide just one (e.g. GOOS=windows), which will select
-	// the first doc with that value, ignoring the other one.
-	bc := internal.BuildContext{GOOS: r.FormValue("GOOS"), GOARCH: r.FormValue("GOARCH")}
+	// the first doc with that value, ignoring the other one. Documentation
+	// loaded with build tags is shown only if the tags query parameter names
+	// them, as a comma-separated list.
+	bc := internal.BuildContext{GOOS: r.FormValue("GOOS"), GOARCH: r.FormValue("GOARCH"), Tags: r.FormValue("tags")}
+	if format := docFormat(r); format != "" && tab == tabMain {
+		return serveDocFormat(ctx, w, ds, um, bc, format)
+	}
 	d, err := fetchDetailsForUnit(ctx, r, tab, ds, um, info.RequestedVersion, bc, s.vulnClient)
 	if err != nil {
 		return err
@@ -230,6 +251,16 @@ func (s *Server) serveUnitPage(ctx context.Context, w http.ResponseWriter, r *ht
 		IsLatestMinor:         lv == latestInfo.MinorVersion,
 	}
 
+	if ads, ok := ds.(internal.AlternativesDataSource); ok {
+		// The deprecation banner already shows the deprecation comment.
+		alt, err := ads.GetSuggestedAlternative(ctx, um.ModulePath, false)
+		if err != nil {
+			// Don't fail, but don't display a banner either.
+			log.Errorf(ctx, "GetSuggestedAlternative(%q): %v", um.ModulePath, err)
+		}
+		page.AlternativePath = alt
+	}
+
 	// Show the banner if there was no error getting the latest major version,
 	// and it is different from the major version of the current module path.
 	latestMajor := internal.MajorVersionForModule(latestInfo.MajorModulePath)
@@ -250,6 +281,34 @@ func (s *Server) serveUnitPage(ctx context.Context, w http.ResponseWriter, r *ht
 	return nil
 }
 
+// withSuggestedAlternative adds a suggestion of a known alternative to the
+// module containing fullPath to err, if it is a 404 error page.
+func withSuggestedAlternative(ctx context.Context, ads internal.AlternativesDataSource, fullPath string, err error) error {
+	var serr *serrors.ServerError
+	if !errors.As(err, &serr) || serr.Status != http.StatusNotFound {
+		return err
+	}
+	alt, aerr := ads.GetSuggestedAlternative(ctx, fullPath, true)
+	if aerr != nil {
+		log.Errorf(ctx, "GetSuggestedAlternative(%q): %v", fullPath, aerr)
+		return err
+	}
+	if alt == "" {
+		return err
+	}
+	// Copy the error and its page, since they may be shared, like
+	// serrors.ErrUnitNotFoundWithoutFetch.
+	serr2 := *serr
+	if serr.Epage != nil {
+		epage := *serr.Epage
+		serr2.Epage = &epage
+	} else {
+		serr2.Epage = &page.ErrorPage{}
+	}
+	serr2.Epage.AlternativePath = alt
+	return &serr2
+}
+
 func (s *Server) shouldServeJSON(r *http.Request) bool {
 	return s.serveStats && r.FormValue("content") == "json"
 }
@@ -304,7 +363,7 @@ func isValidTabForUnit(tab string, um *internal.UnitMeta, details any) bool {
 	if tab == tabLicenses && !(details.(*LicensesDetails).IsRedistributable) {
 		return false
 	}
-	if !um.IsPackage() && (tab == tabImports || tab == tabImportedBy) {
+	if !um.IsPackage() && (tab == tabImports || tab == tabImportedBy || tab == tabAPIDiff || tab == tabTests) {
 		return false
 	}
 	return true
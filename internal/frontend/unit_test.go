@@ -77,6 +77,7 @@ func TestIsValidTab(t *testing.T) {
 		tabImports,
 		tabImportedBy,
 		tabLicenses,
+		tabTests,
 	}
 	for _, test := range []struct {
 		name     string
@@ -99,19 +100,19 @@ func TestIsValidTab(t *testing.T) {
 		{
 			name:     "package",
 			um:       sample.UnitMeta(sample.ModulePath+"/go/packages", sample.ModulePath, sample.VersionString, "packages", true),
-			wantTabs: []string{tabMain, tabVersions, tabImports, tabImportedBy, tabLicenses},
+			wantTabs: []string{tabMain, tabVersions, tabImports, tabImportedBy, tabLicenses, tabTests},
 			details:  &LicensesDetails{IsRedistributable: true},
 		},
 		{
 			name:     "command",
 			um:       sample.UnitMeta(sample.ModulePath+"/cmd", sample.ModulePath, sample.VersionString, "main", true),
-			wantTabs: []string{tabMain, tabVersions, tabImports, tabImportedBy, tabLicenses},
+			wantTabs: []string{tabMain, tabVersions, tabImports, tabImportedBy, tabLicenses, tabTests},
 			details:  &LicensesDetails{IsRedistributable: true},
 		},
 		{
 			name:     "non-redist pkg",
 			um:       sample.UnitMeta(sample.ModulePath+"/go/packages", sample.ModulePath, sample.VersionString, "packages", false),
-			wantTabs: []string{tabMain, tabVersions, tabImports, tabImportedBy},
+			wantTabs: []string{tabMain, tabVersions, tabImports, tabImportedBy, tabTests},
 			details:  &LicensesDetails{IsRedistributable: false},
 		},
 	} {
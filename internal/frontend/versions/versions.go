@@ -88,18 +88,18 @@ type VersionSummary struct {
 }
 
 func FetchVersionsDetails(ctx context.Context, ds internal.DataSource, um *internal.UnitMeta, vc *vuln.Client) (*VersionsDetails, error) {
-	db, ok := ds.(internal.PostgresDB)
+	vds, ok := ds.(internal.VersionsDataSource)
 	if !ok {
-		// The proxydatasource does not support the imported by page.
 		return nil, serrors.DatasourceNotSupportedError()
 	}
-	versions, err := db.GetVersionsForPath(ctx, um.Path)
+	versions, err := vds.GetVersionsForPath(ctx, um.Path)
 	if err != nil {
 		return nil, err
 	}
 
+	// Symbol history is only available from the database.
 	sh := internal.NewSymbolHistory()
-	if !um.IsCommand() {
+	if db, ok := ds.(internal.PostgresDB); ok && !um.IsCommand() {
 		sh, err = db.GetSymbolHistory(ctx, um.Path, um.ModulePath)
 		if err != nil {
 			return nil, err
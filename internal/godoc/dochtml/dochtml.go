@@ -59,11 +59,37 @@ type RenderOptions struct {
 	FileLinkFunc     func(file string) (url string)
 	SourceLinkFunc   func(ast.Node) string
 	SinceVersionFunc func(name string) string
+	// DeferSinceVersions, if set, replaces SinceVersionFunc: the body holds
+	// a placeholder for the version of each symbol, which FillSinceVersions
+	// fills in later. It is for rendering before the versions are known.
+	DeferSinceVersions bool
+	// UsedByLinkFunc optionally returns the URL of the list of packages that
+	// use the symbol with the given name, like "F" or "T.M", to link to from
+	// its heading. It may return the empty string for no link. Constants and
+	// variables declared together with others are not linked.
+	UsedByLinkFunc func(name string) string
+	// DeferUsedByLinks, if set, replaces UsedByLinkFunc as DeferSinceVersions
+	// replaces SinceVersionFunc, with FillUsedByLinks filling in the links.
+	DeferUsedByLinks bool
 	// ModInfo optionally specifies information about the module the package
 	// belongs to in order to render module-related documentation.
 	ModInfo      *ModuleInfo
 	Limit        int64 // If zero, a default limit of 10 megabytes is used.
 	BuildContext internal.BuildContext
+	// Implements optionally maps the names of types to the interfaces they
+	// implement, and ImplementedBy the names of interfaces to the types that
+	// implement them. Both are written as an import path followed by a dot
+	// and a name, except for the predeclared "error".
+	Implements, ImplementedBy map[string][]string
+	// PromotedMethods and PromotedFields optionally map the names of types to
+	// the methods and fields promoted from their embedded types, each written
+	// as the name of the declaring type, in the form above, followed by a dot
+	// and the name of the member.
+	PromotedMethods, PromotedFields map[string][]string
+	// PromotedIncomplete optionally holds the names of types whose promoted
+	// members may be incomplete, because an embedded type could not be
+	// resolved.
+	PromotedIncomplete map[string]bool
 }
 
 // TemplateData holds the data passed to the HTML templates in this package.
@@ -80,9 +106,12 @@ type Parts struct {
 	Body          safehtml.HTML // main body of doc
 	Outline       safehtml.HTML // outline for large screens
 	MobileOutline safehtml.HTML // outline for mobile
-	Links         []render.Link // "Links" section of package doc
+	Links         []Link        // "Links" section of package doc
 }
 
+// A Link is a link in the "Links" section of a package doc.
+type Link = render.Link
+
 // Render renders package documentation HTML for the
 // provided file set and package, in separate parts.
 //
@@ -133,11 +162,19 @@ type item struct {
 	Doc                          string
 	Decl                         ast.Decl   // GenDecl for consts, vars and types; FuncDecl for functions
 	Name                         string     // for types and functions; empty for consts and vars
-	FullName                     string     // for methods, the type name + "." + Name; else same as Name
+	FullName                     string     // for methods, the type name + "." + Name; for a single const or var, its name; else same as Name
 	HeaderStart                  string     // text of header, before source link
 	Examples                     []*example // for types and functions; empty for vars and consts
 	IsDeprecated                 bool
 	Consts, Vars, Funcs, Methods []*item // for types
+	// Implements and ImplementedBy link to related interfaces and types;
+	// for types only.
+	Implements, ImplementedBy []render.Link
+	// Promoted lists the members promoted from embedded types, grouped by
+	// the type that declares them; for types only. PromotedIncomplete
+	// reports whether some of them may be missing.
+	Promoted           []*promotedGroup
+	PromotedIncomplete bool
 	// HTML-specific values, for types and functions
 	Kind        string // for data-kind attribute
 	HeaderClass string // class for header
@@ -162,11 +199,15 @@ func valuesToItems(vs []*doc.Value) []*item {
 }
 
 func valueToItem(v *doc.Value) *item {
-	return &item{
+	i := &item{
 		Doc:          v.Doc,
 		Decl:         v.Decl,
 		IsDeprecated: valueIsDeprecated(v),
 	}
+	if len(v.Names) == 1 {
+		i.FullName = v.Names[0]
+	}
+	return i
 }
 
 func funcsToItems(fs []*doc.Func, hclass, typeName string, exmap map[string][]*example) []*item {
@@ -251,20 +292,17 @@ func renderInfo(ctx context.Context, fset *token.FileSet, p *doc.Package, opt Re
 		delete(p.Notes, k)
 	}
 
+	packageURL := func(path string) string {
+		// Use the same module version for imported packages that belong to
+		// the same module.
+		versionedPath := path
+		if opt.ModInfo != nil {
+			versionedPath = versionedPkgPath(path, opt.ModInfo)
+		}
+		return "/" + versionedPath + LinkQuery(opt.BuildContext)
+	}
 	r := render.New(ctx, fset, p, &render.Options{
-		PackageURL: func(path string) string {
-			// Use the same module version for imported packages that belong to
-			// the same module.
-			versionedPath := path
-			if opt.ModInfo != nil {
-				versionedPath = versionedPkgPath(path, opt.ModInfo)
-			}
-			var search string
-			if opt.BuildContext.GOOS != "" && opt.BuildContext.GOOS != "all" {
-				search = "?GOOS=" + opt.BuildContext.GOOS
-			}
-			return "/" + versionedPath + search
-		},
+		PackageURL: packageURL,
 	})
 
 	fileLink := func(name string) safehtml.HTML {
@@ -274,7 +312,19 @@ func renderInfo(ctx context.Context, fset *token.FileSet, p *doc.Package, opt Re
 		return linkHTML(name, opt.SourceLinkFunc(node), "Documentation-source")
 	}
 	sinceVersion := func(name string) safehtml.HTML {
-		return safehtml.HTMLEscaped(opt.SinceVersionFunc(name))
+		if opt.DeferSinceVersions {
+			return sinceVersionPlaceholder(name)
+		}
+		return sinceVersionHTML(opt.SinceVersionFunc(name))
+	}
+	usedByLink := func(name string) safehtml.HTML {
+		if opt.DeferUsedByLinks {
+			return usedByLinkPlaceholder(name)
+		}
+		if opt.UsedByLinkFunc == nil {
+			return safehtml.HTML{}
+		}
+		return usedByLinkHTML(opt.UsedByLinkFunc(name))
 	}
 	funcs := map[string]any{
 		"render_short_synopsis":    r.ShortSynopsis,
@@ -286,6 +336,7 @@ func renderInfo(ctx context.Context, fset *token.FileSet, p *doc.Package, opt Re
 		"file_link":                fileLink,
 		"source_link":              sourceLink,
 		"since_version":            sinceVersion,
+		"used_by_link":             usedByLink,
 	}
 	examples := collectExamples(p)
 	data := TemplateData{
@@ -295,9 +346,29 @@ func renderInfo(ctx context.Context, fset *token.FileSet, p *doc.Package, opt Re
 		NoteHeaders: buildNoteHeaders(p.Notes),
 	}
 	data.Consts, data.Vars, data.Funcs, data.Types = packageToItems(p, examples.Map)
+	for _, t := range data.Types {
+		t.Implements = typeLinks(opt.Implements[t.Name], p.ImportPath, packageURL)
+		t.ImplementedBy = typeLinks(opt.ImplementedBy[t.Name], p.ImportPath, packageURL)
+		t.Promoted = append(promotedGroups("Methods", opt.PromotedMethods[t.Name], p.ImportPath, packageURL),
+			promotedGroups("Fields", opt.PromotedFields[t.Name], p.ImportPath, packageURL)...)
+		t.PromotedIncomplete = opt.PromotedIncomplete[t.Name]
+	}
 	return funcs, data, r.Links
 }
 
+// LinkQuery returns the query that links to other packages in documentation
+// rendered for bc carry, so that following them keeps the build context.
+func LinkQuery(bc internal.BuildContext) string {
+	if bc.GOOS == "" || bc.GOOS == internal.All {
+		return ""
+	}
+	q := "?GOOS=" + bc.GOOS
+	if bc.Tags != "" {
+		q += "&tags=" + bc.Tags
+	}
+	return q
+}
+
 // executeToHTMLWithLimit executes tmpl on data and returns the result as a safehtml.HTML.
 // It returns an error if the size of the result exceeds limit.
 func executeToHTMLWithLimit(tmpl *template.Template, data any, limit int64) (safehtml.HTML, error) {
@@ -90,6 +90,64 @@ func TestRenderDeprecated(t *testing.T) {
 	compareWithGolden(t, parts, "deprecated-on", *update)
 }
 
+func TestRenderImplements(t *testing.T) {
+	LoadTemplates(templateFS)
+	fset, d := mustLoadPackage("everydecl")
+	opts := testRenderOptions
+	opts.Implements = map[string][]string{
+		"S1": {"error", "everydecl.I1", "io.Reader"},
+	}
+	opts.ImplementedBy = map[string][]string{
+		"I1": {"everydecl.S1", "example.com/module/pkg.T"},
+	}
+	parts, err := Render(context.Background(), fset, d, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body := parts.Body.String()
+	for _, want := range []string{
+		`<a href="/builtin#error">error</a>`,
+		`<a href="#I1">I1</a>`,
+		`<a href="/io#Reader">io.Reader</a>`,
+		`<a href="#S1">S1</a>`,
+		`<a href="/example.com/module@v1.2.3/pkg#T">pkg.T</a>`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("body does not contain %s", want)
+		}
+	}
+}
+
+func TestRenderPromoted(t *testing.T) {
+	LoadTemplates(templateFS)
+	fset, d := mustLoadPackage("everydecl")
+	opts := testRenderOptions
+	opts.PromotedMethods = map[string][]string{
+		"S2": {"error.Error", "everydecl.unexported.M", "net/http.Server.Close", "net/http.Server.Serve"},
+	}
+	opts.PromotedFields = map[string][]string{
+		"S2": {"everydecl.S1.F"},
+	}
+	opts.PromotedIncomplete = map[string]bool{"S2": true}
+	parts, err := Render(context.Background(), fset, d, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Collapse whitespace to make the HTML easier to match.
+	body := strings.Join(strings.Fields(parts.Body.String()), " ")
+	for _, want := range []string{
+		`Methods promoted from <a href="/builtin#error">error</a>:</span> Error </p>`,
+		`Methods promoted from unexported:</span> M </p>`,
+		`Methods promoted from <a href="/net/http#Server">http.Server</a>:</span> <a href="/net/http#Server.Close">Close</a>, <a href="/net/http#Server.Serve">Serve</a> </p>`,
+		`Fields promoted from <a href="#S1">S1</a>:</span> <a href="#S1.F">F</a> </p>`,
+		`Some members promoted from embedded types of other modules may not be shown.`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("body does not contain %s", want)
+		}
+	}
+}
+
 func compareWithGolden(t *testing.T, parts *Parts, name string, update bool) {
 	got := fmt.Sprintf("%s\n----\n%s\n----\n%s\n", parts.Body, parts.Outline, parts.MobileOutline)
 	// Remove blank lines and whitespace around lines.
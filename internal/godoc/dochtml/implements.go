@@ -0,0 +1,38 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dochtml
+
+import (
+	"strings"
+
+	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/godoc/dochtml/internal/render"
+)
+
+// typeLinks returns links to the types or interfaces named by names, each an
+// import path followed by a dot and a name, as in RenderOptions.Implements.
+// Names in the package with import path pkgPath link to the same page, and
+// others to the page of their package, using packageURL.
+func typeLinks(names []string, pkgPath string, packageURL func(string) string) []render.Link {
+	var links []render.Link
+	for _, n := range names {
+		i := strings.LastIndexByte(n, '.')
+		if i < 0 {
+			// A predeclared type, like "error".
+			links = append(links, render.Link{Href: "/builtin#" + n, Text: n})
+			continue
+		}
+		importPath, name := n[:i], n[i+1:]
+		if importPath == pkgPath {
+			links = append(links, render.Link{Href: "#" + name, Text: name})
+			continue
+		}
+		links = append(links, render.Link{
+			Href: packageURL(importPath) + "#" + name,
+			Text: internal.DefaultPackageName(importPath) + "." + name,
+		})
+	}
+	return links
+}
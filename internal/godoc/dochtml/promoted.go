@@ -0,0 +1,54 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dochtml
+
+import (
+	"go/token"
+	"strings"
+
+	"golang.org/x/pkgsite/internal/godoc/dochtml/internal/render"
+)
+
+// A promotedGroup is a list of methods or fields promoted to a type from one
+// of its embedded types.
+type promotedGroup struct {
+	Kind    string      // "Methods" or "Fields"
+	From    render.Link // the embedded type that declares the members
+	Members []render.Link
+}
+
+// promotedGroups groups the members named by names, as in
+// RenderOptions.PromotedMethods, by the type that declares them, preserving
+// their order. Members of unexported and predeclared types are not linked,
+// since they have no documentation of their own.
+func promotedGroups(kind string, names []string, pkgPath string, packageURL func(string) string) []*promotedGroup {
+	var (
+		groups   []*promotedGroup
+		lastType string
+	)
+	for _, n := range names {
+		i := strings.LastIndexByte(n, '.')
+		if i < 0 {
+			continue
+		}
+		typeName, member := n[:i], n[i+1:]
+		if len(groups) == 0 || typeName != lastType {
+			lastType = typeName
+			from := typeLinks([]string{typeName}, pkgPath, packageURL)[0]
+			if j := strings.LastIndexByte(typeName, '.'); j >= 0 && !token.IsExported(typeName[j+1:]) {
+				from.Href = ""
+			}
+			groups = append(groups, &promotedGroup{Kind: kind, From: from})
+		}
+		g := groups[len(groups)-1]
+		link := render.Link{Text: member}
+		if g.From.Href != "" && strings.Contains(typeName, ".") {
+			// Members have ids like "T.M" on the page of their type.
+			link.Href = g.From.Href + "." + member
+		}
+		g.Members = append(g.Members, link)
+	}
+	return groups
+}
@@ -0,0 +1,51 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dochtml
+
+import (
+	"regexp"
+
+	"github.com/google/safehtml"
+	"github.com/google/safehtml/template"
+	"github.com/google/safehtml/uncheckedconversions"
+	"golang.org/x/pkgsite/internal/godoc/dochtml/internal/render"
+)
+
+var sinceVersionTemplate = template.Must(template.New("since").Parse(
+	`<span class="Documentation-sinceVersionLabel">added in</span>` + "\n" +
+		`<span class="Documentation-sinceVersionVersion">{{.}}</span>`))
+
+// sinceVersionHTML returns the HTML that says that a symbol was added in
+// version v, or nothing if v is empty.
+func sinceVersionHTML(v string) safehtml.HTML {
+	if v == "" {
+		return safehtml.HTML{}
+	}
+	return render.ExecuteToHTML(sinceVersionTemplate, v)
+}
+
+// sinceVersionPlaceholder returns the placeholder for the version that
+// introduced the symbol name, when RenderOptions.DeferSinceVersions is set.
+// Symbol names are Go identifiers joined by dots, so they can't end the
+// comment.
+func sinceVersionPlaceholder(name string) safehtml.HTML {
+	return uncheckedconversions.HTMLFromStringKnownToSatisfyTypeContract("<!--since:" + name + "-->")
+}
+
+var sinceVersionPlaceholderRegexp = regexp.MustCompile(`<!--since:([^>]*)-->`)
+
+// FillSinceVersions replaces the placeholders in body, which was rendered
+// with RenderOptions.DeferSinceVersions, with the versions returned by
+// sinceVersion, as if it had been the SinceVersionFunc of the RenderOptions.
+//
+// Comments in the documentation itself are escaped, so they are never taken
+// for placeholders.
+func FillSinceVersions(body safehtml.HTML, sinceVersion func(name string) string) safehtml.HTML {
+	s := sinceVersionPlaceholderRegexp.ReplaceAllStringFunc(body.String(), func(p string) string {
+		name := sinceVersionPlaceholderRegexp.FindStringSubmatch(p)[1]
+		return sinceVersionHTML(sinceVersion(name)).String()
+	})
+	return uncheckedconversions.HTMLFromStringKnownToSatisfyTypeContract(s)
+}
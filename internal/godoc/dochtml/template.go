@@ -66,6 +66,7 @@ var tmpl = map[string]any{
 	"file_link":                func() string { return "" },
 	"source_link":              func(string, any) string { return "" },
 	"since_version":            func(string) safehtml.HTML { return safehtml.HTML{} },
+	"used_by_link":             func(string) safehtml.HTML { return safehtml.HTML{} },
 	"play_url":                 func(*doc.Example) string { return "" },
 	"safe_id":                  render.SafeGoID,
 }
@@ -0,0 +1,47 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dochtml
+
+import (
+	"regexp"
+
+	"github.com/google/safehtml"
+	"github.com/google/safehtml/uncheckedconversions"
+	"golang.org/x/pkgsite/internal/godoc/dochtml/internal/render"
+)
+
+// usedByLinkHTML returns a link to url, the list of the packages that use a
+// symbol, or nothing if url is empty.
+func usedByLinkHTML(url string) safehtml.HTML {
+	if url == "" {
+		return safehtml.HTML{}
+	}
+	return safehtml.HTMLConcat(safehtml.HTMLEscaped(" "),
+		render.ExecuteToHTML(render.LinkTemplate, render.Link{Class: "Documentation-usedBy", Href: url, Text: "Used by"}))
+}
+
+// usedByLinkPlaceholder returns the placeholder for the link to the users of
+// the symbol name, when RenderOptions.DeferUsedByLinks is set. As for
+// sinceVersionPlaceholder, symbol names can't end the comment.
+func usedByLinkPlaceholder(name string) safehtml.HTML {
+	return uncheckedconversions.HTMLFromStringKnownToSatisfyTypeContract("<!--usedby:" + name + "-->")
+}
+
+var usedByLinkPlaceholderRegexp = regexp.MustCompile(`<!--usedby:([^>]*)-->`)
+
+// FillUsedByLinks replaces the placeholders in body, which was rendered with
+// RenderOptions.DeferUsedByLinks, with links to the URLs returned by
+// usedByLink, as if it had been the UsedByLinkFunc of the RenderOptions.
+// A nil usedByLink removes the placeholders.
+func FillUsedByLinks(body safehtml.HTML, usedByLink func(name string) string) safehtml.HTML {
+	s := usedByLinkPlaceholderRegexp.ReplaceAllStringFunc(body.String(), func(p string) string {
+		if usedByLink == nil {
+			return ""
+		}
+		name := usedByLinkPlaceholderRegexp.FindStringSubmatch(p)[1]
+		return usedByLinkHTML(usedByLink(name)).String()
+	})
+	return uncheckedconversions.HTMLFromStringKnownToSatisfyTypeContract(s)
+}
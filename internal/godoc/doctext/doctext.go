@@ -0,0 +1,338 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package doctext renders Go package documentation as Markdown, plain text
+// and JSON, for clients that can't use the HTML produced by package dochtml.
+package doctext
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/doc"
+	"go/format"
+	"go/printer"
+	"go/token"
+	"strings"
+)
+
+// A Package is the documentation of a package. Its exported fields are the
+// structure of the JSON rendering.
+type Package struct {
+	Name       string
+	ImportPath string
+	Synopsis   string
+	// Doc is the package comment. Like the Doc fields of the declarations,
+	// it is in doc comment syntax.
+	Doc string
+	// MinGoVersion is the earliest Go release, like "go1.21", whose
+	// standard library has every symbol the package uses, or empty if it is
+	// unknown. New doesn't set it.
+	MinGoVersion string     `json:",omitempty"`
+	Constants    []*Value   `json:",omitempty"`
+	Variables    []*Value   `json:",omitempty"`
+	Functions    []*Func    `json:",omitempty"`
+	Types        []*Type    `json:",omitempty"`
+	Examples     []*Example `json:",omitempty"`
+
+	pkg *doc.Package
+}
+
+// A Value is a declaration of one or more constants or variables.
+type Value struct {
+	Names []string
+	Decl  string
+	Doc   string `json:",omitempty"`
+}
+
+// A Func is a function or method.
+type Func struct {
+	Name string
+	// Recv is the receiver type of a method, like "T" or "*T".
+	Recv     string `json:",omitempty"`
+	Decl     string
+	Doc      string     `json:",omitempty"`
+	Examples []*Example `json:",omitempty"`
+}
+
+// A Type is a type declaration, with the constants, variables and functions
+// associated with it.
+type Type struct {
+	Name      string
+	Decl      string
+	Doc       string     `json:",omitempty"`
+	Constants []*Value   `json:",omitempty"`
+	Variables []*Value   `json:",omitempty"`
+	Functions []*Func    `json:",omitempty"`
+	Methods   []*Func    `json:",omitempty"`
+	Examples  []*Example `json:",omitempty"`
+}
+
+// An Example is a testable example.
+type Example struct {
+	// Suffix distinguishes examples of the same declaration, as in
+	// "ExampleF_suffix".
+	Suffix string `json:",omitempty"`
+	Doc    string `json:",omitempty"`
+	// Code is a complete program if the example can be run on its own, and
+	// the body of the example function otherwise.
+	Code   string
+	Output string `json:",omitempty"`
+}
+
+// New returns the documentation of the package p, whose declarations are in
+// fset.
+func New(fset *token.FileSet, p *doc.Package) (_ *Package, err error) {
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("doctext.New(%q): %v", p.ImportPath, err)
+		}
+	}()
+
+	// Record the first error, so that each call below needn't check.
+	print := func(n any) string {
+		if err != nil {
+			return ""
+		}
+		var buf bytes.Buffer
+		err = format.Node(&buf, fset, n)
+		return buf.String()
+	}
+	values := func(vs []*doc.Value) []*Value {
+		var r []*Value
+		for _, v := range vs {
+			// The doc comment is rendered separately.
+			decl := *v.Decl
+			decl.Doc = nil
+			r = append(r, &Value{Names: v.Names, Decl: print(&decl), Doc: v.Doc})
+		}
+		return r
+	}
+	examples := func(exs []*doc.Example) []*Example {
+		var r []*Example
+		for _, ex := range exs {
+			r = append(r, &Example{
+				Suffix: ex.Suffix,
+				Doc:    ex.Doc,
+				Code:   exampleCode(ex, print),
+				Output: ex.Output,
+			})
+		}
+		return r
+	}
+	funcs := func(fs []*doc.Func) []*Func {
+		var r []*Func
+		for _, f := range fs {
+			r = append(r, &Func{
+				Name:     f.Name,
+				Recv:     f.Recv,
+				Decl:     print(f.Decl),
+				Doc:      f.Doc,
+				Examples: examples(f.Examples),
+			})
+		}
+		return r
+	}
+
+	dp := &Package{
+		Name:       p.Name,
+		ImportPath: p.ImportPath,
+		Synopsis:   p.Synopsis(p.Doc),
+		Doc:        p.Doc,
+		Constants:  values(p.Consts),
+		Variables:  values(p.Vars),
+		Functions:  funcs(p.Funcs),
+		Examples:   examples(p.Examples),
+		pkg:        p,
+	}
+	for _, t := range p.Types {
+		dp.Types = append(dp.Types, &Type{
+			Name:      t.Name,
+			Decl:      print(t.Decl),
+			Doc:       t.Doc,
+			Constants: values(t.Consts),
+			Variables: values(t.Vars),
+			Functions: funcs(t.Funcs),
+			Methods:   funcs(t.Methods),
+			Examples:  examples(t.Examples),
+		})
+	}
+	if err != nil {
+		return nil, err
+	}
+	return dp, nil
+}
+
+// exampleCode returns the code of ex, printed with print.
+func exampleCode(ex *doc.Example, print func(any) string) string {
+	if ex.Play != nil {
+		return print(ex.Play)
+	}
+	var n any = ex.Code
+	if len(ex.Comments) > 0 {
+		n = &printer.CommentedNode{Node: ex.Code, Comments: ex.Comments}
+	}
+	code := print(n)
+	// Remove the braces around the body of the example function.
+	if _, ok := ex.Code.(*ast.BlockStmt); ok {
+		code = strings.TrimSuffix(strings.TrimPrefix(code, "{\n"), "}")
+		var lines []string
+		for _, l := range strings.Split(strings.TrimRight(code, "\n"), "\n") {
+			lines = append(lines, strings.TrimPrefix(l, "\t"))
+		}
+		code = strings.Join(lines, "\n") + "\n"
+	}
+	return code
+}
+
+// Markdown renders the documentation as Markdown.
+func (p *Package) Markdown() []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "# package %s\n\n", p.Name)
+	fmt.Fprintf(&buf, "    import %q\n\n", p.ImportPath)
+	p.markdownDoc(&buf, p.Doc, 2)
+	p.markdownExamples(&buf, p.Examples)
+
+	markdownValues := func(title string, vs []*Value) {
+		if len(vs) > 0 {
+			fmt.Fprintf(&buf, "## %s\n\n", title)
+		}
+		for _, v := range vs {
+			markdownCode(&buf, "go", v.Decl)
+			p.markdownDoc(&buf, v.Doc, 3)
+		}
+	}
+	markdownFuncs := func(level int, fs []*Func) {
+		for _, f := range fs {
+			name := f.Name
+			if f.Recv != "" {
+				name = fmt.Sprintf("(%s) %s", f.Recv, f.Name)
+			}
+			fmt.Fprintf(&buf, "%s func %s\n\n", strings.Repeat("#", level), name)
+			markdownCode(&buf, "go", f.Decl)
+			p.markdownDoc(&buf, f.Doc, level+1)
+			p.markdownExamples(&buf, f.Examples)
+		}
+	}
+
+	markdownValues("Constants", p.Constants)
+	markdownValues("Variables", p.Variables)
+	if len(p.Functions) > 0 {
+		buf.WriteString("## Functions\n\n")
+		markdownFuncs(3, p.Functions)
+	}
+	if len(p.Types) > 0 {
+		buf.WriteString("## Types\n\n")
+	}
+	for _, t := range p.Types {
+		fmt.Fprintf(&buf, "### type %s\n\n", t.Name)
+		markdownCode(&buf, "go", t.Decl)
+		p.markdownDoc(&buf, t.Doc, 4)
+		p.markdownExamples(&buf, t.Examples)
+		for _, vs := range [][]*Value{t.Constants, t.Variables} {
+			for _, v := range vs {
+				markdownCode(&buf, "go", v.Decl)
+				p.markdownDoc(&buf, v.Doc, 4)
+			}
+		}
+		markdownFuncs(4, t.Functions)
+		markdownFuncs(4, t.Methods)
+	}
+	return buf.Bytes()
+}
+
+// markdownDoc writes the doc comment text as Markdown, with headings at the
+// given level.
+func (p *Package) markdownDoc(buf *bytes.Buffer, text string, level int) {
+	if text == "" {
+		return
+	}
+	pr := p.pkg.Printer()
+	pr.HeadingLevel = level
+	buf.Write(pr.Markdown(p.pkg.Parser().Parse(text)))
+	buf.WriteString("\n")
+}
+
+func (p *Package) markdownExamples(buf *bytes.Buffer, exs []*Example) {
+	for _, ex := range exs {
+		buf.WriteString("Example")
+		if ex.Suffix != "" {
+			fmt.Fprintf(buf, " (%s)", ex.Suffix)
+		}
+		buf.WriteString(":\n\n")
+		markdownCode(buf, "go", ex.Code)
+		if ex.Output != "" {
+			buf.WriteString("Output:\n\n")
+			markdownCode(buf, "", ex.Output)
+		}
+	}
+}
+
+// markdownCode writes code as a fenced code block in the given language.
+func markdownCode(buf *bytes.Buffer, lang, code string) {
+	// Use a fence longer than any run of backquotes in the code.
+	fence := "```"
+	for strings.Contains(code, fence) {
+		fence += "`"
+	}
+	fmt.Fprintf(buf, "%s%s\n%s\n%s\n\n", fence, lang, strings.TrimSuffix(code, "\n"), fence)
+}
+
+// Text renders the documentation as plain text, in the format of
+// "go doc -all".
+func (p *Package) Text() []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "package %s // import %q\n\n", p.Name, p.ImportPath)
+	p.textDoc(&buf, p.Doc, "")
+
+	textValues := func(vs []*Value) {
+		for _, v := range vs {
+			fmt.Fprintf(&buf, "%s\n", v.Decl)
+			p.textDoc(&buf, v.Doc, "    ")
+		}
+	}
+	textFuncs := func(fs []*Func) {
+		for _, f := range fs {
+			fmt.Fprintf(&buf, "%s\n", f.Decl)
+			p.textDoc(&buf, f.Doc, "    ")
+		}
+	}
+	section := func(title string, n int) {
+		if n > 0 {
+			fmt.Fprintf(&buf, "\n%s\n\n", title)
+		}
+	}
+
+	section("CONSTANTS", len(p.Constants))
+	textValues(p.Constants)
+	section("VARIABLES", len(p.Variables))
+	textValues(p.Variables)
+	section("FUNCTIONS", len(p.Functions))
+	textFuncs(p.Functions)
+	section("TYPES", len(p.Types))
+	for _, t := range p.Types {
+		fmt.Fprintf(&buf, "%s\n", t.Decl)
+		p.textDoc(&buf, t.Doc, "    ")
+		textValues(t.Constants)
+		textValues(t.Variables)
+		textFuncs(t.Functions)
+		textFuncs(t.Methods)
+	}
+	return buf.Bytes()
+}
+
+// textDoc writes the doc comment text as plain text, with each line
+// prefixed by prefix, followed by a blank line.
+func (p *Package) textDoc(buf *bytes.Buffer, text, prefix string) {
+	if text == "" {
+		if prefix != "" {
+			buf.WriteString("\n")
+		}
+		return
+	}
+	pr := p.pkg.Printer()
+	pr.TextPrefix = prefix
+	buf.Write(pr.Text(p.pkg.Parser().Parse(text)))
+	buf.WriteString("\n")
+}
@@ -0,0 +1,173 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package doctext
+
+import (
+	"encoding/json"
+	"go/ast"
+	"go/doc"
+	"go/parser"
+	"go/token"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+const (
+	src = `// Package p does things.
+//
+// # Usage
+//
+// Call [F].
+package p
+
+// C is a constant.
+const C = 1
+
+// F does a thing.
+func F() {}
+
+// T is a type.
+type T struct{}
+
+// NewT returns a T.
+func NewT() *T { return nil }
+
+// M is a method.
+func (t *T) M() {}
+`
+	testSrc = `package p_test
+
+import (
+	"fmt"
+
+	"example.com/p"
+)
+
+func ExampleF() {
+	p.F()
+	fmt.Println("done")
+	// Output: done
+}
+`
+)
+
+func newTestPackage(t *testing.T) *Package {
+	t.Helper()
+	fset := token.NewFileSet()
+	var files []*ast.File
+	for name, s := range map[string]string{"p.go": src, "p_test.go": testSrc} {
+		f, err := parser.ParseFile(fset, name, s, parser.ParseComments)
+		if err != nil {
+			t.Fatal(err)
+		}
+		files = append(files, f)
+	}
+	d, err := doc.NewFromFiles(fset, files, "example.com/p")
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := New(fset, d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func TestMarkdown(t *testing.T) {
+	got := string(newTestPackage(t).Markdown())
+	want := "# package p\n\n" +
+		"    import \"example.com/p\"\n\n" +
+		"Package p does things.\n\n" +
+		"## Usage {#hdr-Usage}\n\n" +
+		"Call [F](#F).\n\n" +
+		"## Constants\n\n" +
+		"```go\nconst C = 1\n```\n\n" +
+		"C is a constant.\n\n" +
+		"## Functions\n\n" +
+		"### func F\n\n" +
+		"```go\nfunc F()\n```\n\n" +
+		"F does a thing.\n\n" +
+		"Example:\n\n" +
+		"```go\npackage main\n\nimport (\n\t\"fmt\"\n\n\t\"example.com/p\"\n)\n\nfunc main() {\n\tp.F()\n\tfmt.Println(\"done\")\n}\n```\n\n" +
+		"Output:\n\n" +
+		"```\ndone\n```\n\n" +
+		"## Types\n\n" +
+		"### type T\n\n" +
+		"```go\ntype T struct{}\n```\n\n" +
+		"T is a type.\n\n" +
+		"#### func NewT\n\n" +
+		"```go\nfunc NewT() *T\n```\n\n" +
+		"NewT returns a T.\n\n" +
+		"#### func (*T) M\n\n" +
+		"```go\nfunc (t *T) M()\n```\n\n" +
+		"M is a method.\n\n"
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("mismatch (-want, +got):\n%s", diff)
+	}
+}
+
+func TestText(t *testing.T) {
+	got := string(newTestPackage(t).Text())
+	want := `package p // import "example.com/p"
+
+Package p does things.
+
+# Usage
+
+Call F.
+
+
+CONSTANTS
+
+const C = 1
+    C is a constant.
+
+
+FUNCTIONS
+
+func F()
+    F does a thing.
+
+
+TYPES
+
+type T struct{}
+    T is a type.
+
+func NewT() *T
+    NewT returns a T.
+
+func (t *T) M()
+    M is a method.
+
+`
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("mismatch (-want, +got):\n%s", diff)
+	}
+}
+
+func TestJSON(t *testing.T) {
+	data, err := json.Marshal(newTestPackage(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got Package
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.ImportPath != "example.com/p" || got.Synopsis != "Package p does things." {
+		t.Errorf("got import path %q and synopsis %q", got.ImportPath, got.Synopsis)
+	}
+	if len(got.Functions) != 1 || len(got.Functions[0].Examples) != 1 {
+		t.Fatalf("got functions %+v, want F with one example", got.Functions)
+	}
+	if ex := got.Functions[0].Examples[0]; ex.Output != "done\n" {
+		t.Errorf("got example output %q, want %q", ex.Output, "done\n")
+	}
+	if len(got.Types) != 1 || len(got.Types[0].Methods) != 1 || got.Types[0].Methods[0].Recv != "*T" {
+		t.Errorf("got types %+v, want T with method M on *T", got.Types)
+	}
+}
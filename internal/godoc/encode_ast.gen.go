@@ -3493,7 +3493,7 @@ func init() {
 		})
 }
 
-// Fields of encPackage: GOOS GOARCH Files ModulePackagePaths
+// Fields of encPackage: GOOS GOARCH Files ModulePackagePaths Implements ImplementedBy PromotedMethods PromotedFields PromotedIncomplete
 
 func encode_encPackage(e *codec.Encoder, x *encPackage) {
 	if !e.StartStruct(x == nil, x) {
@@ -3508,6 +3508,26 @@ func encode_encPackage(e *codec.Encoder, x *encPackage) {
 		e.EncodeUint(3)
 		encode_map_string_bool(e, x.ModulePackagePaths)
 	}
+	if x.Implements != nil {
+		e.EncodeUint(4)
+		encode_map_string_slice_string(e, x.Implements)
+	}
+	if x.ImplementedBy != nil {
+		e.EncodeUint(5)
+		encode_map_string_slice_string(e, x.ImplementedBy)
+	}
+	if x.PromotedMethods != nil {
+		e.EncodeUint(6)
+		encode_map_string_slice_string(e, x.PromotedMethods)
+	}
+	if x.PromotedFields != nil {
+		e.EncodeUint(7)
+		encode_map_string_slice_string(e, x.PromotedFields)
+	}
+	if x.PromotedIncomplete != nil {
+		e.EncodeUint(8)
+		encode_map_string_bool(e, x.PromotedIncomplete)
+	}
 	e.EndStruct()
 }
 
@@ -3532,6 +3552,16 @@ func decode_encPackage(d *codec.Decoder, p **encPackage) {
 			decode_slice_File(d, &x.Files)
 		case 3:
 			decode_map_string_bool(d, &x.ModulePackagePaths)
+		case 4:
+			decode_map_string_slice_string(d, &x.Implements)
+		case 5:
+			decode_map_string_slice_string(d, &x.ImplementedBy)
+		case 6:
+			decode_map_string_slice_string(d, &x.PromotedMethods)
+		case 7:
+			decode_map_string_slice_string(d, &x.PromotedFields)
+		case 8:
+			decode_map_string_bool(d, &x.PromotedIncomplete)
 		default:
 			d.UnknownField("encPackage", n)
 		}
@@ -3899,6 +3929,41 @@ func init() {
 		func(d *codec.Decoder) any { var x map[string]bool; decode_map_string_bool(d, &x); return x })
 }
 
+func encode_map_string_slice_string(e *codec.Encoder, m map[string][]string) {
+	if m == nil {
+		e.EncodeNil()
+		return
+	}
+	e.StartList(2 * len(m))
+	for k, v := range m {
+		e.EncodeString(k)
+		encode_slice_string(e, v)
+	}
+}
+
+func decode_map_string_slice_string(d *codec.Decoder, p *map[string][]string) {
+	n2 := d.StartList()
+	if n2 < 0 {
+		return
+	}
+	n := n2 / 2
+	m := make(map[string][]string, n)
+	var k string
+	var v []string
+	for i := 0; i < n; i++ {
+		k = d.DecodeString()
+		decode_slice_string(d, &v)
+		m[k] = v
+	}
+	*p = m
+}
+
+func init() {
+	codec.Register(map[string][]string(nil),
+		func(e *codec.Encoder, x any) { encode_map_string_slice_string(e, x.(map[string][]string)) },
+		func(d *codec.Decoder) any { var x map[string][]string; decode_map_string_slice_string(d, &x); return x })
+}
+
 // Fields of File: Name AST
 
 func encode_File(e *codec.Encoder, x *File) {
@@ -3954,6 +4019,35 @@ func init() {
 		})
 }
 
+func encode_slice_string(e *codec.Encoder, s []string) {
+	if s == nil {
+		e.EncodeNil()
+		return
+	}
+	e.StartList(len(s))
+	for _, x := range s {
+		e.EncodeString(x)
+	}
+}
+
+func decode_slice_string(d *codec.Decoder, p *[]string) {
+	n := d.StartList()
+	if n < 0 {
+		return
+	}
+	s := make([]string, n)
+	for i := 0; i < n; i++ {
+		s[i] = d.DecodeString()
+	}
+	*p = s
+}
+
+func init() {
+	codec.Register([]string(nil),
+		func(e *codec.Encoder, x any) { encode_slice_string(e, x.([]string)) },
+		func(d *codec.Decoder) any { var x []string; decode_slice_string(d, &x); return x })
+}
+
 // Fields of ast_File: Doc Package Name Decls Scope Imports Unresolved Comments
 
 func encode_ast_File(e *codec.Encoder, x *ast.File) {
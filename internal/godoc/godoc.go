@@ -7,7 +7,9 @@ package godoc
 
 import (
 	"go/ast"
+	"go/build/constraint"
 	"go/token"
+	"sort"
 	"strings"
 
 	"golang.org/x/pkgsite/internal/godoc/dochtml"
@@ -39,6 +41,23 @@ type Package struct {
 type encPackage struct { // fields that can be directly encoded
 	Files              []*File
 	ModulePackagePaths map[string]bool
+	// Implements maps the names of the package's types to the interfaces
+	// they implement, and ImplementedBy maps the names of the package's
+	// interfaces to the types that implement them. Types and interfaces are
+	// written as an import path followed by a dot and a name, except for the
+	// predeclared "error".
+	Implements    map[string][]string
+	ImplementedBy map[string][]string
+	// PromotedMethods and PromotedFields map the names of the package's types
+	// to the exported methods and fields promoted to them from embedded
+	// types. Each is written as the name of the embedded type that declares
+	// it, in the form above, followed by a dot and the name of the member.
+	PromotedMethods map[string][]string
+	PromotedFields  map[string][]string
+	// PromotedIncomplete holds the names of the package's types that embed
+	// a type that could not be resolved, so that some of their promoted
+	// members may be missing.
+	PromotedIncomplete map[string]bool
 }
 
 // A File contains everything needed about a source file to render documentation.
@@ -47,6 +66,47 @@ type File struct {
 	AST  *ast.File
 }
 
+// FileNames returns the names of the package's files, other than test files,
+// in sorted order.
+func (p *Package) FileNames() []string {
+	var names []string
+	for _, f := range p.Files {
+		if !strings.HasSuffix(f.Name, "_test.go") {
+			names = append(names, f.Name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// FileConstraints returns the //go:build constraints of the package's files,
+// other than test files, as a map from the name of each file that has one to
+// its expression. Constraints implied by file names, like those of
+// "f_windows.go", are not included.
+func (p *Package) FileConstraints() map[string]string {
+	cs := map[string]string{}
+	for _, f := range p.Files {
+		if strings.HasSuffix(f.Name, "_test.go") {
+			continue
+		}
+		// Build constraints must appear before the package clause.
+		for _, cg := range f.AST.Comments {
+			if cg.Pos() >= f.AST.Package {
+				break
+			}
+			for _, c := range cg.List {
+				if !constraint.IsGoBuild(c.Text) {
+					continue
+				}
+				if x, err := constraint.Parse(c.Text); err == nil {
+					cs[f.Name] = x.String()
+				}
+			}
+		}
+	}
+	return cs
+}
+
 // NewPackage returns a new Package with the given fset and set of module package paths.
 func NewPackage(fset *token.FileSet, modPaths map[string]bool) *Package {
 	return &Package{
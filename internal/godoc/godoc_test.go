@@ -6,6 +6,7 @@ package godoc
 
 import (
 	"bytes"
+	"context"
 	"go/format"
 	"go/parser"
 	"go/token"
@@ -110,3 +111,36 @@ func (t) U()
 		t.Errorf("mismatch (-want, +got):\n%s", diff)
 	}
 }
+
+func TestFileConstraints(t *testing.T) {
+	ctx := context.Background()
+	files := map[string]string{
+		"a.go":         "// Package p is for testing.\npackage p\n",
+		"b.go":         "// Copyright notice.\n\n//go:build linux && (amd64 || arm64)\n\npackage p\n\n//go:build ignored\nvar V int\n",
+		"b_windows.go": "package p\n",
+		"c_test.go":    "//go:build integration\n\npackage p\n",
+	}
+	fset := token.NewFileSet()
+	p := NewPackage(fset, nil)
+	for _, name := range []string{"a.go", "b.go", "b_windows.go", "c_test.go"} {
+		f, err := parser.ParseFile(fset, name, files[name], parser.ParseComments)
+		if err != nil {
+			t.Fatal(err)
+		}
+		p.AddFile(f, true)
+	}
+	// The constraints survive encoding.
+	data, err := p.Encode(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err = DecodePackage(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := p.FileConstraints()
+	want := map[string]string{"b.go": "linux && (amd64 || arm64)"}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("mismatch (-want +got):\n%s", diff)
+	}
+}
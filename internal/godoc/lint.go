@@ -0,0 +1,335 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package godoc
+
+import (
+	"fmt"
+	"go/ast"
+	"go/doc"
+	"go/doc/comment"
+	"go/token"
+	"path"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/derrors"
+	"golang.org/x/pkgsite/internal/stdlib"
+)
+
+// Lint checks the documentation of the package for common problems: exported
+// symbols without doc comments, doc comments that don't start with the name
+// of the symbol, doc links to symbols the package doesn't declare, and
+// examples named after symbols the package doesn't declare. Links to other
+// packages are not checked.
+//
+// Unlike DocInfo, Lint does not modify p's AST. The problems are sorted by
+// position.
+func (p *Package) Lint(innerPath string, modInfo *ModuleInfo) (_ []*internal.DocProblem, err error) {
+	defer derrors.Wrap(&err, "godoc.Package.Lint(%q, %q, %q)", modInfo.ModulePath, modInfo.ResolvedVersion, innerPath)
+
+	importPath := path.Join(modInfo.ModulePath, innerPath)
+	if modInfo.ModulePath == stdlib.ModulePath {
+		importPath = innerPath
+	}
+	var files []*ast.File
+	for _, f := range p.Files {
+		files = append(files, f.AST)
+	}
+	// Even with PreserveAST, go/doc removes unexported declarations from
+	// the AST unless it is asked for all of them, so do that and skip the
+	// unexported ones below.
+	d, err := doc.NewFromFiles(p.Fset, files, importPath, doc.AllDecls|doc.PreserveAST)
+	if err != nil {
+		return nil, fmt.Errorf("doc.NewFromFiles: %v", err)
+	}
+	l := &linter{fset: p.Fset, pkg: d}
+	l.parser = d.Parser()
+	l.lookupSym = l.parser.LookupSym
+	// Make every well-formed [Name] a link, so that checkLinks can report
+	// the ones to missing symbols.
+	l.parser.LookupSym = func(recv, name string) bool { return true }
+
+	l.lintPackage(files)
+	if d.Name != "main" {
+		// The exported symbols of a command are not part of an API.
+		l.lintValues(d.Consts, "constant")
+		l.lintValues(d.Vars, "variable")
+		l.lintFuncs(d.Funcs, "")
+		for _, t := range d.Types {
+			l.lintType(t)
+		}
+	}
+	for _, f := range p.Files {
+		if strings.HasSuffix(f.Name, "_test.go") {
+			l.lintExamples(f.AST)
+		}
+	}
+	internal.SortDocProblems(l.problems)
+	return l.problems, nil
+}
+
+type linter struct {
+	fset      *token.FileSet
+	pkg       *doc.Package
+	parser    *comment.Parser
+	lookupSym func(recv, name string) bool
+	problems  []*internal.DocProblem
+}
+
+func (l *linter) report(kind internal.DocProblemKind, symbol string, pos token.Pos, format string, args ...any) {
+	p := l.fset.Position(pos)
+	l.problems = append(l.problems, &internal.DocProblem{
+		Kind:     kind,
+		Symbol:   symbol,
+		Filename: path.Base(p.Filename),
+		Line:     p.Line,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+func (l *linter) lintPackage(files []*ast.File) {
+	if l.pkg.Doc != "" {
+		l.checkLinks("", l.pkg.Doc, l.packagePos(files))
+		return
+	}
+	l.report(internal.DocProblemMissing, "", l.packagePos(files), "package %s has no package comment", l.pkg.Name)
+}
+
+// packagePos returns the position of the package clause of the first non-test
+// file, in file name order.
+func (l *linter) packagePos(files []*ast.File) token.Pos {
+	var (
+		pos  token.Pos
+		name string
+	)
+	for _, f := range files {
+		fn := l.fset.Position(f.Package).Filename
+		if strings.HasSuffix(fn, "_test.go") {
+			continue
+		}
+		if name == "" || fn < name {
+			pos, name = f.Package, fn
+		}
+	}
+	return pos
+}
+
+func (l *linter) lintValues(vs []*doc.Value, kind string) {
+	for _, v := range vs {
+		name := firstExported(v.Names)
+		if name == "" {
+			continue
+		}
+		if v.Doc != "" {
+			l.checkLinks(name, v.Doc, v.Decl.Pos())
+			// Doc comments of groups describe the whole group.
+			if !v.Decl.Lparen.IsValid() {
+				l.checkPrefix(name, v.Doc, v.Decl.Pos(), false)
+			}
+			continue
+		}
+		// In a group without a doc comment, each spec with exported names
+		// needs a comment of its own.
+		for _, s := range v.Decl.Specs {
+			spec := s.(*ast.ValueSpec)
+			if spec.Doc != nil || spec.Comment != nil {
+				continue
+			}
+			for _, n := range spec.Names {
+				if ast.IsExported(n.Name) {
+					l.report(internal.DocProblemMissing, n.Name, n.Pos(), "exported %s %s has no doc comment", kind, n.Name)
+					break
+				}
+			}
+		}
+	}
+}
+
+func (l *linter) lintFuncs(fs []*doc.Func, recv string) {
+	for _, f := range fs {
+		if f.Level > 0 || !ast.IsExported(f.Name) {
+			// Promoted methods are checked where they are declared.
+			continue
+		}
+		name, kind := f.Name, "function"
+		if recv != "" {
+			name, kind = recv+"."+f.Name, "method"
+		}
+		if f.Doc == "" {
+			l.report(internal.DocProblemMissing, name, f.Decl.Name.Pos(), "exported %s %s has no doc comment", kind, name)
+			continue
+		}
+		l.checkPrefix(name, f.Doc, f.Decl.Name.Pos(), false)
+		l.checkLinks(name, f.Doc, f.Decl.Name.Pos())
+	}
+}
+
+func (l *linter) lintType(t *doc.Type) {
+	// Exported values and functions can be associated with unexported
+	// types.
+	l.lintValues(t.Consts, "constant")
+	l.lintValues(t.Vars, "variable")
+	l.lintFuncs(t.Funcs, "")
+	if !ast.IsExported(t.Name) {
+		return
+	}
+	pos := t.Decl.Pos()
+	for _, s := range t.Decl.Specs {
+		if ts := s.(*ast.TypeSpec); ts.Name.Name == t.Name {
+			pos = ts.Name.Pos()
+		}
+	}
+	if t.Doc == "" {
+		l.report(internal.DocProblemMissing, t.Name, pos, "exported type %s has no doc comment", t.Name)
+	} else {
+		if !t.Decl.Lparen.IsValid() || len(t.Decl.Specs) == 1 {
+			l.checkPrefix(t.Name, t.Doc, pos, true)
+		}
+		l.checkLinks(t.Name, t.Doc, pos)
+	}
+	l.lintFuncs(t.Methods, t.Name)
+}
+
+// firstExported returns the first exported name in names, or the empty string
+// if there is none.
+func firstExported(names []string) string {
+	for _, n := range names {
+		if ast.IsExported(n) {
+			return n
+		}
+	}
+	return ""
+}
+
+// checkPrefix reports a problem if the doc comment text of the symbol with
+// the given name doesn't start with the name. If article is true, the name may
+// follow "A", "An" or "The", as is common for types.
+func (l *linter) checkPrefix(name, text string, pos token.Pos, article bool) {
+	_, short, _ := strings.Cut(name, ".")
+	if short == "" {
+		short = name
+	}
+	if article {
+		for _, a := range []string{"A ", "An ", "The "} {
+			if strings.HasPrefix(text, a) {
+				text = text[len(a):]
+				break
+			}
+		}
+	}
+	if strings.HasPrefix(text, short+" ") || strings.HasPrefix(text, "Deprecated:") {
+		return
+	}
+	l.report(internal.DocProblemPrefix, name, pos, "doc comment of %s should start with %q", name, short+" ")
+}
+
+// checkLinks reports the doc links in the doc comment text of the symbol with
+// the given name that don't refer to symbols the package declares.
+func (l *linter) checkLinks(name, text string, pos token.Pos) {
+	var walkText func([]comment.Text)
+	walkText = func(ts []comment.Text) {
+		for _, t := range ts {
+			switch t := t.(type) {
+			case *comment.Link:
+				walkText(t.Text)
+			case *comment.DocLink:
+				if t.ImportPath == "" && t.Name != "" && !l.lookupSym(t.Recv, t.Name) {
+					target := t.Name
+					if t.Recv != "" {
+						target = t.Recv + "." + t.Name
+					}
+					l.report(internal.DocProblemLink, name, pos, "doc link [%s] does not refer to a declaration in package %s", target, l.pkg.Name)
+				}
+			}
+		}
+	}
+	var walkBlocks func([]comment.Block)
+	walkBlocks = func(bs []comment.Block) {
+		for _, b := range bs {
+			switch b := b.(type) {
+			case *comment.Paragraph:
+				walkText(b.Text)
+			case *comment.Heading:
+				walkText(b.Text)
+			case *comment.List:
+				for _, item := range b.Items {
+					walkBlocks(item.Content)
+				}
+			}
+		}
+	}
+	walkBlocks(l.parser.Parse(text).Content)
+}
+
+// lintExamples reports the examples in the test file f whose names don't
+// refer to a symbol of the package. Examples are named as described in the
+// documentation of the testing package: Example, ExampleF, ExampleT and
+// ExampleT_M, each optionally followed by an underscore and a suffix that
+// starts with a lower-case letter.
+func (l *linter) lintExamples(f *ast.File) {
+	for _, decl := range f.Decls {
+		fd, ok := decl.(*ast.FuncDecl)
+		if !ok || fd.Recv != nil || !isExampleName(fd.Name.Name) {
+			continue
+		}
+		if fd.Type.Params.NumFields() != 0 || fd.Type.Results.NumFields() != 0 {
+			continue
+		}
+		id := strings.TrimPrefix(fd.Name.Name, "Example")
+		if l.exampleTarget(id) {
+			continue
+		}
+		if i := strings.LastIndex(id, "_"); i >= 0 {
+			if r, _ := utf8.DecodeRuneInString(id[i+1:]); unicode.IsLower(r) && l.exampleTarget(id[:i]) {
+				continue
+			}
+		}
+		l.report(internal.DocProblemExample, "", fd.Name.Pos(), "example %s refers to unknown identifier %s", fd.Name.Name, id)
+	}
+}
+
+// isExampleName reports whether name is the name of an example function.
+func isExampleName(name string) bool {
+	if !strings.HasPrefix(name, "Example") {
+		return false
+	}
+	r, _ := utf8.DecodeRuneInString(name[len("Example"):])
+	return !unicode.IsLower(r)
+}
+
+// exampleTarget reports whether id names a function, type or method of the
+// package in the form used by examples, or is empty.
+func (l *linter) exampleTarget(id string) bool {
+	if id == "" {
+		return true
+	}
+	for _, f := range l.pkg.Funcs {
+		if f.Name == id {
+			return true
+		}
+	}
+	typ, method, isMethod := strings.Cut(id, "_")
+	for _, t := range l.pkg.Types {
+		if t.Name != typ {
+			for _, f := range t.Funcs {
+				if !isMethod && f.Name == id {
+					return true
+				}
+			}
+			continue
+		}
+		if !isMethod {
+			return true
+		}
+		for _, m := range t.Methods {
+			if m.Name == method {
+				return true
+			}
+		}
+	}
+	return false
+}
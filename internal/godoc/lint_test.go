@@ -0,0 +1,60 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package godoc
+
+import (
+	"bytes"
+	"go/format"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/pkgsite/internal"
+)
+
+func TestLint(t *testing.T) {
+	p, err := packageForDir(filepath.Join("testdata", "lint"), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	printFiles := func() string {
+		var buf bytes.Buffer
+		for _, f := range p.Files {
+			if err := format.Node(&buf, p.Fset, f.AST); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return buf.String()
+	}
+	before := printFiles()
+	mi := &ModuleInfo{ModulePath: "a.com/M", ResolvedVersion: "v1.2.3"}
+	got, err := p.Lint("lint", mi)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if after := printFiles(); after != before {
+		t.Error("Lint modified the AST")
+	}
+
+	problem := func(kind internal.DocProblemKind, symbol, filename string, line int, msg string) *internal.DocProblem {
+		return &internal.DocProblem{Kind: kind, Symbol: symbol, Filename: filename, Line: line, Message: msg}
+	}
+	want := []*internal.DocProblem{
+		problem(internal.DocProblemExample, "", "example_test.go", 11, "example ExampleMissing refers to unknown identifier Missing"),
+		problem(internal.DocProblemExample, "", "example_test.go", 13, "example ExampleT_Missing refers to unknown identifier T_Missing"),
+		problem(internal.DocProblemMissing, "", "lint.go", 1, "package lint has no package comment"),
+		problem(internal.DocProblemLink, "A", "lint.go", 4, "doc link [Missing] does not refer to a declaration in package lint"),
+		problem(internal.DocProblemLink, "A", "lint.go", 4, "doc link [T.Missing] does not refer to a declaration in package lint"),
+		problem(internal.DocProblemMissing, "B", "lint.go", 6, "exported constant B has no doc comment"),
+		problem(internal.DocProblemMissing, "F", "lint.go", 16, "exported constant F has no doc comment"),
+		problem(internal.DocProblemPrefix, "H", "lint.go", 23, `doc comment of H should start with "H "`),
+		problem(internal.DocProblemMissing, "T.N", "lint.go", 31, "exported method T.N has no doc comment"),
+		problem(internal.DocProblemPrefix, "V", "lint.go", 37, `doc comment of V should start with "V "`),
+		problem(internal.DocProblemMissing, "NewUnexportedType", "lint.go", 43, "exported function NewUnexportedType has no doc comment"),
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("mismatch (-want, +got):\n%s", diff)
+	}
+}
@@ -15,10 +15,12 @@ import (
 	"strings"
 
 	"github.com/google/safehtml/template"
+	"github.com/google/safehtml/uncheckedconversions"
 	"golang.org/x/mod/semver"
 	"golang.org/x/pkgsite/internal"
 	"golang.org/x/pkgsite/internal/derrors"
 	"golang.org/x/pkgsite/internal/godoc/dochtml"
+	"golang.org/x/pkgsite/internal/godoc/doctext"
 	"golang.org/x/pkgsite/internal/source"
 	"golang.org/x/pkgsite/internal/stdlib"
 )
@@ -161,12 +163,17 @@ func (p *Package) renderOptions(innerPath string, sourceInfo *source.Info, modIn
 	}
 
 	return dochtml.RenderOptions{
-		FileLinkFunc:     fileLinkFunc,
-		SourceLinkFunc:   sourceLinkFunc,
-		ModInfo:          modInfo,
-		SinceVersionFunc: sinceVersionFunc(modInfo.ModulePath, nameToVersion),
-		Limit:            int64(MaxDocumentationHTML),
-		BuildContext:     bc,
+		FileLinkFunc:       fileLinkFunc,
+		SourceLinkFunc:     sourceLinkFunc,
+		ModInfo:            modInfo,
+		SinceVersionFunc:   sinceVersionFunc(modInfo.ModulePath, nameToVersion),
+		Limit:              int64(MaxDocumentationHTML),
+		BuildContext:       bc,
+		Implements:         p.Implements,
+		ImplementedBy:      p.ImplementedBy,
+		PromotedMethods:    p.PromotedMethods,
+		PromotedFields:     p.PromotedFields,
+		PromotedIncomplete: p.PromotedIncomplete,
 	}
 }
 
@@ -212,11 +219,84 @@ func sinceVersionFunc(modulePath string, nameToVersion map[string]string) func(n
 	}
 }
 
-// Render renders the documentation for the package.
+// Render renders the documentation for the package. If usedByLink is not nil,
+// the heading of each symbol links to the URL it returns for the symbol's
+// name, as for dochtml.RenderOptions.UsedByLinkFunc.
 // Rendering destroys p's AST; do not call any methods of p after it returns.
 func (p *Package) Render(ctx context.Context, innerPath string,
 	sourceInfo *source.Info, modInfo *ModuleInfo, nameToVersion map[string]string,
-	bc internal.BuildContext) (_ *dochtml.Parts, err error) {
+	usedByLink func(name string) string, bc internal.BuildContext) (_ *dochtml.Parts, err error) {
+	opts := p.renderOptions(innerPath, sourceInfo, modInfo, nameToVersion, bc)
+	opts.UsedByLinkFunc = usedByLink
+	return p.render(ctx, innerPath, modInfo, opts)
+}
+
+// RendererVersion identifies the HTML produced by Render. Change it whenever
+// a change to this package, to dochtml or to the templates in static/doc
+// changes that HTML, so that documentation rendered by Prerender with an
+// earlier version is rendered again.
+const RendererVersion = "2"
+
+// Prerender renders the documentation for the package like Render, to be
+// stored with the package and served by RenderedParts. The versions that
+// introduced each symbol depend on the other versions of the module, and the
+// links to the users of each symbol on the server, so they are left as
+// placeholders for RenderedParts to fill in.
+// Rendering destroys p's AST; do not call any methods of p after it returns.
+func (p *Package) Prerender(ctx context.Context, innerPath string,
+	sourceInfo *source.Info, modInfo *ModuleInfo, bc internal.BuildContext) (_ *internal.RenderedDoc, err error) {
+	defer derrors.Wrap(&err, "godoc.Package.Prerender(%q, %q, %q)", modInfo.ModulePath, modInfo.ResolvedVersion, innerPath)
+
+	files := p.FileNames()
+	opts := p.renderOptions(innerPath, sourceInfo, modInfo, nil, bc)
+	opts.DeferSinceVersions = true
+	opts.DeferUsedByLinks = true
+	parts, err := p.render(ctx, innerPath, modInfo, opts)
+	if err != nil {
+		return nil, err
+	}
+	r := &internal.RenderedDoc{
+		RendererVersion: RendererVersion,
+		SourceURL:       sourceInfo.ModuleURL(),
+		LinkQuery:       dochtml.LinkQuery(bc),
+		Body:            parts.Body.String(),
+		Outline:         parts.Outline.String(),
+		MobileOutline:   parts.MobileOutline.String(),
+		Files:           files,
+	}
+	for _, l := range parts.Links {
+		r.Links = append(r.Links, internal.RenderedLink{Href: l.Href, Text: l.Text})
+	}
+	return r, nil
+}
+
+// RenderedParts returns the documentation in r, which was produced by
+// Prerender, as Render would return it for the same arguments. It returns
+// nil if r is nil, or if it must be rendered again because it was produced
+// by another version of the renderer, with other source links or for a build
+// context with other links.
+func RenderedParts(r *internal.RenderedDoc, sourceInfo *source.Info, modInfo *ModuleInfo,
+	nameToVersion map[string]string, usedByLink func(name string) string, bc internal.BuildContext) *dochtml.Parts {
+	if r == nil || r.RendererVersion != RendererVersion ||
+		r.SourceURL != sourceInfo.ModuleURL() || r.LinkQuery != dochtml.LinkQuery(bc) {
+		return nil
+	}
+	// The HTML was produced by this program, so it is known to be safe.
+	toHTML := uncheckedconversions.HTMLFromStringKnownToSatisfyTypeContract
+	body := dochtml.FillSinceVersions(toHTML(r.Body), sinceVersionFunc(modInfo.ModulePath, nameToVersion))
+	parts := &dochtml.Parts{
+		Body:          dochtml.FillUsedByLinks(body, usedByLink),
+		Outline:       toHTML(r.Outline),
+		MobileOutline: toHTML(r.MobileOutline),
+	}
+	for _, l := range r.Links {
+		parts.Links = append(parts.Links, dochtml.Link{Href: l.Href, Text: l.Text})
+	}
+	return parts
+}
+
+// render renders the documentation for the package with opts.
+func (p *Package) render(ctx context.Context, innerPath string, modInfo *ModuleInfo, opts dochtml.RenderOptions) (_ *dochtml.Parts, err error) {
 	p.renderCalled = true
 
 	d, err := p.DocPackage(innerPath, modInfo)
@@ -224,7 +304,6 @@ func (p *Package) Render(ctx context.Context, innerPath string,
 		return nil, err
 	}
 
-	opts := p.renderOptions(innerPath, sourceInfo, modInfo, nameToVersion, bc)
 	parts, err := dochtml.Render(ctx, p.Fset, d, opts)
 	if errors.Is(err, ErrTooLarge) {
 		return &dochtml.Parts{Body: template.MustParseAndExecuteToHTML(DocTooLargeReplacement)}, nil
@@ -235,6 +314,19 @@ func (p *Package) Render(ctx context.Context, innerPath string,
 	return parts, nil
 }
 
+// RenderText returns the documentation for the package, for rendering as
+// Markdown, plain text or JSON.
+// Rendering destroys p's AST; do not call any methods of p after it returns.
+func (p *Package) RenderText(innerPath string, modInfo *ModuleInfo) (_ *doctext.Package, err error) {
+	p.renderCalled = true
+
+	d, err := p.DocPackage(innerPath, modInfo)
+	if err != nil {
+		return nil, err
+	}
+	return doctext.New(p.Fset, d)
+}
+
 // RenderFromUnit is a convenience function that first decodes the source
 // in the unit, which must exist, and then calls Render.
 func RenderFromUnit(ctx context.Context, u *internal.Unit,
@@ -254,5 +346,18 @@ func RenderFromUnit(ctx context.Context, u *internal.Unit,
 	} else if u.Path != u.ModulePath {
 		innerPath = u.Path[len(u.ModulePath)+1:]
 	}
-	return docPkg.Render(ctx, innerPath, u.SourceInfo, modInfo, nil, bc)
+	return docPkg.Render(ctx, innerPath, u.SourceInfo, modInfo, nil, nil, bc)
+}
+
+// RenderTextFromUnit is like RenderFromUnit, but calls RenderText.
+func RenderTextFromUnit(u *internal.Unit) (_ *doctext.Package, err error) {
+	docPkg, err := DecodePackage(u.Documentation[0].Source)
+	if err != nil {
+		return nil, err
+	}
+	modInfo := &ModuleInfo{
+		ModulePath:      u.ModulePath,
+		ResolvedVersion: u.Version,
+	}
+	return docPkg.RenderText(internal.Suffix(u.Path, u.ModulePath), modInfo)
 }
@@ -119,7 +119,7 @@ func TestRenderParts_SinceVersion(t *testing.T) {
 		// TF is a method.
 		"T.M": "v1.4.0",
 	}
-	parts, err := p.Render(ctx, "p", si, mi, nameToVersion, internal.BuildContext{})
+	parts, err := p.Render(ctx, "p", si, mi, nameToVersion, nil, internal.BuildContext{})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -158,6 +158,90 @@ func TestRenderParts_SinceVersion(t *testing.T) {
 	}
 }
 
+func TestPrerender(t *testing.T) {
+	dochtml.LoadTemplates(templateFS)
+	ctx := context.Background()
+	si := source.NewGitHubInfo("a.com/M", "", "abcde")
+	mi := &ModuleInfo{
+		ModulePath:      "a.com/M",
+		ResolvedVersion: "v1.2.3",
+	}
+	nameToVersion := map[string]string{
+		"F":   "v1.0.0",
+		"T":   "v1.3.0",
+		"T.M": "v1.4.0",
+	}
+	bc := internal.BuildContext{GOOS: "windows", GOARCH: "amd64"}
+	usedByLink := func(name string) string { return "?tab=importedby&symbol=" + name }
+
+	p, err := packageForDir(filepath.Join("testdata", "p"), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := p.Render(ctx, "p", si, mi, nameToVersion, usedByLink, bc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p, err = packageForDir(filepath.Join("testdata", "p"), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantFiles := p.FileNames()
+	r, err := p.Prerender(ctx, "p", si, mi, bc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.RendererVersion != RendererVersion {
+		t.Errorf("got renderer version %q, want %q", r.RendererVersion, RendererVersion)
+	}
+	if !cmp.Equal(r.Files, wantFiles) {
+		t.Errorf("got files %v, want %v", r.Files, wantFiles)
+	}
+	got := RenderedParts(r, si, mi, nameToVersion, usedByLink, bc)
+	if got == nil {
+		t.Fatal("RenderedParts returned nil")
+	}
+	if link := `<a class="Documentation-usedBy" href="?tab=importedby&amp;symbol=T.M">Used by</a>`; !strings.Contains(got.Body.String(), link) {
+		t.Errorf("body does not contain %s", link)
+	}
+	for _, c := range []struct {
+		name      string
+		got, want string
+	}{
+		{"body", got.Body.String(), want.Body.String()},
+		{"outline", got.Outline.String(), want.Outline.String()},
+		{"mobile outline", got.MobileOutline.String(), want.MobileOutline.String()},
+	} {
+		if diff := cmp.Diff(c.want, c.got); diff != "" {
+			t.Errorf("%s mismatch (-want +got):\n%s", c.name, diff)
+		}
+	}
+	if diff := cmp.Diff(want.Links, got.Links); diff != "" {
+		t.Errorf("links mismatch (-want +got):\n%s", diff)
+	}
+
+	// The rendered documentation can't be used if it was produced for
+	// other links.
+	stale := *r
+	stale.RendererVersion = "0"
+	for _, test := range []struct {
+		name string
+		r    *internal.RenderedDoc
+		si   *source.Info
+		bc   internal.BuildContext
+	}{
+		{"nil", nil, si, bc},
+		{"renderer version", &stale, si, bc},
+		{"source", r, source.NewGitHubInfo("a.com/M", "", "other"), bc},
+		{"build context", r, si, internal.BuildContext{GOOS: "linux", GOARCH: "amd64"}},
+	} {
+		if got := RenderedParts(test.r, test.si, mi, nameToVersion, usedByLink, test.bc); got != nil {
+			t.Errorf("%s: got parts, want nil", test.name)
+		}
+	}
+}
+
 func TestCleanImports(t *testing.T) {
 	importPath := "a/b/c"
 	for _, test := range []struct {
@@ -0,0 +1,13 @@
+package lint_test
+
+func ExampleG() {}
+
+func ExampleT_M() {}
+
+func ExampleT_suffix() {}
+
+func Example_suffix() {}
+
+func ExampleMissing() {}
+
+func ExampleT_Missing() {}
@@ -0,0 +1,45 @@
+package lint
+
+// A is documented, and links to [B] and [T.M], but not to [Missing] or [T.Missing].
+const A = 1
+
+const B = 2
+
+// Values of B.
+const (
+	C = 3
+	D = 4
+)
+
+const (
+	E = 5 // E is documented.
+	F = 6
+)
+
+// G does things, described in [io.Reader].
+func G() {}
+
+// Does H.
+func H() {}
+
+// T is a type.
+type T int
+
+// M is a method.
+func (T) M() {}
+
+func (T) N() {}
+
+// The U type.
+type U struct{}
+
+// A thing.
+type V struct{}
+
+func unexported() {}
+
+type unexportedType int
+
+func NewUnexportedType() unexportedType { return 0 }
+
+func (unexportedType) Method() {}
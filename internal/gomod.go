@@ -0,0 +1,55 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package internal
+
+// GoModDirectives holds the directives of a module's go.mod file that refer
+// to module versions.
+type GoModDirectives struct {
+	Requires []*ModuleRequire
+	Replaces []*ModuleReplace
+	Excludes []*ModuleExclude
+	Retracts []*ModuleRetract
+}
+
+// ModuleRequire is a require directive: the module requires ModulePath at
+// Version or later.
+type ModuleRequire struct {
+	ModulePath string
+	Version    string
+	// Indirect reports whether the requirement is marked "// indirect".
+	Indirect bool
+}
+
+// ModuleReplace is a replace directive.
+type ModuleReplace struct {
+	// OldPath and OldVersion are the module version being replaced.
+	// OldVersion is empty if every version of OldPath is replaced.
+	OldPath, OldVersion string
+	// NewPath and NewVersion are the replacement. NewVersion is empty if
+	// NewPath is a directory rather than a module path.
+	NewPath, NewVersion string
+}
+
+// ModuleExclude is an exclude directive.
+type ModuleExclude struct {
+	ModulePath string
+	Version    string
+}
+
+// ModuleRetract is a retract directive, covering the versions from Low to
+// High inclusive. Low and High are the same for a single version.
+type ModuleRetract struct {
+	Low, High string
+	Rationale string
+}
+
+// ModuleDependent is a module version whose go.mod file requires some
+// module version.
+type ModuleDependent struct {
+	ModulePath string
+	Version    string
+	// Indirect reports whether the requirement is marked "// indirect".
+	Indirect bool
+}
@@ -4,23 +4,127 @@
 
 package internal
 
-import "context"
+import (
+	"context"
+	"io/fs"
+)
 
 // PostgresDB provides an interface satisfied by *(internal/postgres.DB) so that
 // packages in pkgsite can use the database if it exists without needing a
 // dependency on the database driver packages.
 type PostgresDB interface {
 	DataSource
+	AlternativesDataSource
+	ChecksumDataSource
+	DependenciesDataSource
+	DocHealthDataSource
+	ImportedByDataSource
+	RenderedDocDataSource
+	SymbolUsedByDataSource
+	VersionsDataSource
 
 	IsExcluded(ctx context.Context, path, version string) bool
-	GetImportedBy(ctx context.Context, pkgPath, modulePath string, limit int) (paths []string, err error)
-	GetImportedByCount(ctx context.Context, pkgPath, modulePath string) (_ int, err error)
 	GetLatestMajorPathForV1Path(ctx context.Context, v1path string) (_ string, _ int, err error)
 	GetStdlibPathsWithSuffix(ctx context.Context, suffix string) (paths []string, err error)
 	GetSymbolHistory(ctx context.Context, packagePath, modulePath string) (_ *SymbolHistory, err error)
 	GetVersionMap(ctx context.Context, modulePath, requestedVersion string) (_ *VersionMap, err error)
 	GetVersionMaps(ctx context.Context, paths []string, requestedVersion string) (_ []*VersionMap, err error)
-	GetVersionsForPath(ctx context.Context, path string) (_ []*ModuleInfo, err error)
 	InsertModule(ctx context.Context, m *Module, lmv *LatestModuleVersions) (isLatest bool, err error)
 	UpsertVersionMap(ctx context.Context, vm *VersionMap) (err error)
 }
+
+// ImportedByDataSource is implemented by DataSources that know which packages
+// import a package.
+type ImportedByDataSource interface {
+	// GetImportedBy returns the paths of up to limit packages that import
+	// pkgPath, other than those in the module modulePath.
+	GetImportedBy(ctx context.Context, pkgPath, modulePath string, limit int) (paths []string, err error)
+	// GetImportedByCount returns the number of packages that import pkgPath.
+	GetImportedByCount(ctx context.Context, pkgPath, modulePath string) (_ int, err error)
+}
+
+// RefResolvingDataSource is implemented by DataSources that can serve
+// modules at any version control ref, such as a branch name or a commit
+// hash, and not only at the versions the frontend supports.
+type RefResolvingDataSource interface {
+	// ResolvesRefs reports whether the module or package at path may be
+	// requested at any ref.
+	ResolvesRefs(path string) bool
+}
+
+// RenderedDocDataSource is implemented by DataSources that store the
+// documentation of units rendered ahead of time.
+type RenderedDocDataSource interface {
+	// UpdateRenderedDoc replaces the rendered documentation of the unit with
+	// the given path in the module version, for the build context bc.
+	UpdateRenderedDoc(ctx context.Context, path, modulePath, version string, bc BuildContext, r *RenderedDoc) (err error)
+}
+
+// SymbolUsedByDataSource is implemented by DataSources that know which
+// packages refer to the symbols of a package.
+type SymbolUsedByDataSource interface {
+	// GetSymbolUsedBy returns the paths of up to limit packages that refer
+	// to the exported symbol of pkgPath with the given name, other than
+	// those in the module modulePath. Methods and fields are named
+	// "Type.Name".
+	GetSymbolUsedBy(ctx context.Context, pkgPath, modulePath, symbol string, limit int) (paths []string, err error)
+}
+
+// VersionsDataSource is implemented by DataSources that know the versions of
+// the modules containing a path.
+type VersionsDataSource interface {
+	// GetVersionsForPath returns a list of tagged versions of the modules
+	// containing path, sorted in descending semver order if any exist. If
+	// none, it returns the 10 most recent pseudo-versions.
+	GetVersionsForPath(ctx context.Context, path string) (_ []*ModuleInfo, err error)
+}
+
+// DependenciesDataSource is implemented by DataSources that know the go.mod
+// directives of module versions.
+type DependenciesDataSource interface {
+	// GetGoModDirectives returns the go.mod directives of the module version.
+	// It returns an error wrapping derrors.NotFound if the module version has
+	// no go.mod file.
+	GetGoModDirectives(ctx context.Context, modulePath, version string) (_ *GoModDirectives, err error)
+	// GetModuleDependents returns up to limit module versions whose go.mod
+	// files require modulePath at version, sorted by module path and version.
+	GetModuleDependents(ctx context.Context, modulePath, version string, limit int) (_ []*ModuleDependent, err error)
+}
+
+// ChecksumDataSource is implemented by DataSources that record the go.sum
+// lines of the module versions they verified against a checksum database.
+type ChecksumDataSource interface {
+	// GetGoSum returns the go.sum lines for the module version. It returns nil
+	// if the module version was not verified.
+	GetGoSum(ctx context.Context, modulePath, version string) (_ []string, err error)
+}
+
+// DocHealthDataSource is implemented by DataSources that know the problems
+// found in the documentation of the packages of module versions.
+type DocHealthDataSource interface {
+	// GetDocProblems returns the documentation problems of the packages in
+	// the module version, keyed by package path and sorted by position.
+	// Packages without problems are omitted.
+	GetDocProblems(ctx context.Context, modulePath, version string) (_ map[string][]*DocProblem, err error)
+}
+
+// AlternativesDataSource is implemented by DataSources that know which modules
+// are forks or copies of other modules.
+type AlternativesDataSource interface {
+	// GetSuggestedAlternative returns the path of a module to suggest instead
+	// of the one containing path, or the empty string if there is none.
+	// Alternatives learned from deprecation comments are considered only if
+	// includeDeprecated is true.
+	GetSuggestedAlternative(ctx context.Context, path string, includeDeprecated bool) (_ string, err error)
+}
+
+// ModuleFSDataSource is implemented by DataSources that can read the files of
+// the module versions they serve. The database does not store module
+// contents, so it does not implement it; the frontend reads them from the
+// proxy instead.
+type ModuleFSDataSource interface {
+	// GetModuleFS returns the contents of the module version, with paths
+	// relative to the module root, or to the src directory for the standard
+	// library.
+	GetModuleFS(ctx context.Context, modulePath, version string) (_ fs.FS, err error)
+}
@@ -80,3 +80,15 @@ func (c *Cache[K, V]) Put(k K, v V) {
 	c.tick++
 	c.entries[k] = &entry[V]{lastUsed: c.tick, v: v}
 }
+
+// Entries returns a copy of the entries in the Cache. Unlike Get, it does not
+// affect which entries are the least recently used.
+func (c *Cache[K, V]) Entries() map[K]V {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	m := make(map[K]V, len(c.entries))
+	for k, e := range c.entries {
+		m[k] = e.v
+	}
+	return m
+}
@@ -94,3 +94,19 @@ func TestSizeFive(t *testing.T) {
 	getHasKey(13, true)
 	getHasKey(14, true)
 }
+
+func TestEntries(t *testing.T) {
+	c := New[int, int](2)
+	c.Put(1, 1)
+	c.Put(2, 2)
+	got := c.Entries()
+	want := map[int]int{1: 1, 2: 2}
+	if len(got) != len(want) || got[1] != 1 || got[2] != 2 {
+		t.Errorf("c.Entries(): got %v, want %v", got, want)
+	}
+	// Entries must not count as a use, so 1 is still the oldest.
+	c.Put(3, 3)
+	if _, ok := c.Get(1); ok {
+		t.Error("c.Get(1): got true, want false")
+	}
+}
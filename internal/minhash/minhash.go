@@ -0,0 +1,118 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package minhash estimates the similarity of sets using MinHash signatures,
+// and groups similar signatures with locality-sensitive hashing.
+//
+// A signature holds, for each of NumHashes hash functions, the minimum hash
+// of the elements of a set. The fraction of positions at which two signatures
+// agree estimates the Jaccard similarity of their sets: the size of their
+// intersection divided by the size of their union.
+//
+// To find similar signatures without comparing every pair, each signature is
+// split into NumBands bands of RowsPerBand hashes. Signatures that agree on
+// every hash of at least one band are candidates for comparison. With the
+// values below, sets with a similarity of 0.8 become candidates with
+// probability greater than 0.999, and sets with a similarity of 0.3 with
+// probability less than 0.13.
+package minhash
+
+import (
+	"encoding/binary"
+	"hash/fnv"
+)
+
+const (
+	// NumHashes is the length of a Signature.
+	NumHashes = NumBands * RowsPerBand
+
+	// NumBands is the number of bands that a Signature is split into.
+	NumBands = 16
+
+	// RowsPerBand is the number of hashes in each band.
+	RowsPerBand = 4
+)
+
+// A Signature is the MinHash signature of a set.
+type Signature []uint64
+
+// New returns the signature of the set of elements, which are themselves
+// hashes. Duplicate elements do not affect the signature. It returns nil if
+// elements is empty.
+func New(elements []uint64) Signature {
+	if len(elements) == 0 {
+		return nil
+	}
+	sig := make(Signature, NumHashes)
+	for i := range sig {
+		sig[i] = ^uint64(0)
+	}
+	for _, e := range elements {
+		for i := range sig {
+			if h := mix(e ^ seeds[i]); h < sig[i] {
+				sig[i] = h
+			}
+		}
+	}
+	return sig
+}
+
+// Similarity returns the estimated Jaccard similarity of the sets whose
+// signatures are s and t, between 0 and 1. It returns 0 if either signature
+// is not valid.
+func (s Signature) Similarity(t Signature) float64 {
+	if len(s) != NumHashes || len(t) != NumHashes {
+		return 0
+	}
+	n := 0
+	for i := range s {
+		if s[i] == t[i] {
+			n++
+		}
+	}
+	return float64(n) / NumHashes
+}
+
+// Bands returns a hash of each of the NumBands bands of s. Signatures with an
+// equal hash for the same band are likely to be similar. It returns nil if s
+// is not valid.
+func (s Signature) Bands() []uint64 {
+	if len(s) != NumHashes {
+		return nil
+	}
+	bands := make([]uint64, NumBands)
+	var buf [8]byte
+	for b := range bands {
+		h := fnv.New64a()
+		for _, v := range s[b*RowsPerBand : (b+1)*RowsPerBand] {
+			binary.LittleEndian.PutUint64(buf[:], v)
+			h.Write(buf[:])
+		}
+		bands[b] = h.Sum64()
+	}
+	return bands
+}
+
+// seeds are combined with each element to derive NumHashes hash functions
+// from mix. They must never change, since signatures are stored.
+var seeds = func() [NumHashes]uint64 {
+	var s [NumHashes]uint64
+	x := uint64(0x9e3779b97f4a7c15)
+	for i := range s {
+		x = mix(x + uint64(i))
+		s[i] = x
+	}
+	return s
+}()
+
+// mix is the finalizer of the SplitMix64 generator, a bijection that spreads
+// the bits of x evenly.
+func mix(x uint64) uint64 {
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
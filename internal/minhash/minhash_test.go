@@ -0,0 +1,75 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package minhash
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSimilarity(t *testing.T) {
+	// elements returns the integers in [lo, hi).
+	elements := func(lo, hi int) []uint64 {
+		var es []uint64
+		for i := lo; i < hi; i++ {
+			es = append(es, uint64(i))
+		}
+		return es
+	}
+	for _, test := range []struct {
+		name string
+		a, b []uint64
+		want float64 // Jaccard similarity
+	}{
+		{"identical", elements(0, 100), elements(0, 100), 1},
+		{"duplicates", elements(0, 100), append(elements(0, 100), elements(0, 50)...), 1},
+		{"disjoint", elements(0, 100), elements(100, 200), 0},
+		{"mostly the same", elements(0, 100), elements(10, 110), 90.0 / 110},
+		{"half the same", elements(0, 100), elements(50, 150), 50.0 / 150},
+		{"subset", elements(0, 100), elements(0, 25), 0.25},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			got := New(test.a).Similarity(New(test.b))
+			// The standard error of the estimate is at most 1/(2*sqrt(NumHashes)).
+			if math.Abs(got-test.want) > 3/(2*math.Sqrt(NumHashes)) {
+				t.Errorf("got %.2f, want about %.2f", got, test.want)
+			}
+			if test.want == 1 && got != 1 {
+				t.Errorf("got %.2f for identical sets, want 1", got)
+			}
+		})
+	}
+}
+
+func TestBands(t *testing.T) {
+	a := New([]uint64{1, 2, 3, 4, 5})
+	b := New([]uint64{5, 4, 3, 2, 1})
+	c := New([]uint64{6, 7, 8, 9, 10})
+	ab, bb, cb := a.Bands(), b.Bands(), c.Bands()
+	if len(ab) != NumBands {
+		t.Fatalf("got %d bands, want %d", len(ab), NumBands)
+	}
+	for i := range ab {
+		if ab[i] != bb[i] {
+			t.Errorf("band %d differs for equal sets", i)
+		}
+		if ab[i] == cb[i] {
+			t.Errorf("band %d is the same for disjoint sets", i)
+		}
+	}
+}
+
+func TestEmpty(t *testing.T) {
+	s := New(nil)
+	if s != nil {
+		t.Errorf("got %v, want nil", s)
+	}
+	if got := s.Similarity(s); got != 0 {
+		t.Errorf("Similarity: got %f, want 0", got)
+	}
+	if got := s.Bands(); got != nil {
+		t.Errorf("Bands: got %v, want nil", got)
+	}
+}
@@ -20,6 +20,7 @@ func (u *Unit) RemoveNonRedistributableData() {
 	if !u.IsRedistributable {
 		u.Readme = nil
 		u.Documentation = nil
+		u.DocProblems = nil
 	}
 }
 
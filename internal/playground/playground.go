@@ -0,0 +1,408 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package playground runs the programs of the example playgrounds on
+// documentation pages with the local Go toolchain, in place of the Go
+// playground at play.golang.org. Programs and shared snippets never leave the
+// machine, and running them doesn't need a network connection if their
+// dependencies are in the module cache or on the local filesystem.
+//
+// Each program is built in a temporary module and run with limits on its run
+// time and output. That is the only sandboxing: the program runs with the
+// privileges of the server, so a Local must only serve its own user. It
+// answers only requests for a loopback host, and refuses to run or share
+// programs sent by other origins, so that neither other machines nor the web
+// pages that its user visits can run programs with it.
+package playground
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/mod/modfile"
+	"golang.org/x/pkgsite/internal/derrors"
+	"golang.org/x/pkgsite/internal/log"
+	"golang.org/x/tools/txtar"
+)
+
+// Default limits for a Config.
+const (
+	DefaultBuildTimeout = time.Minute
+	DefaultRunTimeout   = 10 * time.Second
+	DefaultMaxOutput    = 1 << 20
+)
+
+// maxBodySize is the size of the largest program that can be run or shared.
+const maxBodySize = 1 << 20
+
+// Config configures a Local.
+type Config struct {
+	// ShareDir is the directory where shared programs are stored. It is
+	// created if it doesn't exist.
+	ShareDir string
+	// LocalModules maps the paths of modules on the local filesystem to
+	// their directories. Programs that require them use the local copy.
+	LocalModules map[string]string
+	// Env holds extra environment variables for the go command, such as
+	// GOPROXY=off.
+	Env []string
+	// BuildTimeout limits the time to resolve the dependencies of a program
+	// and build it, and RunTimeout the time the program runs. MaxOutput
+	// is the largest number of bytes of output that are returned.
+	BuildTimeout time.Duration
+	RunTimeout   time.Duration
+	MaxOutput    int
+}
+
+// A Local is an http.Handler that serves the requests that the frontend sends
+// to the Go playground: /play/compile runs a program, /play/share stores one
+// and returns the path of a page that shows it, and /play/p/<id> is that
+// page.
+type Local struct {
+	cfg Config
+	// mu serializes builds and runs, so that they don't compete for the
+	// machine and their time limits are meaningful.
+	mu sync.Mutex
+}
+
+// NewLocal returns a Local with the given configuration. Zero limits are
+// replaced by their defaults.
+func NewLocal(cfg Config) *Local {
+	if cfg.BuildTimeout == 0 {
+		cfg.BuildTimeout = DefaultBuildTimeout
+	}
+	if cfg.RunTimeout == 0 {
+		cfg.RunTimeout = DefaultRunTimeout
+	}
+	if cfg.MaxOutput == 0 {
+		cfg.MaxOutput = DefaultMaxOutput
+	}
+	return &Local{cfg: cfg}
+}
+
+// ServeHTTP implements http.Handler.
+func (l *Local) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	// Check the host to defeat DNS rebinding, where a page on another site
+	// gets a name of that site to resolve to the loopback address.
+	if !IsLoopbackAddr(r.Host) {
+		http.Error(w, "the local playground only serves requests for localhost", http.StatusForbidden)
+		return
+	}
+	if r.Method == http.MethodPost {
+		if err := checkSameOrigin(r); err != nil {
+			http.Error(w, err.Error(), http.StatusForbidden)
+			return
+		}
+	}
+	switch {
+	case r.URL.Path == "/play/compile" && r.Method == http.MethodPost:
+		l.serveCompile(w, r)
+	case r.URL.Path == "/play/share" && r.Method == http.MethodPost:
+		l.serveShare(w, r)
+	case strings.HasPrefix(r.URL.Path, "/play/p/") && (r.Method == http.MethodGet || r.Method == http.MethodHead):
+		l.serveShared(w, r)
+	default:
+		http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+	}
+}
+
+// A CompileResponse is the response to /play/compile. It has the form of the
+// responses of the Go playground.
+type CompileResponse struct {
+	// Errors holds the errors from building the program, or from running
+	// it for too long.
+	Errors string
+	// Events is the output of the program.
+	Events []*Event
+	// Status is the exit status of the program.
+	Status int
+}
+
+// An Event is output written by a program.
+type Event struct {
+	Message string
+	Kind    string // "stdout" or "stderr"
+	Delay   time.Duration
+}
+
+func (l *Local) serveCompile(w http.ResponseWriter, r *http.Request) {
+	// Other sites can't send JSON without the browser first asking the
+	// server whether it accepts their requests, which it doesn't.
+	if mt, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mt != "application/json" {
+		http.Error(w, "programs must be sent as JSON", http.StatusUnsupportedMediaType)
+		return
+	}
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBodySize))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+		return
+	}
+	var req struct{ Body string }
+	if err := json.Unmarshal(body, &req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	resp, err := l.Run(r.Context(), req.Body)
+	if err != nil {
+		log.Errorf(r.Context(), "playground: %v", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// checkSameOrigin returns an error if r was sent by a page of another origin.
+// Browsers set the Sec-Fetch-Site or the Origin header, or both, on the
+// requests that pages send with POST.
+func checkSameOrigin(r *http.Request) error {
+	if site := r.Header.Get("Sec-Fetch-Site"); site != "" && site != "same-origin" {
+		return fmt.Errorf("cross-origin request (Sec-Fetch-Site: %s)", site)
+	}
+	if origin := r.Header.Get("Origin"); origin != "" {
+		u, err := url.Parse(origin)
+		if err != nil || u.Host != r.Host {
+			return fmt.Errorf("cross-origin request (Origin: %s)", origin)
+		}
+	}
+	return nil
+}
+
+// IsLoopbackAddr reports whether addr, a host or a host:port pair, names a
+// loopback interface: localhost or a loopback IP address. An address without
+// a host, like ":8080", names every interface, so it is not a loopback
+// address.
+func IsLoopbackAddr(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		host = strings.TrimSuffix(strings.TrimPrefix(addr, "["), "]")
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// Run builds and runs the program in src, which is a Go file optionally
+// followed by other files in txtar format, like a go.mod file. An error is
+// returned only if the program couldn't be run for reasons other than its
+// contents.
+func (l *Local) Run(ctx context.Context, src string) (_ *CompileResponse, err error) {
+	defer derrors.Wrap(&err, "Local.Run")
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	dir, err := os.MkdirTemp("", "pkgsite-play-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	if err := l.writeModule(dir, src); err != nil {
+		return &CompileResponse{Errors: err.Error()}, nil
+	}
+	bctx, cancel := context.WithTimeout(ctx, l.cfg.BuildTimeout)
+	defer cancel()
+	prog := "prog"
+	if runtime.GOOS == "windows" {
+		prog += ".exe"
+	}
+	for _, args := range [][]string{{"mod", "tidy"}, {"build", "-o", prog, "."}} {
+		cmd := exec.CommandContext(bctx, "go", args...)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(), "GOWORK=off", "GOFLAGS=-mod=mod", "GOTOOLCHAIN=local")
+		cmd.Env = append(cmd.Env, l.cfg.Env...)
+		out, err := cmd.CombinedOutput()
+		if bctx.Err() != nil {
+			return &CompileResponse{Errors: "timeout building program"}, nil
+		}
+		var eerr *exec.ExitError
+		if errors.As(err, &eerr) {
+			// Show paths relative to the module.
+			msg := strings.ReplaceAll(string(out), dir+string(filepath.Separator), "")
+			return &CompileResponse{Errors: strings.ReplaceAll(msg, dir, ".")}, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	rctx, cancel := context.WithTimeout(ctx, l.cfg.RunTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(rctx, filepath.Join(dir, prog))
+	cmd.Dir = dir
+	cmd.Env = []string{"HOME=" + dir, "TMPDIR=" + dir, "PATH=" + os.Getenv("PATH")}
+	out := &output{max: l.cfg.MaxOutput, cancel: cancel}
+	cmd.Stdout = out.writer("stdout")
+	cmd.Stderr = out.writer("stderr")
+	err = cmd.Run()
+	resp := &CompileResponse{Events: out.events}
+	switch {
+	case out.truncated:
+		resp.Events = append(resp.Events, &Event{Message: "\n[output truncated]\n", Kind: "stderr"})
+	case rctx.Err() != nil:
+		resp.Errors = "timeout running program"
+	}
+	var eerr *exec.ExitError
+	if errors.As(err, &eerr) {
+		// A program that was stopped doesn't have an exit status.
+		if resp.Errors == "" && !out.truncated {
+			resp.Status = eerr.ExitCode()
+			resp.Events = append(resp.Events, &Event{Message: fmt.Sprintf("\nProgram exited: %v.\n", err), Kind: "stderr"})
+		}
+	} else if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// writeModule writes the module of the program in src to dir. The module
+// requires the local copies of the modules in l.cfg.LocalModules.
+func (l *Local) writeModule(dir, src string) error {
+	ar := txtar.Parse([]byte(src))
+	files := append([]txtar.File{{Name: "prog.go", Data: ar.Comment}}, ar.Files...)
+	for _, f := range files {
+		if !fs.ValidPath(f.Name) || strings.Contains(f.Name, `\`) {
+			return fmt.Errorf("invalid file name %q", f.Name)
+		}
+	}
+	var (
+		gomod *modfile.File
+		err   error
+	)
+	for _, f := range files {
+		if f.Name == "go.mod" {
+			gomod, err = modfile.Parse("go.mod", f.Data, nil)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		p := filepath.Join(dir, filepath.FromSlash(f.Name))
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(p, f.Data, 0o644); err != nil {
+			return err
+		}
+	}
+	if gomod == nil {
+		gomod = &modfile.File{}
+		if err := gomod.AddModuleStmt("play.ground"); err != nil {
+			return err
+		}
+	}
+	for _, req := range gomod.Require {
+		if d, ok := l.cfg.LocalModules[req.Mod.Path]; ok {
+			if err := gomod.AddReplace(req.Mod.Path, "", d, ""); err != nil {
+				return err
+			}
+		}
+	}
+	data, err := gomod.Format()
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, "go.mod"), data, 0o644)
+}
+
+// output collects the output of a program as events, up to a maximum size.
+// When it reaches the maximum, it calls cancel to stop the program.
+type output struct {
+	mu        sync.Mutex
+	events    []*Event
+	size, max int
+	truncated bool
+	cancel    func()
+}
+
+func (o *output) writer(kind string) io.Writer {
+	return writerFunc(func(b []byte) (int, error) {
+		o.mu.Lock()
+		defer o.mu.Unlock()
+		n := len(b)
+		if o.size+len(b) > o.max {
+			b = b[:o.max-o.size]
+			o.truncated = true
+			o.cancel()
+		}
+		o.size += len(b)
+		if len(b) == 0 {
+			return n, nil
+		}
+		if last := len(o.events) - 1; last >= 0 && o.events[last].Kind == kind {
+			o.events[last].Message += string(b)
+		} else {
+			o.events = append(o.events, &Event{Message: string(b), Kind: kind})
+		}
+		return n, nil
+	})
+}
+
+type writerFunc func([]byte) (int, error)
+
+func (f writerFunc) Write(b []byte) (int, error) { return f(b) }
+
+// shareIDRegexp matches the IDs of shared programs.
+var shareIDRegexp = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// serveShare stores the program in the request body, and responds with the
+// path of the page that shows it.
+func (l *Local) serveShare(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBodySize))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+		return
+	}
+	// Like the Go playground, use a prefix of a hash of the program as its
+	// ID, so that sharing the same program twice gives the same ID.
+	sum := sha256.Sum256(body)
+	id := base64.RawURLEncoding.EncodeToString(sum[:])[:11]
+	if err := os.MkdirAll(l.cfg.ShareDir, 0o755); err == nil {
+		err = os.WriteFile(filepath.Join(l.cfg.ShareDir, id+".txt"), body, 0o644)
+	}
+	if err != nil {
+		log.Errorf(r.Context(), "playground: sharing: %v", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	fmt.Fprint(w, "/play/p/"+id)
+}
+
+// serveShared serves the program shared with the ID in the path.
+func (l *Local) serveShared(w http.ResponseWriter, r *http.Request) {
+	id := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/play/p/"), ".go")
+	if !shareIDRegexp.MatchString(id) {
+		http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		return
+	}
+	data, err := os.ReadFile(filepath.Join(l.cfg.ShareDir, id+".txt"))
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Write(data)
+}
@@ -0,0 +1,295 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package playground
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/pkgsite/internal/testenv"
+	"golang.org/x/pkgsite/internal/testing/testhelper"
+)
+
+// offline keeps the go command from using the network.
+var offline = []string{"GOPROXY=off"}
+
+func TestRun(t *testing.T) {
+	testenv.MustHaveExecPath(t, "go")
+
+	localModule, _ := testhelper.WriteTxtarToTempDir(t, `
+-- go.mod --
+module example.com/local
+-- local.go --
+package local
+
+// Greeting is a greeting.
+const Greeting = "hello from a local module"
+`)
+	l := NewLocal(Config{
+		LocalModules: map[string]string{"example.com/local": localModule},
+		Env:          offline,
+		RunTimeout:   5 * time.Second,
+		MaxOutput:    100,
+	})
+	for _, test := range []struct {
+		name       string
+		src        string
+		wantOutput string
+		wantErrors string
+		wantStatus int
+	}{
+		{
+			name: "hello",
+			src: `package main
+
+import "fmt"
+
+func main() { fmt.Println("hello") }
+`,
+			wantOutput: "hello\n",
+		},
+		{
+			name: "local module",
+			src: `package main
+
+import (
+	"fmt"
+
+	"example.com/local"
+)
+
+func main() { fmt.Println(local.Greeting) }
+-- go.mod --
+module play.ground
+
+require example.com/local v0.0.0
+`,
+			wantOutput: "hello from a local module\n",
+		},
+		{
+			name: "build error",
+			src: `package main
+
+func main() { undefined() }
+`,
+			wantErrors: "prog.go:3:15: undefined: undefined",
+		},
+		{
+			name: "exit status",
+			src: `package main
+
+import "os"
+
+func main() { os.Exit(3) }
+`,
+			wantOutput: "\nProgram exited: exit status 3.\n",
+			wantStatus: 3,
+		},
+		{
+			name: "too much output",
+			src: `package main
+
+import "fmt"
+
+func main() {
+	for {
+		fmt.Println("0123456789")
+	}
+}
+`,
+			wantOutput: strings.Repeat("0123456789\n", 9) + "0\n[output truncated]\n",
+		},
+		{
+			name: "invalid file name",
+			src: `package main
+-- ../x.go --
+package main
+`,
+			wantErrors: `invalid file name "../x.go"`,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			resp, err := l.Run(context.Background(), test.src)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(resp.Errors, test.wantErrors) || (test.wantErrors == "" && resp.Errors != "") {
+				t.Errorf("got errors %q, want %q", resp.Errors, test.wantErrors)
+			}
+			var got strings.Builder
+			for _, e := range resp.Events {
+				got.WriteString(e.Message)
+			}
+			if got.String() != test.wantOutput {
+				t.Errorf("got output %q, want %q", got.String(), test.wantOutput)
+			}
+			if resp.Status != test.wantStatus {
+				t.Errorf("got status %d, want %d", resp.Status, test.wantStatus)
+			}
+		})
+	}
+}
+
+func TestRunTimeout(t *testing.T) {
+	testenv.MustHaveExecPath(t, "go")
+
+	l := NewLocal(Config{Env: offline, RunTimeout: 500 * time.Millisecond})
+	resp, err := l.Run(context.Background(), `package main
+
+import "time"
+
+func main() { time.Sleep(time.Hour) }
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "timeout running program"; resp.Errors != want {
+		t.Errorf("got errors %q, want %q", resp.Errors, want)
+	}
+}
+
+func TestServeHTTP(t *testing.T) {
+	testenv.MustHaveExecPath(t, "go")
+
+	l := NewLocal(Config{ShareDir: t.TempDir(), Env: offline})
+	const prog = `package main
+
+import "fmt"
+
+func main() { fmt.Print("shared") }
+`
+	serve := func(method, path, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(method, path, strings.NewReader(body))
+		r.Host = "localhost:8080"
+		if path == "/play/compile" {
+			r.Header.Set("Content-Type", "application/json")
+		}
+		l.ServeHTTP(w, r)
+		return w
+	}
+
+	w := serve("POST", "/play/compile", `{"body": `+jsonString(prog)+`, "version": 2}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("compile: got status %d, want %d", w.Code, http.StatusOK)
+	}
+	var resp CompileResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Events) != 1 || resp.Events[0].Message != "shared" {
+		t.Errorf("compile: got events %+v, want one with %q", resp.Events, "shared")
+	}
+
+	w = serve("POST", "/play/share", prog)
+	if w.Code != http.StatusOK {
+		t.Fatalf("share: got status %d, want %d", w.Code, http.StatusOK)
+	}
+	path := w.Body.String()
+	if !strings.HasPrefix(path, "/play/p/") {
+		t.Fatalf("share: got %q, want a path under /play/p/", path)
+	}
+	if w := serve("POST", "/play/share", prog); w.Body.String() != path {
+		t.Errorf("sharing again: got %q, want %q", w.Body.String(), path)
+	}
+	w = serve("GET", path, "")
+	if w.Code != http.StatusOK || w.Body.String() != prog {
+		t.Errorf("GET %s: got %d %q, want %d %q", path, w.Code, w.Body.String(), http.StatusOK, prog)
+	}
+
+	for _, path := range []string{"/play/p/unknown", "/play/p/..%2fx", "/play/other"} {
+		if w := serve("GET", path, ""); w.Code != http.StatusNotFound {
+			t.Errorf("GET %s: got status %d, want %d", path, w.Code, http.StatusNotFound)
+		}
+	}
+}
+
+func TestServeHTTPRejects(t *testing.T) {
+	l := NewLocal(Config{ShareDir: t.TempDir(), Env: offline})
+	const body = `{"body": "package main\n\nfunc main() {}\n"}`
+	for _, test := range []struct {
+		name       string
+		path       string
+		host       string
+		header     map[string]string
+		wantStatus int
+	}{
+		{
+			name:       "other host",
+			path:       "/play/compile",
+			host:       "attacker.example.com:8080",
+			header:     map[string]string{"Content-Type": "application/json"},
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "cross-site",
+			path:       "/play/compile",
+			host:       "localhost:8080",
+			header:     map[string]string{"Content-Type": "application/json", "Sec-Fetch-Site": "cross-site"},
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "other origin",
+			path:       "/play/share",
+			host:       "localhost:8080",
+			header:     map[string]string{"Origin": "https://attacker.example.com"},
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "not JSON",
+			path:       "/play/compile",
+			host:       "127.0.0.1:8080",
+			header:     map[string]string{"Content-Type": "text/plain"},
+			wantStatus: http.StatusUnsupportedMediaType,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("POST", test.path, strings.NewReader(body))
+			r.Host = test.host
+			for k, v := range test.header {
+				r.Header.Set(k, v)
+			}
+			l.ServeHTTP(w, r)
+			if w.Code != test.wantStatus {
+				t.Errorf("got status %d, want %d", w.Code, test.wantStatus)
+			}
+		})
+	}
+}
+
+func TestIsLoopbackAddr(t *testing.T) {
+	for _, test := range []struct {
+		addr string
+		want bool
+	}{
+		{"localhost:8080", true},
+		{"localhost", true},
+		{"127.0.0.1:8080", true},
+		{"[::1]:8080", true},
+		{"[::1]", true},
+		{":8080", false},
+		{"0.0.0.0:8080", false},
+		{"example.com:8080", false},
+		{"", false},
+	} {
+		if got := IsLoopbackAddr(test.addr); got != test.want {
+			t.Errorf("IsLoopbackAddr(%q) = %t, want %t", test.addr, got, test.want)
+		}
+	}
+}
+
+func jsonString(s string) string {
+	b, err := json.Marshal(s)
+	if err != nil {
+		panic(err)
+	}
+	return string(b)
+}
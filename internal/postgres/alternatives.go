@@ -0,0 +1,158 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/derrors"
+	"golang.org/x/pkgsite/internal/middleware/stats"
+)
+
+// Values of AlternativeModulePath.Source.
+const (
+	// AlternativeSourceAdmin is the source of alternatives added by an
+	// administrator through the worker.
+	AlternativeSourceAdmin = "admin"
+	// AlternativeSourceGoMod is the source of alternatives learned from a
+	// module version whose go.mod file declares a different module path.
+	AlternativeSourceGoMod = "go.mod"
+	// AlternativeSourceDeprecated is the source of alternatives learned from a
+	// "Deprecated: use X" comment in a go.mod file.
+	AlternativeSourceDeprecated = "deprecated"
+)
+
+// An AlternativeModulePath records that the modules of a series are known to
+// be forks or copies of another module, which should be used instead.
+type AlternativeModulePath struct {
+	// ModulePath is the series path of the modules, without a major version
+	// suffix like "/v3" or ".v3" for gopkg.in paths. It is case-sensitive.
+	ModulePath string
+	// Alternative is the path of the module to use instead.
+	Alternative string
+	// Source says how the alternative was learned. It is one of the
+	// AlternativeSource constants.
+	Source    string
+	CreatedAt time.Time
+}
+
+// InsertAlternativeModulePath records that a.Alternative is an alternative to
+// the modules in the series of a.ModulePath. An existing entry for the series
+// is replaced, unless it was added by an administrator and a was not.
+func (db *DB) InsertAlternativeModulePath(ctx context.Context, a *AlternativeModulePath) (err error) {
+	defer derrors.WrapStack(&err, "InsertAlternativeModulePath(ctx, %q, %q, %q)", a.ModulePath, a.Alternative, a.Source)
+
+	_, err = db.db.Exec(ctx, `
+		INSERT INTO alternative_module_paths (module_path, alternative, source)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (module_path) DO UPDATE
+		SET
+			alternative = excluded.alternative,
+			source = excluded.source,
+			created_at = CURRENT_TIMESTAMP
+		WHERE excluded.source = $4 OR alternative_module_paths.source <> $4
+	`, internal.SeriesPathForModule(a.ModulePath), a.Alternative, a.Source, AlternativeSourceAdmin)
+	return err
+}
+
+// DeleteAlternativeModulePath deletes the alternative for the series of
+// modulePath, if there is one.
+func (db *DB) DeleteAlternativeModulePath(ctx context.Context, modulePath string) (err error) {
+	defer derrors.WrapStack(&err, "DeleteAlternativeModulePath(ctx, %q)", modulePath)
+
+	_, err = db.db.Exec(ctx, `DELETE FROM alternative_module_paths WHERE module_path = $1`,
+		internal.SeriesPathForModule(modulePath))
+	return err
+}
+
+// DeleteAlternativeModulePathFromSource deletes the alternative for the
+// series of modulePath, if there is one and it was learned from source.
+func (db *DB) DeleteAlternativeModulePathFromSource(ctx context.Context, modulePath, source string) (err error) {
+	defer derrors.WrapStack(&err, "DeleteAlternativeModulePathFromSource(ctx, %q, %q)", modulePath, source)
+
+	_, err = db.db.Exec(ctx, `DELETE FROM alternative_module_paths WHERE module_path = $1 AND source = $2`,
+		internal.SeriesPathForModule(modulePath), source)
+	return err
+}
+
+// GetAlternativeModulePath returns the alternative for the series of
+// modulePath. It returns an error wrapping derrors.NotFound if there is none.
+func (db *DB) GetAlternativeModulePath(ctx context.Context, modulePath string) (_ *AlternativeModulePath, err error) {
+	defer derrors.WrapStack(&err, "GetAlternativeModulePath(ctx, %q)", modulePath)
+
+	a := &AlternativeModulePath{}
+	err = db.db.QueryRow(ctx, `
+		SELECT module_path, alternative, source, created_at
+		FROM alternative_module_paths
+		WHERE module_path = $1
+	`, internal.SeriesPathForModule(modulePath)).Scan(&a.ModulePath, &a.Alternative, &a.Source, &a.CreatedAt)
+	switch {
+	case err == sql.ErrNoRows:
+		return nil, derrors.NotFound
+	case err != nil:
+		return nil, err
+	}
+	return a, nil
+}
+
+// GetAlternativeModulePaths returns all the alternatives, sorted by module
+// path.
+func (db *DB) GetAlternativeModulePaths(ctx context.Context) (_ []*AlternativeModulePath, err error) {
+	defer derrors.WrapStack(&err, "GetAlternativeModulePaths(ctx)")
+
+	var as []*AlternativeModulePath
+	err = db.db.RunQuery(ctx, `
+		SELECT module_path, alternative, source, created_at
+		FROM alternative_module_paths
+		ORDER BY module_path
+	`, func(rows *sql.Rows) error {
+		a := &AlternativeModulePath{}
+		if err := rows.Scan(&a.ModulePath, &a.Alternative, &a.Source, &a.CreatedAt); err != nil {
+			return err
+		}
+		as = append(as, a)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return as, nil
+}
+
+// GetSuggestedAlternative returns the path of a module to suggest instead of
+// the one containing path, or the empty string if there is none. The
+// alternative of the longest series path that is a componentwise prefix of
+// path is used. Alternatives learned from deprecation comments are considered
+// only if includeDeprecated is true.
+func (db *DB) GetSuggestedAlternative(ctx context.Context, path string, includeDeprecated bool) (_ string, err error) {
+	defer derrors.WrapStack(&err, "GetSuggestedAlternative(ctx, %q, %t)", path, includeDeprecated)
+	defer stats.Elapsed(ctx, "GetSuggestedAlternative")()
+
+	var candidates []string
+	elems := strings.Split(path, "/")
+	for i := 1; i <= len(elems); i++ {
+		candidates = append(candidates, internal.SeriesPathForModule(strings.Join(elems[:i], "/")))
+	}
+	var alt string
+	err = db.db.QueryRow(ctx, `
+		SELECT alternative
+		FROM alternative_module_paths
+		WHERE module_path = ANY($1) AND ($2 OR source <> $3)
+		ORDER BY length(module_path) DESC
+		LIMIT 1
+	`, pq.Array(candidates), includeDeprecated, AlternativeSourceDeprecated).Scan(&alt)
+	switch {
+	case err == sql.ErrNoRows:
+		return "", nil
+	case err != nil:
+		return "", err
+	}
+	return alt, nil
+}
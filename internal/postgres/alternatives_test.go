@@ -0,0 +1,86 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package postgres
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"golang.org/x/pkgsite/internal/derrors"
+)
+
+func TestAlternativeModulePaths(t *testing.T) {
+	t.Parallel()
+	testDB, release := acquire(t)
+	defer release()
+	ctx := context.Background()
+
+	insert := func(modulePath, alt, source string) {
+		t.Helper()
+		if err := testDB.InsertAlternativeModulePath(ctx, &AlternativeModulePath{
+			ModulePath:  modulePath,
+			Alternative: alt,
+			Source:      source,
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	check := func(modulePath, wantAlt, wantSource string) {
+		t.Helper()
+		got, err := testDB.GetAlternativeModulePath(ctx, modulePath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.Alternative != wantAlt || got.Source != wantSource {
+			t.Errorf("%s: got %q from %q, want %q from %q", modulePath, got.Alternative, got.Source, wantAlt, wantSource)
+		}
+	}
+
+	// Entries are keyed by series path.
+	insert("example.com/fork/v2", "example.com/orig", AlternativeSourceGoMod)
+	check("example.com/fork", "example.com/orig", AlternativeSourceGoMod)
+	check("example.com/fork/v3", "example.com/orig", AlternativeSourceGoMod)
+
+	// Administrators override learned entries, but not the other way around.
+	insert("example.com/fork", "example.com/admin", AlternativeSourceAdmin)
+	check("example.com/fork", "example.com/admin", AlternativeSourceAdmin)
+	insert("example.com/fork", "example.com/deprecated", AlternativeSourceDeprecated)
+	check("example.com/fork", "example.com/admin", AlternativeSourceAdmin)
+
+	insert("example.com/fork/sub", "example.com/other", AlternativeSourceDeprecated)
+	for _, test := range []struct {
+		path              string
+		includeDeprecated bool
+		want              string
+	}{
+		{"example.com/fork", true, "example.com/admin"},
+		{"example.com/fork/v2/pkg", true, "example.com/admin"},
+		{"example.com/fork/sub/pkg", true, "example.com/other"},
+		{"example.com/fork/sub/pkg", false, "example.com/admin"},
+		{"example.com/forked", true, ""},
+	} {
+		got, err := testDB.GetSuggestedAlternative(ctx, test.path, test.includeDeprecated)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != test.want {
+			t.Errorf("GetSuggestedAlternative(%q, %t) = %q, want %q", test.path, test.includeDeprecated, got, test.want)
+		}
+	}
+
+	// Deleting by source leaves entries from other sources.
+	if err := testDB.DeleteAlternativeModulePathFromSource(ctx, "example.com/fork", AlternativeSourceGoMod); err != nil {
+		t.Fatal(err)
+	}
+	check("example.com/fork", "example.com/admin", AlternativeSourceAdmin)
+
+	if err := testDB.DeleteAlternativeModulePath(ctx, "example.com/fork/v2"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := testDB.GetAlternativeModulePath(ctx, "example.com/fork"); !errors.Is(err, derrors.NotFound) {
+		t.Errorf("after delete: got %v, want NotFound", err)
+	}
+}
@@ -108,12 +108,20 @@ func deletePackagesInModuleFromSearchDocuments(ctx context.Context, tx *database
 	return nil
 }
 
+// deleteModuleFromImportsUnique deletes the rows for modulePath from
+// imports_unique and symbol_references_unique.
 func deleteModuleFromImportsUnique(ctx context.Context, db *database.DB, modulePath string) (err error) {
 	defer derrors.Wrap(&err, "deleteModuleFromImportsUnique(%q)", modulePath)
 
-	_, err = db.Exec(ctx, `
+	if _, err := db.Exec(ctx, `
 		DELETE FROM imports_unique
 		WHERE from_module_path = $1
+	`, modulePath); err != nil {
+		return err
+	}
+	_, err = db.Exec(ctx, `
+		DELETE FROM symbol_references_unique
+		WHERE from_module_path = $1
 	`, modulePath)
 	return err
 }
@@ -95,6 +95,34 @@ func (db *DB) GetImportedBy(ctx context.Context, pkgPath, modulePath string, lim
 	return database.Collect1[string](ctx, db.db, query, pkgPath, modulePath, limit)
 }
 
+// GetSymbolUsedBy returns the paths of up to limit packages, other than those
+// in the module modulePath, whose latest version refers to the exported
+// symbol of pkgPath with the given name, such as "F" or "T.M".
+func (db *DB) GetSymbolUsedBy(ctx context.Context, pkgPath, modulePath, symbol string, limit int) (paths []string, err error) {
+	defer derrors.WrapStack(&err, "GetSymbolUsedBy(ctx, %q, %q, %q)", pkgPath, modulePath, symbol)
+	defer stats.Elapsed(ctx, "GetSymbolUsedBy")()
+
+	if pkgPath == "" || symbol == "" {
+		return nil, fmt.Errorf("pkgPath and symbol cannot be empty: %w", derrors.InvalidArgument)
+	}
+	query := `
+		SELECT
+			DISTINCT from_path
+		FROM
+			symbol_references_unique
+		WHERE
+			to_path = $1
+		AND
+			symbol = $2
+		AND
+			from_module_path <> $3
+		ORDER BY
+			from_path
+		LIMIT $4`
+
+	return database.Collect1[string](ctx, db.db, query, pkgPath, symbol, modulePath, limit)
+}
+
 // GetImportedByCount returns the number of packages that import pkgPath.
 func (db *DB) GetImportedByCount(ctx context.Context, pkgPath, modulePath string) (_ int, err error) {
 	defer derrors.WrapStack(&err, "GetImportedByCount(ctx, %q, %q)", pkgPath, modulePath)
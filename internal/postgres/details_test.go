@@ -282,6 +282,48 @@ func TestGetImportedBy(t *testing.T) {
 	}
 }
 
+func TestGetSymbolUsedBy(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	testDB, release := acquire(t)
+	defer release()
+
+	var (
+		m1 = sample.Module("path.to/foo", "v1.1.0", "bar")
+		m2 = sample.Module("path2.to/foo", "v1.2.0", "bar2")
+		m3 = sample.Module("path3.to/foo", "v1.3.0", "bar3")
+
+		pkg1 = m1.Packages()[0]
+		pkg2 = m2.Packages()[0]
+		pkg3 = m3.Packages()[0]
+	)
+	pkg2.Imports = []string{pkg1.Path}
+	pkg2.SymbolReferences = map[string][]string{pkg1.Path: {"F", "T"}}
+	pkg3.Imports = []string{pkg1.Path}
+	pkg3.SymbolReferences = map[string][]string{pkg1.Path: {"F"}}
+	for _, m := range []*internal.Module{m1, m2, m3} {
+		MustInsertModule(ctx, t, testDB, m)
+	}
+
+	for _, test := range []struct {
+		symbol, modulePath string
+		want               []string
+	}{
+		{"F", m1.ModulePath, []string{pkg2.Path, pkg3.Path}},
+		{"T", m1.ModulePath, []string{pkg2.Path}},
+		{"F", m2.ModulePath, []string{pkg3.Path}},
+		{"G", m1.ModulePath, nil},
+	} {
+		got, err := testDB.GetSymbolUsedBy(ctx, pkg1.Path, test.modulePath, test.symbol, 100)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff(test.want, got); diff != "" {
+			t.Errorf("GetSymbolUsedBy(%q, %q) mismatch (-want +got):\n%s", test.symbol, test.modulePath, diff)
+		}
+	}
+}
+
 func TestJSONBScanner(t *testing.T) {
 	t.Parallel()
 	type S struct{ A int }
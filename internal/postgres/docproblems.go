@@ -0,0 +1,79 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/lib/pq"
+	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/database"
+	"golang.org/x/pkgsite/internal/derrors"
+	"golang.org/x/pkgsite/internal/middleware/stats"
+)
+
+// insertDocProblems replaces the documentation problems stored for the units
+// in paths by those in pathToProblems.
+func insertDocProblems(ctx context.Context, tx *database.DB,
+	paths []string,
+	pathToUnitID map[string]int,
+	pathToProblems map[string][]*internal.DocProblem) (err error) {
+	defer derrors.WrapStack(&err, "insertDocProblems(%d paths)", len(paths))
+
+	var (
+		unitIDs []int
+		values  []any
+	)
+	for _, path := range paths {
+		unitID := pathToUnitID[path]
+		unitIDs = append(unitIDs, unitID)
+		for _, dp := range pathToProblems[path] {
+			values = append(values, unitID, dp.Kind, dp.Symbol, dp.Filename, dp.Line, dp.Message)
+		}
+	}
+	if _, err := tx.Exec(ctx, `DELETE FROM doc_problems WHERE unit_id = ANY($1)`, pq.Array(unitIDs)); err != nil {
+		return err
+	}
+	if len(values) == 0 {
+		return nil
+	}
+	cols := []string{"unit_id", "kind", "symbol", "filename", "line", "message"}
+	return tx.BulkInsert(ctx, "doc_problems", cols, values, database.OnConflictDoNothing)
+}
+
+// GetDocProblems returns the documentation problems of the packages in the
+// given module version, keyed by package path and sorted by position.
+func (db *DB) GetDocProblems(ctx context.Context, modulePath, version string) (_ map[string][]*internal.DocProblem, err error) {
+	defer derrors.WrapStack(&err, "GetDocProblems(ctx, %q, %q)", modulePath, version)
+	defer stats.Elapsed(ctx, "GetDocProblems")()
+
+	problems := map[string][]*internal.DocProblem{}
+	err = db.db.RunQuery(ctx, `
+		SELECT p.path, d.kind, d.symbol, d.filename, d.line, d.message
+		FROM doc_problems d
+		INNER JOIN units u ON u.id = d.unit_id
+		INNER JOIN paths p ON p.id = u.path_id
+		INNER JOIN modules m ON m.id = u.module_id
+		WHERE m.module_path = $1 AND m.version = $2
+	`, func(rows *sql.Rows) error {
+		var (
+			path string
+			dp   internal.DocProblem
+		)
+		if err := rows.Scan(&path, &dp.Kind, &dp.Symbol, &dp.Filename, &dp.Line, &dp.Message); err != nil {
+			return err
+		}
+		problems[path] = append(problems[path], &dp)
+		return nil
+	}, modulePath, version)
+	if err != nil {
+		return nil, err
+	}
+	for _, dps := range problems {
+		internal.SortDocProblems(dps)
+	}
+	return problems, nil
+}
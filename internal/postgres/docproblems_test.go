@@ -0,0 +1,56 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package postgres
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/testing/sample"
+)
+
+func TestDocProblems(t *testing.T) {
+	t.Parallel()
+	testDB, release := acquire(t)
+	defer release()
+	ctx := context.Background()
+
+	m := sample.Module("example.com/a", "v1.0.0", "p", "q")
+	problems := []*internal.DocProblem{
+		{Kind: internal.DocProblemMissing, Filename: "p.go", Line: 1, Message: "package p has no package comment"},
+		{Kind: internal.DocProblemLink, Symbol: "F", Filename: "p.go", Line: 7, Message: "doc link [G] does not refer to a declaration in package p"},
+	}
+	setProblems := func(dps []*internal.DocProblem) {
+		for _, u := range m.Units {
+			if u.Path == "example.com/a/p" {
+				u.DocProblems = dps
+			}
+		}
+	}
+	setProblems(problems)
+	MustInsertModule(ctx, t, testDB, m)
+
+	got, err := testDB.GetDocProblems(ctx, m.ModulePath, m.Version)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string][]*internal.DocProblem{"example.com/a/p": problems}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("mismatch (-want +got):\n%s", diff)
+	}
+
+	// Reinserting the module replaces the problems.
+	setProblems(nil)
+	MustInsertModule(ctx, t, testDB, m)
+	got, err = testDB.GetDocProblems(ctx, m.ModulePath, m.Version)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 0 {
+		t.Errorf("after reinsert: got %v, want no problems", got)
+	}
+}
@@ -0,0 +1,187 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/database"
+	"golang.org/x/pkgsite/internal/derrors"
+	"golang.org/x/pkgsite/internal/middleware/stats"
+)
+
+// insertGoModDirectives replaces the go.mod directives stored for the module
+// with ID moduleID by those of m.
+func insertGoModDirectives(ctx context.Context, db *database.DB, m *internal.Module, moduleID int) (err error) {
+	defer derrors.WrapStack(&err, "insertGoModDirectives(ctx, %q, %q)", m.ModulePath, m.Version)
+
+	for _, table := range []string{"module_requires", "module_replaces", "module_excludes", "module_retracts"} {
+		if _, err := db.Exec(ctx, fmt.Sprintf(`DELETE FROM %s WHERE module_id = $1`, table), moduleID); err != nil {
+			return err
+		}
+	}
+	d := m.GoMod
+	if d == nil {
+		return nil
+	}
+
+	var requireValues, replaceValues, excludeValues, retractValues []any
+	for _, r := range d.Requires {
+		requireValues = append(requireValues, moduleID, r.ModulePath, r.Version, r.Indirect)
+	}
+	for _, r := range d.Replaces {
+		replaceValues = append(replaceValues, moduleID, r.OldPath, r.OldVersion, r.NewPath, r.NewVersion)
+	}
+	for _, e := range d.Excludes {
+		excludeValues = append(excludeValues, moduleID, e.ModulePath, e.Version)
+	}
+	for _, r := range d.Retracts {
+		retractValues = append(retractValues, moduleID, r.Low, r.High, r.Rationale)
+	}
+	// A go.mod file may repeat a directive, so ignore duplicates.
+	for _, t := range []struct {
+		table  string
+		cols   []string
+		values []any
+	}{
+		{"module_requires", []string{"module_id", "required_path", "required_version", "indirect"}, requireValues},
+		{"module_replaces", []string{"module_id", "old_path", "old_version", "new_path", "new_version"}, replaceValues},
+		{"module_excludes", []string{"module_id", "excluded_path", "excluded_version"}, excludeValues},
+		{"module_retracts", []string{"module_id", "low", "high", "rationale"}, retractValues},
+	} {
+		if len(t.values) == 0 {
+			continue
+		}
+		if err := db.BulkInsert(ctx, t.table, t.cols, t.values, database.OnConflictDoNothing); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetGoModDirectives returns the go.mod directives of the given module
+// version. It returns an error wrapping derrors.NotFound if the module version
+// is not in the database or has no go.mod file.
+func (db *DB) GetGoModDirectives(ctx context.Context, modulePath, version string) (_ *internal.GoModDirectives, err error) {
+	defer derrors.WrapStack(&err, "GetGoModDirectives(ctx, %q, %q)", modulePath, version)
+	defer stats.Elapsed(ctx, "GetGoModDirectives")()
+
+	var (
+		moduleID int
+		hasGoMod bool
+	)
+	err = db.db.QueryRow(ctx, `
+		SELECT id, has_go_mod
+		FROM modules
+		WHERE module_path = $1 AND version = $2
+	`, modulePath, version).Scan(&moduleID, &hasGoMod)
+	switch {
+	case err == sql.ErrNoRows:
+		return nil, derrors.NotFound
+	case err != nil:
+		return nil, err
+	case !hasGoMod:
+		return nil, fmt.Errorf("no go.mod file: %w", derrors.NotFound)
+	}
+
+	d := &internal.GoModDirectives{}
+	err = db.db.RunQuery(ctx, `
+		SELECT required_path, required_version, indirect
+		FROM module_requires
+		WHERE module_id = $1
+		ORDER BY required_path
+	`, func(rows *sql.Rows) error {
+		var r internal.ModuleRequire
+		if err := rows.Scan(&r.ModulePath, &r.Version, &r.Indirect); err != nil {
+			return err
+		}
+		d.Requires = append(d.Requires, &r)
+		return nil
+	}, moduleID)
+	if err != nil {
+		return nil, err
+	}
+	err = db.db.RunQuery(ctx, `
+		SELECT old_path, old_version, new_path, new_version
+		FROM module_replaces
+		WHERE module_id = $1
+		ORDER BY old_path, old_version
+	`, func(rows *sql.Rows) error {
+		var r internal.ModuleReplace
+		if err := rows.Scan(&r.OldPath, &r.OldVersion, &r.NewPath, &r.NewVersion); err != nil {
+			return err
+		}
+		d.Replaces = append(d.Replaces, &r)
+		return nil
+	}, moduleID)
+	if err != nil {
+		return nil, err
+	}
+	err = db.db.RunQuery(ctx, `
+		SELECT excluded_path, excluded_version
+		FROM module_excludes
+		WHERE module_id = $1
+		ORDER BY excluded_path, excluded_version
+	`, func(rows *sql.Rows) error {
+		var e internal.ModuleExclude
+		if err := rows.Scan(&e.ModulePath, &e.Version); err != nil {
+			return err
+		}
+		d.Excludes = append(d.Excludes, &e)
+		return nil
+	}, moduleID)
+	if err != nil {
+		return nil, err
+	}
+	err = db.db.RunQuery(ctx, `
+		SELECT low, high, rationale
+		FROM module_retracts
+		WHERE module_id = $1
+		ORDER BY low, high
+	`, func(rows *sql.Rows) error {
+		var r internal.ModuleRetract
+		if err := rows.Scan(&r.Low, &r.High, &r.Rationale); err != nil {
+			return err
+		}
+		d.Retracts = append(d.Retracts, &r)
+		return nil
+	}, moduleID)
+	if err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// GetModuleDependents returns up to limit module versions whose go.mod files
+// require modulePath at version, sorted by module path and then by version,
+// latest first.
+func (db *DB) GetModuleDependents(ctx context.Context, modulePath, version string, limit int) (_ []*internal.ModuleDependent, err error) {
+	defer derrors.WrapStack(&err, "GetModuleDependents(ctx, %q, %q, %d)", modulePath, version, limit)
+	defer stats.Elapsed(ctx, "GetModuleDependents")()
+
+	query := `
+		SELECT m.module_path, m.version, r.indirect
+		FROM module_requires r
+		INNER JOIN modules m ON m.id = r.module_id
+		WHERE r.required_path = $1 AND r.required_version = $2
+		ORDER BY m.module_path, m.sort_version DESC
+		LIMIT $3`
+	var deps []*internal.ModuleDependent
+	err = db.db.RunQuery(ctx, query, func(rows *sql.Rows) error {
+		var d internal.ModuleDependent
+		if err := rows.Scan(&d.ModulePath, &d.Version, &d.Indirect); err != nil {
+			return err
+		}
+		deps = append(deps, &d)
+		return nil
+	}, modulePath, version, limit)
+	if err != nil {
+		return nil, err
+	}
+	return deps, nil
+}
@@ -0,0 +1,69 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package postgres
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/derrors"
+	"golang.org/x/pkgsite/internal/testing/sample"
+)
+
+func TestGoModDirectives(t *testing.T) {
+	t.Parallel()
+	testDB, release := acquire(t)
+	defer release()
+	ctx := context.Background()
+
+	dep := sample.Module("example.com/dep", "v1.2.0", "")
+	MustInsertModule(ctx, t, testDB, dep)
+
+	want := &internal.GoModDirectives{
+		Requires: []*internal.ModuleRequire{
+			{ModulePath: "example.com/dep", Version: "v1.2.0"},
+			{ModulePath: "example.com/other", Version: "v0.1.0", Indirect: true},
+		},
+		Replaces: []*internal.ModuleReplace{{OldPath: "example.com/other", NewPath: "../other"}},
+		Excludes: []*internal.ModuleExclude{{ModulePath: "example.com/dep", Version: "v1.1.0"}},
+		Retracts: []*internal.ModuleRetract{{Low: "v1.0.0", High: "v1.0.0", Rationale: "bad"}},
+	}
+	for _, v := range []string{"v1.0.0", "v1.1.0"} {
+		m := sample.Module("example.com/a", v, "")
+		m.GoMod = want
+		MustInsertModule(ctx, t, testDB, m)
+	}
+	b := sample.Module("example.com/b", "v1.0.0", "")
+	b.GoMod = &internal.GoModDirectives{
+		Requires: []*internal.ModuleRequire{{ModulePath: "example.com/dep", Version: "v1.1.0"}},
+	}
+	MustInsertModule(ctx, t, testDB, b)
+
+	got, err := testDB.GetGoModDirectives(ctx, "example.com/a", "v1.1.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("GetGoModDirectives mismatch (-want +got):\n%s", diff)
+	}
+	if _, err := testDB.GetGoModDirectives(ctx, "example.com/a", "v9.0.0"); !errors.Is(err, derrors.NotFound) {
+		t.Errorf("GetGoModDirectives for missing version: got %v, want NotFound", err)
+	}
+
+	gotDeps, err := testDB.GetModuleDependents(ctx, "example.com/dep", "v1.2.0", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantDeps := []*internal.ModuleDependent{
+		{ModulePath: "example.com/a", Version: "v1.1.0"},
+		{ModulePath: "example.com/a", Version: "v1.0.0"},
+	}
+	if diff := cmp.Diff(wantDeps, gotDeps); diff != "" {
+		t.Errorf("GetModuleDependents mismatch (-want +got):\n%s", diff)
+	}
+}
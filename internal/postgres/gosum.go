@@ -0,0 +1,37 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/lib/pq"
+	"golang.org/x/pkgsite/internal/derrors"
+	"golang.org/x/pkgsite/internal/middleware/stats"
+)
+
+// GetGoSum returns the go.sum lines recorded for the given module version
+// when it was verified against the checksum database. It returns nil if the
+// module version was not verified, and an error wrapping derrors.NotFound if
+// it is not in the database.
+func (db *DB) GetGoSum(ctx context.Context, modulePath, version string) (_ []string, err error) {
+	defer derrors.WrapStack(&err, "GetGoSum(ctx, %q, %q)", modulePath, version)
+	defer stats.Elapsed(ctx, "GetGoSum")()
+
+	var lines []string
+	err = db.db.QueryRow(ctx, `
+		SELECT go_sum
+		FROM modules
+		WHERE module_path = $1 AND version = $2
+	`, modulePath, version).Scan(pq.Array(&lines))
+	switch {
+	case err == sql.ErrNoRows:
+		return nil, derrors.NotFound
+	case err != nil:
+		return nil, err
+	}
+	return lines, nil
+}
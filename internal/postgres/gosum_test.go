@@ -0,0 +1,49 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package postgres
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/pkgsite/internal/derrors"
+	"golang.org/x/pkgsite/internal/testing/sample"
+)
+
+func TestGetGoSum(t *testing.T) {
+	t.Parallel()
+	testDB, release := acquire(t)
+	defer release()
+	ctx := context.Background()
+
+	verified := sample.Module("example.com/verified", "v1.0.0", "")
+	verified.GoSum = []string{
+		"example.com/verified v1.0.0 h1:zip=",
+		"example.com/verified v1.0.0/go.mod h1:mod=",
+	}
+	MustInsertModule(ctx, t, testDB, verified)
+	MustInsertModule(ctx, t, testDB, sample.Module("example.com/unverified", "v1.0.0", ""))
+
+	for _, test := range []struct {
+		modulePath string
+		want       []string
+	}{
+		{"example.com/verified", verified.GoSum},
+		{"example.com/unverified", nil},
+	} {
+		got, err := testDB.GetGoSum(ctx, test.modulePath, "v1.0.0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff(test.want, got); diff != "" {
+			t.Errorf("GetGoSum(%q) mismatch (-want +got):\n%s", test.modulePath, diff)
+		}
+	}
+	if _, err := testDB.GetGoSum(ctx, "example.com/verified", "v9.0.0"); !errors.Is(err, derrors.NotFound) {
+		t.Errorf("GetGoSum for missing version: got %v, want NotFound", err)
+	}
+}
@@ -104,6 +104,12 @@ func (db *DB) saveModule(ctx context.Context, m *internal.Module, lmv *internal.
 		if err := insertLicenses(ctx, tx, m, moduleID); err != nil {
 			return err
 		}
+		if err := insertGoModDirectives(ctx, tx, m, moduleID); err != nil {
+			return err
+		}
+		if err := insertMinHash(ctx, tx, m, moduleID); err != nil {
+			return err
+		}
 		pathToUnitID, pathToDocs, err := db.insertUnits(ctx, tx, m, moduleID, pathToID)
 		if err != nil {
 			return err
@@ -145,6 +151,9 @@ func (db *DB) saveModule(ctx context.Context, m *internal.Module, lmv *internal.
 		if err := insertImportsUnique(ctx, tx, m); err != nil {
 			return err
 		}
+		if err := insertSymbolReferencesUnique(ctx, tx, m); err != nil {
+			return err
+		}
 
 		var pkgPaths []string
 		for _, u := range m.Packages() {
@@ -249,13 +258,15 @@ func insertModule(ctx context.Context, db *database.DB, m *internal.Module) (_ i
 			source_info,
 			redistributable,
 			has_go_mod,
-			incompatible)
-		VALUES($1,$2,$3,$4,$5,$6,$7,$8,$9,$10)
+			incompatible,
+			go_sum)
+		VALUES($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11)
 		ON CONFLICT
 			(module_path, version)
 		DO UPDATE SET
 			source_info=excluded.source_info,
-			redistributable=excluded.redistributable
+			redistributable=excluded.redistributable,
+			go_sum=excluded.go_sum
 		RETURNING id`,
 		m.ModulePath,
 		m.Version,
@@ -267,6 +278,7 @@ func insertModule(ctx context.Context, db *database.DB, m *internal.Module) (_ i
 		m.IsRedistributable,
 		m.HasGoMod,
 		version.IsIncompatible(m.Version),
+		pq.Array(m.GoSum),
 	).Scan(&moduleID)
 	if err != nil {
 		return 0, err
@@ -330,6 +342,29 @@ func insertImportsUnique(ctx context.Context, tx *database.DB, m *internal.Modul
 	return tx.BulkUpsert(ctx, "imports_unique", cols, values, cols)
 }
 
+// insertSymbolReferencesUnique replaces the rows of the
+// symbol_references_unique table for the given module. Like
+// insertImportsUnique, it should only be called if the given module's version
+// is the latest. The previous rows are deleted by insertImportsUnique.
+func insertSymbolReferencesUnique(ctx context.Context, tx *database.DB, m *internal.Module) (err error) {
+	defer internal.RequestState(ctx, "inserting into symbol_references_unique table")()
+	defer derrors.WrapStack(&err, "insertSymbolReferencesUnique(%q, %q)", m.ModulePath, m.Version)
+
+	var values []any
+	for _, u := range m.Units {
+		for toPath, symbols := range u.SymbolReferences {
+			for _, s := range symbols {
+				values = append(values, u.Path, m.ModulePath, toPath, s)
+			}
+		}
+	}
+	if len(values) == 0 {
+		return nil
+	}
+	cols := []string{"from_path", "from_module_path", "to_path", "symbol"}
+	return tx.BulkUpsert(ctx, "symbol_references_unique", cols, values, cols)
+}
+
 // insertUnits inserts the units for a module into the units table.
 // It must be called inside a transaction.
 //
@@ -353,12 +388,14 @@ func (pdb *DB) insertUnits(ctx context.Context, tx *database.DB,
 		sort.Strings(u.Imports)
 	}
 	var (
-		paths         []string
-		unitValues    []any
-		pathToReadme  = map[string]*internal.Readme{}
-		pathToImports = map[string][]string{}
-		pathIDToPath  = map[int]string{}
-		pathToAllDocs = map[string][]*internal.Documentation{}
+		paths             []string
+		unitValues        []any
+		pathToReadme      = map[string]*internal.Readme{}
+		pathToImports     = map[string][]string{}
+		pathToTests       = map[string][]*internal.TestFunc{}
+		pathToDocProblems = map[string][]*internal.DocProblem{}
+		pathIDToPath      = map[int]string{}
+		pathToAllDocs     = map[string][]*internal.Documentation{}
 	)
 	pathToPkgDocs = map[string][]*internal.Documentation{}
 	for _, u := range m.Units {
@@ -410,6 +447,12 @@ func (pdb *DB) insertUnits(ctx context.Context, tx *database.DB,
 		if len(u.Imports) > 0 {
 			pathToImports[u.Path] = u.Imports
 		}
+		if len(u.Tests) > 0 {
+			pathToTests[u.Path] = u.Tests
+		}
+		if len(u.DocProblems) > 0 {
+			pathToDocProblems[u.Path] = u.DocProblems
+		}
 		paths = append(paths, u.Path)
 	}
 	pathIDToUnitID, err := insertUnits(ctx, tx, unitValues)
@@ -429,6 +472,12 @@ func (pdb *DB) insertUnits(ctx context.Context, tx *database.DB,
 	if err := insertImports(ctx, tx, paths, pathToUnitID, pathToImports); err != nil {
 		return nil, nil, err
 	}
+	if err := insertTestFuncs(ctx, tx, paths, pathToUnitID, pathToTests); err != nil {
+		return nil, nil, err
+	}
+	if err := insertDocProblems(ctx, tx, paths, pathToUnitID, pathToDocProblems); err != nil {
+		return nil, nil, err
+	}
 	return pathToUnitID, pathToPkgDocs, nil
 }
 
@@ -512,7 +561,21 @@ func insertDocs(ctx context.Context, db *database.DB,
 					if doc.GOOS == "" || doc.GOARCH == "" {
 						ch <- database.RowItem{Err: errors.New("empty GOOS or GOARCH")}
 					}
-					ch <- database.RowItem{Values: []any{unitID, doc.GOOS, doc.GOARCH, doc.Synopsis, doc.Source}}
+					var (
+						rendererVersion string
+						rendered        []byte
+					)
+					if doc.Rendered != nil {
+						var err error
+						rendered, err = json.Marshal(doc.Rendered)
+						if err != nil {
+							ch <- database.RowItem{Err: err}
+							continue
+						}
+						rendererVersion = doc.Rendered.RendererVersion
+					}
+					ch <- database.RowItem{Values: []any{unitID, doc.GOOS, doc.GOARCH, doc.Tags, doc.Synopsis, doc.Source, doc.MinGoVersion,
+						rendererVersion, rendered}}
 				}
 			}
 			close(ch)
@@ -520,8 +583,8 @@ func insertDocs(ctx context.Context, db *database.DB,
 		return ch
 	}
 
-	uniqueCols := []string{"unit_id", "goos", "goarch"}
-	docCols := append(uniqueCols, "synopsis", "source")
+	uniqueCols := []string{"unit_id", "goos", "goarch", "build_tags"}
+	docCols := append(uniqueCols, "synopsis", "source", "min_go_version", "renderer_version", "rendered")
 	return db.CopyUpsert(ctx, "documentation",
 		docCols, database.CopyFromChan(generateRows()), uniqueCols, "id")
 }
@@ -529,6 +592,9 @@ func insertDocs(ctx context.Context, db *database.DB,
 // getDocIDsForPath returns a map of the unit path to documentation.id to
 // documentation, for all of the docs in pathToDocs. This will be used to
 // insert data into the documentation_symbols.documentation_id column.
+//
+// Docs for build contexts with build tags are left out, since symbols are
+// only recorded for each GOOS/GOARCH pair.
 func getDocIDsForPath(ctx context.Context, db *database.DB,
 	pathToUnitID map[string]int,
 	pathToDocs map[string][]*internal.Documentation) (_ map[string]map[int]*internal.Documentation, err error) {
@@ -549,7 +615,7 @@ func getDocIDsForPath(ctx context.Context, db *database.DB,
 			pathToDocIDToDoc[path] = map[int]*internal.Documentation{}
 		}
 		for _, doc := range pathToDocs[path] {
-			if doc.GOOS == goos && doc.GOARCH == goarch {
+			if doc.GOOS == goos && doc.GOARCH == goarch && doc.Tags == "" {
 				pathToDocIDToDoc[path][id] = doc
 			}
 		}
@@ -562,7 +628,7 @@ func getDocIDsForPath(ctx context.Context, db *database.DB,
 		unitIDs = append(unitIDs, pathToUnitID[path])
 	}
 
-	q := `SELECT id, unit_id, goos, goarch FROM documentation WHERE unit_id = ANY($1)`
+	q := `SELECT id, unit_id, goos, goarch FROM documentation WHERE unit_id = ANY($1) AND build_tags = ''`
 	if err := db.RunQuery(ctx, q, collect, pq.Array(unitIDs)); err != nil {
 		return nil, err
 	}
@@ -741,7 +807,9 @@ func (db *DB) ReconcileSearch(ctx context.Context, modulePath, version string, s
 			}
 		}
 
-		// Remove old rows from imports_unique.
+		// Remove old rows from imports_unique. Rows are also removed from
+		// symbol_references_unique, which cannot be recomputed from the
+		// database; they will be restored when the module is next fetched.
 		if err := deleteModuleFromImportsUnique(ctx, tx, modulePath); err != nil {
 			return err
 		}
@@ -42,7 +42,13 @@ func upsertSymbolHistory(ctx context.Context, ddb *database.DB,
 			var values []any
 			builds := []internal.BuildContext{{GOOS: doc.GOOS, GOARCH: doc.GOARCH}}
 			if doc.GOOS == internal.All {
-				builds = internal.BuildContexts
+				builds = nil
+				for _, b := range internal.BuildContexts {
+					// Symbol history is kept only for GOOS/GOARCH pairs.
+					if b.Tags == "" {
+						builds = append(builds, b)
+					}
+				}
 			}
 			for _, b := range builds {
 				dbNameToVersion := map[string]string{}
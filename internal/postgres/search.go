@@ -185,6 +185,8 @@ const (
 	// Start this off gently (close to 1), but consider lowering
 	// it as time goes by and more of the ecosystem converts to modules.
 	noGoModPenalty = 0.8
+	// Module is a near duplicate of an older one, such as a fork or a copy.
+	nearDuplicatePenalty = 0.5
 )
 
 // scoreExpr is the expression that computes the search score.
@@ -196,6 +198,8 @@ const (
 //     dramatic: being 2x as popular only has an additive effect.
 //   - A penalty factor for non-redistributable modules, since a lot of
 //     details cannot be displayed.
+//   - A penalty factor for modules that are near duplicates of others, since
+//     users usually want the original.
 //
 // The first argument to ts_rank is an array of weights for the four tsvector sections,
 // in the order D, C, B, A.
@@ -204,8 +208,9 @@ var scoreExpr = fmt.Sprintf(`
 		ts_rank('{0.1, 0.2, 1.0, 1.0}', tsv_search_tokens, websearch_to_tsquery($1)) *
 		ln(exp(1)+imported_by_count) *
 		CASE WHEN redistributable THEN 1 ELSE %f END *
-		CASE WHEN COALESCE(has_go_mod, true) THEN 1 ELSE %f END
-	`, nonRedistributablePenalty, noGoModPenalty)
+		CASE WHEN COALESCE(has_go_mod, true) THEN 1 ELSE %f END *
+		CASE WHEN near_duplicate_of IS NULL THEN 1 ELSE %f END
+	`, nonRedistributablePenalty, noGoModPenalty, nearDuplicatePenalty)
 
 // hedgedSearch executes multiple search methods and returns the first
 // available result.
@@ -538,6 +543,7 @@ var upsertSearchStatement = fmt.Sprintf(`
 		version_updated_at,
 		commit_time,
 		has_go_mod,
+		near_duplicate_of,
 		-- TODO(https://golang.org/issue/44142): The path_tokens column is used
 		-- to easily iterate on tsv_path_tokens, and can be removed once
 		-- symbol search implementation is done.
@@ -561,6 +567,7 @@ var upsertSearchStatement = fmt.Sprintf(`
 		CURRENT_TIMESTAMP,
 		m.commit_time,
 		m.has_go_mod,
+		m.near_duplicate_of,
 		$4,
 		SETWEIGHT(TO_TSVECTOR('%s', replace($4, '_', '-')), 'A'),
 		(
@@ -593,6 +600,7 @@ var upsertSearchStatement = fmt.Sprintf(`
 		redistributable=excluded.redistributable,
 		commit_time=excluded.commit_time,
 		has_go_mod=excluded.has_go_mod,
+		near_duplicate_of=excluded.near_duplicate_of,
 		path_tokens=excluded.path_tokens,
 		tsv_path_tokens=excluded.tsv_path_tokens,
 		tsv_search_tokens=excluded.tsv_search_tokens,
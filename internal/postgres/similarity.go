@@ -0,0 +1,200 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/lib/pq"
+	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/database"
+	"golang.org/x/pkgsite/internal/derrors"
+	"golang.org/x/pkgsite/internal/log"
+	"golang.org/x/pkgsite/internal/minhash"
+)
+
+// nearDuplicateThreshold is the estimated similarity of their contents above
+// which one module version is considered a near duplicate of another.
+const nearDuplicateThreshold = 0.8
+
+// maxNearDuplicateCandidates is the maximum number of module versions whose
+// contents are compared with those of a module version being inserted.
+const maxNearDuplicateCandidates = 100
+
+// insertMinHash stores the MinHash signature of m, whose ID is moduleID. If the
+// contents of m are nearly the same as those of a module version in a
+// different series, then it marks the newer of the two as a near duplicate of the
+// older one; see isOlder. A module that others are already near duplicates of
+// stays the original, so reprocessing an original never makes it a near
+// duplicate of one of its copies. It must be called inside a transaction,
+// after m has been inserted into the modules table and before its search
+// documents are updated.
+func insertMinHash(ctx context.Context, tx *database.DB, m *internal.Module, moduleID int) (err error) {
+	defer derrors.WrapStack(&err, "insertMinHash(ctx, %q, %q)", m.ModulePath, m.Version)
+
+	for _, q := range []string{
+		`DELETE FROM module_minhash_bands WHERE module_id = $1`,
+		`DELETE FROM module_minhashes WHERE module_id = $1`,
+		`UPDATE modules SET near_duplicate_of = NULL WHERE id = $1`,
+	} {
+		if _, err := tx.Exec(ctx, q, moduleID); err != nil {
+			return err
+		}
+	}
+	if len(m.MinHash) != minhash.NumHashes {
+		return nil
+	}
+	if _, err := tx.Exec(ctx, `INSERT INTO module_minhashes (module_id, signature) VALUES ($1, $2)`,
+		moduleID, pq.Array(toInt64s(m.MinHash))); err != nil {
+		return err
+	}
+	bands := toInt64s(m.MinHash.Bands())
+	var bandValues []any
+	for i, h := range bands {
+		bandValues = append(bandValues, i, h, moduleID)
+	}
+	if err := tx.BulkInsert(ctx, "module_minhash_bands", []string{"band", "hash", "module_id"}, bandValues, ""); err != nil {
+		return err
+	}
+
+	match, err := mostSimilarModule(ctx, tx, m, bands)
+	if err != nil || match == nil {
+		return err
+	}
+	self := &similarModule{id: moduleID, modulePath: m.ModulePath, version: m.Version, commitTime: m.CommitTime}
+	if err := tx.QueryRow(ctx, `SELECT created_at FROM modules WHERE id = $1`, moduleID).Scan(&self.createdAt); err != nil {
+		return err
+	}
+	original, cp := match, self
+	if isOlder(self, match) {
+		original, cp = self, match
+	}
+	cpIsOriginal, err := isNearDuplicateOriginal(ctx, tx, cp.modulePath)
+	if err != nil {
+		return err
+	}
+	if cpIsOriginal {
+		origIsOriginal, err := isNearDuplicateOriginal(ctx, tx, original.modulePath)
+		if err != nil || origIsOriginal {
+			return err
+		}
+		original, cp = cp, original
+	}
+	log.Infof(ctx, "%s@%s is a near duplicate of %s@%s", cp.modulePath, cp.version, original.modulePath, original.version)
+	if _, err := tx.Exec(ctx, `
+		UPDATE modules SET near_duplicate_of = $1
+		WHERE id = $2 AND near_duplicate_of IS NULL`,
+		original.modulePath, cp.id); err != nil {
+		return err
+	}
+	if cp == self {
+		// The search documents of m are updated after this.
+		return nil
+	}
+	_, err = tx.Exec(ctx, `
+		UPDATE search_documents SET near_duplicate_of = $1
+		WHERE module_path = $2 AND version = $3 AND near_duplicate_of IS NULL`,
+		original.modulePath, cp.modulePath, cp.version)
+	return err
+}
+
+type similarModule struct {
+	id                  int
+	modulePath, version string
+	commitTime          time.Time
+	createdAt           time.Time
+	similarity          float64
+}
+
+// isOlder reports whether a is older than b. Forks often have the same commit
+// times as the modules they copy, so ties are broken by which module version
+// pkgsite saw first, and then by module path, so that the result doesn't
+// depend on the order in which they are processed.
+func isOlder(a, b *similarModule) bool {
+	if !a.commitTime.Equal(b.commitTime) {
+		return a.commitTime.Before(b.commitTime)
+	}
+	if !a.createdAt.Equal(b.createdAt) {
+		return a.createdAt.Before(b.createdAt)
+	}
+	return a.modulePath < b.modulePath
+}
+
+// isNearDuplicateOriginal reports whether some module version is marked as a
+// near duplicate of modulePath.
+func isNearDuplicateOriginal(ctx context.Context, tx *database.DB, modulePath string) (bool, error) {
+	var b bool
+	err := tx.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM modules WHERE near_duplicate_of = $1)`, modulePath).Scan(&b)
+	return b, err
+}
+
+// mostSimilarModule returns the module version outside the series of m whose
+// contents are most similar to those of m, if their similarity is at
+// least nearDuplicateThreshold. Otherwise it returns nil. Ties are broken in
+// favor of the oldest module version. bands are the bands of the signature of
+// m. The candidates are the module versions with the most bands in common with
+// m, which are the most likely to be similar to it. Other major versions of
+// m are not candidates, since they usually share most of their files with it.
+func mostSimilarModule(ctx context.Context, tx *database.DB, m *internal.Module, bands []int64) (_ *similarModule, err error) {
+	defer derrors.WrapStack(&err, "mostSimilarModule(ctx, %q, %q)", m.ModulePath, m.Version)
+
+	var best *similarModule
+	err = tx.RunQuery(ctx, `
+		SELECT m.id, m.module_path, m.version, m.commit_time, m.created_at, h.signature
+		FROM (
+			SELECT b.module_id, COUNT(*) AS num_bands
+			FROM module_minhash_bands b
+			INNER JOIN unnest($1::BIGINT[]) WITH ORDINALITY AS q(hash, band)
+			ON b.band = q.band - 1 AND b.hash = q.hash
+			GROUP BY b.module_id
+		) c
+		INNER JOIN modules m ON m.id = c.module_id
+		INNER JOIN module_minhashes h ON h.module_id = m.id
+		WHERE m.series_path != $2
+		ORDER BY c.num_bands DESC, m.commit_time, m.created_at, m.module_path
+		LIMIT $3
+	`, func(rows *sql.Rows) error {
+		var (
+			s   similarModule
+			sig []int64
+		)
+		if err := rows.Scan(&s.id, &s.modulePath, &s.version, &s.commitTime, &s.createdAt, pq.Array(&sig)); err != nil {
+			return err
+		}
+		s.similarity = m.MinHash.Similarity(toUint64s(sig))
+		if s.similarity < nearDuplicateThreshold {
+			return nil
+		}
+		if best == nil || s.similarity > best.similarity ||
+			(s.similarity == best.similarity && isOlder(&s, best)) {
+			best = &s
+		}
+		return nil
+	}, pq.Array(bands), internal.SeriesPathForModule(m.ModulePath), maxNearDuplicateCandidates)
+	if err != nil {
+		return nil, err
+	}
+	return best, nil
+}
+
+// toInt64s converts hashes to int64s, which the database can store.
+func toInt64s(hashes []uint64) []int64 {
+	r := make([]int64, len(hashes))
+	for i, h := range hashes {
+		r[i] = int64(h)
+	}
+	return r
+}
+
+// toUint64s is the inverse of toInt64s.
+func toUint64s(ns []int64) minhash.Signature {
+	r := make(minhash.Signature, len(ns))
+	for i, n := range ns {
+		r[i] = uint64(n)
+	}
+	return r
+}
@@ -0,0 +1,95 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"golang.org/x/pkgsite/internal/minhash"
+	"golang.org/x/pkgsite/internal/testing/sample"
+)
+
+func TestNearDuplicates(t *testing.T) {
+	t.Parallel()
+	testDB, release := acquire(t)
+	defer release()
+	ctx := context.Background()
+
+	var elements []uint64
+	for i := uint64(0); i < 100; i++ {
+		elements = append(elements, i)
+	}
+	sig := minhash.New(elements)
+	other := minhash.New(elements[50:])
+	var tieElements []uint64
+	for i := uint64(1000); i < 1100; i++ {
+		tieElements = append(tieElements, i)
+	}
+	tie := minhash.New(tieElements)
+	var seriesElements []uint64
+	for i := uint64(2000); i < 2100; i++ {
+		seriesElements = append(seriesElements, i)
+	}
+	series := minhash.New(seriesElements)
+
+	insert := func(modulePath string, sig minhash.Signature, commitTime time.Time) {
+		m := sample.Module(modulePath, sample.VersionString, "")
+		m.MinHash = sig
+		m.CommitTime = commitTime
+		MustInsertModule(ctx, t, testDB, m)
+	}
+	nearDuplicateOf := func(modulePath string) string {
+		t.Helper()
+		var s sql.NullString
+		if err := testDB.db.QueryRow(ctx,
+			`SELECT near_duplicate_of FROM modules WHERE module_path = $1`, modulePath).Scan(&s); err != nil {
+			t.Fatal(err)
+		}
+		return s.String
+	}
+
+	t0 := sample.CommitTime
+	insert("example.com/orig", sig, t0)
+	// A newer copy is a near duplicate of the original.
+	insert("example.com/fork", sig, t0.Add(time.Hour))
+	// An older module that is inserted later doesn't make the original a
+	// near duplicate of it, because the original already has copies.
+	insert("example.com/older", sig, t0.Add(-time.Hour))
+	// Reprocessing the original keeps it the original.
+	insert("example.com/orig", sig, t0)
+	// Of two modules with the same commit time, the one seen first is the
+	// original, whatever the order in which they are processed later.
+	insert("example.com/tie2", tie, t0)
+	insert("example.com/tie1", tie, t0)
+	insert("example.com/tie2", tie, t0)
+	// A new major version is not a near duplicate of an earlier one.
+	insert("example.com/series", series, t0)
+	insert("example.com/series/v2", series, t0.Add(time.Hour))
+	// Modules with different contents are not near duplicates.
+	insert("example.com/different", other, t0.Add(time.Hour))
+	// Nor are modules that are too small to compare.
+	insert("example.com/small", nil, t0.Add(time.Hour))
+
+	for _, test := range []struct {
+		modulePath, want string
+	}{
+		{"example.com/orig", ""},
+		{"example.com/fork", "example.com/orig"},
+		{"example.com/older", "example.com/orig"},
+		{"example.com/tie1", "example.com/tie2"},
+		{"example.com/tie2", ""},
+		{"example.com/series", ""},
+		{"example.com/series/v2", ""},
+		{"example.com/different", ""},
+		{"example.com/small", ""},
+	} {
+		if got := nearDuplicateOf(test.modulePath); got != test.want {
+			t.Errorf("%s: got near duplicate of %q, want %q", test.modulePath, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/lib/pq"
+	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/database"
+	"golang.org/x/pkgsite/internal/derrors"
+	"golang.org/x/pkgsite/internal/middleware/stats"
+)
+
+// insertTestFuncs replaces the test functions stored for the units in paths
+// by those in pathToTests.
+func insertTestFuncs(ctx context.Context, tx *database.DB,
+	paths []string,
+	pathToUnitID map[string]int,
+	pathToTests map[string][]*internal.TestFunc) (err error) {
+	defer derrors.WrapStack(&err, "insertTestFuncs(%d paths)", len(paths))
+
+	var (
+		unitIDs []int
+		values  []any
+	)
+	for _, path := range paths {
+		unitID := pathToUnitID[path]
+		unitIDs = append(unitIDs, unitID)
+		for _, tf := range pathToTests[path] {
+			values = append(values, unitID, tf.Filename, tf.Name, tf.Kind, tf.CorpusSize)
+		}
+	}
+	if _, err := tx.Exec(ctx, `DELETE FROM test_funcs WHERE unit_id = ANY($1)`, pq.Array(unitIDs)); err != nil {
+		return err
+	}
+	if len(values) == 0 {
+		return nil
+	}
+	cols := []string{"unit_id", "filename", "name", "kind", "corpus_size"}
+	return tx.BulkInsert(ctx, "test_funcs", cols, values, database.OnConflictDoNothing)
+}
+
+// getTestFuncs returns the test functions of the unit with ID unitID, sorted
+// by kind and then by name.
+func (db *DB) getTestFuncs(ctx context.Context, unitID int) (_ []*internal.TestFunc, err error) {
+	defer derrors.WrapStack(&err, "getTestFuncs(ctx, %d)", unitID)
+	defer stats.Elapsed(ctx, "getTestFuncs")()
+
+	var tfs []*internal.TestFunc
+	err = db.db.RunQuery(ctx, `
+		SELECT filename, name, kind, corpus_size
+		FROM test_funcs
+		WHERE unit_id = $1
+	`, func(rows *sql.Rows) error {
+		var tf internal.TestFunc
+		if err := rows.Scan(&tf.Filename, &tf.Name, &tf.Kind, &tf.CorpusSize); err != nil {
+			return err
+		}
+		tfs = append(tfs, &tf)
+		return nil
+	}, unitID)
+	if err != nil {
+		return nil, err
+	}
+	internal.SortTestFuncs(tfs)
+	return tfs, nil
+}
@@ -0,0 +1,60 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package postgres
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/testing/sample"
+)
+
+func TestTestFuncs(t *testing.T) {
+	t.Parallel()
+	testDB, release := acquire(t)
+	defer release()
+	ctx := context.Background()
+
+	m := sample.Module("example.com/a", "v1.0.0", "p")
+	want := []*internal.TestFunc{
+		{Name: "TestA", Kind: internal.TestKindTest, Filename: "p_test.go"},
+		{Name: "BenchmarkA", Kind: internal.TestKindBenchmark, Filename: "p_test.go"},
+		{Name: "FuzzA", Kind: internal.TestKindFuzz, Filename: "fuzz_test.go", CorpusSize: 3},
+	}
+	pkgPath := "example.com/a/p"
+	for _, u := range m.Units {
+		if u.Path == pkgPath {
+			u.Tests = want
+		}
+	}
+	MustInsertModule(ctx, t, testDB, m)
+
+	um := sample.UnitMeta(pkgPath, m.ModulePath, m.Version, "p", true)
+	u, err := testDB.GetUnit(ctx, um, internal.WithTests, internal.BuildContext{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(want, u.Tests); diff != "" {
+		t.Errorf("mismatch (-want +got):\n%s", diff)
+	}
+
+	// Reinserting the module replaces the test functions.
+	want = want[:1]
+	for _, u := range m.Units {
+		if u.Path == pkgPath {
+			u.Tests = want
+		}
+	}
+	MustInsertModule(ctx, t, testDB, m)
+	u, err = testDB.GetUnit(ctx, um, internal.WithTests, internal.BuildContext{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(want, u.Tests); diff != "" {
+		t.Errorf("after reinsert: mismatch (-want +got):\n%s", diff)
+	}
+}
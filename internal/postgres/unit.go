@@ -7,6 +7,7 @@ package postgres
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	"sort"
 	"strings"
@@ -16,6 +17,7 @@ import (
 	"golang.org/x/pkgsite/internal"
 	"golang.org/x/pkgsite/internal/database"
 	"golang.org/x/pkgsite/internal/derrors"
+	"golang.org/x/pkgsite/internal/godoc"
 	"golang.org/x/pkgsite/internal/licenses"
 	"golang.org/x/pkgsite/internal/middleware/stats"
 	"golang.org/x/pkgsite/internal/stdlib"
@@ -238,7 +240,8 @@ func (db *DB) GetUnit(ctx context.Context, um *internal.UnitMeta, fields interna
 		}
 	}
 	if fields&internal.WithImports == 0 &&
-		fields&internal.WithLicenses == 0 {
+		fields&internal.WithLicenses == 0 &&
+		fields&internal.WithTests == 0 {
 		return u, nil
 	}
 
@@ -259,6 +262,12 @@ func (db *DB) GetUnit(ctx context.Context, um *internal.UnitMeta, fields interna
 			u.NumImports = len(imports)
 		}
 	}
+	if fields&internal.WithTests != 0 {
+		u.Tests, err = db.getTestFuncs(ctx, unitID)
+		if err != nil {
+			return nil, err
+		}
+	}
 	if fields&internal.WithLicenses != 0 {
 		lics, err := db.getLicenses(ctx, u.Path, u.ModulePath, unitID)
 		if err != nil {
@@ -327,6 +336,7 @@ func getPackagesInUnit(ctx context.Context, db *database.DB, fullPath, modulePat
 		"d.synopsis",
 		"d.GOOS",
 		"d.GOARCH",
+		"d.build_tags",
 		"u.license_types",
 		"u.license_paths",
 	).From("units u")
@@ -375,6 +385,7 @@ func getPackagesInUnit(ctx context.Context, db *database.DB, fullPath, modulePat
 			database.NullIsEmpty(&pkg.Synopsis),
 			database.NullIsEmpty(&bc.GOOS),
 			database.NullIsEmpty(&bc.GOARCH),
+			database.NullIsEmpty(&bc.Tags),
 			pq.Array(&licenseTypes),
 			pq.Array(&licensePaths),
 		); err != nil {
@@ -420,7 +431,7 @@ func (db *DB) getUnitWithAllFields(ctx context.Context, um *internal.UnitMeta, b
 	var licenseMetas []*licenses.Metadata
 	var isRedistributable bool
 	err = db.db.RunQuery(ctx, `
-		SELECT d.goos, d.goarch, u.id, p.id, u.module_id, u.license_types, u.license_paths, u.redistributable
+		SELECT d.goos, d.goarch, d.build_tags, u.id, p.id, u.module_id, u.license_types, u.license_paths, u.redistributable
 		FROM units u
 		INNER JOIN paths p ON p.id = u.path_id
 		INNER JOIN modules m ON m.id = u.module_id
@@ -438,7 +449,7 @@ func (db *DB) getUnitWithAllFields(ctx context.Context, um *internal.UnitMeta, b
 			licensePaths []string
 		)
 
-		if err := rows.Scan(database.NullIsEmpty(&bc.GOOS), database.NullIsEmpty(&bc.GOARCH), &unitID, &pathID, &moduleID, pq.Array(&licenseTypes), pq.Array(&licensePaths), &isRedistributable); err != nil {
+		if err := rows.Scan(database.NullIsEmpty(&bc.GOOS), database.NullIsEmpty(&bc.GOARCH), database.NullIsEmpty(&bc.Tags), &unitID, &pathID, &moduleID, pq.Array(&licenseTypes), pq.Array(&licensePaths), &isRedistributable); err != nil {
 			return err
 		}
 
@@ -474,6 +485,8 @@ func (db *DB) getUnitWithAllFields(ctx context.Context, um *internal.UnitMeta, b
 			r.contents,
 			d.synopsis,
 			d.source,
+			d.min_go_version,
+			d.rendered,
 			COALESCE((
 				SELECT COUNT(unit_id)
 				FROM imports
@@ -492,16 +505,19 @@ func (db *DB) getUnitWithAllFields(ctx context.Context, um *internal.UnitMeta, b
 		ON r.unit_id = u.id
 
 		LEFT JOIN (
-			SELECT synopsis, source, goos, goarch, unit_id
+			SELECT synopsis, source, min_go_version, goos, goarch, build_tags, unit_id,
+				-- Documentation rendered by another renderer is rendered again.
+				CASE WHEN renderer_version = $6 THEN rendered END AS rendered
 			FROM documentation d
-			WHERE d.GOOS = $3 AND d.GOARCH = $4
+			WHERE d.GOOS = $3 AND d.GOARCH = $4 AND d.build_tags = $5
         ) d
 		ON d.unit_id = u.id
 		WHERE u.id = $2
 	`
 	var (
-		r internal.Readme
-		u internal.Unit
+		r        internal.Readme
+		u        internal.Unit
+		rendered []byte
 	)
 	u.BuildContexts = bcs
 	var goos, goarch any
@@ -509,13 +525,15 @@ func (db *DB) getUnitWithAllFields(ctx context.Context, um *internal.UnitMeta, b
 		goos = bcMatched.GOOS
 		goarch = bcMatched.GOARCH
 	}
-	doc := &internal.Documentation{GOOS: bcMatched.GOOS, GOARCH: bcMatched.GOARCH}
+	doc := &internal.Documentation{GOOS: bcMatched.GOOS, GOARCH: bcMatched.GOARCH, Tags: bcMatched.Tags}
 	end := stats.Elapsed(ctx, "getUnitWithAllFields-readme-and-imports")
-	err = db.db.QueryRow(ctx, query, pathID, unitID, goos, goarch).Scan(
+	err = db.db.QueryRow(ctx, query, pathID, unitID, goos, goarch, bcMatched.Tags, godoc.RendererVersion).Scan(
 		database.NullIsEmpty(&r.Filepath),
 		database.NullIsEmpty(&r.Contents),
 		database.NullIsEmpty(&doc.Synopsis),
 		&doc.Source,
+		database.NullIsEmpty(&doc.MinGoVersion),
+		&rendered,
 		&u.NumImports,
 		&u.NumImportedBy,
 	)
@@ -527,6 +545,12 @@ func (db *DB) getUnitWithAllFields(ctx context.Context, um *internal.UnitMeta, b
 			u.Readme = &r
 		}
 		if doc.GOOS != "" {
+			if rendered != nil {
+				doc.Rendered = &internal.RenderedDoc{}
+				if err := json.Unmarshal(rendered, doc.Rendered); err != nil {
+					return nil, err
+				}
+			}
 			u.Documentation = []*internal.Documentation{doc}
 		}
 	default:
@@ -634,3 +658,30 @@ func getModuleReadme(ctx context.Context, db *database.DB, modulePath, resolvedV
 		return nil, err
 	}
 }
+
+// UpdateRenderedDoc replaces the rendered documentation of the unit with the
+// given path in the module version, for the build context bc.
+func (db *DB) UpdateRenderedDoc(ctx context.Context, path, modulePath, version string, bc internal.BuildContext, r *internal.RenderedDoc) (err error) {
+	defer derrors.WrapStack(&err, "UpdateRenderedDoc(ctx, %q, %q, %q, %v)", path, modulePath, version, bc)
+
+	rendered, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	_, err = db.db.Exec(ctx, `
+		UPDATE documentation d
+		SET renderer_version = $1, rendered = $2
+		FROM units u
+		INNER JOIN paths p ON p.id = u.path_id
+		INNER JOIN modules m ON m.id = u.module_id
+		WHERE
+			d.unit_id = u.id
+			AND p.path = $3
+			AND m.module_path = $4
+			AND m.version = $5
+			AND d.goos = $6
+			AND d.goarch = $7
+			AND d.build_tags = $8
+	`, r.RendererVersion, rendered, path, modulePath, version, bc.GOOS, bc.GOARCH, bc.Tags)
+	return err
+}
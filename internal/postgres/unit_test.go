@@ -15,6 +15,7 @@ import (
 	"github.com/google/go-cmp/cmp/cmpopts"
 	"github.com/google/safehtml"
 	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/godoc"
 	"golang.org/x/pkgsite/internal/licenses"
 	"golang.org/x/pkgsite/internal/source"
 	"golang.org/x/pkgsite/internal/stdlib"
@@ -758,6 +759,39 @@ func TestGetUnitBuildContext(t *testing.T) {
 	}
 }
 
+func TestGetUnitConfiguredBuildContext(t *testing.T) {
+	// Not parallel: this test changes the configured build contexts.
+	testDB, release := acquire(t)
+	defer release()
+	ctx := context.Background()
+
+	bcs, err := internal.ParseBuildContexts("linux/amd64 wasip1/wasm")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func(old []internal.BuildContext) { internal.BuildContexts = old }(internal.BuildContexts)
+	internal.BuildContexts = bcs
+
+	m := sample.Module("a.com/wasip1", "v1.2.3", "p")
+	pkg := m.Packages()[0]
+	linuxDoc := sample.Documentation("linux", "amd64", `package p; var L int`)
+	wasip1Doc := sample.Documentation("wasip1", "wasm", `package p; var W int`)
+	pkg.Documentation = []*internal.Documentation{linuxDoc, wasip1Doc}
+	MustInsertModule(ctx, t, testDB, m)
+
+	um := sample.UnitMeta("a.com/wasip1/p", "a.com/wasip1", "v1.2.3", "p", true)
+	u, err := testDB.GetUnit(ctx, um, internal.WithMain, bcs[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]*internal.Documentation{wasip1Doc}, u.Documentation); diff != "" {
+		t.Errorf("mismatch (-want, +got):\n%s", diff)
+	}
+	if got := u.BuildContexts; !cmp.Equal(got, bcs) {
+		t.Errorf("got %v, want %v", got, bcs)
+	}
+}
+
 func unit(fullPath, modulePath, version, name string, readme *internal.Readme, suffixes []string) *internal.Unit {
 	u := unitNoLicenses(fullPath, modulePath, version, name, readme, suffixes)
 	u.Licenses = sample.LicenseMetadata()
@@ -865,3 +899,38 @@ func newUnitMeta(path, modulePath, version string) *internal.UnitMeta {
 		},
 	}
 }
+
+func TestUpdateRenderedDoc(t *testing.T) {
+	t.Parallel()
+	testDB, release := acquire(t)
+	defer release()
+	ctx := context.Background()
+
+	m := sample.Module("a.com/rendered", "v1.2.3", "p")
+	pkg := m.Packages()[0]
+	doc := sample.Documentation("linux", "amd64", `package p; var L int`)
+	doc.Rendered = &internal.RenderedDoc{RendererVersion: "stale", Body: "<p>stale</p>"}
+	pkg.Documentation = []*internal.Documentation{doc}
+	MustInsertModule(ctx, t, testDB, m)
+
+	um := sample.UnitMeta(pkg.Path, m.ModulePath, m.Version, pkg.Name, true)
+	getRendered := func() *internal.RenderedDoc {
+		t.Helper()
+		u, err := testDB.GetUnit(ctx, um, internal.WithMain, internal.BuildContext{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return u.Documentation[0].Rendered
+	}
+	// Documentation rendered by another renderer is not returned.
+	if got := getRendered(); got != nil {
+		t.Fatalf("got stale rendered doc %+v, want nil", got)
+	}
+	want := &internal.RenderedDoc{RendererVersion: godoc.RendererVersion, Body: "<p>current</p>"}
+	if err := testDB.UpdateRenderedDoc(ctx, pkg.Path, m.ModulePath, m.Version, doc.BuildContext(), want); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(want, getRendered()); diff != "" {
+		t.Errorf("mismatch (-want, +got):\n%s", diff)
+	}
+}
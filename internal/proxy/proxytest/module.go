@@ -5,7 +5,11 @@
 // Package proxytest supports testing with the proxy.
 package proxytest
 
-import "fmt"
+import (
+	"fmt"
+
+	"golang.org/x/pkgsite/internal/testing/testhelper"
+)
 
 // Module represents a module version used by the proxy server.
 type Module struct {
@@ -16,6 +20,25 @@ type Module struct {
 	zip        []byte
 }
 
+// GoMod returns the contents of the go.mod file that the proxy serves for m.
+// If m has no go.mod file, it is a bare-bones one.
+func (m *Module) GoMod() string {
+	if goMod := m.Files["go.mod"]; goMod != "" {
+		return goMod
+	}
+	return fmt.Sprintf("module %s\n\ngo 1.12", m.ModulePath)
+}
+
+// Zip returns the contents of the zip file that the proxy serves for m.
+func (m *Module) Zip() ([]byte, error) {
+	files := map[string]string{}
+	for path, contents := range m.Files {
+		p := m.ModulePath + "@" + m.Version + "/" + path
+		files[p] = contents
+	}
+	return testhelper.ZipContents(files)
+}
+
 // ChangePath returns a copy of m with a different module path.
 func (m *Module) ChangePath(modulePath string) *Module {
 	m2 := *m
@@ -15,7 +15,6 @@ import (
 
 	"golang.org/x/mod/semver"
 	"golang.org/x/pkgsite/internal/proxy"
-	"golang.org/x/pkgsite/internal/testing/testhelper"
 	"golang.org/x/pkgsite/internal/version"
 )
 
@@ -63,14 +62,7 @@ func (s *Server) handleLatest(modulePath, urlPath string) {
 
 // handleMod creates a mod endpoint for the specified module version.
 func (s *Server) handleMod(m *Module) {
-	defaultGoMod := func(modulePath string) string {
-		// defaultGoMod creates a bare-bones go.mod contents.
-		return fmt.Sprintf("module %s\n\ngo 1.12", modulePath)
-	}
-	goMod := m.Files["go.mod"]
-	if goMod == "" {
-		goMod = defaultGoMod(m.ModulePath)
-	}
+	goMod := m.GoMod()
 	s.mux.HandleFunc(fmt.Sprintf("/%s/@v/%s.mod", m.ModulePath, m.Version),
 		func(w http.ResponseWriter, r *http.Request) {
 			http.ServeContent(w, r, m.ModulePath, time.Now(), strings.NewReader(goMod))
@@ -175,12 +167,7 @@ func cleanModule(m *Module) *Module {
 		m.Version = "v1.0.0"
 	}
 
-	files := map[string]string{}
-	for path, contents := range m.Files {
-		p := m.ModulePath + "@" + m.Version + "/" + path
-		files[p] = contents
-	}
-	zip, err := testhelper.ZipContents(files)
+	zip, err := m.Zip()
 	if err != nil {
 		panic(err)
 	}
@@ -937,3 +937,19 @@ func FilesInfo(dir string) *Info {
 		},
 	}
 }
+
+// SiteInfo returns an Info that links to the given module version in the
+// server's /source namespace, which serves the files of modules that the
+// server has fetched.
+func SiteInfo(modulePath, version string) *Info {
+	return &Info{
+		repoURL: "/source/" + modulePath + "@" + version,
+		templates: urlTemplates{
+			Repo:      "{repo}",
+			Directory: "{repo}/{dir}",
+			File:      "{repo}/{file}",
+			Line:      "{repo}/{file}#L{line}",
+			Raw:       "{repo}/{file}?raw=1",
+		},
+	}
+}
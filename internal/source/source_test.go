@@ -587,3 +587,21 @@ func TestFilesInfo(t *testing.T) {
 	check(info.ModuleURL(), "/files/Users/bob/")
 	check(info.FileURL("dir/a.go"), "/files/Users/bob/dir/a.go")
 }
+
+func TestSiteInfo(t *testing.T) {
+	info := SiteInfo("example.com/m", "v1.2.3")
+
+	check := func(got, want string) {
+		t.Helper()
+		if got != want {
+			t.Errorf("got %q, want %q", got, want)
+		}
+	}
+
+	check(info.RepoURL(), "/source/example.com/m@v1.2.3")
+	check(info.ModuleURL(), "/source/example.com/m@v1.2.3")
+	check(info.DirectoryURL("dir"), "/source/example.com/m@v1.2.3/dir")
+	check(info.FileURL("dir/a.go"), "/source/example.com/m@v1.2.3/dir/a.go")
+	check(info.LineURL("dir/a.go", 7), "/source/example.com/m@v1.2.3/dir/a.go#L7")
+	check(info.RawURL("dir/a.go"), "/source/example.com/m@v1.2.3/dir/a.go?raw=1")
+}
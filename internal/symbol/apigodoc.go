@@ -205,6 +205,8 @@ func parseRow(s string) (vr versionedRow, ok bool) {
 			return
 		}
 		vr.name, rest = rest[:sp], rest[sp+1:]
+		// Drop the type parameters of generic types ("Seq[$0 interface{}]").
+		vr.name, _, _ = strings.Cut(vr.name, "[")
 		switch {
 		case strings.HasPrefix(rest, "struct, "):
 			rest = rest[len("struct, "):]
@@ -244,7 +246,9 @@ func parseRow(s string) (vr versionedRow, ok bool) {
 		vr.kind = "func"
 		rest = rest[len("func "):]
 		if i := strings.IndexByte(rest, '('); i != -1 {
-			vr.name = rest[:i]
+			// Drop the type parameters of generic functions
+			// ("Contains[$0 interface{ ~[]$1 }, $1 comparable]").
+			vr.name, _, _ = strings.Cut(rest[:i], "[")
 			return vr, true
 		}
 	case strings.HasPrefix(rest, "method "): // "method (*File) SetModTime(time.Time)"
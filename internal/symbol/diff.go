@@ -0,0 +1,170 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package symbol
+
+import (
+	"sort"
+	"strings"
+
+	"golang.org/x/pkgsite/internal"
+)
+
+// APIChange describes how a symbol differs between two versions of a package.
+type APIChange struct {
+	// Name is the name of the symbol, as in internal.SymbolMeta.Name.
+	Name string
+
+	// Kind is the kind of the symbol in the new version, or in the old
+	// version if the symbol was removed.
+	Kind internal.SymbolKind
+
+	// Old and New are the synopses of the symbol in the old and new versions.
+	// Old is empty if the symbol was added, and New is empty if it was
+	// removed.
+	Old, New string
+
+	// Builds are the build contexts the change applies to. It is nil if the
+	// change applies to all of them.
+	Builds []internal.BuildContext
+
+	// Incompatible reports whether the change can break code that uses the
+	// old version of the package.
+	Incompatible bool
+}
+
+// APIDiff lists the differences between the exported APIs of two versions of
+// a package.
+type APIDiff struct {
+	Added, Removed, Changed []*APIChange
+}
+
+// Incompatible reports whether any of the changes in d is incompatible.
+func (d *APIDiff) Incompatible() bool {
+	for _, cs := range [][]*APIChange{d.Added, d.Removed, d.Changed} {
+		for _, c := range cs {
+			if c.Incompatible {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// DiffAPI compares the symbols of oldVersion and newVersion in sh, build
+// context by build context.
+//
+// As with the Go 1 compatibility rules checked by cmd/api, removing a symbol
+// or changing its declaration is incompatible, while adding one is not. The
+// exception is a method added to an interface, which breaks implementations
+// of the interface outside the package. Since the synopsis of a variable
+// includes its initial value, a changed variable is not considered
+// incompatible.
+func DiffAPI(sh *internal.SymbolHistory, oldVersion, newVersion string) *APIDiff {
+	oldSyms := symbolsByBuild(sh.SymbolsAtVersion(oldVersion))
+	newSyms := symbolsByBuild(sh.SymbolsAtVersion(newVersion))
+
+	// Group the differences in each build context by symbol and synopses, so
+	// that a change made in every build context is reported once.
+	type key struct{ name, old, new string }
+	changes := map[key]*APIChange{}
+	for _, build := range internal.BuildContexts {
+		olds, news := oldSyms[build], newSyms[build]
+		names := map[string]bool{}
+		for name := range olds {
+			names[name] = true
+		}
+		for name := range news {
+			names[name] = true
+		}
+		for name := range names {
+			o, n := olds[name], news[name]
+			k := key{name: name, old: o.Synopsis, new: n.Synopsis}
+			if k.old == k.new {
+				continue
+			}
+			c := changes[k]
+			if c == nil {
+				c = &APIChange{Name: name, Old: k.old, New: k.new, Kind: n.Kind}
+				if c.New == "" {
+					c.Kind = o.Kind
+				}
+				c.Incompatible = isIncompatible(o, n, news)
+				changes[k] = c
+			}
+			c.Builds = append(c.Builds, build)
+		}
+	}
+
+	d := &APIDiff{}
+	for _, c := range changes {
+		if len(c.Builds) == len(internal.BuildContexts) {
+			c.Builds = nil
+		}
+		switch {
+		case c.Old == "":
+			d.Added = append(d.Added, c)
+		case c.New == "":
+			d.Removed = append(d.Removed, c)
+		default:
+			d.Changed = append(d.Changed, c)
+		}
+	}
+	for _, cs := range [][]*APIChange{d.Added, d.Removed, d.Changed} {
+		sortChanges(cs)
+	}
+	return d
+}
+
+// symbolsByBuild returns the symbols of nameToMeta, keyed by build context and
+// name.
+func symbolsByBuild(nameToMeta map[string]map[internal.SymbolMeta]*internal.SymbolBuildContexts) map[internal.BuildContext]map[string]internal.SymbolMeta {
+	m := map[internal.BuildContext]map[string]internal.SymbolMeta{}
+	for name, metas := range nameToMeta {
+		for sm, builds := range metas {
+			for _, b := range builds.BuildContexts() {
+				if m[b] == nil {
+					m[b] = map[string]internal.SymbolMeta{}
+				}
+				m[b][name] = sm
+			}
+		}
+	}
+	return m
+}
+
+// isIncompatible reports whether the change from o to n is incompatible. One
+// of them may be the zero SymbolMeta, if the symbol is missing from that
+// version. news holds the symbols of the new version.
+func isIncompatible(o, n internal.SymbolMeta, news map[string]internal.SymbolMeta) bool {
+	switch {
+	case n.Synopsis == "":
+		return true
+	case o.Synopsis == "":
+		if n.Kind != internal.SymbolKindMethod || n.ParentName == "" {
+			return false
+		}
+		parent := news[n.ParentName]
+		return strings.HasPrefix(parent.Synopsis, "type "+n.ParentName+" interface")
+	default:
+		return n.Kind != internal.SymbolKindVariable
+	}
+}
+
+// sortChanges sorts cs by name, then by the first of their build contexts.
+func sortChanges(cs []*APIChange) {
+	sort.Slice(cs, func(i, j int) bool {
+		if cs[i].Name != cs[j].Name {
+			return cs[i].Name < cs[j].Name
+		}
+		bi, bj := internal.BuildContextAll, internal.BuildContextAll
+		if len(cs[i].Builds) > 0 {
+			bi = cs[i].Builds[0]
+		}
+		if len(cs[j].Builds) > 0 {
+			bj = cs[j].Builds[0]
+		}
+		return internal.CompareBuildContexts(bi, bj) < 0
+	})
+}
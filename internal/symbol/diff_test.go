@@ -0,0 +1,74 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package symbol
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/pkgsite/internal"
+)
+
+func TestDiffAPI(t *testing.T) {
+	var (
+		kept  = internal.SymbolMeta{Name: "Kept", Kind: internal.SymbolKindFunction, Synopsis: "func Kept()"}
+		iface = internal.SymbolMeta{Name: "I", Kind: internal.SymbolKindType, Synopsis: "type I interface{ ... }"}
+		strct = internal.SymbolMeta{Name: "S", Kind: internal.SymbolKindType, Synopsis: "type S struct"}
+	)
+	sh := internal.NewSymbolHistory()
+	add := func(v string, sm internal.SymbolMeta, builds ...internal.BuildContext) {
+		if len(builds) == 0 {
+			builds = []internal.BuildContext{internal.BuildContextAll}
+		}
+		for _, b := range builds {
+			sh.AddSymbol(sm, v, b)
+		}
+	}
+	for _, v := range []string{"v1.0.0", "v1.1.0"} {
+		add(v, kept)
+		add(v, iface)
+		add(v, strct)
+	}
+	add("v1.0.0", internal.SymbolMeta{Name: "Removed", Kind: internal.SymbolKindFunction, Synopsis: "func Removed()"})
+	add("v1.0.0", internal.SymbolMeta{Name: "Sig", Kind: internal.SymbolKindFunction, Synopsis: "func Sig(int)"})
+	add("v1.1.0", internal.SymbolMeta{Name: "Sig", Kind: internal.SymbolKindFunction, Synopsis: "func Sig(int64)"})
+	add("v1.0.0", internal.SymbolMeta{Name: "V", Kind: internal.SymbolKindVariable, Synopsis: "var V = 1"})
+	add("v1.1.0", internal.SymbolMeta{Name: "V", Kind: internal.SymbolKindVariable, Synopsis: "var V = 2"})
+	add("v1.0.0", internal.SymbolMeta{Name: "Unix", Kind: internal.SymbolKindFunction, Synopsis: "func Unix()"},
+		internal.BuildContextLinux, internal.BuildContextDarwin)
+	add("v1.1.0", internal.SymbolMeta{Name: "Unix", Kind: internal.SymbolKindFunction, Synopsis: "func Unix()"},
+		internal.BuildContextLinux)
+	add("v1.1.0", internal.SymbolMeta{Name: "Added", Kind: internal.SymbolKindFunction, Synopsis: "func Added()"})
+	add("v1.1.0", internal.SymbolMeta{Name: "I.M", Kind: internal.SymbolKindMethod, ParentName: "I", Synopsis: "M()"})
+	add("v1.1.0", internal.SymbolMeta{Name: "S.M", Kind: internal.SymbolKindMethod, ParentName: "S", Synopsis: "func (S) M()"})
+
+	got := DiffAPI(sh, "v1.0.0", "v1.1.0")
+	want := &APIDiff{
+		Added: []*APIChange{
+			{Name: "Added", Kind: internal.SymbolKindFunction, New: "func Added()"},
+			{Name: "I.M", Kind: internal.SymbolKindMethod, New: "M()", Incompatible: true},
+			{Name: "S.M", Kind: internal.SymbolKindMethod, New: "func (S) M()"},
+		},
+		Removed: []*APIChange{
+			{Name: "Removed", Kind: internal.SymbolKindFunction, Old: "func Removed()", Incompatible: true},
+			{Name: "Unix", Kind: internal.SymbolKindFunction, Old: "func Unix()",
+				Builds: []internal.BuildContext{internal.BuildContextDarwin}, Incompatible: true},
+		},
+		Changed: []*APIChange{
+			{Name: "Sig", Kind: internal.SymbolKindFunction, Old: "func Sig(int)", New: "func Sig(int64)", Incompatible: true},
+			{Name: "V", Kind: internal.SymbolKindVariable, Old: "var V = 1", New: "var V = 2"},
+		},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("mismatch (-want +got):\n%s", diff)
+	}
+	if !got.Incompatible() {
+		t.Error("got compatible, want incompatible")
+	}
+
+	if got := DiffAPI(sh, "v1.1.0", "v1.1.0"); got.Incompatible() || len(got.Added)+len(got.Removed)+len(got.Changed) > 0 {
+		t.Errorf("diffing a version with itself: got %+v, want no changes", got)
+	}
+}
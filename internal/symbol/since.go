@@ -0,0 +1,125 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package symbol
+
+import (
+	"fmt"
+	"go/ast"
+	"path/filepath"
+	"strconv"
+
+	"golang.org/x/mod/semver"
+	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/derrors"
+	"golang.org/x/pkgsite/internal/stdlib"
+)
+
+// StdlibAPIVersions records the Go release that introduced each exported
+// package-level symbol of the standard library.
+type StdlibAPIVersions struct {
+	versions apiVersions
+}
+
+// LoadStdlibAPIVersions parses the go*.txt files in dir, which is usually the
+// api directory of a Go installation.
+func LoadStdlibAPIVersions(dir string) (_ *StdlibAPIVersions, err error) {
+	defer derrors.Wrap(&err, "LoadStdlibAPIVersions(%q)", dir)
+
+	files, err := filepath.Glob(filepath.Join(dir, "go*.txt"))
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no go*.txt files in %q", dir)
+	}
+	av, err := ParsePackageAPIInfo(files)
+	if err != nil {
+		return nil, err
+	}
+	return &StdlibAPIVersions{versions: av}, nil
+}
+
+// Since returns the Go release, like "go1.21", that introduced the
+// package-level constant, variable, type or function name in the standard
+// library package pkgPath. It returns the empty string if the symbol is not
+// known.
+func (s *StdlibAPIVersions) Since(pkgPath, name string) string {
+	pv, ok := s.versions[pkgPath]
+	if !ok {
+		return ""
+	}
+	for _, m := range []map[string]string{pv.funcSince, pv.typeSince, pv.constSince, pv.varSince} {
+		if v, ok := m[name]; ok {
+			return v
+		}
+	}
+	return ""
+}
+
+// MinGoVersion returns the earliest Go release, like "go1.21", whose standard
+// library provides every package-level symbol referred to by files. Only
+// qualified identifiers like "slices.Contains" are considered; methods and
+// struct fields are not. It returns the empty string if files use nothing
+// added after Go 1.0.
+func (s *StdlibAPIVersions) MinGoVersion(files []*ast.File) string {
+	var (
+		minVersion string // Go release, like "go1.21"
+		minSemver  string // minVersion as a semantic version, like "v1.21.0"
+	)
+	for _, f := range files {
+		imports := stdlibImportNames(f)
+		if len(imports) == 0 {
+			continue
+		}
+		ast.Inspect(f, func(n ast.Node) bool {
+			sel, ok := n.(*ast.SelectorExpr)
+			if !ok {
+				return true
+			}
+			id, ok := sel.X.(*ast.Ident)
+			// A non-nil Obj means the identifier was declared in the file, so
+			// it shadows any import.
+			if !ok || id.Obj != nil {
+				return true
+			}
+			pkgPath, ok := imports[id.Name]
+			if !ok {
+				return true
+			}
+			v := s.Since(pkgPath, sel.Sel.Name)
+			if v == "" || v == "go1" {
+				return true
+			}
+			if sv := stdlib.VersionForTag(v); sv != "" && (minSemver == "" || semver.Compare(sv, minSemver) > 0) {
+				minVersion, minSemver = v, sv
+			}
+			return true
+		})
+	}
+	return minVersion
+}
+
+// stdlibImportNames returns a map from the names that f uses for the
+// standard library packages it imports to their import paths.
+func stdlibImportNames(f *ast.File) map[string]string {
+	names := map[string]string{}
+	for _, imp := range f.Imports {
+		p, err := strconv.Unquote(imp.Path.Value)
+		if err != nil || !stdlib.Contains(p) {
+			continue
+		}
+		var name string
+		if imp.Name != nil {
+			name = imp.Name.Name
+		} else {
+			name = internal.DefaultPackageName(p)
+		}
+		if name == "_" || name == "." {
+			continue
+		}
+		names[name] = p
+	}
+	return names
+}
@@ -0,0 +1,85 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package symbol
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"testing"
+)
+
+func TestMinGoVersion(t *testing.T) {
+	av, err := LoadStdlibAPIVersions("testdata/api")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range []struct {
+		name, src, want string
+	}{
+		{
+			name: "go1 only",
+			src: `package p
+import ("fmt"; "strings")
+func f() { fmt.Println(strings.Contains("a", "b")) }`,
+			want: "",
+		},
+		{
+			name: "latest wins",
+			src: `package p
+import ("io"; "os"; "strings")
+var _ = strings.Cut
+var _ io.Writer = io.Discard
+func f() { os.ReadFile("x") }`,
+			want: "go1.18",
+		},
+		{
+			name: "generic function",
+			src: `package p
+import "slices"
+var _ = slices.Contains[[]int]`,
+			want: "go1.21",
+		},
+		{
+			name: "major version suffix",
+			src: `package p
+import "math/rand/v2"
+var _ = rand.IntN`,
+			want: "go1.22",
+		},
+		{
+			name: "renamed import",
+			src: `package p
+import str "strings"
+var _ = str.Cut`,
+			want: "go1.18",
+		},
+		{
+			name: "shadowed import",
+			src: `package p
+import "strings"
+type T struct{ Cut int }
+func f(strings T) int { return strings.Cut }`,
+			want: "",
+		},
+		{
+			name: "non-stdlib import",
+			src: `package p
+import "example.com/strings"
+var _ = strings.Cut`,
+			want: "",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			f, err := parser.ParseFile(token.NewFileSet(), "p.go", test.src, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := av.MinGoVersion([]*ast.File{f}); got != test.want {
+				t.Errorf("got %q, want %q", got, test.want)
+			}
+		})
+	}
+}
@@ -8,6 +8,7 @@ package fakedatasource
 import (
 	"context"
 	"fmt"
+	"io/fs"
 	"sort"
 	"strings"
 
@@ -25,6 +26,8 @@ var errNotImplemented = fmt.Errorf("not implemented: %w", derrors.Unsupported)
 type FakeDataSource struct {
 	modules    map[module.Version]*internal.Module
 	importedBy map[string][]string
+	usedBy     map[string][]string // keyed by package path, ".", symbol
+	moduleFS   map[module.Version]fs.FS
 }
 
 // New returns an initialized FakeDataSource.
@@ -32,6 +35,8 @@ func New() *FakeDataSource {
 	return &FakeDataSource{
 		modules:    make(map[module.Version]*internal.Module),
 		importedBy: make(map[string][]string),
+		usedBy:     make(map[string][]string),
+		moduleFS:   make(map[module.Version]fs.FS),
 	}
 }
 
@@ -351,6 +356,67 @@ func (ds *FakeDataSource) GetImportedBy(ctx context.Context, pkgPath, modulePath
 	return importedBy, nil
 }
 
+// GetSymbolUsedBy returns the set of packages referring to the given symbol
+// of pkgPath.
+func (ds *FakeDataSource) GetSymbolUsedBy(ctx context.Context, pkgPath, modulePath, symbol string, limit int) (paths []string, err error) {
+	usedBy := append([]string{}, ds.usedBy[pkgPath+"."+symbol]...)
+	sort.Strings(usedBy)
+	if len(usedBy) > limit {
+		usedBy = usedBy[:limit]
+	}
+	return usedBy, nil
+}
+
+// SetModuleFS sets the contents of the given module version.
+func (ds *FakeDataSource) SetModuleFS(modulePath, version string, fsys fs.FS) {
+	ds.moduleFS[module.Version{Path: modulePath, Version: version}] = fsys
+}
+
+// GetModuleFS returns the contents of the given module version set with
+// SetModuleFS.
+func (ds *FakeDataSource) GetModuleFS(ctx context.Context, modulePath, version string) (fs.FS, error) {
+	fsys, ok := ds.moduleFS[module.Version{Path: modulePath, Version: version}]
+	if !ok {
+		return nil, derrors.NotFound
+	}
+	return fsys, nil
+}
+
+// UpdateRenderedDoc replaces the rendered documentation of the unit with the
+// given path in the module version, for the build context bc.
+func (ds *FakeDataSource) UpdateRenderedDoc(ctx context.Context, path, modulePath, version string, bc internal.BuildContext, r *internal.RenderedDoc) error {
+	m := ds.getModule(modulePath, version)
+	if m == nil {
+		return derrors.NotFound
+	}
+	u := findUnit(m, path)
+	if u == nil {
+		return derrors.NotFound
+	}
+	for _, d := range u.Documentation {
+		if d.BuildContext() == bc {
+			d.Rendered = r
+		}
+	}
+	return nil
+}
+
+// GetDocProblems returns the documentation problems of the units of the
+// given module version.
+func (ds *FakeDataSource) GetDocProblems(ctx context.Context, modulePath, version string) (map[string][]*internal.DocProblem, error) {
+	m := ds.getModule(modulePath, version)
+	if m == nil {
+		return nil, derrors.NotFound
+	}
+	problems := map[string][]*internal.DocProblem{}
+	for _, u := range m.Units {
+		if len(u.DocProblems) > 0 {
+			problems[u.Path] = u.DocProblems
+		}
+	}
+	return problems, nil
+}
+
 func (ds *FakeDataSource) GetImportedByCount(ctx context.Context, pkgPath, modulePath string) (int, error) {
 	return 0, nil
 }
@@ -475,6 +541,11 @@ func (ds *FakeDataSource) InsertModule(ctx context.Context, m *internal.Module,
 			for _, pkg := range u.Imports {
 				ds.importedBy[pkg] = append(ds.importedBy[pkg], u.Path)
 			}
+			for pkg, symbols := range u.SymbolReferences {
+				for _, s := range symbols {
+					ds.usedBy[pkg+"."+s] = append(ds.usedBy[pkg+"."+s], u.Path)
+				}
+			}
 		}
 	}
 
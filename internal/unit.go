@@ -5,6 +5,8 @@
 package internal
 
 import (
+	"sort"
+
 	"golang.org/x/pkgsite/internal/licenses"
 )
 
@@ -57,6 +59,22 @@ type Unit struct {
 	// SymbolHistory is a map of symbolName to the version when the symbol was
 	// first added to the package.
 	SymbolHistory map[string]string
+
+	// Tests are the tests, benchmarks and fuzz targets of the package, sorted
+	// by kind and then by name.
+	Tests []*TestFunc
+
+	// SymbolReferences maps the import paths of the packages that the package
+	// imports, other than those in the standard library, to the sorted names
+	// of their exported symbols that it refers to: package-level names, and
+	// methods and fields written as "Type.Name". It is only populated when the
+	// unit is fetched, and is not read from the database.
+	SymbolReferences map[string][]string
+
+	// DocProblems are the problems found in the documentation of the
+	// package, sorted by position. Like SymbolReferences, they are only
+	// populated when the unit is fetched.
+	DocProblems []*DocProblem
 }
 
 // Documentation is the rendered documentation for a given package
@@ -64,11 +82,130 @@ type Unit struct {
 type Documentation struct {
 	// The values of the GOOS and GOARCH environment variables used to parse the
 	// package.
-	GOOS     string
-	GOARCH   string
+	GOOS   string
+	GOARCH string
+	// Tags are the additional build tags used to parse the package, as in
+	// BuildContext.Tags.
+	Tags     string
 	Synopsis string
 	Source   []byte // encoded ast.Files; see godoc.Package.Encode
 	API      []*Symbol
+	// MinGoVersion is the earliest Go release, like "go1.21", whose standard
+	// library has every symbol the package uses. It is empty if unknown or if
+	// the package only uses symbols from Go 1.0.
+	MinGoVersion string
+	// Rendered is the documentation rendered to HTML when the package was
+	// fetched, or nil if it was not rendered.
+	Rendered *RenderedDoc
+}
+
+// RenderedDoc is the HTML documentation of a package, rendered ahead of time
+// so that it doesn't have to be rendered for each request. See
+// godoc.Package.Prerender.
+type RenderedDoc struct {
+	// RendererVersion identifies the renderer that produced the HTML.
+	RendererVersion string
+	// SourceURL is the URL of the module's source at its version, which the
+	// source links in the HTML point into.
+	SourceURL string
+	// LinkQuery is the query added to links to other packages, which
+	// depends on the build context the HTML was rendered for.
+	LinkQuery string
+	// The HTML of the parts of the documentation. The body holds
+	// placeholders for the versions that introduced each symbol.
+	Body, Outline, MobileOutline string
+	// Links are the links in the "Links" section of the package doc.
+	Links []RenderedLink
+	// Files are the names of the non-test .go files of the package.
+	Files []string
+}
+
+// A RenderedLink is a link in a RenderedDoc.
+type RenderedLink struct {
+	Href, Text string
+}
+
+// A TestFunc is a test, benchmark or fuzz target declared in the _test.go
+// files of a package.
+type TestFunc struct {
+	Name string
+	Kind TestKind
+	// Filename is the name of the file that declares the function.
+	Filename string
+	// CorpusSize is the number of files in the seed corpus of a fuzz target,
+	// in the package's testdata/fuzz/<Name> directory. It is zero for
+	// other kinds.
+	CorpusSize int
+}
+
+// A TestKind is the kind of a TestFunc.
+type TestKind string
+
+// The kinds of TestFunc, in the order they are listed.
+const (
+	TestKindTest      TestKind = "test"
+	TestKindBenchmark TestKind = "benchmark"
+	TestKindFuzz      TestKind = "fuzz"
+)
+
+var testKindOrder = map[TestKind]int{TestKindTest: 0, TestKindBenchmark: 1, TestKindFuzz: 2}
+
+// SortTestFuncs sorts tfs by kind and then by name.
+func SortTestFuncs(tfs []*TestFunc) {
+	sort.Slice(tfs, func(i, j int) bool {
+		if ki, kj := testKindOrder[tfs[i].Kind], testKindOrder[tfs[j].Kind]; ki != kj {
+			return ki < kj
+		}
+		if tfs[i].Name != tfs[j].Name {
+			return tfs[i].Name < tfs[j].Name
+		}
+		return tfs[i].Filename < tfs[j].Filename
+	})
+}
+
+// A DocProblem is a problem with the documentation of a package, like an
+// exported symbol without a doc comment.
+type DocProblem struct {
+	Kind DocProblemKind
+	// Symbol is the name of the symbol whose documentation has the problem,
+	// like "F" or "T.M", or the empty string for the package comment.
+	Symbol string
+	// Filename is the name of the file that declares the symbol, and Line
+	// is the line of the declaration.
+	Filename string
+	Line     int
+	Message  string
+}
+
+// A DocProblemKind is the kind of a DocProblem.
+type DocProblemKind string
+
+const (
+	// DocProblemMissing is an exported symbol, or a package, without a doc
+	// comment.
+	DocProblemMissing DocProblemKind = "missing"
+	// DocProblemPrefix is a doc comment that doesn't start with the name of
+	// the symbol it documents.
+	DocProblemPrefix DocProblemKind = "prefix"
+	// DocProblemLink is a doc link, like [Name], to a symbol that the
+	// package doesn't declare.
+	DocProblemLink DocProblemKind = "link"
+	// DocProblemExample is an example whose name refers to a symbol that the
+	// package doesn't declare.
+	DocProblemExample DocProblemKind = "example"
+)
+
+// SortDocProblems sorts dps by position and then by message.
+func SortDocProblems(dps []*DocProblem) {
+	sort.Slice(dps, func(i, j int) bool {
+		if dps[i].Filename != dps[j].Filename {
+			return dps[i].Filename < dps[j].Filename
+		}
+		if dps[i].Line != dps[j].Line {
+			return dps[i].Line < dps[j].Line
+		}
+		return dps[i].Message < dps[j].Message
+	})
 }
 
 // Readme is a README at the specified filepath.
@@ -113,4 +250,5 @@ const (
 	WithMain FieldSet = 1 << iota
 	WithImports
 	WithLicenses
+	WithTests
 )
@@ -0,0 +1,161 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"golang.org/x/mod/module"
+	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/derrors"
+	"golang.org/x/pkgsite/internal/log"
+	"golang.org/x/pkgsite/internal/postgres"
+)
+
+// knownAlternativeFor returns the module that modulePath is known to be an
+// alternative to, or the empty string if there is none. Alternatives learned
+// from deprecation comments are ignored, because a deprecated module is not a
+// copy of the module that replaces it.
+func knownAlternativeFor(ctx context.Context, db *postgres.DB, modulePath string) (_ string, err error) {
+	a, err := db.GetAlternativeModulePath(ctx, modulePath)
+	if errors.Is(err, derrors.NotFound) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	if a.Source == postgres.AlternativeSourceDeprecated {
+		return "", nil
+	}
+	return a.Alternative, nil
+}
+
+// recordAlternative adds an entry to the alternative_module_paths table if the
+// fetch of ft shows that the module is an alternative to another one: either
+// the go.mod file of the latest version declares a different module path, or
+// the go.mod file of the latest version lmv has a comment like "Deprecated:
+// use example.com/mod". Only the latest version counts, so that the old
+// versions of a renamed module, which still declare the old path, don't make
+// it an alternative. Once the latest version is processed successfully, an
+// entry learned from a go.mod file is deleted. Errors are logged, not
+// returned.
+func recordAlternative(ctx context.Context, db *postgres.DB, ft *fetchTask, lmv *internal.LatestModuleVersions) {
+	if ft.Error == nil && lmv != nil && ft.ResolvedVersion == lmv.CookedVersion {
+		// The latest version declares its own path, so an alternative learned
+		// from the go.mod file of an earlier one no longer applies.
+		if err := db.DeleteAlternativeModulePathFromSource(ctx, ft.ModulePath, postgres.AlternativeSourceGoMod); err != nil {
+			log.Error(ctx, err)
+		}
+	}
+	var a *postgres.AlternativeModulePath
+	switch {
+	case ft.Status == derrors.ToStatus(derrors.AlternativeModule):
+		if ft.GoModPath == "" || isSameSeries(ft.GoModPath, ft.ModulePath) {
+			return
+		}
+		if lmv == nil || ft.ResolvedVersion != lmv.CookedVersion {
+			return
+		}
+		a = &postgres.AlternativeModulePath{
+			ModulePath:  ft.ModulePath,
+			Alternative: ft.GoModPath,
+			Source:      postgres.AlternativeSourceGoMod,
+		}
+	case ft.Error == nil && ft.Module != nil && ft.Module.Deprecated:
+		if lmv == nil || ft.ResolvedVersion != lmv.RawVersion {
+			return
+		}
+		alt := alternativeFromDeprecation(ft.ModulePath, ft.Module.DeprecationComment)
+		if alt == "" {
+			return
+		}
+		a = &postgres.AlternativeModulePath{
+			ModulePath:  ft.ModulePath,
+			Alternative: alt,
+			Source:      postgres.AlternativeSourceDeprecated,
+		}
+	default:
+		return
+	}
+	if err := db.InsertAlternativeModulePath(ctx, a); err != nil {
+		log.Error(ctx, err)
+	}
+}
+
+// useRegexp matches the module path in deprecation comments like
+// "use example.com/mod instead".
+var useRegexp = regexp.MustCompile(`(?i)\buse\s+(\S+)`)
+
+// alternativeFromDeprecation returns the module path that the deprecation
+// comment of modulePath recommends using instead, or the empty string if there
+// is none. A recommendation of a different major version of the same module
+// is not an alternative.
+func alternativeFromDeprecation(modulePath, comment string) string {
+	m := useRegexp.FindStringSubmatch(comment)
+	if m == nil {
+		return ""
+	}
+	alt := strings.Trim(m[1], "`\"'()[]<>.,;:")
+	alt = strings.TrimPrefix(alt, "https://")
+	alt = strings.TrimPrefix(alt, "pkg.go.dev/")
+	if module.CheckPath(alt) != nil || isSameSeries(alt, modulePath) {
+		return ""
+	}
+	return alt
+}
+
+func isSameSeries(path1, path2 string) bool {
+	return internal.SeriesPathForModule(path1) == internal.SeriesPathForModule(path2)
+}
+
+// handleAddAlternative adds or replaces the alternative for the series of the
+// "module" query parameter with the "alternative" query parameter.
+func (s *Server) handleAddAlternative(w http.ResponseWriter, r *http.Request) (err error) {
+	defer derrors.Wrap(&err, "handleAddAlternative")
+
+	modulePath := r.FormValue("module")
+	alt := r.FormValue("alternative")
+	if modulePath == "" || alt == "" {
+		return &serverError{
+			http.StatusBadRequest,
+			errors.New("must provide 'module' and 'alternative' query params"),
+		}
+	}
+	for _, p := range []string{modulePath, alt} {
+		if err := module.CheckPath(p); err != nil {
+			return &serverError{http.StatusBadRequest, err}
+		}
+	}
+	if err := s.db.InsertAlternativeModulePath(r.Context(), &postgres.AlternativeModulePath{
+		ModulePath:  modulePath,
+		Alternative: alt,
+		Source:      postgres.AlternativeSourceAdmin,
+	}); err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "Added %s as an alternative to %s", internal.SeriesPathForModule(modulePath), alt)
+	return nil
+}
+
+// handleDeleteAlternative deletes the alternative for the series of the
+// "module" query parameter.
+func (s *Server) handleDeleteAlternative(w http.ResponseWriter, r *http.Request) (err error) {
+	defer derrors.Wrap(&err, "handleDeleteAlternative")
+
+	modulePath := r.FormValue("module")
+	if modulePath == "" {
+		return &serverError{http.StatusBadRequest, errors.New("must provide 'module' query param")}
+	}
+	if err := s.db.DeleteAlternativeModulePath(r.Context(), modulePath); err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "Deleted the alternative for %s", internal.SeriesPathForModule(modulePath))
+	return nil
+}
@@ -0,0 +1,26 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package worker
+
+import "testing"
+
+func TestAlternativeFromDeprecation(t *testing.T) {
+	const modulePath = "example.com/old"
+	for _, test := range []struct {
+		comment, want string
+	}{
+		{"use example.com/new instead.", "example.com/new"},
+		{"Use example.com/new.", "example.com/new"},
+		{"This module is no longer maintained; use `example.com/new/v2`.", "example.com/new/v2"},
+		{"use https://pkg.go.dev/example.com/new", "example.com/new"},
+		{"no longer maintained", ""},
+		{"use the standard library", ""},
+		{"use example.com/old/v2", ""},
+	} {
+		if got := alternativeFromDeprecation(modulePath, test.comment); got != test.want {
+			t.Errorf("alternativeFromDeprecation(%q, %q) = %q, want %q", modulePath, test.comment, got, test.want)
+		}
+	}
+}
@@ -24,6 +24,7 @@ import (
 	"golang.org/x/mod/semver"
 	"golang.org/x/pkgsite/internal"
 	"golang.org/x/pkgsite/internal/cache"
+	"golang.org/x/pkgsite/internal/checksum"
 	"golang.org/x/pkgsite/internal/dcensus"
 	"golang.org/x/pkgsite/internal/derrors"
 	"golang.org/x/pkgsite/internal/experiment"
@@ -103,6 +104,9 @@ type Fetcher struct {
 	Cache        *cache.Cache
 	loadShedder  *loadShedder
 	Source       string
+	GitRepos     map[string]string  // module path to git repo to fetch from instead of the proxy
+	Verifier     *checksum.Verifier // if non-nil, verifies modules fetched from the proxy
+	LoadOptions  fetch.LoadOptions  // how the packages of fetched modules are loaded
 }
 
 // FetchAndUpdateState fetches and processes a module version, and then updates
@@ -191,6 +195,7 @@ func (f *Fetcher) FetchAndUpdateState(ctx context.Context, modulePath, requested
 	}
 	ft := f.fetchAndInsertModule(ctx, modulePath, requestedVersion, lmv)
 	nPackages = int64(len(ft.PackageVersionStates))
+	recordAlternative(ctx, f.DB, ft, lmv)
 	span.AddAttributes(trace.Int64Attribute("numPackages", nPackages))
 
 	// If there were any errors processing the module then we didn't insert it.
@@ -313,8 +318,25 @@ func (f *Fetcher) fetchAndInsertModule(ctx context.Context, modulePath, requeste
 	}
 
 	moduleGetter := fetch.NewProxyModuleGetter(f.ProxyClient, f.SourceClient)
+	if f.Verifier != nil {
+		moduleGetter = fetch.NewVerifiedProxyModuleGetter(f.ProxyClient, f.SourceClient, f.Verifier)
+	}
 	if modulePath == "std" {
 		moduleGetter = fetch.NewStdlibZipModuleGetter()
+	} else if dir, ok := f.GitRepos[modulePath]; ok {
+		g, err := fetch.NewGitModuleGetter(ctx, modulePath, dir)
+		if err != nil {
+			ft.Error = err
+			return ft
+		}
+		moduleGetter = g
+	}
+	// A module without a go.mod file cannot declare its own path, so fetch
+	// compares its path to the known alternatives.
+	alt, err := knownAlternativeFor(ctx, f.DB, modulePath)
+	if err != nil {
+		ft.Error = err
+		return ft
 	}
 	// Fetch the module, and the current @main and @master version of this module.
 	// The @main and @master version will be used to update the version_map
@@ -325,7 +347,7 @@ func (f *Fetcher) fetchAndInsertModule(ctx context.Context, modulePath, requeste
 	go func() {
 		defer wg.Done()
 		start := time.Now()
-		fr := fetch.FetchModule(ctx, modulePath, requestedVersion, moduleGetter)
+		fr := fetch.FetchModuleWithKnownAlternative(ctx, modulePath, requestedVersion, moduleGetter, alt, f.LoadOptions)
 		if fr == nil {
 			panic("fetch.FetchModule should never return a nil FetchResult")
 		}
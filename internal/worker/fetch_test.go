@@ -16,6 +16,7 @@ import (
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
 	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/fetch"
 	"golang.org/x/pkgsite/internal/godoc"
 	"golang.org/x/pkgsite/internal/licenses"
 	"golang.org/x/pkgsite/internal/postgres"
@@ -293,7 +294,7 @@ func TestFetchAndUpdateStateCacheZip(t *testing.T) {
 	defer teardownProxy()
 
 	// With a plain proxy, we download the zip twice.
-	f := &Fetcher{proxyClient, source.NewClient(http.DefaultClient), testDB, nil, nil, ""}
+	f := &Fetcher{proxyClient, source.NewClient(http.DefaultClient), testDB, nil, nil, "", nil, nil, fetch.LoadOptions{}}
 	if _, _, err := f.FetchAndUpdateState(ctx, "m.com", "v1.0.0", testAppVersion); err != nil {
 		t.Fatal(err)
 	}
@@ -363,7 +364,7 @@ func TestFetchGo121(t *testing.T) {
 	defer teardownProxy()
 
 	sourceClient := source.NewClient(http.DefaultClient)
-	f := &Fetcher{proxyClient, sourceClient, testDB, nil, nil, ""}
+	f := &Fetcher{proxyClient, sourceClient, testDB, nil, nil, "", nil, nil, fetch.LoadOptions{}}
 	got, _, err := f.FetchAndUpdateState(context.Background(), modulePath, version, testAppVersion)
 	if err != nil {
 		t.Fatalf("FetchAndUpdateState(%q, %q): %v", sample.ModulePath, version, err)
@@ -165,6 +165,99 @@ func TestFetchAndUpdateState_Mismatch(t *testing.T) {
 
 	fetchAndCheckStatus(ctx, t, proxyClient, sample.ModulePath, sample.VersionString,
 		derrors.ToStatus(derrors.AlternativeModule))
+
+	// The mismatch is recorded as an alternative.
+	got, err := testDB.GetAlternativeModulePath(ctx, sample.ModulePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Alternative != goModPath || got.Source != postgres.AlternativeSourceGoMod {
+		t.Errorf("got alternative %q from %q, want %q from %q",
+			got.Alternative, got.Source, goModPath, postgres.AlternativeSourceGoMod)
+	}
+}
+
+func TestFetchAndUpdateState_Renamed(t *testing.T) {
+	// Check that the old versions of a renamed module, which declare the old
+	// path, don't make it an alternative, and that processing the latest
+	// version deletes an alternative learned from a go.mod file.
+	ctx := context.Background()
+
+	defer postgres.ResetTestDB(testDB, t)
+
+	proxyClient, teardownProxy := proxytest.SetupTestClient(t, []*proxytest.Module{
+		{
+			ModulePath: sample.ModulePath,
+			Version:    "v1.0.0",
+			Files: map[string]string{
+				"go.mod":     "module example.com/oldpath",
+				"foo/foo.go": "// Package foo\npackage foo\n\nconst Foo = 42",
+			},
+		},
+		{
+			ModulePath: sample.ModulePath,
+			Version:    "v1.1.0",
+			Files: map[string]string{
+				"go.mod":     "module " + sample.ModulePath,
+				"foo/foo.go": "// Package foo\npackage foo\n\nconst Foo = 42",
+			},
+		},
+	})
+	defer teardownProxy()
+
+	fetchAndCheckStatus(ctx, t, proxyClient, sample.ModulePath, "v1.0.0", derrors.ToStatus(derrors.AlternativeModule))
+	if _, err := testDB.GetAlternativeModulePath(ctx, sample.ModulePath); !errors.Is(err, derrors.NotFound) {
+		t.Errorf("after old version: got %v, want NotFound", err)
+	}
+
+	if err := testDB.InsertAlternativeModulePath(ctx, &postgres.AlternativeModulePath{
+		ModulePath:  sample.ModulePath,
+		Alternative: "example.com/oldpath",
+		Source:      postgres.AlternativeSourceGoMod,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	fetchAndCheckStatus(ctx, t, proxyClient, sample.ModulePath, "v1.1.0", http.StatusOK)
+	if _, err := testDB.GetAlternativeModulePath(ctx, sample.ModulePath); !errors.Is(err, derrors.NotFound) {
+		t.Errorf("after latest version: got %v, want NotFound", err)
+	}
+}
+
+func TestFetchAndUpdateState_KnownAlternative(t *testing.T) {
+	// Check that a module without a go.mod file is not processed if it is a
+	// known alternative, unless the alternative was learned from a
+	// deprecation comment.
+	ctx := context.Background()
+
+	defer postgres.ResetTestDB(testDB, t)
+
+	proxyClient, teardownProxy := proxytest.SetupTestClient(t, []*proxytest.Module{
+		{
+			ModulePath: sample.ModulePath,
+			Version:    sample.VersionString,
+			Files: map[string]string{
+				"foo/foo.go": "// Package foo\npackage foo\n\nconst Foo = 42",
+			},
+		},
+	})
+	defer teardownProxy()
+
+	for _, test := range []struct {
+		source   string
+		wantCode int
+	}{
+		{postgres.AlternativeSourceDeprecated, http.StatusOK},
+		{postgres.AlternativeSourceAdmin, derrors.ToStatus(derrors.AlternativeModule)},
+	} {
+		if err := testDB.InsertAlternativeModulePath(ctx, &postgres.AlternativeModulePath{
+			ModulePath:  sample.ModulePath,
+			Alternative: "example.com/original",
+			Source:      test.source,
+		}); err != nil {
+			t.Fatal(err)
+		}
+		fetchAndCheckStatus(ctx, t, proxyClient, sample.ModulePath, sample.VersionString, test.wantCode)
+	}
 }
 
 func TestFetchAndUpdateState_DeleteOlder(t *testing.T) {
@@ -325,7 +418,7 @@ func TestTrimLargeCode(t *testing.T) {
 
 func fetchAndCheckStatus(ctx context.Context, t *testing.T, proxyClient *proxy.Client, modulePath, version string, wantCode int) {
 	t.Helper()
-	f := Fetcher{proxyClient, source.NewClient(http.DefaultClient), testDB, nil, nil, ""}
+	f := Fetcher{proxyClient, source.NewClient(http.DefaultClient), testDB, nil, nil, "", nil, nil, fetch.LoadOptions{}}
 	code, _, err := f.FetchAndUpdateState(ctx, modulePath, version, testAppVersion)
 	switch code {
 	case http.StatusOK:
@@ -203,6 +203,21 @@ func (s *Server) doExcludedPage(w http.ResponseWriter, r *http.Request) (err err
 	return renderPage(r.Context(), w, page, s.templates[excludedTemplate])
 }
 
+func (s *Server) doAlternativesPage(w http.ResponseWriter, r *http.Request) (err error) {
+	alternatives, err := s.db.GetAlternativeModulePaths(r.Context())
+	if err != nil {
+		return annotation{err, "error fetching alternatives"}
+	}
+	page := struct {
+		Env          string
+		Alternatives []*postgres.AlternativeModulePath
+	}{
+		Env:          env(s.cfg),
+		Alternatives: alternatives,
+	}
+	return renderPage(r.Context(), w, page, s.templates[alternativesTemplate])
+}
+
 func env(cfg *config.Config) string {
 	e := cfg.DeploymentEnvironment()
 	return strings.ToUpper(e[:1]) + e[1:]
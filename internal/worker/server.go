@@ -27,10 +27,12 @@ import (
 	"golang.org/x/mod/semver"
 	"golang.org/x/pkgsite/internal"
 	"golang.org/x/pkgsite/internal/cache"
+	"golang.org/x/pkgsite/internal/checksum"
 	"golang.org/x/pkgsite/internal/config"
 	"golang.org/x/pkgsite/internal/config/serverconfig"
 	"golang.org/x/pkgsite/internal/dcensus"
 	"golang.org/x/pkgsite/internal/derrors"
+	"golang.org/x/pkgsite/internal/fetch"
 	"golang.org/x/pkgsite/internal/godoc/dochtml"
 	"golang.org/x/pkgsite/internal/index"
 	"golang.org/x/pkgsite/internal/log"
@@ -50,6 +52,8 @@ type Server struct {
 	indexClient    *index.Client
 	proxyClient    *proxy.Client
 	sourceClient   *source.Client
+	verifier       *checksum.Verifier
+	loadOptions    fetch.LoadOptions
 	cache          *cache.Cache
 	betaCache      *cache.Cache
 	db             *postgres.DB
@@ -68,6 +72,8 @@ type ServerConfig struct {
 	IndexClient          *index.Client
 	ProxyClient          *proxy.Client
 	SourceClient         *source.Client
+	Verifier             *checksum.Verifier
+	LoadOptions          fetch.LoadOptions
 	RedisCacheClient     *redis.Client
 	RedisBetaCacheClient *redis.Client
 	Queue                queue.Queue
@@ -77,16 +83,17 @@ type ServerConfig struct {
 }
 
 const (
-	indexTemplate    = "index.tmpl"
-	versionsTemplate = "versions.tmpl"
-	excludedTemplate = "excluded.tmpl"
+	indexTemplate        = "index.tmpl"
+	versionsTemplate     = "versions.tmpl"
+	excludedTemplate     = "excluded.tmpl"
+	alternativesTemplate = "alternatives.tmpl"
 )
 
 // NewServer creates a new Server with the given dependencies.
 func NewServer(cfg *config.Config, scfg ServerConfig) (_ *Server, err error) {
 	defer derrors.Wrap(&err, "NewServer(db, %+v)", scfg)
 	templates := map[string]*template.Template{}
-	for _, templateName := range []string{indexTemplate, versionsTemplate, excludedTemplate} {
+	for _, templateName := range []string{indexTemplate, versionsTemplate, excludedTemplate, alternativesTemplate} {
 		t, err := parseTemplate(cfg, scfg.StaticPath, templateName)
 		if err != nil {
 			return nil, err
@@ -117,6 +124,8 @@ func NewServer(cfg *config.Config, scfg ServerConfig) (_ *Server, err error) {
 		indexClient:    scfg.IndexClient,
 		proxyClient:    scfg.ProxyClient,
 		sourceClient:   scfg.SourceClient,
+		verifier:       scfg.Verifier,
+		loadOptions:    scfg.LoadOptions,
 		cache:          c,
 		betaCache:      bc,
 		queue:          scfg.Queue,
@@ -223,6 +232,16 @@ func (s *Server) Install(handle func(string, http.Handler)) {
 	// the file private/config/excluded.txt into the databse.
 	handle("/populate-excluded-prefixes", rmw(s.errorHandler(s.handlePopulateExcludedPrefixes)))
 
+	// manual: add-alternative records that the modules in the series of the
+	// "module" query parameter are alternatives to the module in the
+	// "alternative" query parameter, so that they are not processed unless
+	// they have a go.mod file.
+	handle("/add-alternative", rmw(s.errorHandler(s.handleAddAlternative)))
+
+	// manual: delete-alternative deletes the alternative for the series of the
+	// "module" query parameter.
+	handle("/delete-alternative", rmw(s.errorHandler(s.handleDeleteAlternative)))
+
 	// manual: clear-cache clears the redis cache.
 	handle("/clear-cache", rmw(s.clearCache(s.cache)))
 
@@ -268,6 +287,9 @@ func (s *Server) DebugHandler() (http.Handler, error) {
 	// Serve a list of excluded prefixes and module versions.
 	mux.Handle("/excluded", http.HandlerFunc(s.handleHTMLPage(s.doExcludedPage)))
 
+	// Serve a list of known alternative module paths.
+	mux.Handle("/alternatives", http.HandlerFunc(s.handleHTMLPage(s.doAlternativesPage)))
+
 	return mux, nil
 }
 
@@ -374,6 +396,9 @@ func (s *Server) doFetch(w http.ResponseWriter, r *http.Request) (string, int) {
 		DB:           s.db,
 		Cache:        s.cache,
 		loadShedder:  s.loadShedder,
+		GitRepos:     s.cfg.GitRepos,
+		Verifier:     s.verifier,
+		LoadOptions:  s.loadOptions,
 	}
 	if r.FormValue(queue.DisableProxyFetchParam) == queue.DisableProxyFetchValue {
 		f.ProxyClient = f.ProxyClient.WithFetchDisabled()
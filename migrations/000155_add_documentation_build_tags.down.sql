@@ -0,0 +1,15 @@
+-- Copyright 2024 The Go Authors. All rights reserved.
+-- Use of this source code is governed by a BSD-style
+-- license that can be found in the LICENSE file.
+
+BEGIN;
+
+DELETE FROM documentation WHERE build_tags != '';
+
+ALTER TABLE documentation DROP CONSTRAINT documentation_unit_id_goos_goarch_build_tags_key;
+
+ALTER TABLE documentation ADD CONSTRAINT documentation_big_unit_id_goos_goarch_key UNIQUE (unit_id, goos, goarch);
+
+ALTER TABLE documentation DROP COLUMN build_tags;
+
+END;
@@ -0,0 +1,16 @@
+-- Copyright 2024 The Go Authors. All rights reserved.
+-- Use of this source code is governed by a BSD-style
+-- license that can be found in the LICENSE file.
+
+BEGIN;
+
+ALTER TABLE documentation ADD COLUMN build_tags TEXT NOT NULL DEFAULT '';
+
+COMMENT ON COLUMN documentation.build_tags IS
+'COLUMN build_tags is the sorted, comma-separated list of build tags, in addition to goos and goarch, used to load the package. It is empty for most rows.';
+
+ALTER TABLE documentation DROP CONSTRAINT documentation_big_unit_id_goos_goarch_key;
+
+ALTER TABLE documentation ADD UNIQUE (unit_id, goos, goarch, build_tags);
+
+END;
@@ -0,0 +1,5 @@
+-- Copyright 2024 The Go Authors. All rights reserved.
+-- Use of this source code is governed by a BSD-style
+-- license that can be found in the LICENSE file.
+
+-- Values can't be removed from an ENUM type, so there is nothing to undo.
@@ -0,0 +1,6 @@
+-- Copyright 2024 The Go Authors. All rights reserved.
+-- Use of this source code is governed by a BSD-style
+-- license that can be found in the LICENSE file.
+
+ALTER TYPE goos ADD VALUE IF NOT EXISTS 'ios';
+ALTER TYPE goos ADD VALUE IF NOT EXISTS 'wasip1';
@@ -0,0 +1,12 @@
+-- Copyright 2024 The Go Authors. All rights reserved.
+-- Use of this source code is governed by a BSD-style
+-- license that can be found in the LICENSE file.
+
+BEGIN;
+
+DROP TABLE module_retracts;
+DROP TABLE module_excludes;
+DROP TABLE module_replaces;
+DROP TABLE module_requires;
+
+END;
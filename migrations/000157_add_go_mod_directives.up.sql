@@ -0,0 +1,57 @@
+-- Copyright 2024 The Go Authors. All rights reserved.
+-- Use of this source code is governed by a BSD-style
+-- license that can be found in the LICENSE file.
+
+BEGIN;
+
+CREATE TABLE module_requires (
+    module_id INTEGER NOT NULL REFERENCES modules(id) ON DELETE CASCADE,
+    required_path TEXT NOT NULL,
+    required_version TEXT NOT NULL,
+    indirect BOOLEAN NOT NULL,
+    PRIMARY KEY (module_id, required_path)
+);
+
+COMMENT ON TABLE module_requires IS
+'TABLE module_requires contains the require directives of the go.mod file of a module version.
+The module version represented by module_id requires required_path at required_version.';
+
+CREATE INDEX idx_module_requires_required_path_version ON
+    module_requires USING btree (required_path, required_version);
+
+CREATE TABLE module_replaces (
+    module_id INTEGER NOT NULL REFERENCES modules(id) ON DELETE CASCADE,
+    old_path TEXT NOT NULL,
+    old_version TEXT NOT NULL,
+    new_path TEXT NOT NULL,
+    new_version TEXT NOT NULL,
+    PRIMARY KEY (module_id, old_path, old_version)
+);
+
+COMMENT ON TABLE module_replaces IS
+'TABLE module_replaces contains the replace directives of the go.mod file of a module version.
+old_version is empty if every version of old_path is replaced, and new_version is empty if new_path is a directory.';
+
+CREATE TABLE module_excludes (
+    module_id INTEGER NOT NULL REFERENCES modules(id) ON DELETE CASCADE,
+    excluded_path TEXT NOT NULL,
+    excluded_version TEXT NOT NULL,
+    PRIMARY KEY (module_id, excluded_path, excluded_version)
+);
+
+COMMENT ON TABLE module_excludes IS
+'TABLE module_excludes contains the exclude directives of the go.mod file of a module version.';
+
+CREATE TABLE module_retracts (
+    module_id INTEGER NOT NULL REFERENCES modules(id) ON DELETE CASCADE,
+    low TEXT NOT NULL,
+    high TEXT NOT NULL,
+    rationale TEXT NOT NULL,
+    PRIMARY KEY (module_id, low, high)
+);
+
+COMMENT ON TABLE module_retracts IS
+'TABLE module_retracts contains the retract directives of the go.mod file of a module version.
+A single retracted version has equal low and high.';
+
+END;
@@ -0,0 +1,12 @@
+-- Copyright 2024 The Go Authors. All rights reserved.
+-- Use of this source code is governed by a BSD-style
+-- license that can be found in the LICENSE file.
+
+BEGIN;
+
+ALTER TABLE modules ADD COLUMN go_sum TEXT[];
+
+COMMENT ON COLUMN modules.go_sum IS
+'COLUMN go_sum holds the go.sum lines for the module zip and go.mod file, as recorded in the checksum database the module was verified against. It is NULL if the module was not verified.';
+
+END;
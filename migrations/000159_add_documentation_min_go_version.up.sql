@@ -0,0 +1,12 @@
+-- Copyright 2024 The Go Authors. All rights reserved.
+-- Use of this source code is governed by a BSD-style
+-- license that can be found in the LICENSE file.
+
+BEGIN;
+
+ALTER TABLE documentation ADD COLUMN min_go_version TEXT NOT NULL DEFAULT '';
+
+COMMENT ON COLUMN documentation.min_go_version IS
+'COLUMN min_go_version is the earliest Go release, like "go1.21", whose standard library has every symbol the package uses for this build context. It is empty if unknown or if the package only uses symbols from Go 1.0.';
+
+END;
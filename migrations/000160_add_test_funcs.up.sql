@@ -0,0 +1,20 @@
+-- Copyright 2024 The Go Authors. All rights reserved.
+-- Use of this source code is governed by a BSD-style
+-- license that can be found in the LICENSE file.
+
+BEGIN;
+
+CREATE TABLE test_funcs (
+    unit_id INTEGER NOT NULL REFERENCES units(id) ON DELETE CASCADE,
+    filename TEXT NOT NULL,
+    name TEXT NOT NULL,
+    kind TEXT NOT NULL,
+    corpus_size INTEGER NOT NULL,
+    PRIMARY KEY (unit_id, filename, name)
+);
+
+COMMENT ON TABLE test_funcs IS
+'TABLE test_funcs contains the tests, benchmarks and fuzz targets declared in the _test.go files of a package.
+kind is one of "test", "benchmark" or "fuzz", and corpus_size is the number of files in the seed corpus of a fuzz target.';
+
+END;
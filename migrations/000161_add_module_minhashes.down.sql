@@ -0,0 +1,12 @@
+-- Copyright 2024 The Go Authors. All rights reserved.
+-- Use of this source code is governed by a BSD-style
+-- license that can be found in the LICENSE file.
+
+BEGIN;
+
+ALTER TABLE search_documents DROP COLUMN near_duplicate_of;
+ALTER TABLE modules DROP COLUMN near_duplicate_of;
+DROP TABLE module_minhash_bands;
+DROP TABLE module_minhashes;
+
+END;
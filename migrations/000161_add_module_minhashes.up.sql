@@ -0,0 +1,37 @@
+-- Copyright 2024 The Go Authors. All rights reserved.
+-- Use of this source code is governed by a BSD-style
+-- license that can be found in the LICENSE file.
+
+BEGIN;
+
+CREATE TABLE module_minhashes (
+    module_id INTEGER PRIMARY KEY REFERENCES modules(id) ON DELETE CASCADE,
+    signature BIGINT[] NOT NULL
+);
+
+COMMENT ON TABLE module_minhashes IS
+'TABLE module_minhashes contains the MinHash signature of the contents of the .go files of a module version, as computed by internal/minhash.';
+
+CREATE TABLE module_minhash_bands (
+    band SMALLINT NOT NULL,
+    hash BIGINT NOT NULL,
+    module_id INTEGER NOT NULL REFERENCES modules(id) ON DELETE CASCADE,
+    PRIMARY KEY (band, hash, module_id)
+);
+
+COMMENT ON TABLE module_minhash_bands IS
+'TABLE module_minhash_bands is an index of the rows of module_minhashes. Each signature is split into bands, and module versions with the same hash for a band are likely to have similar contents.';
+
+CREATE INDEX idx_module_minhash_bands_module_id ON module_minhash_bands(module_id);
+
+ALTER TABLE modules ADD COLUMN near_duplicate_of TEXT;
+
+COMMENT ON COLUMN modules.near_duplicate_of IS
+'COLUMN near_duplicate_of is the path of an older module whose contents are nearly the same as those of this module version, which is likely to be a fork or a copy of it. It is NULL if there is no such module.';
+
+ALTER TABLE search_documents ADD COLUMN near_duplicate_of TEXT;
+
+COMMENT ON COLUMN search_documents.near_duplicate_of IS
+'COLUMN near_duplicate_of is modules.near_duplicate_of for the module version of the document. Near duplicates are ranked lower in search.';
+
+END;
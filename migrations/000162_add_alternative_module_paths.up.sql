@@ -0,0 +1,35 @@
+-- Copyright 2024 The Go Authors. All rights reserved.
+-- Use of this source code is governed by a BSD-style
+-- license that can be found in the LICENSE file.
+
+BEGIN;
+
+CREATE TABLE alternative_module_paths (
+    module_path TEXT PRIMARY KEY,
+    alternative TEXT NOT NULL,
+    source TEXT NOT NULL CHECK (source IN ('admin', 'go.mod', 'deprecated')),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+COMMENT ON TABLE alternative_module_paths IS
+'TABLE alternative_module_paths maps the series paths of modules that are known to be forks or copies of other modules to the paths of those modules.';
+
+COMMENT ON COLUMN alternative_module_paths.source IS
+'COLUMN source says how the alternative was learned: ''admin'' if it was added through the worker, ''go.mod'' if a module version was served under a path that differs from the one in its go.mod file, and ''deprecated'' if the go.mod file has a "Deprecated: use X" comment.';
+
+-- These entries were previously compiled into internal/fetch.
+INSERT INTO alternative_module_paths (module_path, alternative, source) VALUES
+    ('github.com/Azure/Azure-sdk-for-go', 'github.com/Azure/azure-sdk-for-go', 'admin'),
+    ('github.com/azure/azure-sdk-for-go', 'github.com/Azure/azure-sdk-for-go', 'admin'),
+    ('github.com/evenh/azure-sdk-for-go', 'github.com/Azure/azure-sdk-for-go', 'admin'),
+    ('github.com/msopentech/azure-sdk-for-go', 'github.com/Azure/azure-sdk-for-go', 'admin'),
+    ('github.com/MSOpenTech/azure-sdk-for-go', 'github.com/Azure/azure-sdk-for-go', 'admin'),
+    ('github.com/scott-the-programmer/azure-sdk-for-go', 'github.com/Azure/azure-sdk-for-go', 'admin'),
+    ('gopkg.in/Azure/azure-sdk-for-go', 'github.com/Azure/azure-sdk-for-go', 'admin'),
+    ('gopkg.in/azure/azure-sdk-for-go', 'github.com/Azure/azure-sdk-for-go', 'admin'),
+    ('github.com/masslessparticle/azure-sdk-for-go', 'github.com/Azure/azure-sdk-for-go', 'admin'),
+    ('github.com/aliyun/alibaba-cloud-sdk-go', 'github.com/Azure/azure-sdk-for-go', 'admin'),
+    ('github.com/johnstairs/azure-sdk-for-go', 'github.com/Azure/azure-sdk-for-go', 'admin'),
+    ('github.com/shopify/sarama', 'github.com/Shopify/sarama', 'admin');
+
+END;
@@ -0,0 +1,20 @@
+-- Copyright 2024 The Go Authors. All rights reserved.
+-- Use of this source code is governed by a BSD-style
+-- license that can be found in the LICENSE file.
+
+BEGIN;
+
+CREATE TABLE symbol_references_unique (
+    from_path TEXT NOT NULL,
+    from_module_path TEXT NOT NULL,
+    to_path TEXT NOT NULL,
+    symbol TEXT NOT NULL,
+    PRIMARY KEY (to_path, symbol, from_path, from_module_path)
+);
+
+CREATE INDEX idx_symbol_references_unique_from_module_path ON symbol_references_unique (from_module_path);
+
+COMMENT ON TABLE symbol_references_unique IS
+'TABLE symbol_references_unique records that package from_path in the latest version of from_module_path refers to the exported package-level symbol named symbol in (some version of) to_path. Like imports_unique, it is used to compute the importers of a symbol. References to the standard library are not recorded.';
+
+END;
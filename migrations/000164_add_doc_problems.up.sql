@@ -0,0 +1,21 @@
+-- Copyright 2024 The Go Authors. All rights reserved.
+-- Use of this source code is governed by a BSD-style
+-- license that can be found in the LICENSE file.
+
+BEGIN;
+
+CREATE TABLE doc_problems (
+    unit_id INTEGER NOT NULL REFERENCES units(id) ON DELETE CASCADE,
+    kind TEXT NOT NULL,
+    symbol TEXT NOT NULL,
+    filename TEXT NOT NULL,
+    line INTEGER NOT NULL,
+    message TEXT NOT NULL,
+    PRIMARY KEY (unit_id, filename, line, message)
+);
+
+COMMENT ON TABLE doc_problems IS
+'TABLE doc_problems contains the problems found in the documentation of a package when it was fetched.
+kind is one of "missing", "prefix", "link" or "example"; symbol is empty for problems with the package comment.';
+
+END;
@@ -0,0 +1,17 @@
+-- Copyright 2024 The Go Authors. All rights reserved.
+-- Use of this source code is governed by a BSD-style
+-- license that can be found in the LICENSE file.
+
+BEGIN;
+
+ALTER TABLE documentation
+    ADD COLUMN renderer_version TEXT NOT NULL DEFAULT '',
+    ADD COLUMN rendered BYTEA;
+
+COMMENT ON COLUMN documentation.renderer_version IS
+'COLUMN renderer_version identifies the renderer that produced the rendered column, as in godoc.RendererVersion. It is empty if the documentation was not rendered when it was fetched.';
+
+COMMENT ON COLUMN documentation.rendered IS
+'COLUMN rendered holds the documentation rendered to HTML when it was fetched, as a JSON-encoded internal.RenderedDoc. The frontend serves it instead of rendering the source column when renderer_version is current.';
+
+END;
@@ -0,0 +1,10 @@
+-- Copyright 2024 The Go Authors. All rights reserved.
+-- Use of this source code is governed by a BSD-style
+-- license that can be found in the LICENSE file.
+
+BEGIN;
+
+COMMENT ON TABLE symbol_references_unique IS
+'TABLE symbol_references_unique records that package from_path in the latest version of from_module_path refers to the exported symbol named symbol in (some version of) to_path. The symbol is a package-level name, or a type name and the name of a method or field joined by a dot. Like imports_unique, it is used to compute the importers of a symbol. References to the standard library are not recorded.';
+
+END;
@@ -57,6 +57,7 @@ window.addEventListener('load', () => {
   initModals();
   initJumpLinks();
   registerCookieNotice();
+  registerLiveReload();
 });
 
 // Pressing '/' focuses the search box
@@ -156,3 +157,25 @@ function registerCookieNotice() {
     });
   }
 }
+
+/**
+ * registerLiveReload reloads the page when the server reports that the content
+ * it serves has changed. It is only enabled by cmd/pkgsite, when watching local
+ * modules for edits.
+ */
+function registerLiveReload() {
+  if (document.documentElement.dataset['liveReload'] !== 'true' || !window.EventSource) {
+    return;
+  }
+  let generation: string | undefined;
+  const events = new EventSource('/_reload');
+  events.addEventListener('reload', e => {
+    const data = (e as MessageEvent).data;
+    if (generation === undefined) {
+      generation = data;
+    } else if (data !== generation) {
+      events.close();
+      window.location.reload();
+    }
+  });
+}
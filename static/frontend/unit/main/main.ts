@@ -139,6 +139,6 @@ window.addEventListener('hashchange', () => openDeprecatedSymbol());
  */
 document.querySelectorAll('.js-buildContextSelect').forEach(el => {
   el.addEventListener('change', e => {
-    window.location.search = `?GOOS=${(e.target as HTMLSelectElement).value}`;
+    window.location.search = `?${(e.target as HTMLSelectElement).value}`;
   });
 });
@@ -109,6 +109,16 @@ require example v1
     expect(window.open).toHaveBeenCalledWith('https://play.golang.org/p/abcdefg');
   });
 
+  it('opens a local share after pressing share', async () => {
+    mocked(window.fetch).mockResolvedValue({
+      text: () => Promise.resolve('/play/p/abcdefg'),
+    } as Response);
+    el('[aria-label="Share Code"]').click();
+    await flushPromises();
+
+    expect(window.open).toHaveBeenCalledWith('/play/p/abcdefg');
+  });
+
   it('replaces textarea with formated code after pressing format', async () => {
     mocked(window.fetch).mockResolvedValue({
       json: () =>
@@ -166,6 +176,7 @@ require example v1
 
     expect(window.fetch).toHaveBeenCalledWith('/play/compile', {
       body: JSON.stringify({ body: snippetWithModeFile, version: 2 }),
+      headers: { 'Content-Type': 'application/json' },
       method: 'POST',
     });
     expect(el('.Documentation-exampleOutput').textContent).toContain(
@@ -186,6 +197,7 @@ require example v1
 
     expect(window.fetch).toHaveBeenCalledWith('/play/compile', {
       body: JSON.stringify({ body: snippetWithModeFile, version: 2 }),
+      headers: { 'Content-Type': 'application/json' },
       method: 'POST',
     });
     expect(el('.Documentation-exampleOutput').textContent).toContain('// mocked error');
@@ -204,6 +216,7 @@ require example v1
 
     expect(window.fetch).toHaveBeenCalledWith('/play/compile', {
       body: JSON.stringify({ body: snippetWithModeFile, version: 2 }),
+      headers: { 'Content-Type': 'application/json' },
       method: 'POST',
     });
     expect(el('.Documentation-exampleOutput').innerHTML).toBe('&lt;input required&gt;');
@@ -228,6 +241,7 @@ require example v1
 
     expect(window.fetch).toHaveBeenCalledWith('/play/compile', {
       body: JSON.stringify({ body: codeSnippet, version: 2 }),
+      headers: { 'Content-Type': 'application/json' },
       method: 'POST',
     });
     expect(el('.Documentation-exampleOutput').innerHTML).toBe('&lt;input required&gt;');
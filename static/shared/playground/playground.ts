@@ -225,7 +225,8 @@ require ${moduleVars.modulepath} ${moduleVars.version}
     })
       .then(res => res.text())
       .then(shareId => {
-        const href = PLAYGROUND_BASE_URL + shareId;
+        // A local playground responds with the path of the shared snippet.
+        const href = shareId.startsWith('/') ? shareId : PLAYGROUND_BASE_URL + shareId;
         this.setOutputHTML(`<a href="${href}">${href}</a>`);
         window.open(href);
       })
@@ -267,6 +268,7 @@ require ${moduleVars.modulepath} ${moduleVars.version}
 
     fetch('/play/compile', {
       method: 'POST',
+      headers: { 'Content-Type': 'application/json' },
       body: JSON.stringify({ body: this.getCodeWithModFile(), version: 2 }),
     })
       .then(res => res.json())